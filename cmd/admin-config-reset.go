@@ -137,6 +137,10 @@ func mainAdminConfigReset(ctx *cli.Context) error {
 		}
 	}
 
+	if skipDryRun(dryRunMessage{Action: "reset config", Target: aliasedURL, Value: input}) {
+		return nil
+	}
+
 	// Call reset config API
 	restart, e := client.DelConfigKV(globalContext, input)
 	fatalIf(probe.NewError(e), "Unable to reset '%s' on the server", input)
@@ -125,6 +125,10 @@ func mainAdminConfigSet(ctx *cli.Context) error {
 
 	}
 
+	if skipDryRun(dryRunMessage{Action: "set config", Target: aliasedURL, Value: input}) {
+		return nil
+	}
+
 	// Call set config API
 	restart, e := client.SetConfigKV(globalContext, input)
 	fatalIf(probe.NewError(e), "Unable to set '%s' to server", input)
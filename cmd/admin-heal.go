@@ -52,7 +52,7 @@ var adminHealFlags = []cli.Flag{
 		Hidden: true,
 	},
 	cli.BoolFlag{
-		Name:   "dry-run, n",
+		Name:   "n",
 		Usage:  "only inspect data, but do not mutate",
 		Hidden: true,
 	},
@@ -664,7 +664,7 @@ func mainAdminHeal(ctx *cli.Context) error {
 		ScanMode:  transformScanArg(ctx.String("scan")),
 		Remove:    ctx.Bool("remove"),
 		Recursive: ctx.Bool("recursive"),
-		DryRun:    ctx.Bool("dry-run"),
+		DryRun:    globalDryRun || ctx.Bool("n"),
 		Recreate:  ctx.Bool("rewrite"),
 	}
 
@@ -33,20 +33,13 @@ import (
 	"github.com/minio/pkg/v2/console"
 )
 
-var serviceRestartFlag = []cli.Flag{
-	cli.BoolFlag{
-		Name:  "dry-run",
-		Usage: "do not attempt a restart, however verify the peer status",
-	},
-}
-
 var adminServiceRestartCmd = cli.Command{
 	Name:         "restart",
 	Usage:        "restart a MinIO cluster",
 	Action:       mainAdminServiceRestart,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(serviceRestartFlag, globalFlags...),
+	Flags:        globalFlags,
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -162,7 +155,7 @@ func mainAdminServiceRestart(ctx *cli.Context) error {
 	// Restart the specified MinIO server
 	result, e := client.ServiceAction(ctxt, madmin.ServiceActionOpts{
 		Action: madmin.ServiceActionRestart,
-		DryRun: ctx.Bool("dry-run"),
+		DryRun: globalDryRun,
 	})
 	if e != nil {
 		// Attempt an older API server might be old
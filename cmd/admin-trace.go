@@ -666,6 +666,11 @@ func shortTrace(ti madmin.ServiceTraceInfo) shortTraceMsg {
 	return s
 }
 
+// jsonHost keeps the host of the traced node, not the alias.
+func (s shortTraceMsg) jsonHost() (string, bool) {
+	return "", true
+}
+
 func (s shortTraceMsg) JSON() string {
 	s.Status = "success"
 	buf := &bytes.Buffer{}
@@ -752,6 +757,11 @@ func colorizedNodeName(nodeName string) string {
 	return console.Colorize(fmt.Sprintf("Node%d", colors[idx]), nodeName)
 }
 
+// jsonHost keeps the host of the traced node, not the alias.
+func (t traceMessage) jsonHost() (string, bool) {
+	return "", true
+}
+
 func (t traceMessage) JSON() string {
 	trc := verboseTrace{
 		trcType:    t.Trace.TraceType,
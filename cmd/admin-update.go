@@ -147,7 +147,7 @@ func mainAdminServerUpdate(ctx *cli.Context) error {
 	// Update the specified MinIO server, optionally also
 	// with the provided update URL.
 	us, e := client.ServerUpdateV2(globalContext, madmin.ServerUpdateOpts{
-		DryRun:    ctx.Bool("dry-run"),
+		DryRun:    globalDryRun,
 		UpdateURL: updateURL,
 	})
 	fatalIf(probe.NewError(e), "Unable to update the server.")
@@ -74,6 +74,11 @@ func mainAliasRemove(ctx *cli.Context) error {
 	args := ctx.Args()
 	alias := args.Get(0)
 
+	if skipDryRun(dryRunMessage{Action: "remove alias", Target: alias}) {
+		aliasMustExist(alias)
+		return nil
+	}
+
 	aliasMsg := removeAlias(alias) // Remove an alias
 	aliasMsg.op = "remove"
 	printMsg(aliasMsg)
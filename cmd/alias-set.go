@@ -191,7 +191,9 @@ func probeS3Signature(ctx context.Context, accessKey, secretKey, url string, pee
 		ConnReadDeadline:  globalConnReadDeadline,
 		ConnWriteDeadline: globalConnWriteDeadline,
 		UploadLimit:       int64(globalLimitUpload),
+		UploadLimitShared: int64(globalLimitUploadShared),
 		DownloadLimit:     int64(globalLimitDownload),
+		LimitBurst:        int64(globalLimitBurst),
 	}
 	if peerCert != nil {
 		configurePeerCertificate(s3Config, peerCert)
@@ -342,6 +344,10 @@ func mainAliasSet(cli *cli.Context, deprecated bool) error {
 	s3Config, err := BuildS3Config(ctx, alias, url, accessKey, secretKey, api, path, peerCert)
 	fatalIf(err.Trace(alias, url, accessKey), "Unable to initialize new alias from the provided credentials.")
 
+	if skipDryRun(dryRunMessage{Action: "set alias", Target: alias}) {
+		return nil
+	}
+
 	msg := setAlias(alias, aliasConfigV10{
 		URL:       s3Config.HostURL,
 		AccessKey: s3Config.AccessKey,
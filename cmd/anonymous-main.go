@@ -437,6 +437,9 @@ func runAnonymousCmd(args cli.Args) {
 			fatalIf(errDummy().Trace(), "Invalid access permission: `"+string(perms)+"`.")
 		}
 		targetURL = args.Get(2)
+		if skipDryRun(dryRunMessage{Action: "set anonymous access", Target: targetURL, Value: string(perms)}) {
+			return
+		}
 		probeErr = doSetAccess(ctx, targetURL, perms)
 		if probeErr == nil {
 			perms, _, probeErr = doGetAccess(ctx, targetURL)
@@ -447,6 +450,9 @@ func runAnonymousCmd(args cli.Args) {
 			fatalIf(errDummy().Trace(), "Invalid access file: `"+string(perms)+"`.")
 		}
 		targetURL = args.Get(2)
+		if skipDryRun(dryRunMessage{Action: "set anonymous policy", Target: targetURL, Value: string(perms)}) {
+			return
+		}
 		probeErr = doSetAccessJSON(ctx, targetURL, perms)
 	case "get", "get-json":
 		targetURL = args.Get(1)
@@ -262,6 +262,10 @@ var completeCmds = map[string]complete.Predictor{
 	"/event/list":   s3Complete{deepLevel: 2},
 	"/event/remove": s3Complete{deepLevel: 2},
 
+	"/resume/list":  nil,
+	"/resume/gc":    s3Completer,
+	"/resume/clean": nil,
+
 	"/encrypt/set":   s3Complete{deepLevel: 2},
 	"/encrypt/info":  s3Complete{deepLevel: 2},
 	"/encrypt/clear": s3Complete{deepLevel: 2},
@@ -20,6 +20,9 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -55,6 +58,26 @@ var catFlags = []cli.Flag{
 		Name:  "tail",
 		Usage: "tail number of bytes at ending of file",
 	},
+	cli.Int64Flag{
+		Name:  "tail-lines",
+		Usage: "display only the last N lines of a text object",
+	},
+	cli.BoolFlag{
+		Name:  "number, n",
+		Usage: "number all output lines",
+	},
+	cli.BoolFlag{
+		Name:  "reassemble",
+		Usage: "reassemble an object copied with 'mc cp --split' from its manifest, verifying each part",
+	},
+	cli.BoolFlag{
+		Name:  "pager",
+		Usage: "page the output with $PAGER in a terminal even if it does not look like text",
+	},
+	cli.BoolFlag{
+		Name:  "no-pager",
+		Usage: "do not page the output with $PAGER, text is paged by default in a terminal",
+	},
 }
 
 // Display contents of a file.
@@ -76,6 +99,7 @@ FLAGS:
   {{end}}
 ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  PAGER:           pager for the output in a terminal, defaults to "less -R"
 
 EXAMPLES:
   1. Stream an object from Amazon S3 cloud storage to mplayer standard input.
@@ -99,6 +123,15 @@ EXAMPLES:
 
   7. Display the content of a particular object version
      {{.Prompt}} {{.HelpName}} --vid "3ddac055-89a7-40fa-8cd3-530a5581b6b8" play/my-bucket/my-object
+
+  8. Reassemble an object copied with 'mc cp --split' from its parts into a local file.
+     {{.Prompt}} {{.HelpName}} --reassemble gateway/images/disk.img.manifest > disk.img
+
+  9. Display the last 100 lines of a large log object with line numbers, without downloading all of it.
+     {{.Prompt}} {{.HelpName}} --tail-lines 100 --number play/logs/server.log
+
+  10. Display a large configuration object without paging it, as when piping the output.
+     {{.Prompt}} {{.HelpName}} --no-pager play/configs/nginx.conf
 `,
 }
 
@@ -109,6 +142,35 @@ func checkCatSyntax(ctx *cli.Context) {
 	}
 }
 
+// lineNumberWriter prefixes every line written to it with its
+// number, numbering continues across writes.
+type lineNumberWriter struct {
+	writer io.Writer
+	line   int64
+	inLine bool
+}
+
+// Write writes input with line numbers, the returned length
+// does not count the line numbers.
+func (l *lineNumberWriter) Write(input []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, b := range input {
+		if !l.inLine {
+			l.line++
+			fmt.Fprintf(&buf, "%6d\t", l.line)
+			l.inLine = true
+		}
+		buf.WriteByte(b)
+		if b == '\n' {
+			l.inLine = false
+		}
+	}
+	if _, e := buf.WriteTo(l.writer); e != nil {
+		return 0, e
+	}
+	return len(input), nil
+}
+
 // prettyStdout replaces some non printable characters
 // with <hex> format to be better viewable by the user
 type prettyStdout struct {
@@ -159,13 +221,17 @@ func (s prettyStdout) Write(input []byte) (int, error) {
 }
 
 type catOpts struct {
-	args      []string
-	versionID string
-	timeRef   time.Time
-	startO    int64
-	tailO     int64
-	isZip     bool
-	stdinMode bool
+	args       []string
+	versionID  string
+	timeRef    time.Time
+	startO     int64
+	tailO      int64
+	isZip      bool
+	stdinMode  bool
+	reassemble bool
+	tailLines  int64
+	stdout     io.Writer
+	pager      *catPager
 }
 
 // parseCatSyntax performs command-line input validation for cat command.
@@ -211,6 +277,31 @@ func parseCatSyntax(ctx *cli.Context) catOpts {
 	if o.stdinMode && (o.isZip || o.startO != 0 || o.tailO != 0) {
 		fatalIf(errInvalidArgument().Trace(), "You cannot use --zip --tail or --offset with stdin")
 	}
+	o.tailLines = ctx.Int64("tail-lines")
+	if o.tailLines < 0 {
+		fatalIf(errInvalidArgument().Trace(), "You cannot specify negative --tail-lines")
+	}
+	if o.tailLines > 0 && (o.isZip || o.startO != 0 || o.tailO != 0 || o.stdinMode) {
+		fatalIf(errInvalidArgument().Trace(), "You cannot combine --tail-lines with --zip, --tail, --offset or stdin")
+	}
+
+	o.stdout = newCatStdout()
+	if ctx.Bool("pager") && ctx.Bool("no-pager") {
+		fatalIf(errInvalidArgument().Trace(), "You cannot specify both --pager and --no-pager")
+	}
+	// Piping to something else than a terminal never pages.
+	if isTerminal() && !ctx.Bool("no-pager") {
+		o.pager = newCatPager(ctx.Bool("pager"))
+		o.stdout = newPrettyStdout(o.pager)
+	}
+	if ctx.Bool("number") {
+		o.stdout = &lineNumberWriter{writer: o.stdout}
+	}
+
+	o.reassemble = ctx.Bool("reassemble")
+	if o.reassemble && (o.isZip || o.startO != 0 || o.tailO != 0 || o.tailLines != 0 || o.versionID != "" || !o.timeRef.IsZero()) {
+		fatalIf(errInvalidArgument().Trace(), "You cannot combine --reassemble with --zip, --tail, --tail-lines, --offset, --version-id or --rewind")
+	}
 
 	return o
 }
@@ -242,6 +333,11 @@ func catURL(ctx context.Context, sourceURL string, encKeyDB map[string][]prefixS
 					o.startO = 0
 				}
 			}
+			if o.tailLines > 0 && content.Size > 0 {
+				if o.startO, err = tailLinesOffset(ctx, sourceURL, versionID, encKeyDB, content.Size, o.tailLines); err != nil {
+					return err.Trace(sourceURL)
+				}
+			}
 
 			if client.GetURL().Type == objectStorage {
 				size = content.Size - o.startO
@@ -263,25 +359,119 @@ func catURL(ctx context.Context, sourceURL string, encKeyDB map[string][]prefixS
 		}
 		defer reader.Close()
 	}
-	return catOut(reader, size).Trace(sourceURL)
+	return catOutTo(o.stdout, o.pager.sniff(reader), size).Trace(sourceURL)
+}
+
+// tailLinesChunkSize is the size of the ranged reads used to
+// find the last lines of an object.
+const tailLinesChunkSize = 64 * 1024
+
+// tailLinesOffset returns the offset at which the last n lines of an
+// object of the given size start, reading chunks backwards from its
+// end. A newline ending the object does not start a new line.
+func tailLinesOffset(ctx context.Context, sourceURL, versionID string, encKeyDB map[string][]prefixSSEPair, size, n int64) (int64, *probe.Error) {
+	var newlines int64
+	for end := size; end > 0; {
+		start := end - tailLinesChunkSize
+		if start < 0 {
+			start = 0
+		}
+		reader, err := getSourceStreamFromURL(ctx, sourceURL, encKeyDB, getSourceOpts{
+			GetOptions: GetOptions{VersionID: versionID, RangeStart: start, RangeLength: end - start},
+		})
+		if err != nil {
+			return 0, err.Trace(sourceURL)
+		}
+		chunk, e := io.ReadAll(io.LimitReader(reader, end-start))
+		reader.Close()
+		if e != nil {
+			return 0, probe.NewError(e).Trace(sourceURL)
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+			if newlines++; newlines == n {
+				return start + int64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// catSplitManifest writes the object described by a manifest of
+// 'cp --split' to stdout, part after part. The size and SHA-256 sum of
+// each part are verified once it has been written.
+func catSplitManifest(ctx context.Context, manifestURL string, encKeyDB map[string][]prefixSSEPair, o catOpts) *probe.Error {
+	if !strings.HasSuffix(manifestURL, splitManifestSuffix) {
+		return errInvalidArgument().Trace(manifestURL)
+	}
+	reader, err := getSourceStreamFromURL(ctx, manifestURL, encKeyDB, getSourceOpts{})
+	if err != nil {
+		return err.Trace(manifestURL)
+	}
+	var manifest splitManifest
+	e := json.NewDecoder(reader).Decode(&manifest)
+	reader.Close()
+	if e != nil {
+		return probe.NewError(e).Trace(manifestURL)
+	}
+
+	keyURL := strings.TrimSuffix(manifestURL, splitManifestSuffix)
+	if manifest.Version != 1 || manifest.Key == "" || !strings.HasSuffix(keyURL, manifest.Key) {
+		return probe.NewError(fmt.Errorf("`%s` is not a manifest written by 'mc cp --split'", manifestURL))
+	}
+	prefix := strings.TrimSuffix(keyURL, manifest.Key)
+
+	var total int64
+	for _, part := range manifest.Parts {
+		partURL := prefix + part.Key
+		reader, err := getSourceStreamFromURL(ctx, partURL, encKeyDB, getSourceOpts{})
+		if err != nil {
+			return err.Trace(partURL)
+		}
+		hash := sha256.New()
+		err = catOutTo(o.stdout, io.TeeReader(o.pager.sniff(reader), hash), part.Size)
+		reader.Close()
+		if err != nil {
+			return err.Trace(partURL)
+		}
+		if sum := hex.EncodeToString(hash.Sum(nil)); sum != part.SHA256 {
+			return probe.NewError(fmt.Errorf("SHA-256 of `%s` is %s, manifest records %s", partURL, sum, part.SHA256))
+		}
+		total += part.Size
+	}
+	if total != manifest.Size {
+		return probe.NewError(UnexpectedEOF{
+			TotalSize:    manifest.Size,
+			TotalWritten: total,
+		}).Trace(manifestURL)
+	}
+	return nil
 }
 
 // catOut reads from reader stream and writes to stdout. Also check the length of the
 // read bytes against size parameter (if not -1) and return the appropriate error
 func catOut(r io.Reader, size int64) *probe.Error {
-	var n int64
-	var e error
-
-	var stdout io.Writer
+	return catOutTo(newCatStdout(), r, size)
+}
 
+// newCatStdout returns the writer for the output of cat.
+func newCatStdout() io.Writer {
 	// In case of a user showing the object content in a terminal,
 	// avoid printing control and other bad characters to avoid
 	// terminal session corruption
 	if isTerminal() {
-		stdout = newPrettyStdout(os.Stdout)
-	} else {
-		stdout = os.Stdout
+		return newPrettyStdout(os.Stdout)
 	}
+	return os.Stdout
+}
+
+// catOutTo is catOut writing to stdout.
+func catOutTo(stdout io.Writer, r io.Reader, size int64) *probe.Error {
+	var n int64
+	var e error
 
 	// Read till EOF.
 	if n, e = io.Copy(stdout, r); e != nil {
@@ -322,12 +512,13 @@ func mainCat(cliCtx *cli.Context) error {
 
 	// check 'cat' cli arguments.
 	o := parseCatSyntax(cliCtx)
+	defer o.pager.close()
 
 	// Set command flags from context.
 
 	// handle std input data.
 	if o.stdinMode {
-		fatalIf(catOut(os.Stdin, -1).Trace(), "Unable to read from standard input.")
+		fatalIf(catOutTo(o.stdout, o.pager.sniff(os.Stdin), -1).Trace(), "Unable to read from standard input.")
 		return nil
 	}
 
@@ -344,6 +535,10 @@ func mainCat(cliCtx *cli.Context) error {
 
 	// Convert arguments to URLs: expand alias, fix format.
 	for _, url := range o.args {
+		if o.reassemble {
+			fatalIf(catSplitManifest(ctx, url, encKeyDB, o).Trace(url), "Unable to reassemble `"+url+"`.")
+			continue
+		}
 		fatalIf(catURL(ctx, url, encKeyDB, o).Trace(url), "Unable to read from `"+url+"`.")
 	}
 
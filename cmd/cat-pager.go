@@ -0,0 +1,111 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"unicode/utf8"
+
+	"github.com/google/shlex"
+	"github.com/minio/pkg/v2/env"
+)
+
+// catSniffSize is the number of bytes looked at to tell text from binary content.
+const catSniffSize = 4096
+
+// defaultCatPager is used when $PAGER is not set.
+const defaultCatPager = "less -R"
+
+// catPager pipes the output of cat through $PAGER. Whether to page is
+// decided on the first content written, binary content is written to
+// stdout unless paging is forced.
+type catPager struct {
+	force   bool
+	decided bool
+	out     io.Writer
+	stdin   io.WriteCloser
+	cmd     *exec.Cmd
+}
+
+func newCatPager(force bool) *catPager {
+	return &catPager{force: force, out: os.Stdout}
+}
+
+// Write writes to the pager once started, to stdout otherwise.
+func (p *catPager) Write(b []byte) (int, error) {
+	return p.out.Write(b)
+}
+
+// sniff starts the pager if the first content read from r looks like
+// text, the returned reader must be read instead of r.
+func (p *catPager) sniff(r io.Reader) io.Reader {
+	if p == nil || p.decided {
+		return r
+	}
+	p.decided = true
+	br := bufio.NewReaderSize(r, catSniffSize)
+	head, _ := br.Peek(catSniffSize)
+	if p.force || looksLikeText(head) {
+		p.start()
+	}
+	return br
+}
+
+// start runs $PAGER, output goes to stdout when it cannot be started.
+func (p *catPager) start() {
+	args, e := shlex.Split(env.Get("PAGER", defaultCatPager))
+	if e != nil || len(args) == 0 {
+		return
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, e := cmd.StdinPipe()
+	if e != nil {
+		return
+	}
+	if e = cmd.Start(); e != nil {
+		return
+	}
+	p.cmd, p.stdin, p.out = cmd, stdin, stdin
+}
+
+// close ends the input of the pager and waits for the user to quit it.
+func (p *catPager) close() {
+	if p == nil || p.cmd == nil {
+		return
+	}
+	p.stdin.Close()
+	p.cmd.Wait()
+}
+
+// looksLikeText returns true for valid UTF-8 content without NUL bytes,
+// ignoring a character cut at the end of b.
+func looksLikeText(b []byte) bool {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return false
+	}
+	for i := 0; i < utf8.UTFMax-1 && len(b) > 0 && !utf8.Valid(b); i++ {
+		b = b[:len(b)-1]
+	}
+	return utf8.Valid(b)
+}
@@ -62,3 +62,46 @@ func TestPrettyStdout(t *testing.T) {
 		}
 	}
 }
+
+func TestLineNumberWriter(t *testing.T) {
+	testCases := []struct {
+		writes []string
+		output string
+	}{
+		{nil, ""},
+		{[]string{"a\nb\n"}, "     1\ta\n     2\tb\n"},
+		{[]string{"a", "b\nc"}, "     1\tab\n     2\tc"},
+		{[]string{"a\n", "\n", "b"}, "     1\ta\n     2\t\n     3\tb"},
+	}
+
+	for i, testCase := range testCases {
+		output := bytes.NewBuffer(nil)
+		w := &lineNumberWriter{writer: output}
+		for _, s := range testCase.writes {
+			if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+				t.Fatalf("Test %d: unexpected write result %d, %v", i+1, n, err)
+			}
+		}
+		if output.String() != testCase.output {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.output, output.String())
+		}
+	}
+}
+
+func TestLooksLikeText(t *testing.T) {
+	testCases := []struct {
+		input []byte
+		text  bool
+	}{
+		{[]byte("key = value\n"), true},
+		{[]byte{}, true},
+		{[]byte("caf\xc3"), true}, // a character cut by the sniffed size
+		{[]byte("\x89PNG\r\n\x1a\n\x00\x00"), false},
+		{[]byte("\xff\xfe\xfd plain"), false},
+	}
+	for i, testCase := range testCases {
+		if text := looksLikeText(testCase.input); text != testCase.text {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.text, text)
+		}
+	}
+}
@@ -18,6 +18,10 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/minio/cli"
@@ -47,3 +51,109 @@ func TestCLIOnUsageError(t *testing.T) {
 		checkOnUsageError(cmd, "")
 	}
 }
+
+func TestDryRunCommands(t *testing.T) {
+	leaves := make(map[string]bool)
+	var walk func([]cli.Command, string)
+	walk = func(commands []cli.Command, parent string) {
+		for _, cmd := range commands {
+			name := strings.TrimSpace(parent + " " + cmd.Name)
+			if len(cmd.Subcommands) > 0 {
+				walk(cmd.Subcommands, name)
+				continue
+			}
+			leaves[name] = true
+		}
+	}
+	walk(appCmds, "")
+	for name := range dryRunCommands {
+		if !leaves[name] {
+			t.Errorf("--dry-run command `%s` not found", name)
+		}
+	}
+
+	defer func(configDir string, dryRun, quiet bool) {
+		mcCustomConfigDir, globalDryRun, globalQuiet = configDir, dryRun, quiet
+	}(mcCustomConfigDir, globalDryRun, globalQuiet)
+	defer func(exiter func(int)) { cli.OsExiter = exiter }(cli.OsExiter)
+	var exitCode int
+	cli.OsExiter = func(code int) { exitCode = code }
+
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	if e := os.MkdirAll(filepath.Join(src, "dir"), 0o755); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.WriteFile(filepath.Join(src, "dir", "object"), []byte("content"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	run := func(args ...string) error {
+		args = append([]string{"mc", "--config-dir", filepath.Join(root, "config"), "--quiet"}, args...)
+		return registerApp("mc").Run(args)
+	}
+
+	// Commands honoring --dry-run make no change.
+	for _, args := range [][]string{
+		{"cp", "--dry-run", "--recursive", src + "/", filepath.Join(root, "cp") + "/"},
+		{"mirror", "--dry-run", src, filepath.Join(root, "mirror")},
+		{"mv", "--dry-run", "--recursive", src + "/", filepath.Join(root, "mv") + "/"},
+		{"rm", "--dry-run", "--recursive", "--force", src},
+	} {
+		if e := run(args...); e != nil {
+			t.Fatalf("%v: %v", args, e)
+		}
+	}
+	for _, name := range []string{"cp", "mirror", "mv"} {
+		if _, e := os.Stat(filepath.Join(root, name, "dir", "object")); !os.IsNotExist(e) {
+			t.Errorf("%s --dry-run copied the object", name)
+		}
+	}
+	if _, e := os.Stat(filepath.Join(src, "dir", "object")); e != nil {
+		t.Errorf("--dry-run removed the source: %v", e)
+	}
+
+	// The others fail without making their change.
+	if e := run("mb", "--dry-run", filepath.Join(root, "bucket")); e == nil || exitCode == 0 {
+		t.Error("expected mb --dry-run to fail")
+	}
+	if _, e := os.Stat(filepath.Join(root, "bucket")); !os.IsNotExist(e) {
+		t.Error("mb --dry-run created the bucket")
+	}
+}
+
+func TestParseJSONArrayFlag(t *testing.T) {
+	defer func(jsonArray bool) { globalJSONArray = jsonArray }(globalJSONArray)
+	testCases := []struct {
+		args      []string
+		expected  []string
+		jsonArray bool
+	}{
+		{[]string{"mc", "ls", "--json=array", "s3/"}, []string{"mc", "ls", "--json", "s3/"}, true},
+		{[]string{"mc", "-json=array", "ls"}, []string{"mc", "--json", "ls"}, true},
+		{[]string{"mc", "ls", "--json", "s3/"}, []string{"mc", "ls", "--json", "s3/"}, false},
+		// Arguments after "--" are not flags.
+		{[]string{"mc", "rm", "--", "--json=array"}, []string{"mc", "rm", "--", "--json=array"}, false},
+	}
+	for i, testCase := range testCases {
+		globalJSONArray = false
+		args := parseJSONArrayFlag(testCase.args)
+		if !reflect.DeepEqual(args, testCase.expected) || globalJSONArray != testCase.jsonArray {
+			t.Errorf("Test %d: expected %q and %v, got %q and %v", i+1, testCase.expected, testCase.jsonArray, args, globalJSONArray)
+		}
+	}
+}
+
+// Test the --json=array output is closed by the exit hooks, which run
+// on fatal errors and signals.
+func TestJSONArrayExitHook(t *testing.T) {
+	defer func() {
+		jsonArray.opened, jsonArray.closed = false, false
+	}()
+	remove := atExit(closeJSONArray)
+	defer remove()
+	printJSONArrayElement(`{"status":"success"}`)
+	runExitHooks()
+	if !jsonArray.closed {
+		t.Error("expected the exit hooks to close the array")
+	}
+}
@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -101,6 +102,30 @@ func (e ObjectAlreadyExistsAsDirectory) Error() string {
 	return "Object `" + e.Object + "` already exists as directory."
 }
 
+// PathTypeConflict - a file and a directory of the same path, e.g. an S3
+// key which is also the prefix of other keys copied to a filesystem.
+type PathTypeConflict struct {
+	Path  string
+	IsDir bool // a directory exists where a file is written
+}
+
+func (e PathTypeConflict) Error() string {
+	if e.IsDir {
+		return "Unable to write file `" + e.Path + "`, a directory exists at its path. Use --overwrite-dir-with-file to replace it."
+	}
+	return "Unable to create directory `" + e.Path + "`, a file exists at its path. Use --overwrite-file-with-dir to replace it."
+}
+
+// ObjectNotConsistent - object was not returned as written before the timeout.
+type ObjectNotConsistent struct {
+	Object  string
+	Timeout time.Duration
+}
+
+func (e ObjectNotConsistent) Error() string {
+	return "Object `" + e.Object + "` did not become consistent within " + e.Timeout.String() + "."
+}
+
 // ObjectOnGlacier - object is of storage class glacier.
 type ObjectOnGlacier struct {
 	Object string
@@ -157,6 +182,22 @@ func (e TooManyLevelsSymlink) Error() string {
 	return "Requested path `" + e.Path + "` has too many levels of symlinks"
 }
 
+// SSECKeyRequired - object seems to be encrypted with SSE-C and was
+// read without a key.
+type SSECKeyRequired GenericFileError
+
+func (e SSECKeyRequired) Error() string {
+	return "Object `" + e.Path + "` is likely encrypted with SSE-C, provide its key with --encrypt-key"
+}
+
+// SSECKeyMismatch - object was read with an SSE-C key it was not
+// encrypted with.
+type SSECKeyMismatch GenericFileError
+
+func (e SSECKeyMismatch) Error() string {
+	return "Access to `" + e.Path + "` was denied with the given SSE-C key, the key likely does not match the one it was encrypted with"
+}
+
 // EmptyPath (EINVAL) - invalid argument.
 type EmptyPath struct{}
 
@@ -213,6 +254,29 @@ func (e UnexpectedExcessRead) Error() string {
 	return msg
 }
 
+// ChecksumMismatch - copied object does not match its source.
+type ChecksumMismatch struct {
+	Source, Target string
+}
+
+func (e ChecksumMismatch) Error() string {
+	return fmt.Sprintf("Checksum of `%s` does not match its source `%s`.", e.Target, e.Source)
+}
+
+// UploadNotAborted - multipart uploads left behind by a failed upload.
+type UploadNotAborted struct {
+	URL       string
+	UploadIDs []string
+}
+
+func (e UploadNotAborted) Error() string {
+	uploads := "Multipart uploads"
+	if len(e.UploadIDs) > 0 {
+		uploads = fmt.Sprintf("Multipart uploads `%s`", strings.Join(e.UploadIDs, "`, `"))
+	}
+	return fmt.Sprintf("%s of `%s` may be left with their parts stored, remove them with `mc rm --incomplete %s`.", uploads, e.URL, e.URL)
+}
+
 // SameFile - source and destination are same files.
 type SameFile struct {
 	Source, Destination string
@@ -19,9 +19,11 @@ package cmd
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -29,6 +31,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -57,6 +60,9 @@ const (
 	slashSeperator   = "/"
 	metadataKey      = "X-Amz-Meta-Mc-Attrs"
 	metadataKeyS3Cmd = "X-Amz-Meta-S3cmd-Attrs"
+
+	// symlinkMetadataKey holds the target of a link uploaded as is.
+	symlinkMetadataKey = "X-Amz-Meta-Mc-Symlink-Target"
 )
 
 // GOOS specific ignore list.
@@ -99,6 +105,93 @@ func isNotSupported(e error) bool {
 	return errno.Err == syscall.ENOTSUP || errno.Err == syscall.EOPNOTSUPP
 }
 
+// xattrMetaPrefix is the metadata prefix under which extended
+// attributes of files are stored on object storage.
+const xattrMetaPrefix = "X-Amz-Meta-Xattr-"
+
+// encodeXattrName escapes an extended attribute name so it survives
+// the case folding of metadata keys, only lower case letters, digits,
+// '.', '-' and '_' are kept as is.
+func encodeXattrName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+	return b.String()
+}
+
+// decodeXattrName reverses encodeXattrName.
+func decodeXattrName(name string) (string, error) {
+	name = strings.ToLower(name)
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] != '%' {
+			b.WriteByte(name[i])
+			continue
+		}
+		if i+2 >= len(name) {
+			return "", fmt.Errorf("invalid escaped extended attribute name `%s`", name)
+		}
+		c, e := strconv.ParseUint(name[i+1:i+3], 16, 8)
+		if e != nil {
+			return "", fmt.Errorf("invalid escaped extended attribute name `%s`", name)
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// xattrsToMetadata returns the extended attributes of a file as
+// object metadata.
+func xattrsToMetadata(path string) (map[string]string, error) {
+	xattrs, e := listXattrs(path)
+	if e != nil {
+		return nil, e
+	}
+	metadata := make(map[string]string, len(xattrs))
+	for name, value := range xattrs {
+		metadata[xattrMetaPrefix+encodeXattrName(name)] = base64.StdEncoding.EncodeToString(value)
+	}
+	return metadata, nil
+}
+
+// unrestoredXattrs - the extended attributes which could not be set on
+// a file, warned about once.
+var unrestoredXattrs sync.Map
+
+// restoreXattrs sets the extended attributes found in object metadata on
+// a file. An attribute which cannot be set, e.g. without the privileges
+// of its namespace or on a filesystem not holding it, is skipped.
+func restoreXattrs(path string, metadata map[string]string) error {
+	for k, v := range metadata {
+		k = http.CanonicalHeaderKey(k)
+		if !strings.HasPrefix(k, xattrMetaPrefix) {
+			continue
+		}
+		name, e := decodeXattrName(strings.TrimPrefix(k, xattrMetaPrefix))
+		if e != nil {
+			return e
+		}
+		value, e := base64.StdEncoding.DecodeString(v)
+		if e != nil {
+			return e
+		}
+		if e = setXattr(path, name, value); e != nil {
+			if _, loaded := unrestoredXattrs.LoadOrStore(name, struct{}{}); !loaded && !globalQuiet && !globalJSON {
+				console.Infof("[Warn] Unable to set extended attribute `%s` on `%s`, it is not preserved: %v\n", name, path, e)
+			}
+		}
+	}
+	return nil
+}
+
 // isIgnoredFile returns true if 'filename' is on the exclude list.
 func isIgnoredFile(filename string) bool {
 	matchFile := filepath.Base(filename)
@@ -287,8 +380,7 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 
 	if objectDir != "" {
 		// Create any missing top level directories.
-		if e := os.MkdirAll(objectDir, 0o777); e != nil {
-			err := f.toClientError(e, f.PathURL.Path)
+		if err := f.makeObjectDir(objectDir, opts.overwriteFileWithDir); err != nil {
 			return 0, err.Trace(f.PathURL.Path)
 		}
 
@@ -299,6 +391,22 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 	}
 
 	objectPath := f.PathURL.Path
+	if err := f.replaceObjectDir(objectPath, opts.overwriteDirWithFile); err != nil {
+		return 0, err.Trace(objectPath)
+	}
+
+	committed := false
+	if opts.noClobber {
+		if err := f.claimObject(objectPath); err != nil {
+			return 0, err.Trace(objectPath)
+		}
+		// Release the claimed name if the put fails.
+		defer func() {
+			if !committed {
+				os.Remove(objectPath)
+			}
+		}()
+	}
 
 	// Write to a temporary file "object.part.minio" before commit.
 	objectPartPath := objectPath + partSuffix
@@ -307,12 +415,23 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 	// should remove any partial download if any.
 	defer os.Remove(objectPartPath)
 
-	tmpFile, e := os.OpenFile(objectPartPath, os.O_CREATE|os.O_WRONLY, 0o666)
+	// Truncate a partial file left behind by an interrupted copy, its
+	// trailing bytes would otherwise end up in a smaller object.
+	tmpFile, e := os.OpenFile(objectPartPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o666)
 	if e != nil {
 		err := f.toClientError(e, f.PathURL.Path)
 		return 0, err.Trace(f.PathURL.Path)
 	}
 
+	// Reserve the disk space upfront, failing fast when it is insufficient.
+	if opts.preallocate {
+		if e = preallocateFile(tmpFile, size); e != nil {
+			tmpFile.Close()
+			err := f.toClientError(e, f.PathURL.Path)
+			return 0, err.Trace(f.PathURL.Path)
+		}
+	}
+
 	attr := make(map[string]string)
 	if _, ok := opts.metadata[metadataKey]; ok && opts.isPreserve {
 		attr, e = parseAttribute(opts.metadata)
@@ -371,6 +490,7 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 		err := f.toClientError(e, objectPath)
 		return totalWritten, err.Trace(objectPartPath, objectPath)
 	}
+	committed = true
 
 	if len(attr) != 0 && opts.isPreserve {
 		atime, mtime, err := parseAtimeMtime(attr)
@@ -384,14 +504,116 @@ func (f *fsClient) put(_ context.Context, reader io.Reader, size int64, progress
 		}
 	}
 
+	if opts.preserveXattr {
+		if e := restoreXattrs(objectPath, opts.metadata); e != nil {
+			return totalWritten, probe.NewError(e).Trace(objectPath)
+		}
+	}
+
 	return totalWritten, nil
 }
 
+// makeObjectDir - creates the directory of an object and its parents. A
+// file at the path of one of them, e.g. copied from an S3 key which is
+// also the prefix of other keys, is replaced with overwriteFile only.
+func (f *fsClient) makeObjectDir(objectDir string, overwriteFile bool) *probe.Error {
+	for {
+		e := os.MkdirAll(objectDir, 0o777)
+		if e == nil {
+			return nil
+		}
+		filePath := fileInDirPath(objectDir)
+		if filePath == "" {
+			return f.toClientError(e, objectDir)
+		}
+		if !overwriteFile {
+			return probe.NewError(PathTypeConflict{Path: filePath})
+		}
+		if e = os.Remove(filePath); e != nil {
+			return f.toClientError(e, filePath)
+		}
+	}
+}
+
+// fileInDirPath - returns the closest existing path element of dir
+// which is not a directory, or "" when there is none.
+func fileInDirPath(dir string) string {
+	for p := filepath.Clean(dir); ; p = filepath.Dir(p) {
+		if st, e := os.Stat(p); e == nil {
+			if st.IsDir() {
+				return ""
+			}
+			return p
+		}
+		if p == filepath.Dir(p) {
+			return ""
+		}
+	}
+}
+
+// replaceObjectDir - fails if a directory exists at the path of an
+// object, e.g. created for keys below an S3 prefix of the same name,
+// unless overwriteDir allows removing it with all its contents.
+func (f *fsClient) replaceObjectDir(objectPath string, overwriteDir bool) *probe.Error {
+	st, e := os.Lstat(objectPath)
+	if e != nil || !st.IsDir() {
+		return nil
+	}
+	if !overwriteDir {
+		return probe.NewError(PathTypeConflict{Path: objectPath, IsDir: true})
+	}
+	if e = os.RemoveAll(objectPath); e != nil {
+		return f.toClientError(e, objectPath)
+	}
+	return nil
+}
+
+// claimObject - atomically creates an empty file at objectPath for a
+// no-clobber put, failing when the object is already present.
+func (f *fsClient) claimObject(objectPath string) *probe.Error {
+	fd, e := os.OpenFile(objectPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o666)
+	if e != nil {
+		if os.IsExist(e) {
+			return probe.NewError(ObjectAlreadyExists{Object: objectPath})
+		}
+		return f.toClientError(e, objectPath)
+	}
+	if e = fd.Close(); e != nil {
+		os.Remove(objectPath)
+		return probe.NewError(e)
+	}
+	return nil
+}
+
 // Put - create a new file with metadata.
 func (f *fsClient) Put(ctx context.Context, reader io.Reader, size int64, progress io.Reader, opts PutOptions) (int64, *probe.Error) {
+	if target, ok := opts.metadata[symlinkMetadataKey]; ok && opts.preserveSymlink {
+		return 0, f.putSymlink(target, opts.noClobber)
+	}
 	return f.put(ctx, reader, size, progress, opts)
 }
 
+// putSymlink - recreates a link recorded by an upload with --no-dereference.
+func (f *fsClient) putSymlink(target string, noClobber bool) *probe.Error {
+	objectPath := f.PathURL.Path
+	if e := os.MkdirAll(filepath.Dir(objectPath), 0o777); e != nil {
+		return f.toClientError(e, objectPath).Trace(objectPath)
+	}
+	if !noClobber {
+		// Replace an existing file, but never a directory.
+		if e := os.Remove(objectPath); e != nil && !os.IsNotExist(e) {
+			return f.toClientError(e, objectPath).Trace(objectPath)
+		}
+	}
+	if e := os.Symlink(target, objectPath); e != nil {
+		if os.IsExist(e) {
+			return probe.NewError(ObjectAlreadyExists{Object: objectPath})
+		}
+		return f.toClientError(e, objectPath).Trace(objectPath)
+	}
+	return nil
+}
+
 func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progress io.Reader, opts PutOptions) (int64, *probe.Error) {
 	// ContentType is not handled on purpose.
 	// For filesystem this is a redundant information.
@@ -401,8 +623,7 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 
 	if objectDir != "" {
 		// Create any missing top level directories.
-		if e := os.MkdirAll(objectDir, 0o777); e != nil {
-			err := f.toClientError(e, f.PathURL.Path)
+		if err := f.makeObjectDir(objectDir, opts.overwriteFileWithDir); err != nil {
 			return 0, err.Trace(f.PathURL.Path)
 		}
 
@@ -413,6 +634,22 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 	}
 
 	objectPath := f.PathURL.Path
+	if err := f.replaceObjectDir(objectPath, opts.overwriteDirWithFile); err != nil {
+		return 0, err.Trace(objectPath)
+	}
+
+	committed := false
+	if opts.noClobber {
+		if err := f.claimObject(objectPath); err != nil {
+			return 0, err.Trace(objectPath)
+		}
+		// Release the claimed name if the put fails.
+		defer func() {
+			if !committed {
+				os.Remove(objectPath)
+			}
+		}()
+	}
 
 	// Write to a temporary file "object.part.minio" before commit.
 	objectPartPath := objectPath + partSuffix
@@ -421,12 +658,23 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 	// should remove any partial download if any.
 	defer os.Remove(objectPartPath)
 
-	tmpFile, e := os.OpenFile(objectPartPath, os.O_CREATE|os.O_WRONLY, 0o666)
+	// Truncate a partial file left behind by an interrupted copy, its
+	// trailing bytes would otherwise end up in a smaller object.
+	tmpFile, e := os.OpenFile(objectPartPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o666)
 	if e != nil {
 		err := f.toClientError(e, f.PathURL.Path)
 		return 0, err.Trace(f.PathURL.Path)
 	}
 
+	// Reserve the disk space upfront, failing fast when it is insufficient.
+	if opts.preallocate {
+		if e = preallocateFile(tmpFile, size); e != nil {
+			tmpFile.Close()
+			err := f.toClientError(e, f.PathURL.Path)
+			return 0, err.Trace(f.PathURL.Path)
+		}
+	}
+
 	attr := make(map[string]string)
 	if _, ok := opts.metadata[metadataKey]; ok && opts.isPreserve {
 		attr, e = parseAttribute(opts.metadata)
@@ -478,6 +726,7 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 		err := f.toClientError(e, objectPath)
 		return totalWritten, err.Trace(objectPartPath, objectPath)
 	}
+	committed = true
 
 	if len(attr) != 0 && opts.isPreserve {
 		atime, mtime, err := parseAtimeMtime(attr)
@@ -491,6 +740,12 @@ func (f *fsClient) putN(_ context.Context, reader io.Reader, size int64, progres
 		}
 	}
 
+	if opts.preserveXattr {
+		if e := restoreXattrs(objectPath, opts.metadata); e != nil {
+			return totalWritten, probe.NewError(e).Trace(objectPath)
+		}
+	}
+
 	return totalWritten, nil
 }
 
@@ -553,6 +808,12 @@ func (f *fsClient) Get(_ context.Context, opts GetOptions) (io.ReadCloser, *prob
 			return nil, err.Trace(f.PathURL.Path)
 		}
 	}
+	if opts.RangeLength > 0 {
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(fileData, opts.RangeLength), fileData}, nil
+	}
 
 	return fileData, nil
 }
@@ -734,7 +995,7 @@ func (f *fsClient) List(_ context.Context, opts ListOptions) <-chan *ClientConte
 
 	if opts.Recursive {
 		if opts.ShowDir == DirNone {
-			go f.listRecursiveInRoutine(contentCh)
+			go f.listRecursiveInRoutine(contentCh, opts.Symlinks, opts.ExcludePrefixes)
 		} else {
 			go f.listDirOpt(contentCh, opts.Incomplete, opts.WithMetadata, opts.ShowDir)
 		}
@@ -1003,7 +1264,7 @@ func (f *fsClient) listDirOpt(contentCh chan *ClientContent, isIncomplete, _ boo
 	}
 }
 
-func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent) {
+func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent, symlinks SymlinkOpt, excludePrefixes []string) {
 	// close channels upon return.
 	defer close(contentCh)
 	var dirName string
@@ -1013,7 +1274,11 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent) {
 		pathURL.Path = filepath.FromSlash(pathURL.Path)
 		pathURL.Separator = os.PathSeparator
 	}
-	visitFS := func(fp string, fi os.FileInfo, e error) error {
+	// Real paths of the directories being walked through followed
+	// links, used to stop symlink cycles with SymlinkFollow.
+	walking := map[string]bool{}
+	var visitFS func(fp string, fi os.FileInfo, e error) error
+	visitFS = func(fp string, fi os.FileInfo, e error) error {
 		// If file path ends with filepath.Separator and equals to root path, skip it.
 		if strings.HasSuffix(fp, string(pathURL.Separator)) {
 			if fp == dirName {
@@ -1052,6 +1317,19 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent) {
 				}
 			}
 		}
+		// Excluded folders are pruned from the walk.
+		if e == nil && len(excludePrefixes) > 0 {
+			key := filepath.ToSlash(strings.TrimPrefix(fp, dirName))
+			if fi.IsDir() {
+				key += "/"
+			}
+			if matchExcludePrefix(key, excludePrefixes) != "" {
+				if fi.IsDir() {
+					return xfilepath.ErrSkipDir
+				}
+				return nil
+			}
+		}
 		if e != nil {
 			// If operation is not permitted, we throw quickly back.
 			if strings.Contains(e.Error(), "operation not permitted") {
@@ -1069,11 +1347,31 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent) {
 			return e
 		}
 		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			if symlinks == SymlinkPreserve {
+				target, e := os.Readlink(fp)
+				if e != nil {
+					contentCh <- &ClientContent{Err: f.toClientError(e, fp)}
+					return nil
+				}
+				contentCh <- &ClientContent{
+					URL:      *newClientURL(fp),
+					Time:     fi.ModTime(),
+					Type:     fi.Mode(),
+					Metadata: map[string]string{symlinkMetadataKey: target},
+				}
+				return nil
+			}
 			fi, e = os.Stat(fp)
 			if e != nil {
+				if symlinks == SymlinkFollow && errors.Is(e, syscall.ELOOP) {
+					contentCh <- &ClientContent{Err: f.toClientError(e, fp)}
+				}
 				// Ignore any errors for symlink
 				return nil
 			}
+			if symlinks == SymlinkFollow && fi.IsDir() {
+				return f.walkSymlinkDir(fp, walking, contentCh, visitFS)
+			}
 		}
 		if fi.Mode().IsRegular() {
 			contentCh <- &ClientContent{
@@ -1102,6 +1400,11 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent) {
 		// filePrefix is kept for filtering incoming contents through WalkFunc.
 		filePrefix = pathURL.Path
 	}
+	if symlinks == SymlinkFollow {
+		if realDir, e := filepath.EvalSymlinks(dirName); e == nil {
+			walking[realDir] = true
+		}
+	}
 	// walks invokes our custom function.
 	e := xfilepath.Walk(dirName, visitFS)
 	if e != nil {
@@ -1111,6 +1414,31 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent) {
 	}
 }
 
+// walkSymlinkDir - walks the directory behind the link fp, reporting
+// TooManyLevelsSymlink instead when the link loops back into a
+// directory that is already being walked.
+func (f *fsClient) walkSymlinkDir(fp string, walking map[string]bool, contentCh chan *ClientContent, walkFn xfilepath.WalkFunc) error {
+	realDir, e := filepath.EvalSymlinks(fp)
+	if e != nil {
+		contentCh <- &ClientContent{Err: f.toClientError(e, fp)}
+		return nil
+	}
+	realParent, e := filepath.EvalSymlinks(filepath.Dir(fp))
+	if e != nil {
+		contentCh <- &ClientContent{Err: f.toClientError(e, fp)}
+		return nil
+	}
+	if walking[realDir] || realParent == realDir || strings.HasPrefix(realParent, realDir+string(os.PathSeparator)) {
+		contentCh <- &ClientContent{Err: probe.NewError(TooManyLevelsSymlink{Path: fp})}
+		return nil
+	}
+	walking[realDir] = true
+	defer delete(walking, realDir)
+
+	// A trailing separator makes the walk descend into the link target.
+	return xfilepath.Walk(fp+string(os.PathSeparator), walkFn)
+}
+
 // MakeBucket - create a new bucket.
 func (f *fsClient) MakeBucket(_ context.Context, _ string, _, _ bool) *probe.Error {
 	// TODO: ignoreExisting has no effect currently. In the future, we want
@@ -1281,6 +1609,16 @@ func (f *fsClient) Stat(_ context.Context, opts StatOptions) (content *ClientCon
 		content.Metadata[metadataKey] = fileAttr
 	}
 
+	if opts.preserveXattr {
+		metaData, e := xattrsToMetadata(f.PathURL.Path)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(f.PathURL.Path)
+		}
+		for k, v := range metaData {
+			content.Metadata[k] = v
+		}
+	}
+
 	return content, nil
 }
 
@@ -20,11 +20,15 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
+	"github.com/minio/mc/pkg/probe"
 	checkv1 "gopkg.in/check.v1"
 )
 
@@ -269,6 +273,143 @@ func (s *TestSuite) TestPut(c *checkv1.C) {
 	c.Assert(n, checkv1.Equals, int64(len(data)))
 }
 
+// Test put never overwrites an existing file with no-clobber.
+func (s *TestSuite) TestPutNoClobber(c *checkv1.C) {
+	root, e := os.MkdirTemp(os.TempDir(), "fs-")
+	c.Assert(e, checkv1.IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsClient, err := fsNew(objectPath)
+	c.Assert(err, checkv1.IsNil)
+
+	_, err = fsClient.Put(context.Background(), strings.NewReader("first"), 5, nil, PutOptions{noClobber: true})
+	c.Assert(err, checkv1.IsNil)
+
+	_, err = fsClient.Put(context.Background(), strings.NewReader("second"), 6, nil, PutOptions{noClobber: true})
+	c.Assert(err, checkv1.NotNil)
+	_, ok := err.ToGoError().(ObjectAlreadyExists)
+	c.Assert(ok, checkv1.Equals, true)
+
+	content, e := os.ReadFile(objectPath)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(content), checkv1.Equals, "first")
+}
+
+// Test a key and a prefix of the same name fail to be written as a file
+// and a directory of the same path, unless replacing is allowed.
+func (s *TestSuite) TestPutTypeConflict(c *checkv1.C) {
+	root, e := os.MkdirTemp(os.TempDir(), "fs-")
+	c.Assert(e, checkv1.IsNil)
+	defer os.RemoveAll(root)
+
+	put := func(key, data string, opts PutOptions) *probe.Error {
+		fsClient, err := fsNew(filepath.Join(root, key))
+		c.Assert(err, checkv1.IsNil)
+		_, err = fsClient.Put(context.Background(), strings.NewReader(data), int64(len(data)), nil, opts)
+		return err
+	}
+
+	// A file in the way of a directory.
+	c.Assert(put("a", "file", PutOptions{}), checkv1.IsNil)
+	err := put("a/b", "nested", PutOptions{})
+	c.Assert(err, checkv1.NotNil)
+	conflict, ok := err.ToGoError().(PathTypeConflict)
+	c.Assert(ok, checkv1.Equals, true)
+	c.Assert(conflict, checkv1.Equals, PathTypeConflict{Path: filepath.Join(root, "a")})
+	c.Assert(put("a/b", "nested", PutOptions{overwriteFileWithDir: true}), checkv1.IsNil)
+	content, e := os.ReadFile(filepath.Join(root, "a", "b"))
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(content), checkv1.Equals, "nested")
+
+	// A directory in the way of a file.
+	err = put("a", "file", PutOptions{})
+	c.Assert(err, checkv1.NotNil)
+	conflict, ok = err.ToGoError().(PathTypeConflict)
+	c.Assert(ok, checkv1.Equals, true)
+	c.Assert(conflict, checkv1.Equals, PathTypeConflict{Path: filepath.Join(root, "a"), IsDir: true})
+	c.Assert(put("a", "file", PutOptions{overwriteDirWithFile: true}), checkv1.IsNil)
+	content, e = os.ReadFile(filepath.Join(root, "a"))
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(content), checkv1.Equals, "file")
+}
+
+// Test overwriting a file with a smaller object leaves no trailing bytes,
+// also when a larger partial file of an interrupted copy was left behind.
+func (s *TestSuite) TestPutOverwriteSmaller(c *checkv1.C) {
+	root, e := os.MkdirTemp(os.TempDir(), "fs-")
+	c.Assert(e, checkv1.IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	c.Assert(os.WriteFile(objectPath, []byte(strings.Repeat("x", 4096)), 0o666), checkv1.IsNil)
+	c.Assert(os.WriteFile(objectPath+partSuffix, []byte(strings.Repeat("y", 4096)), 0o666), checkv1.IsNil)
+
+	fsClient, err := fsNew(objectPath)
+	c.Assert(err, checkv1.IsNil)
+	_, err = fsClient.Put(context.Background(), strings.NewReader("small"), 5, nil, PutOptions{})
+	c.Assert(err, checkv1.IsNil)
+
+	content, e := os.ReadFile(objectPath)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(content), checkv1.Equals, "small")
+}
+
+// Test listing symlinks with each symlink policy.
+func (s *TestSuite) TestListSymlinks(c *checkv1.C) {
+	root, e := os.MkdirTemp(os.TempDir(), "fs-")
+	c.Assert(e, checkv1.IsNil)
+	defer os.RemoveAll(root)
+
+	c.Assert(os.MkdirAll(filepath.Join(root, "dir"), 0o777), checkv1.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(root, "dir", "file"), []byte("hello"), 0o666), checkv1.IsNil)
+	c.Assert(os.Symlink("dir", filepath.Join(root, "dirlink")), checkv1.IsNil)
+	c.Assert(os.Symlink("..", filepath.Join(root, "dir", "loop")), checkv1.IsNil)
+
+	fsClient, err := fsNew(root + string(os.PathSeparator))
+	c.Assert(err, checkv1.IsNil)
+
+	list := func(symlinks SymlinkOpt) (names []string, loops int) {
+		for content := range fsClient.List(context.Background(), ListOptions{Recursive: true, ShowDir: DirNone, Symlinks: symlinks}) {
+			if content.Err != nil {
+				_, ok := content.Err.ToGoError().(TooManyLevelsSymlink)
+				c.Assert(ok, checkv1.Equals, true)
+				loops++
+				continue
+			}
+			name, _ := filepath.Rel(root, content.URL.Path)
+			names = append(names, filepath.ToSlash(name))
+			if content.Type&os.ModeSymlink != 0 {
+				names[len(names)-1] += "@" + content.Metadata[symlinkMetadataKey]
+			}
+		}
+		return names, loops
+	}
+
+	names, loops := list(SymlinkDefault)
+	c.Assert(names, checkv1.DeepEquals, []string{"dir/file"})
+	c.Assert(loops, checkv1.Equals, 0)
+
+	names, loops = list(SymlinkFollow)
+	c.Assert(names, checkv1.DeepEquals, []string{"dir/file", "dirlink/file"})
+	c.Assert(loops, checkv1.Equals, 2)
+
+	names, loops = list(SymlinkPreserve)
+	c.Assert(names, checkv1.DeepEquals, []string{"dir/file", "dir/loop@..", "dirlink@dir"})
+	c.Assert(loops, checkv1.Equals, 0)
+
+	linkClient, err := fsNew(filepath.Join(root, "restored"))
+	c.Assert(err, checkv1.IsNil)
+	_, err = linkClient.Put(context.Background(), strings.NewReader(""), 0, nil, PutOptions{
+		metadata:        map[string]string{symlinkMetadataKey: "dir"},
+		preserveSymlink: true,
+	})
+	c.Assert(err, checkv1.IsNil)
+	target, e := os.Readlink(filepath.Join(root, "restored"))
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(target, checkv1.Equals, "dir")
+}
+
 // Test read a file.
 func (s *TestSuite) TestGet(c *checkv1.C) {
 	root, e := os.MkdirTemp(os.TempDir(), "fs-")
@@ -382,3 +523,38 @@ func (s *TestSuite) TestCopy(c *checkv1.C) {
 	err = fsClientTarget.Copy(context.Background(), sourcePath, CopyOptions{size: int64(len(data))}, nil)
 	c.Assert(err, checkv1.IsNil)
 }
+
+// Test an extended attribute which cannot be set is skipped, the others
+// are restored.
+func (s *TestSuite) TestRestoreXattrs(c *checkv1.C) {
+	if !xattrSupported {
+		c.Skip("extended attributes are not supported")
+	}
+	path := filepath.Join(c.MkDir(), "file")
+	c.Assert(os.WriteFile(path, []byte("data"), 0o644), checkv1.IsNil)
+	if e := setXattr(path, "user.probe", []byte("probe")); e != nil {
+		c.Skip("extended attributes are not supported by the filesystem")
+	}
+
+	metadata := map[string]string{}
+	// Attributes outside of any namespace cannot be set.
+	for name, value := range map[string]string{"invalid": "a", "user.mime_type": "text/plain"} {
+		metadata[xattrMetaPrefix+encodeXattrName(name)] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	c.Assert(restoreXattrs(path, metadata), checkv1.IsNil)
+	xattrs, e := listXattrs(path)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(string(xattrs["user.mime_type"]), checkv1.Equals, "text/plain")
+	_, ok := xattrs["invalid"]
+	c.Assert(ok, checkv1.Equals, false)
+}
+
+// Test extended attribute names survive metadata key case folding.
+func (s *TestSuite) TestXattrNameEncoding(c *checkv1.C) {
+	for _, name := range []string{"user.mime_type", "com.apple.FinderInfo", "security.selinux", "user.with space"} {
+		key := http.CanonicalHeaderKey(xattrMetaPrefix + encodeXattrName(name))
+		decoded, e := decodeXattrName(strings.TrimPrefix(key, xattrMetaPrefix))
+		c.Assert(e, checkv1.IsNil)
+		c.Assert(decoded, checkv1.Equals, name)
+	}
+}
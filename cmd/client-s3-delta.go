@@ -0,0 +1,234 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+// maxDeltaParts is the maximum number of parts of a multipart upload.
+const maxDeltaParts = 10000
+
+// deltaBlock - checksums of a block of the new content of a delta upload.
+type deltaBlock struct {
+	weak   uint32
+	strong [sha256.Size]byte
+}
+
+// rollingSum - the weak checksum of rsync, a rolls the plain sum of the
+// window and b the sum weighted by the distance to its end.
+type rollingSum struct {
+	a, b uint32
+	size uint32
+}
+
+func newRollingSum(window []byte) rollingSum {
+	r := rollingSum{size: uint32(len(window))}
+	for i, c := range window {
+		r.a += uint32(c)
+		r.b += (r.size - uint32(i)) * uint32(c)
+	}
+	return r
+}
+
+// roll moves the window one byte, out leaves it and in enters it.
+func (r *rollingSum) roll(out, in byte) {
+	r.a += uint32(in) - uint32(out)
+	r.b += r.a - r.size*uint32(out)
+}
+
+func (r rollingSum) sum() uint32 {
+	return r.a&0xffff | r.b<<16
+}
+
+// matchDeltaBlocks - finds the blocks anywhere in old, returns the
+// offset in old of every block found by its index.
+func matchDeltaBlocks(old io.Reader, blocks []deltaBlock, blockSize int) (map[int]int64, error) {
+	byWeak := make(map[uint32][]int, len(blocks))
+	// Tags of the weak checksums, rejecting most positions without a
+	// map lookup, as rsync does.
+	var tags [1 << 16]bool
+	for i, block := range blocks {
+		byWeak[block.weak] = append(byWeak[block.weak], i)
+		tags[block.weak>>16^block.weak&0xffff] = true
+	}
+
+	matches := make(map[int]int64)
+	reader := bufio.NewReaderSize(old, 1<<20)
+	window := make([]byte, blockSize)
+	if _, e := io.ReadFull(reader, window); e != nil {
+		if e == io.ErrUnexpectedEOF || e == io.EOF {
+			return matches, nil
+		}
+		return nil, e
+	}
+
+	sum := newRollingSum(window)
+	// The window starts at offset in old and at pos in the ring buffer.
+	var offset int64
+	pos := 0
+	for {
+		weak := sum.sum()
+		if tags[weak>>16^weak&0xffff] {
+			if candidates, ok := byWeak[weak]; ok {
+				var strong [sha256.Size]byte
+				computed := false
+				for _, i := range candidates {
+					if _, found := matches[i]; found {
+						continue
+					}
+					if !computed {
+						h := sha256.New()
+						h.Write(window[pos:])
+						h.Write(window[:pos])
+						h.Sum(strong[:0])
+						computed = true
+					}
+					if strong == blocks[i].strong {
+						matches[i] = offset
+					}
+				}
+				if len(matches) == len(blocks) {
+					return matches, nil
+				}
+			}
+		}
+
+		c, e := reader.ReadByte()
+		if e == io.EOF {
+			return matches, nil
+		}
+		if e != nil {
+			return nil, e
+		}
+		sum.roll(window[pos], c)
+		window[pos] = c
+		pos = (pos + 1) % blockSize
+		offset++
+	}
+}
+
+// putDelta - uploads reader over the existing object, only its blocks
+// which changed are uploaded, the blocks found anywhere in the object are
+// copied server side into the parts of a multipart upload. Returns false
+// without an error when a full upload is needed instead: the object does
+// not exist, shares no block with reader or the server cannot copy parts.
+func (c *S3Client) putDelta(ctx context.Context, bucket, object string, reader io.ReaderAt, size int64, progress io.Reader, opts minio.PutObjectOptions, blockSize int64) (ui minio.UploadInfo, delta bool, err *probe.Error) {
+	if size <= blockSize || size > maxDeltaParts*blockSize {
+		return ui, false, nil
+	}
+	sse := opts.ServerSideEncryption
+	var ssec encrypt.ServerSide
+	if sse != nil && sse.Type() == encrypt.SSEC {
+		ssec = sse
+	}
+
+	info, e := c.api.StatObject(ctx, bucket, object, minio.StatObjectOptions{ServerSideEncryption: ssec})
+	if e != nil {
+		return ui, false, nil
+	}
+
+	blocks := make([]deltaBlock, size/blockSize)
+	buf := make([]byte, blockSize)
+	for i := range blocks {
+		if _, e = reader.ReadAt(buf, int64(i)*blockSize); e != nil {
+			return ui, false, probe.NewError(e)
+		}
+		blocks[i] = deltaBlock{weak: newRollingSum(buf).sum(), strong: sha256.Sum256(buf)}
+	}
+
+	getOpts := minio.GetObjectOptions{ServerSideEncryption: ssec}
+	if e = getOpts.SetMatchETag(info.ETag); e != nil {
+		return ui, false, probe.NewError(e)
+	}
+	old, e := c.api.GetObject(ctx, bucket, object, getOpts)
+	if e != nil {
+		return ui, false, probe.NewError(e)
+	}
+	matches, e := matchDeltaBlocks(old, blocks, int(blockSize))
+	old.Close()
+	if e != nil {
+		return ui, false, probe.NewError(e)
+	}
+	if len(matches) == 0 {
+		return ui, false, nil
+	}
+
+	// Copied parts are read from the version of the object matched.
+	copyHeader := http.Header{}
+	copyHeader.Set("X-Amz-Copy-Source-If-Match", info.ETag)
+	if ssec != nil {
+		encrypt.SSECopy(ssec).Marshal(copyHeader)
+		ssec.Marshal(copyHeader)
+	}
+	copyMetadata := make(map[string]string, len(copyHeader))
+	for k := range copyHeader {
+		copyMetadata[k] = copyHeader.Get(k)
+	}
+
+	core := minio.Core{Client: c.api}
+	uploadID, e := core.NewMultipartUpload(ctx, bucket, object, opts)
+	if e != nil {
+		return ui, false, probe.NewError(e)
+	}
+	var parts []minio.CompletePart
+	for offset, partID := int64(0), 1; offset < size; offset, partID = offset+blockSize, partID+1 {
+		length := blockSize
+		if size-offset < length {
+			length = size - offset
+		}
+		if oldOffset, ok := matches[partID-1]; ok {
+			part, e := core.CopyObjectPart(ctx, bucket, object, bucket, object, uploadID, partID, oldOffset, length, copyMetadata)
+			if e != nil {
+				core.AbortMultipartUpload(ctx, bucket, object, uploadID)
+				switch minio.ToErrorResponse(e).StatusCode {
+				case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+					return ui, false, nil
+				}
+				return ui, false, probe.NewError(e)
+			}
+			if progress != nil {
+				io.CopyN(io.Discard, progress, length)
+			}
+			parts = append(parts, part)
+			continue
+		}
+		part, e := core.PutObjectPart(ctx, bucket, object, uploadID, partID,
+			hookreader.NewHook(io.NewSectionReader(reader, offset, length), progress), length, minio.PutObjectPartOptions{SSE: ssec})
+		if e != nil {
+			core.AbortMultipartUpload(ctx, bucket, object, uploadID)
+			return ui, false, probe.NewError(e)
+		}
+		parts = append(parts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	if ui, e = core.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts); e != nil {
+		core.AbortMultipartUpload(ctx, bucket, object, uploadID)
+		return ui, false, probe.NewError(e)
+	}
+	ui.Size = size
+	return ui, true, nil
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/pkg/v2/console"
+)
+
+// trailerWarnedHosts - endpoints which rejected trailing checksums, the
+// fallback is only reported once per endpoint.
+var trailerWarnedHosts sync.Map
+
+// parseTrailerChecksum returns the algorithm of --trailer-checksum, one
+// of crc32c, crc32, sha1 and sha256, none for an empty name.
+func parseTrailerChecksum(name string) (minio.ChecksumType, *probe.Error) {
+	switch strings.ToLower(name) {
+	case "":
+		return minio.ChecksumNone, nil
+	case "crc32c":
+		return minio.ChecksumCRC32C, nil
+	case "crc32":
+		return minio.ChecksumCRC32, nil
+	case "sha1":
+		return minio.ChecksumSHA1, nil
+	case "sha256":
+		return minio.ChecksumSHA256, nil
+	}
+	return minio.ChecksumNone, errInvalidArgument().Trace(name)
+}
+
+// checksumReader computes the checksum of a part as it is sent and sets
+// it in the trailer of the request once the part was read.
+type checksumReader struct {
+	*bytes.Reader
+	hash    hash.Hash
+	trailer http.Header
+	key     string
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, e := r.Reader.Read(p)
+	r.hash.Write(p[:n])
+	if e == io.EOF {
+		r.trailer.Set(r.key, base64.StdEncoding.EncodeToString(r.hash.Sum(nil)))
+	}
+	return n, e
+}
+
+// Seek rewinds the part when its upload is retried.
+func (r *checksumReader) Seek(offset int64, whence int) (int64, error) {
+	r.hash.Reset()
+	return r.Reader.Seek(offset, whence)
+}
+
+// trailerRequestTerms are found in the errors of servers rejecting the
+// headers of trailing checksums.
+var trailerRequestTerms = []string{"trailer", "chunked", "checksum-algorithm", "checksum algorithm"}
+
+// isTrailerUnsupported tells if the server rejected a request because
+// it does not know trailing checksums: not implemented, or a bad request
+// naming them. A checksum mismatch or another bad request is an error.
+func isTrailerUnsupported(e error) bool {
+	errResp := minio.ToErrorResponse(e)
+	if errResp.Code == "NotImplemented" || errResp.StatusCode == http.StatusNotImplemented {
+		return true
+	}
+	if errResp.Code != "InvalidArgument" && errResp.Code != "InvalidRequest" {
+		return false
+	}
+	message := strings.ToLower(errResp.Message)
+	for _, term := range trailerRequestTerms {
+		if strings.Contains(message, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// putTrailerChecksum - uploads reader in parts, each part sending its
+// checksum in the trailer of the request, computed while the part is
+// sent, for the server to verify the parts of streams of unknown size
+// which cannot have a Content-MD5. Falls back to a regular upload when
+// the server does not support trailing checksums, every upload tries
+// them again as the endpoint may be served by several servers.
+func (c *S3Client) putTrailerChecksum(ctx context.Context, bucket, object string, reader io.Reader, size int64, progress io.Reader, opts minio.PutObjectOptions, checksum minio.ChecksumType) (minio.UploadInfo, *probe.Error) {
+	api := c.objectAPI(ctx, bucket)
+	fallback := func(reader io.Reader, reason string) (minio.UploadInfo, *probe.Error) {
+		if _, loaded := trailerWarnedHosts.LoadOrStore(c.targetURL.Host, true); !loaded && !globalQuiet && !globalJSON {
+			console.Infof("[Warn] %s, uploading to `%s` without --trailer-checksum.\n", reason, c.targetURL.Host)
+		}
+		ui, e := api.PutObject(ctx, bucket, object, reader, size, opts)
+		if e != nil {
+			return ui, probe.NewError(e)
+		}
+		return ui, nil
+	}
+	if strings.EqualFold(c.config.Signature, "S3v2") || c.targetURL.Host == googleHostName {
+		return fallback(reader, "Trailing checksums require S3v4 signatures")
+	}
+
+	_, partSize, _, e := minio.OptimalPartInfo(size, uint64(opts.PartSize))
+	if e != nil {
+		return minio.UploadInfo{}, probe.NewError(e)
+	}
+	var ssec encrypt.ServerSide
+	if sse := opts.ServerSideEncryption; sse != nil && sse.Type() == encrypt.SSEC {
+		ssec = sse
+	}
+
+	// The algorithm is declared when the upload is created, the
+	// options completing it are left unchanged.
+	newOpts := opts
+	newOpts.UserMetadata = make(map[string]string, len(opts.UserMetadata)+1)
+	for k, v := range opts.UserMetadata {
+		newOpts.UserMetadata[k] = v
+	}
+	newOpts.UserMetadata["X-Amz-Checksum-Algorithm"] = checksum.String()
+
+	core := minio.Core{Client: api}
+	uploadID, e := core.NewMultipartUpload(ctx, bucket, object, newOpts)
+	if e != nil {
+		if isTrailerUnsupported(e) {
+			return fallback(reader, "Trailing checksums are not supported")
+		}
+		return minio.UploadInfo{}, probe.NewError(e)
+	}
+
+	var total int64
+	var parts []minio.CompletePart
+	buf := make([]byte, partSize)
+	for partID := 1; ; partID++ {
+		length, e := io.ReadFull(reader, buf)
+		if e == io.EOF && partID > 1 {
+			break
+		}
+		if e != nil && e != io.EOF && e != io.ErrUnexpectedEOF {
+			core.AbortMultipartUpload(ctx, bucket, object, uploadID)
+			return minio.UploadInfo{}, probe.NewError(e)
+		}
+		last := e != nil
+
+		// The trailer is sized by its initial value, the checksum of
+		// no data has the length of any checksum.
+		hasher := checksum.Hasher()
+		trailer := http.Header{}
+		trailer.Set(checksum.Key(), base64.StdEncoding.EncodeToString(hasher.Sum(nil)))
+		part, e := core.PutObjectPart(ctx, bucket, object, uploadID, partID,
+			&checksumReader{Reader: bytes.NewReader(buf[:length]), hash: hasher, trailer: trailer, key: checksum.Key()},
+			int64(length), minio.PutObjectPartOptions{SSE: ssec, Trailer: trailer})
+		if e != nil {
+			core.AbortMultipartUpload(ctx, bucket, object, uploadID)
+			// Nothing but the first part was read yet, it can be
+			// uploaded again without a trailer.
+			if partID == 1 && isTrailerUnsupported(e) {
+				return fallback(io.MultiReader(bytes.NewReader(buf[:length]), reader), "Trailing checksums are not supported")
+			}
+			return minio.UploadInfo{}, probe.NewError(e)
+		}
+		if progress != nil {
+			io.CopyN(io.Discard, progress, int64(length))
+		}
+		parts = append(parts, minio.CompletePart{
+			PartNumber:     part.PartNumber,
+			ETag:           part.ETag,
+			ChecksumCRC32:  part.ChecksumCRC32,
+			ChecksumCRC32C: part.ChecksumCRC32C,
+			ChecksumSHA1:   part.ChecksumSHA1,
+			ChecksumSHA256: part.ChecksumSHA256,
+		})
+		total += int64(length)
+		if last {
+			break
+		}
+	}
+	ui, e := core.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts)
+	if e != nil {
+		core.AbortMultipartUpload(ctx, bucket, object, uploadID)
+		return ui, probe.NewError(e)
+	}
+	ui.Size = total
+	return ui, nil
+}
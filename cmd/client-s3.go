@@ -21,7 +21,9 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"hash/fnv"
@@ -35,11 +37,15 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/klauspost/compress/gzhttp"
+	"github.com/minio/pkg/v2/console"
 	"github.com/minio/pkg/v2/env"
 
 	"github.com/minio/minio-go/v7"
@@ -50,6 +56,7 @@ import (
 	"github.com/minio/minio-go/v7/pkg/policy"
 	"github.com/minio/minio-go/v7/pkg/replication"
 	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/minio-go/v7/pkg/set"
 	"github.com/minio/minio-go/v7/pkg/sse"
 	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/minio/pkg/v2/mimedb"
@@ -65,9 +72,23 @@ type S3Client struct {
 	sync.Mutex
 	targetURL    *ClientURL
 	api          *minio.Client
+	accelAPI     *minio.Client
 	virtualStyle bool
+
+	multipartThreshold uint64
+	listVersion        int
+	config             *Config
 }
 
+const (
+	// maxSinglePutSize is the largest object size allowed in a single PUT.
+	maxSinglePutSize = 5 * humanize.GiByte
+	// defaultMultipartPartSize is the part size used by default for uploads.
+	defaultMultipartPartSize = 16 * humanize.MiByte
+	// minMultipartPartSize is the smallest part size allowed for multipart.
+	minMultipartPartSize = 5 * humanize.MiByte
+)
+
 const (
 	amazonHostNameAccelerated = "s3-accelerate.amazonaws.com"
 	googleHostName            = "storage.googleapis.com"
@@ -129,7 +150,7 @@ func getConfigHash(config *Config) uint32 {
 
 	// Generate a hash out of s3Conf.
 	confHash := fnv.New32a()
-	confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey + config.SessionToken))
+	confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey + config.SessionToken + config.Region))
 	confSum := confHash.Sum32()
 	return confSum
 }
@@ -198,7 +219,12 @@ func getTransportForConfig(config *Config, withS3v2 bool) http.RoundTripper {
 		transport = tr
 	}
 
-	transport = limiter.New(config.UploadLimit, config.DownloadLimit, transport)
+	transport = limiter.New(config.UploadLimit, config.DownloadLimit, config.LimitBurst, transport)
+	transport = limiter.NewRequestRate(config.RequestLimit, transport)
+	transport = getSharedLimitTransport(config, transport)
+	transport = rawHeadersTransport{transport: transport}
+	transport = apiCallsTransport{transport: transport}
+	transport = ifNoneMatchTransport{transport: transport}
 
 	if config.Debug {
 		if strings.EqualFold(config.Signature, "S3v4") {
@@ -208,9 +234,187 @@ func getTransportForConfig(config *Config, withS3v2 bool) http.RoundTripper {
 		}
 	}
 	transport = gzhttp.Transport(transport)
+	// Upload ids are read from the decompressed responses.
+	transport = uploadIDsTransport{transport: transport}
 	return transport
 }
 
+// rawHeadersKey is the context key under which a caller can ask for the
+// raw response headers of an object request to be recorded.
+type rawHeadersKey struct{}
+
+// rawHeadersTransport records the response headers of the HEAD and GET
+// object requests into the http.Header found in the request context, if
+// any. Other requests made with that context, e.g. looking up the bucket
+// location, are left out and a retried request only records its last
+// response.
+type rawHeadersTransport struct {
+	transport http.RoundTripper
+}
+
+func (t rawHeadersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, e := t.transport.RoundTrip(req)
+	h, ok := req.Context().Value(rawHeadersKey{}).(http.Header)
+	if !ok || res == nil || (req.Method != http.MethodHead && req.Method != http.MethodGet) || req.URL.Query().Has("location") {
+		return res, e
+	}
+	for k := range h {
+		delete(h, k)
+	}
+	for k, v := range res.Header {
+		h[k] = v
+	}
+	return res, e
+}
+
+// apiCallsKey is the context key under which a caller can ask for the
+// number of API calls made with that context to be counted.
+type apiCallsKey struct{}
+
+// apiCallsTransport increments the *int64 counter found in the
+// request context, if any, for every request sent.
+type apiCallsTransport struct {
+	transport http.RoundTripper
+}
+
+func (t apiCallsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if calls, ok := req.Context().Value(apiCallsKey{}).(*int64); ok {
+		atomic.AddInt64(calls, 1)
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// ifNoneMatchKey is the context key under which a caller can ask for
+// object writes to be made conditional on the object not existing.
+type ifNoneMatchKey struct{}
+
+// ifNoneMatchTransport sets "If-None-Match: *" on the requests creating
+// an object, a single PUT or the completion of a multipart upload, when
+// the request context asks for it.
+type ifNoneMatchTransport struct {
+	transport http.RoundTripper
+}
+
+func (t ifNoneMatchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ok, _ := req.Context().Value(ifNoneMatchKey{}).(bool); ok {
+		_, isPart := req.URL.Query()["uploadId"]
+		if (req.Method == http.MethodPut && !isPart) || (req.Method == http.MethodPost && isPart) {
+			req = req.Clone(req.Context())
+			req.Header.Set("If-None-Match", "*")
+		}
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// uploadRecorderKey is the context key under which a caller can ask for
+// the ids of the multipart uploads created with that context to be
+// recorded.
+type uploadRecorderKey struct{}
+
+// uploadRecorder - the multipart uploads of an object created with a
+// context and not finished yet. They are recorded by the parent recorder
+// found in that context too, e.g. of a copy session, by upload id to the
+// aliased URL of their object.
+type uploadRecorder struct {
+	parent *uploadRecorder
+	url    string
+
+	mu      sync.Mutex
+	uploads map[string]string
+}
+
+// newUploadRecorder returns a recorder of the uploads of url, child of
+// the recorder of ctx, if any.
+func newUploadRecorder(ctx context.Context, url string) *uploadRecorder {
+	parent, _ := ctx.Value(uploadRecorderKey{}).(*uploadRecorder)
+	return &uploadRecorder{parent: parent, url: url, uploads: make(map[string]string)}
+}
+
+func (r *uploadRecorder) add(uploadID, url string) {
+	r.mu.Lock()
+	r.uploads[uploadID] = url
+	r.mu.Unlock()
+	if r.parent != nil {
+		r.parent.add(uploadID, url)
+	}
+}
+
+// remove forgets a finished or aborted upload.
+func (r *uploadRecorder) remove(uploadID string) {
+	r.mu.Lock()
+	delete(r.uploads, uploadID)
+	r.mu.Unlock()
+	if r.parent != nil {
+		r.parent.remove(uploadID)
+	}
+}
+
+// list returns the recorded uploads, by upload id to URL.
+func (r *uploadRecorder) list() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uploads := make(map[string]string, len(r.uploads))
+	for id, url := range r.uploads {
+		uploads[id] = url
+	}
+	return uploads
+}
+
+// uploadIDsTransport records the id of the multipart uploads created
+// into the recorder found in the request context, if any.
+type uploadIDsTransport struct {
+	transport http.RoundTripper
+}
+
+func (t uploadIDsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, e := t.transport.RoundTrip(req)
+	recorder, ok := req.Context().Value(uploadRecorderKey{}).(*uploadRecorder)
+	if !ok || recorder.url == "" || e != nil || res.StatusCode != http.StatusOK || req.Method != http.MethodPost || !req.URL.Query().Has("uploads") {
+		return res, e
+	}
+	body, e := io.ReadAll(res.Body)
+	res.Body.Close()
+	if e != nil {
+		return nil, e
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if xml.Unmarshal(body, &initiated) == nil && initiated.UploadID != "" {
+		recorder.add(initiated.UploadID, recorder.url)
+	}
+	return res, nil
+}
+
+// sharedLimitFallback is used to report only once that the shared
+// upload limit could not be set up.
+var sharedLimitFallback sync.Once
+
+// getSharedLimitTransport limits uploads against a rate shared by all mc
+// processes on this host. When the shared state cannot be used, uploads
+// are limited per process instead.
+func getSharedLimitTransport(config *Config, transport http.RoundTripper) http.RoundTripper {
+	if config.UploadLimitShared <= 0 {
+		return transport
+	}
+	// The state lives in the config dir of the user, others cannot
+	// tamper with it.
+	configDir, err := getMcConfigDir()
+	if err == nil {
+		statePath := filepath.Join(configDir, "limit-upload-shared")
+		sharedTransport, e := limiter.NewShared(statePath, config.UploadLimitShared, config.LimitBurst, transport)
+		if e == nil {
+			return sharedTransport
+		}
+		err = probe.NewError(e)
+	}
+	sharedLimitFallback.Do(func() {
+		errorIf(err, "Unable to use shared upload limit, falling back to per-process limiting.")
+	})
+	return limiter.New(config.UploadLimitShared, 0, config.LimitBurst, transport)
+}
+
 // getCredentialsChainForConfig returns an []credentials.Provider array for the config
 // and the STS configuration (if present)
 func getCredentialsChainForConfig(config *Config, transport http.RoundTripper) ([]credentials.Provider, *probe.Error) {
@@ -257,6 +461,7 @@ func getCredentialsChainForConfig(config *Config, transport http.RoundTripper) (
 // newFactory encloses New function with client cache.
 func newFactory() func(config *Config) (Client, *probe.Error) {
 	clientCache := make(map[uint32]*minio.Client)
+	accelClientCache := make(map[uint32]*minio.Client)
 	var mutex sync.Mutex
 
 	// Return New function.
@@ -267,8 +472,6 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 		// Save if target supports virtual host style.
 		hostName := targetURL.Host
 
-		confSum := getConfigHash(config)
-
 		useTLS := isHostTLS(config)
 
 		// Instantiate s3
@@ -277,6 +480,35 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 		s3Clnt.targetURL = targetURL
 
 		s3Clnt.virtualStyle = isVirtualHostStyle(hostName, config.Lookup)
+
+		// Buckets unreachable in virtual host style are addressed in
+		// path style, unless path style was chosen explicitly.
+		if bucket, _ := s3Clnt.url2BucketAndObject(); s3Clnt.virtualStyle && bucket != "" && !config.Accelerate && !isAmazonAccelerated(hostName) {
+			if !virtualHostReachable(hostName, bucket, useTLS) {
+				pathConfig := *config
+				pathConfig.Lookup = minio.BucketLookupPath
+				config = &pathConfig
+				s3Clnt.virtualStyle = false
+			}
+		}
+
+		// Use the region set or discovered earlier for this bucket, if any.
+		if bucket, _ := s3Clnt.url2BucketAndObject(); bucket != "" {
+			region, ok := bucketRegionOverrides.Load(hostName + "/" + bucket)
+			if !ok && config.Region == "" {
+				region, ok = bucketRegions.Load(hostName + "/" + bucket)
+			}
+			if ok && region.(string) != config.Region {
+				regionConfig := *config
+				regionConfig.Region = region.(string)
+				config = &regionConfig
+			}
+		}
+		s3Clnt.config = config
+
+		confSum := getConfigHash(config)
+		s3Clnt.multipartThreshold = config.MultipartThreshold
+		s3Clnt.listVersion = config.ListVersion
 		isS3AcceleratedEndpoint := isAmazonAccelerated(hostName)
 
 		if s3Clnt.virtualStyle {
@@ -291,7 +523,8 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 		defer mutex.Unlock()
 		var api *minio.Client
 		var found bool
-		if api, found = clientCache[confSum]; !found {
+		needsAccel := config.Accelerate && !isS3AcceleratedEndpoint
+		if api, found = clientCache[confSum]; !found || (needsAccel && accelClientCache[confSum] == nil) {
 
 			transport := getTransportForConfig(config, true)
 
@@ -316,10 +549,15 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 			// Not found. Instantiate a new MinIO
 			var e error
 
+			region := config.Region
+			if region == "" {
+				region = env.Get("MC_REGION", env.Get("AWS_REGION", ""))
+			}
+
 			options := minio.Options{
 				Creds:        creds,
 				Secure:       useTLS,
-				Region:       env.Get("MC_REGION", env.Get("AWS_REGION", "")),
+				Region:       region,
 				BucketLookup: config.Lookup,
 				Transport:    transport,
 			}
@@ -337,17 +575,64 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 			// Set app info.
 			api.SetAppInfo(config.AppName, config.AppVersion)
 
+			// A second client sends object operations to the transfer
+			// acceleration endpoint, when asked for.
+			if needsAccel {
+				if !isAmazon(hostName) {
+					return nil, probe.NewError(errors.New("transfer acceleration is only supported by Amazon S3"))
+				}
+				accelAPI, e := minio.New(hostName, &options)
+				if e != nil {
+					return nil, probe.NewError(e)
+				}
+				accelAPI.SetS3TransferAccelerate(amazonHostNameAccelerated)
+				accelAPI.SetAppInfo(config.AppName, config.AppVersion)
+				accelClientCache[confSum] = accelAPI
+			}
+
 			// Cache the new MinIO Client with hash of config as key.
 			clientCache[confSum] = api
 		}
 
 		// Store the new api object.
 		s3Clnt.api = api
+		if needsAccel {
+			s3Clnt.accelAPI = accelClientCache[confSum]
+		}
 
 		return s3Clnt, nil
 	}
 }
 
+// accelerateBuckets remembers whether transfer acceleration is enabled
+// on the buckets used with --accelerate.
+var accelerateBuckets sync.Map
+
+// objectAPI returns the client for object uploads and downloads, going
+// through the transfer acceleration endpoint if enabled on the bucket.
+// The bucket is checked once, falling back to the standard endpoint.
+func (c *S3Client) objectAPI(ctx context.Context, bucket string) *minio.Client {
+	if c.accelAPI == nil || strings.Contains(bucket, ".") {
+		return c.api
+	}
+	key := c.targetURL.Host + "/" + bucket
+	if enabled, ok := accelerateBuckets.Load(key); ok {
+		if enabled.(bool) {
+			return c.accelAPI
+		}
+		return c.api
+	}
+	_, e := c.accelAPI.BucketExists(ctx, bucket)
+	enabled := minio.ToErrorResponse(e).StatusCode != http.StatusBadRequest
+	if _, loaded := accelerateBuckets.LoadOrStore(key, enabled); !loaded && !enabled && !globalQuiet && !globalJSON {
+		console.Infof("[Warn] Transfer acceleration is not enabled on bucket `%s`, using the standard endpoint.\n", bucket)
+	}
+	if enabled {
+		return c.accelAPI
+	}
+	return c.api
+}
+
 // S3New returns an initialized S3Client structure. If debug is enabled,
 // it also enables an internal trace transport.
 var S3New = newFactory()
@@ -771,21 +1056,25 @@ func (c *S3Client) Select(ctx context.Context, expression string, sse encrypt.Se
 	return reader, nil
 }
 
+// decodeEventKey returns the object name of a notification record. Event
+// keys are always URL encoded, so a space arrives as '+' and a literal '+'
+// or '%' arrives escaped; decoding them only when they happen to contain
+// an encoded slash made mirror --watch upload such objects under the
+// encoded name. Uploads and other requests are unaffected, minio-go
+// encodes the keys of their paths and decodes listed keys itself.
+func decodeEventKey(key string) string {
+	decoded, e := url.QueryUnescape(key)
+	if e != nil {
+		return key
+	}
+	return decoded
+}
+
 func (c *S3Client) notificationToEventsInfo(ninfo notification.Info) []EventInfo {
 	eventsInfo := make([]EventInfo, len(ninfo.Records))
 	for i, record := range ninfo.Records {
 		bucketName := record.S3.Bucket.Name
-		var key string
-		// Unescape only if needed, look for URL encoded content.
-		if strings.Contains(record.S3.Object.Key, "%2F") {
-			var e error
-			key, e = url.QueryUnescape(record.S3.Object.Key)
-			if e != nil {
-				key = record.S3.Object.Key
-			}
-		} else {
-			key = record.S3.Object.Key
-		}
+		key := decodeEventKey(record.S3.Object.Key)
 		u := c.targetURL.Clone()
 		u.Path = path.Join(string(u.Separator), bucketName, key)
 		if strings.HasPrefix(record.EventName, "s3:ObjectCreated:") {
@@ -951,7 +1240,12 @@ func (c *S3Client) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *pr
 	if opts.Zip {
 		o.Set("x-minio-extract", "true")
 	}
-	if opts.RangeStart != 0 {
+	if opts.RangeLength > 0 {
+		err := o.SetRange(opts.RangeStart, opts.RangeStart+opts.RangeLength-1)
+		if err != nil {
+			return nil, probe.NewError(err)
+		}
+	} else if opts.RangeStart != 0 {
 		err := o.SetRange(opts.RangeStart, 0)
 		if err != nil {
 			return nil, probe.NewError(err)
@@ -960,8 +1254,11 @@ func (c *S3Client) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *pr
 	// Disallow automatic decompression for some objects with content-encoding set.
 	o.Set("Accept-Encoding", "identity")
 
-	reader, e := c.api.GetObject(ctx, bucket, object, o)
+	reader, e := c.objectAPI(ctx, bucket).GetObject(ctx, bucket, object, o)
 	if e != nil {
+		if sseErr := sseCustomerKeyError(e, opts.SSE, c.targetURL.String(), nil); sseErr != nil {
+			return nil, probe.NewError(sseErr)
+		}
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse.Code == "NoSuchBucket" {
 			return nil, probe.NewError(BucketDoesNotExist{
@@ -994,6 +1291,9 @@ func (c *S3Client) Copy(ctx context.Context, source string, opts CopyOptions, pr
 	for k, v := range opts.metadata {
 		metadata[k] = v
 	}
+	if _, ok := aclUnsupportedBuckets.Load(c.targetURL.Host + "/" + dstBucket); ok {
+		removeACLHeaders(metadata)
+	}
 
 	delete(metadata, "X-Amz-Storage-Class")
 	if opts.storageClass != "" {
@@ -1037,17 +1337,37 @@ func (c *S3Client) Copy(ctx context.Context, source string, opts CopyOptions, pr
 
 	// Assign metadata after irrelevant parts are delete above
 	destOpts.UserMetadata = metadata
-	destOpts.ReplaceMetadata = len(metadata) > 0
+	destOpts.ReplaceMetadata = len(metadata) > 0 || opts.replaceMetadata
+
+	// ComposeObject reads the source with the region of the target, a
+	// source in another region is copied with a single request or with
+	// a multipart upload reading it in its own region.
+	srcRegion := c.bucketRegion(srcOpts.Bucket)
+	crossRegion := srcRegion != "" && c.config != nil && c.config.Region != "" && srcRegion != c.config.Region
 
+	// Multipart copies are recorded like multipart uploads, see Put.
+	uploads := newUploadRecorder(ctx, c.aliasedURL(dstBucket, dstObject))
+	copyCtx := context.WithValue(ctx, uploadRecorderKey{}, uploads)
+
+	var ui minio.UploadInfo
 	var e error
-	if opts.disableMultipart || opts.size < 64*1024*1024 {
-		_, e = c.api.CopyObject(ctx, destOpts, srcOpts)
-	} else {
-		_, e = c.api.ComposeObject(ctx, destOpts, srcOpts)
+	switch {
+	case opts.disableMultipart || opts.size < 64*1024*1024 || (crossRegion && opts.size <= maxCopyObjectSize):
+		ui, e = c.api.CopyObject(copyCtx, destOpts, srcOpts)
+	case crossRegion:
+		ui, e = c.copyAcrossRegions(copyCtx, srcRegion, srcOpts, destOpts)
+	default:
+		ui, e = c.api.ComposeObject(copyCtx, destOpts, srcOpts)
 	}
 
 	if e != nil {
+		if orphaned := c.abortOrphanedUploads(dstBucket, dstObject, uploads); len(orphaned) > 0 {
+			errorIf(probe.NewError(UploadNotAborted{URL: c.aliasedURL(dstBucket, dstObject), UploadIDs: orphaned}), "Unable to clean up a failed copy.")
+		}
 		errResponse := minio.ToErrorResponse(e)
+		if c.rememberACLUnsupported(e, dstBucket, metadata) {
+			return c.Copy(ctx, source, opts, progress)
+		}
 		if errResponse.Code == "AccessDenied" {
 			return probe.NewError(PathInsufficientPermission{
 				Path: c.targetURL.String(),
@@ -1068,20 +1388,178 @@ func (c *S3Client) Copy(ctx context.Context, source string, opts CopyOptions, pr
 		}
 		return probe.NewError(e)
 	}
+	for uploadID := range uploads.list() {
+		uploads.remove(uploadID)
+	}
+	if opts.waitConsistent.timeout > 0 {
+		if ui.Size == 0 {
+			ui.Size = opts.size
+		}
+		return c.waitConsistent(ctx, ui, opts.tgtSSE, opts.waitConsistent)
+	}
 	return nil
 }
 
-// Put - upload an object with custom metadata.
-func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progress io.Reader, putOpts PutOptions) (int64, *probe.Error) {
-	bucket, object := c.url2BucketAndObject()
-	if bucket == "" {
-		return 0, probe.NewError(BucketNameEmpty{})
+// maxCopyObjectSize is the largest object copied with a single request.
+const maxCopyObjectSize = 5 * humanize.GiByte
+
+// copyAcrossRegions - copies an object from a bucket in srcRegion with
+// a multipart upload, reading its metadata with a client of srcRegion.
+func (c *S3Client) copyAcrossRegions(ctx context.Context, srcRegion string, src minio.CopySrcOptions, dst minio.CopyDestOptions) (minio.UploadInfo, error) {
+	srcURL := *c.targetURL
+	srcURL.Path = string(srcURL.Separator) + src.Bucket + string(srcURL.Separator) + src.Object
+	srcConfig := *c.config
+	srcConfig.HostURL = srcURL.String()
+	srcConfig.Region = srcRegion
+	srcClnt, err := S3New(&srcConfig)
+	if err != nil {
+		return minio.UploadInfo{}, err.ToGoError()
+	}
+
+	statOpts := minio.StatObjectOptions{VersionID: src.VersionID}
+	if src.Encryption != nil && src.Encryption.Type() == encrypt.SSEC {
+		statOpts.ServerSideEncryption = src.Encryption
+	}
+	st, e := srcClnt.(*S3Client).api.StatObject(ctx, src.Bucket, src.Object, statOpts)
+	if e != nil {
+		return minio.UploadInfo{}, e
+	}
+
+	putOpts := minio.PutObjectOptions{
+		UserMetadata:         dst.UserMetadata,
+		ServerSideEncryption: dst.Encryption,
+		Mode:                 dst.Mode,
+		RetainUntilDate:      dst.RetainUntilDate,
+		LegalHold:            dst.LegalHold,
+	}
+	if !dst.ReplaceMetadata {
+		putOpts.UserMetadata = st.UserMetadata
+		putOpts.ContentType = st.ContentType
+	}
+
+	// Headers of every part, copying the version listed and failing if
+	// the source changes during the copy.
+	copySource := s3utils.EncodePath(src.Bucket + "/" + src.Object)
+	if src.VersionID != "" {
+		copySource += "?versionId=" + url.QueryEscape(src.VersionID)
+	}
+	header := make(http.Header)
+	header.Set("x-amz-copy-source", copySource)
+	header.Set("x-amz-copy-source-if-match", st.ETag)
+	if src.Encryption != nil && src.Encryption.Type() == encrypt.SSEC {
+		encrypt.SSECopy(src.Encryption).Marshal(header)
+	}
+	if dst.Encryption != nil && dst.Encryption.Type() == encrypt.SSEC {
+		dst.Encryption.Marshal(header)
+	}
+	partHeaders := make(map[string]string, len(header))
+	for k := range header {
+		partHeaders[k] = header.Get(k)
 	}
 
+	totalParts, partSize, _, e := minio.OptimalPartInfo(st.Size, 0)
+	if e != nil {
+		return minio.UploadInfo{}, e
+	}
+	core := minio.Core{Client: c.api}
+	uploadID, e := core.NewMultipartUpload(ctx, dst.Bucket, dst.Object, putOpts)
+	if e != nil {
+		return minio.UploadInfo{}, e
+	}
+	parts := make([]minio.CompletePart, 0, totalParts)
+	for offset, partID := int64(0), 1; offset < st.Size; offset, partID = offset+partSize, partID+1 {
+		length := partSize
+		if st.Size-offset < length {
+			length = st.Size - offset
+		}
+		part, e := core.CopyObjectPart(ctx, src.Bucket, src.Object, dst.Bucket, dst.Object, uploadID, partID, offset, length, partHeaders)
+		if e != nil {
+			core.AbortMultipartUpload(ctx, dst.Bucket, dst.Object, uploadID)
+			return minio.UploadInfo{}, e
+		}
+		parts = append(parts, part)
+		if dst.Progress != nil {
+			io.CopyN(io.Discard, dst.Progress, length)
+		}
+	}
+	ui, e := core.CompleteMultipartUpload(ctx, dst.Bucket, dst.Object, uploadID, parts, minio.PutObjectOptions{})
+	if e != nil {
+		core.AbortMultipartUpload(ctx, dst.Bucket, dst.Object, uploadID)
+		return minio.UploadInfo{}, e
+	}
+	ui.Size = st.Size
+	return ui, nil
+}
+
+// isMD5ETag returns true for ETags in the S3 format, the hex MD5 sum of
+// the object or, for multipart uploads, of its parts followed by -N.
+func isMD5ETag(etag string) bool {
+	etag = strings.Trim(etag, "\"")
+	if i := strings.LastIndexByte(etag, '-'); i >= 0 {
+		if _, e := strconv.ParseUint(etag[i+1:], 10, 32); e != nil {
+			return false
+		}
+		etag = etag[:i]
+	}
+	if len(etag) != 32 {
+		return false
+	}
+	_, e := hex.DecodeString(etag)
+	return e == nil
+}
+
+// etagsMatch compares the ETag returned by an upload with the one the
+// object is read back with. Some S3 compatible gateways return other
+// ETag formats that may differ between calls for the same object, these
+// are not compared.
+func etagsMatch(uploaded, stat string) bool {
+	if uploaded == "" || !isMD5ETag(uploaded) || !isMD5ETag(stat) {
+		return true
+	}
+	return strings.EqualFold(strings.Trim(uploaded, "\""), strings.Trim(stat, "\""))
+}
+
+// waitConsistent polls the uploaded object until it is returned with
+// the expected ETag and size, for endpoints lacking read-after-write
+// consistency.
+func (c *S3Client) waitConsistent(ctx context.Context, ui minio.UploadInfo, sse encrypt.ServerSide, opts waitConsistentOptions) *probe.Error {
+	statOpts := minio.StatObjectOptions{}
+	statOpts.VersionID = ui.VersionID
+	if sse != nil && sse.Type() == encrypt.SSEC {
+		statOpts.ServerSideEncryption = sse
+	}
+
+	interval := opts.interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	deadline := time.Now().Add(opts.timeout)
+	for {
+		st, e := c.api.StatObject(ctx, ui.Bucket, ui.Key, statOpts)
+		if e == nil && st.Size == ui.Size && (opts.disableETagCheck || etagsMatch(ui.ETag, st.ETag)) {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return probe.NewError(ObjectNotConsistent{Object: ui.Key, Timeout: opts.timeout})
+		}
+		select {
+		case <-ctx.Done():
+			return probe.NewError(ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// putObjectOptions - converts the metadata and options of an upload of
+// an object to bucket into its minio.PutObjectOptions.
+func (c *S3Client) putObjectOptions(bucket string, progress io.Reader, putOpts PutOptions) (minio.PutObjectOptions, *probe.Error) {
 	metadata := make(map[string]string, len(putOpts.metadata))
 	for k, v := range putOpts.metadata {
 		metadata[k] = v
 	}
+	if _, ok := aclUnsupportedBuckets.Load(c.targetURL.Host + "/" + bucket); ok {
+		removeACLHeaders(metadata)
+	}
 
 	// Do not copy storage class, it needs to be specified in putOpts
 	delete(metadata, "X-Amz-Storage-Class")
@@ -1119,7 +1597,7 @@ func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progre
 	if ok {
 		tagsSet, e := tags.Parse(tagsHdr, true)
 		if e != nil {
-			return 0, probe.NewError(e)
+			return minio.PutObjectOptions{}, probe.NewError(e)
 		}
 		tagsMap = tagsSet.ToMap()
 		delete(metadata, "X-Amz-Tagging")
@@ -1174,9 +1652,143 @@ func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progre
 		opts.SendContentMd5 = true
 	}
 
-	ui, e := c.api.PutObject(ctx, bucket, object, reader, size, opts)
+	return opts, nil
+}
+
+// Put - upload an object with custom metadata.
+func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progress io.Reader, putOpts PutOptions) (int64, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if bucket == "" {
+		return 0, probe.NewError(BucketNameEmpty{})
+	}
+
+	// A retry reads reader again from where this upload started,
+	// reporting to progress the bytes not reported yet only.
+	start := int64(-1)
+	if seeker, ok := reader.(io.Seeker); ok {
+		if offset, e := seeker.Seek(0, io.SeekCurrent); e == nil {
+			start = offset
+		}
+	}
+	uploadProg := newUploadProgress(progress)
+	if uploadProg != nil {
+		progress = uploadProg
+	}
+	retry := func() (n int64, err *probe.Error, retried bool) {
+		if start < 0 {
+			return 0, nil, false
+		}
+		if _, e := reader.(io.Seeker).Seek(start, io.SeekStart); e != nil {
+			return 0, nil, false
+		}
+		var retryProgress io.Reader
+		if uploadProg != nil {
+			retryProgress = uploadProg.retry()
+		}
+		n, err = c.Put(ctx, reader, size, retryProgress, putOpts)
+		return n, err, true
+	}
+
+	opts, err := c.putObjectOptions(bucket, progress, putOpts)
+	if err != nil {
+		return 0, err
+	}
+
+	if readerAt, ok := reader.(io.ReaderAt); ok && putOpts.deltaBlockSize > 0 && !putOpts.noClobber && !putOpts.disableMultipart {
+		ui, delta, err := c.putDelta(ctx, bucket, object, readerAt, size, progress, opts, putOpts.deltaBlockSize)
+		if err != nil {
+			return 0, err.Trace(c.targetURL.String())
+		}
+		if delta {
+			return c.putDone(ctx, ui, putOpts)
+		}
+		// The full upload only reports the progress of the parts not
+		// reported by the delta upload.
+		if uploadProg != nil {
+			uploadProg = uploadProg.retry()
+			progress, opts.Progress = uploadProg, uploadProg
+		}
+	}
+
+	if putOpts.trailerChecksum.IsSet() && !opts.SendContentMd5 && !opts.DisableMultipart && !putOpts.noClobber {
+		ui, err := c.putTrailerChecksum(ctx, bucket, object, reader, size, progress, opts, putOpts.trailerChecksum)
+		if err != nil {
+			return 0, err.Trace(c.targetURL.String())
+		}
+		return c.putDone(ctx, ui, putOpts)
+	}
+
+	multipartThreshold := putOpts.multipartThreshold
+	if multipartThreshold == 0 {
+		multipartThreshold = c.multipartThreshold
+	}
+	if multipartThreshold > 0 && size >= 0 && !opts.DisableMultipart {
+		if uint64(size) <= multipartThreshold {
+			opts.DisableMultipart = true
+		} else if opts.PartSize == 0 && uint64(size) < defaultMultipartPartSize {
+			// Objects smaller than the default part size are uploaded with
+			// a single PUT, use a smaller part size to honor the threshold.
+			opts.PartSize = multipartThreshold
+			if opts.PartSize < minMultipartPartSize {
+				opts.PartSize = minMultipartPartSize
+			}
+		}
+	} else if size >= 0 && !opts.DisableMultipart {
+		// A known size fitting in a single part, e.g. a local file of
+		// exactly the part size, is uploaded with a single PUT instead of
+		// a multipart upload of one part, which costs two more requests
+		// and is rejected by some gateways.
+		partSize := opts.PartSize
+		if partSize == 0 {
+			partSize = defaultMultipartPartSize
+		}
+		if uint64(size) <= partSize {
+			opts.DisableMultipart = true
+		}
+	}
+
+	putCtx := ctx
+	if putOpts.noClobber {
+		if _, ok := unconditionalPutHosts.Load(c.targetURL.Host); ok {
+			if err := c.statNoClobber(ctx, bucket, object); err != nil {
+				return 0, err
+			}
+		} else {
+			putCtx = context.WithValue(ctx, ifNoneMatchKey{}, true)
+		}
+	}
+
+	var uploads *uploadRecorder
+	if !opts.DisableMultipart {
+		uploads = newUploadRecorder(ctx, c.aliasedURL(bucket, object))
+		putCtx = context.WithValue(putCtx, uploadRecorderKey{}, uploads)
+	}
+	ui, e := c.objectAPI(ctx, bucket).PutObject(putCtx, bucket, object, reader, size, opts)
 	if e != nil {
+		if uploads != nil {
+			if orphaned := c.abortOrphanedUploads(bucket, object, uploads); len(orphaned) > 0 {
+				errorIf(probe.NewError(UploadNotAborted{URL: c.aliasedURL(bucket, object), UploadIDs: orphaned}), "Unable to clean up a failed upload.")
+			}
+		}
 		errResponse := minio.ToErrorResponse(e)
+		if putOpts.noClobber && (errResponse.Code == "PreconditionFailed" || errResponse.StatusCode == http.StatusPreconditionFailed) {
+			return ui.Size, probe.NewError(ObjectAlreadyExists{
+				Object: object,
+			})
+		}
+		if putCtx != ctx && isConditionalPutUnsupported(e) {
+			// Remember the host and retry with a Stat, when the
+			// content can be read again.
+			unconditionalPutHosts.Store(c.targetURL.Host, struct{}{})
+			if n, err, retried := retry(); retried {
+				return n, err
+			}
+		}
+		if c.rememberACLUnsupported(e, bucket, opts.UserMetadata) {
+			if n, err, retried := retry(); retried {
+				return n, err
+			}
+		}
 		if errResponse.Code == "UnexpectedEOF" || e == io.EOF {
 			return ui.Size, probe.NewError(UnexpectedEOF{
 				TotalSize:    size,
@@ -1213,30 +1825,244 @@ func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progre
 		}
 		return ui.Size, probe.NewError(e)
 	}
+	if uploads != nil {
+		// The uploads created are completed.
+		for uploadID := range uploads.list() {
+			uploads.remove(uploadID)
+		}
+	}
+	return c.putDone(ctx, ui, putOpts)
+}
+
+// putDone - finishes an uploaded object, whichever way it was uploaded,
+// returns its size.
+func (c *S3Client) putDone(ctx context.Context, ui minio.UploadInfo, putOpts PutOptions) (int64, *probe.Error) {
+	if putOpts.waitConsistent.timeout > 0 {
+		if err := c.waitConsistent(ctx, ui, putOpts.sse, putOpts.waitConsistent); err != nil {
+			return ui.Size, err
+		}
+	}
 	return ui.Size, nil
 }
 
+// orphanedUploadsTimeout bounds aborting the multipart uploads left
+// behind by a failed upload.
+const orphanedUploadsTimeout = 30 * time.Second
+
+// abortOrphanedUploads aborts the multipart uploads of an object created
+// by a failed upload, as recorded in uploads. The client aborts them
+// itself but ignores its abort failing, e.g. on a network blip, leaving
+// parts stored and billed. It returns the ids of the uploads still there.
+func (c *S3Client) abortOrphanedUploads(bucket, object string, uploads *uploadRecorder) (orphaned []string) {
+	// The context of the upload may be canceled already.
+	ctx, cancel := context.WithTimeout(context.Background(), orphanedUploadsTimeout)
+	defer cancel()
+
+	for uploadID := range uploads.list() {
+		e := (minio.Core{Client: c.api}).AbortMultipartUpload(ctx, bucket, object, uploadID)
+		if e != nil && minio.ToErrorResponse(e).Code != "NoSuchUpload" {
+			orphaned = append(orphaned, uploadID)
+			continue
+		}
+		uploads.remove(uploadID)
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+// aliasedURL returns the URL of an object with the alias of the client.
+func (c *S3Client) aliasedURL(bucket, object string) string {
+	if c.config.Alias == "" {
+		return c.targetURL.String()
+	}
+	return c.config.Alias + "/" + bucket + "/" + object
+}
+
+// aclUnsupportedBuckets remembers the buckets, as host/bucket, found to
+// reject object ACLs because they enforce bucket owner ownership.
+var aclUnsupportedBuckets sync.Map
+
+// removeACLHeaders deletes the canned ACL and grant headers set by
+// --preserve-acl from metadata.
+func removeACLHeaders(metadata map[string]string) {
+	for k := range metadata {
+		if http.CanonicalHeaderKey(k) == "X-Amz-Acl" || strings.HasPrefix(http.CanonicalHeaderKey(k), "X-Amz-Grant-") {
+			delete(metadata, k)
+		}
+	}
+}
+
+// rememberACLUnsupported returns true when a write carrying ACL headers
+// failed because the bucket does not accept object ACLs. The bucket is
+// remembered so that the retry and the next writes leave ACLs out.
+func (c *S3Client) rememberACLUnsupported(e error, bucket string, metadata map[string]string) bool {
+	if minio.ToErrorResponse(e).Code != "AccessControlListNotSupported" {
+		return false
+	}
+	withACL := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		withACL[k] = v
+	}
+	removeACLHeaders(withACL)
+	if len(withACL) == len(metadata) {
+		return false
+	}
+	if _, loaded := aclUnsupportedBuckets.LoadOrStore(c.targetURL.Host+"/"+bucket, struct{}{}); !loaded && !globalQuiet && !globalJSON {
+		console.Infof("[Warn] Bucket `%s` enforces bucket owner ownership, object ACLs are not copied to it.\n", bucket)
+	}
+	return true
+}
+
+// getObjectACL returns the grants of the object as the grant headers
+// setting the same ACL on a copy.
+func (c *S3Client) getObjectACL(ctx context.Context) (map[string]string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	info, e := c.api.GetObjectACL(ctx, bucket, object)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	grantees := map[string][]string{}
+	for _, grant := range info.Grant {
+		header, ok := map[string]string{
+			"READ":         "X-Amz-Grant-Read",
+			"WRITE":        "X-Amz-Grant-Write",
+			"READ_ACP":     "X-Amz-Grant-Read-Acp",
+			"WRITE_ACP":    "X-Amz-Grant-Write-Acp",
+			"FULL_CONTROL": "X-Amz-Grant-Full-Control",
+		}[grant.Permission]
+		if !ok {
+			continue
+		}
+		grantee := `id="` + grant.Grantee.ID + `"`
+		if grant.Grantee.URI != "" {
+			grantee = `uri="` + grant.Grantee.URI + `"`
+		}
+		grantees[header] = append(grantees[header], grantee)
+	}
+	headers := make(map[string]string, len(grantees))
+	for header, list := range grantees {
+		headers[header] = strings.Join(list, ", ")
+	}
+	return headers, nil
+}
+
+// uploadProgress forwards the progress of an upload to progress, the
+// attempts retrying a failed upload only report the bytes read beyond
+// those reported by the previous ones.
+type uploadProgress struct {
+	progress io.Reader
+
+	// Parts may be uploaded concurrently.
+	mu       sync.Mutex
+	reported int64 // by all attempts
+	read     int64 // by this attempt
+}
+
+// newUploadProgress returns the progress of the first attempt of an
+// upload, or of the next attempt when progress is one already.
+func newUploadProgress(progress io.Reader) *uploadProgress {
+	switch p := progress.(type) {
+	case nil:
+		return nil
+	case *uploadProgress:
+		return p
+	}
+	return &uploadProgress{progress: progress}
+}
+
+func (p *uploadProgress) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	start := p.read
+	p.read += int64(len(b))
+	if p.read <= p.reported {
+		return len(b), nil
+	}
+	var from int64
+	if p.reported > start {
+		from = p.reported - start
+	}
+	p.reported = p.read
+	n, e := p.progress.Read(b[from:])
+	return int(from) + n, e
+}
+
+// retry returns the progress of the next attempt of the upload.
+func (p *uploadProgress) retry() *uploadProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &uploadProgress{progress: p.progress, reported: p.reported}
+}
+
+// unconditionalPutHosts remembers the hosts found not to support
+// conditional writes with "If-None-Match: *".
+var unconditionalPutHosts sync.Map
+
+// isConditionalPutUnsupported returns true if the error indicates the
+// server does not implement conditional writes.
+func isConditionalPutUnsupported(e error) bool {
+	errResp := minio.ToErrorResponse(e)
+	if errResp.Code == "NotImplemented" {
+		return true
+	}
+	return errResp.StatusCode == http.StatusNotImplemented
+}
+
+// statNoClobber - fails with ObjectAlreadyExists when the object is
+// present, used for no-clobber writes to hosts lacking conditional writes.
+func (c *S3Client) statNoClobber(ctx context.Context, bucket, object string) *probe.Error {
+	_, e := c.api.StatObject(ctx, bucket, object, minio.StatObjectOptions{})
+	if e == nil {
+		return probe.NewError(ObjectAlreadyExists{
+			Object: object,
+		})
+	}
+	switch minio.ToErrorResponse(e).Code {
+	case "NoSuchKey", "NoSuchObject":
+		return nil
+	}
+	return probe.NewError(e)
+}
+
 // PutPart - upload an object with custom metadata. (Same as Put)
 func (c *S3Client) PutPart(ctx context.Context, reader io.Reader, size int64, progress io.Reader, putOpts PutOptions) (int64, *probe.Error) {
 	return c.Put(ctx, reader, size, progress, putOpts)
 }
 
-// Remove incomplete uploads.
-func (c *S3Client) removeIncompleteObjects(ctx context.Context, bucket string, objectsCh <-chan minio.ObjectInfo) <-chan minio.RemoveObjectResult {
-	removeObjectErrorCh := make(chan minio.RemoveObjectResult)
-
-	// Goroutine reads from objectsCh and sends error to removeObjectErrorCh if any.
+// removeIncompleteUploads aborts the incomplete uploads of contentCh,
+// only the listed upload when its upload id is known, otherwise every
+// upload of the object.
+func (c *S3Client) removeIncompleteUploads(ctx context.Context, contentCh <-chan *ClientContent) <-chan RemoveResult {
+	resultCh := make(chan RemoveResult)
 	go func() {
-		defer close(removeObjectErrorCh)
-
-		for info := range objectsCh {
-			if err := c.api.RemoveIncompleteUpload(ctx, bucket, info.Key); err != nil {
-				removeObjectErrorCh <- minio.RemoveObjectResult{ObjectName: info.Key, Err: err}
+		defer close(resultCh)
+		for content := range contentCh {
+			bucket, object := c.splitPath(content.URL.Path)
+			if bucket == "" || object == "" {
+				continue
+			}
+			var e error
+			if content.UploadID != "" {
+				e = minio.Core{Client: c.api}.AbortMultipartUpload(ctx, bucket, object, content.UploadID)
+			} else {
+				e = c.api.RemoveIncompleteUpload(ctx, bucket, object)
+			}
+			result := RemoveResult{
+				RemoveObjectResult: minio.RemoveObjectResult{ObjectName: object},
+				BucketName:         bucket,
+				UploadID:           content.UploadID,
+			}
+			if e != nil {
+				result.Err = probe.NewError(e)
+			}
+			select {
+			case resultCh <- result:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
-
-	return removeObjectErrorCh
+	return resultCh
 }
 
 // AddUserAgent - add custom user agent.
@@ -1248,11 +2074,17 @@ func (c *S3Client) AddUserAgent(app, version string) {
 type RemoveResult struct {
 	minio.RemoveObjectResult
 	BucketName string
-	Err        *probe.Error
+	// UploadID is the aborted upload, when removing incomplete uploads.
+	UploadID string
+	Err      *probe.Error
 }
 
 // Remove - remove object or bucket(s).
 func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass, isForceDel bool, contentCh <-chan *ClientContent) <-chan RemoveResult {
+	if isIncomplete {
+		return c.removeIncompleteUploads(ctx, contentCh)
+	}
+
 	resultCh := make(chan RemoveResult)
 
 	prevBucket := ""
@@ -1322,11 +2154,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 				if prevBucket == "" {
 					objectsCh = make(chan minio.ObjectInfo)
 					prevBucket = bucket
-					if isIncomplete {
-						statusCh = c.removeIncompleteObjects(ctx, bucket, objectsCh)
-					} else {
-						statusCh = c.api.RemoveObjectsWithResult(ctx, bucket, objectsCh, opts)
-					}
+					statusCh = c.api.RemoveObjectsWithResult(ctx, bucket, objectsCh, opts)
 				}
 
 				if prevBucket != bucket {
@@ -1349,7 +2177,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 					}
 
 					// Remove bucket if it qualifies.
-					if isRemoveBucket && !isIncomplete {
+					if isRemoveBucket {
 						if e := c.api.RemoveBucket(ctx, prevBucket); e != nil {
 							resultCh <- RemoveResult{
 								BucketName: bucket,
@@ -1360,11 +2188,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 					}
 					// Re-init objectsCh for next bucket
 					objectsCh = make(chan minio.ObjectInfo)
-					if isIncomplete {
-						statusCh = c.removeIncompleteObjects(ctx, bucket, objectsCh)
-					} else {
-						statusCh = c.api.RemoveObjectsWithResult(ctx, bucket, objectsCh, opts)
-					}
+					statusCh = c.api.RemoveObjectsWithResult(ctx, bucket, objectsCh, opts)
 					prevBucket = bucket
 				}
 
@@ -1434,7 +2258,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 			}
 		}
 		// Remove last bucket if it qualifies.
-		if isRemoveBucket && prevBucket != "" && !isIncomplete {
+		if isRemoveBucket && prevBucket != "" {
 			if e := c.api.RemoveBucket(ctx, prevBucket); e != nil {
 				resultCh <- RemoveResult{
 					BucketName: prevBucket,
@@ -1568,6 +2392,41 @@ func (c *S3Client) GetAccess(ctx context.Context) (string, string, *probe.Error)
 	return pType, policyStr, nil
 }
 
+// bucketLookupWorkers is the number of buckets whose details are looked
+// up in parallel when listing the root of an alias.
+const bucketLookupWorkers = 8
+
+// bucketPolicyPublic returns true if a bucket policy allows anonymous
+// requests without conditions, with the reason.
+func bucketPolicyPublic(policyStr string) (bool, string, error) {
+	if policyStr == "" {
+		return false, "no bucket policy", nil
+	}
+	var p policy.BucketAccessPolicy
+	if e := json.Unmarshal([]byte(policyStr), &p); e != nil {
+		return false, "", e
+	}
+	actions := set.NewStringSet()
+	conditional := false
+	for _, statement := range p.Statements {
+		if statement.Effect != "Allow" || !statement.Principal.AWS.Contains("*") {
+			continue
+		}
+		if len(statement.Conditions) > 0 {
+			conditional = true
+			continue
+		}
+		actions = actions.Union(statement.Actions)
+	}
+	switch {
+	case !actions.IsEmpty():
+		return true, "policy allows anonymous " + strings.Join(actions.ToSlice(), ", "), nil
+	case conditional:
+		return false, "policy allows anonymous requests under conditions only", nil
+	}
+	return false, "policy allows no anonymous requests", nil
+}
+
 // SetAccess set access policy permissions.
 func (c *S3Client) SetAccess(ctx context.Context, bucketPolicy string, isJSON bool) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
@@ -1607,18 +2466,117 @@ func (c *S3Client) SetAccess(ctx context.Context, bucketPolicy string, isJSON bo
 	return nil
 }
 
-// listObjectWrapper - select ObjectList mode depending on arguments
-func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object string, isRecursive bool, timeRef time.Time, withVersions, withDeleteMarkers, metadata bool, maxKeys int, zip bool) <-chan minio.ObjectInfo {
+// listObjectWrapper - select ObjectList mode depending on arguments,
+// retrying once in the bucket's region if it differs from the client's.
+func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object, startAfter string, isRecursive bool, timeRef time.Time, withVersions, withDeleteMarkers, metadata bool, maxKeys int, zip bool) <-chan minio.ObjectInfo {
+	objectCh := c.selectListObjects(ctx, bucket, object, startAfter, isRecursive, timeRef, withVersions, withDeleteMarkers, metadata, maxKeys, zip)
+	if bucket == "" {
+		return objectCh
+	}
+
+	retryCh := make(chan minio.ObjectInfo, 1)
+	go func() {
+		defer close(retryCh)
+
+		first := true
+		for objectInfo := range objectCh {
+			if first && objectInfo.Err != nil {
+				if region := bucketRegionMismatch(objectInfo.Err); region != "" {
+					regionClnt, err := c.withBucketRegion(bucket, region)
+					if err == nil {
+						// Stop the listing which failed before retrying.
+						for range objectCh {
+						}
+						for objectInfo := range regionClnt.selectListObjects(ctx, bucket, object, startAfter, isRecursive, timeRef, withVersions, withDeleteMarkers, metadata, maxKeys, zip) {
+							retryCh <- objectInfo
+						}
+						return
+					}
+				}
+			}
+			first = false
+			retryCh <- objectInfo
+		}
+	}()
+	return retryCh
+}
+
+// bucketRegions remembers the regions discovered for buckets, keyed
+// by host and bucket name.
+var bucketRegions sync.Map
+
+// bucketRegionOverrides holds the regions set explicitly for buckets,
+// e.g. with cp --source-region, keyed by host and bucket name. They
+// take precedence over the region of the alias and discovered regions.
+var bucketRegionOverrides sync.Map
+
+// setBucketRegion - sets the region of the bucket of an expanded URL,
+// for the clients created afterwards.
+func setBucketRegion(urlStr, region string) {
+	u := newClientURL(urlStr)
+	if bucket, _ := url2BucketAndObject(u); bucket != "" {
+		bucketRegionOverrides.Store(u.Host+"/"+bucket, region)
+	}
+}
+
+// bucketRegion - returns the region set or discovered for a bucket of
+// the host of the client, empty if unknown.
+func (c *S3Client) bucketRegion(bucket string) string {
+	if region, ok := bucketRegionOverrides.Load(c.targetURL.Host + "/" + bucket); ok {
+		return region.(string)
+	}
+	if region, ok := bucketRegions.Load(c.targetURL.Host + "/" + bucket); ok {
+		return region.(string)
+	}
+	return ""
+}
+
+// bucketRegionMismatch returns the region of the bucket if the error
+// reports that the request was sent to a different region.
+func bucketRegionMismatch(e error) string {
+	errResp := minio.ToErrorResponse(e)
+	switch errResp.Code {
+	case "AuthorizationHeaderMalformed", "PermanentRedirect", "InvalidRegion", "IllegalLocationConstraintException":
+		return errResp.Region
+	}
+	if errResp.StatusCode == http.StatusMovedPermanently {
+		return errResp.Region
+	}
+	return ""
+}
+
+// withBucketRegion remembers region as the region of bucket and returns
+// a client configured for it.
+func (c *S3Client) withBucketRegion(bucket, region string) (*S3Client, *probe.Error) {
+	if c.config == nil || c.config.Region == region {
+		return nil, errInvalidArgument().Trace(bucket, region)
+	}
+	bucketRegions.Store(c.targetURL.Host+"/"+bucket, region)
+	if globalDebug {
+		console.Debugf("Bucket `%s` is in region `%s`, retrying with that region.\n", bucket, region)
+	}
+
+	config := *c.config
+	config.Region = region
+	clnt, err := S3New(&config)
+	if err != nil {
+		return nil, err.Trace(bucket, region)
+	}
+	return clnt.(*S3Client), nil
+}
+
+// selectListObjects - select ObjectList mode depending on arguments
+func (c *S3Client) selectListObjects(ctx context.Context, bucket, object, startAfter string, isRecursive bool, timeRef time.Time, withVersions, withDeleteMarkers, metadata bool, maxKeys int, zip bool) <-chan minio.ObjectInfo {
 	if !timeRef.IsZero() || withVersions {
 		return c.listVersions(ctx, bucket, object, ListOptions{Recursive: isRecursive, TimeRef: timeRef, WithOlderVersions: withVersions, WithDeleteMarkers: withDeleteMarkers})
 	}
 
-	if isGoogle(c.targetURL.Host) {
+	if c.listVersion == 1 || (c.listVersion == 0 && isGoogle(c.targetURL.Host)) {
 		// Google Cloud S3 layer doesn't implement ListObjectsV2 implementation
 		// https://github.com/minio/mc/issues/3073
-		return c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, UseV1: true, MaxKeys: maxKeys})
+		return c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, StartAfter: startAfter, Recursive: isRecursive, UseV1: true, MaxKeys: maxKeys})
 	}
-	opts := minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, WithMetadata: metadata, MaxKeys: maxKeys}
+	opts := minio.ListObjectsOptions{Prefix: object, StartAfter: startAfter, Recursive: isRecursive, WithMetadata: metadata, MaxKeys: maxKeys}
 	if zip {
 		// If prefix ends with .zip, add a slash.
 		if strings.HasSuffix(object, ".zip") {
@@ -1626,7 +2584,64 @@ func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object string,
 		}
 		opts.Set("x-minio-extract", "true")
 	}
-	return c.api.ListObjects(ctx, bucket, opts)
+	if c.listVersion == 2 {
+		return c.api.ListObjects(ctx, bucket, opts)
+	}
+	if _, ok := listV1Hosts.Load(c.targetURL.Host); ok {
+		return c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, StartAfter: startAfter, Recursive: isRecursive, UseV1: true, MaxKeys: maxKeys})
+	}
+	return c.listObjectsWithFallback(ctx, bucket, opts)
+}
+
+// listV1Hosts remembers the hosts found not to support ListObjectsV2.
+var listV1Hosts sync.Map
+
+// listObjectsWithFallback lists with ListObjectsV2 and retries with
+// ListObjects (V1) when the very first response reports V2 as unsupported.
+func (c *S3Client) listObjectsWithFallback(ctx context.Context, bucket string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	objectCh := make(chan minio.ObjectInfo, 1)
+	go func() {
+		defer close(objectCh)
+
+		first := true
+		for object := range c.api.ListObjects(ctx, bucket, opts) {
+			if first && object.Err != nil && isListV2Unsupported(object.Err) {
+				listV1Hosts.Store(c.targetURL.Host, struct{}{})
+				for object := range c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: opts.Prefix, StartAfter: opts.StartAfter, Recursive: opts.Recursive, UseV1: true, MaxKeys: opts.MaxKeys}) {
+					objectCh <- object
+				}
+				return
+			}
+			first = false
+			objectCh <- object
+		}
+	}()
+	return objectCh
+}
+
+// listV2Parameters are the query parameters of ListObjectsV2 unknown to
+// servers implementing ListObjects (V1) only.
+var listV2Parameters = []string{"list-type", "continuation-token", "start-after", "fetch-owner"}
+
+// isListV2Unsupported returns true if the error indicates the server
+// does not implement ListObjectsV2: not implemented, or a bad request
+// naming one of its parameters. Other bad requests, e.g. of an invalid
+// prefix, are errors of that listing only.
+func isListV2Unsupported(e error) bool {
+	errResp := minio.ToErrorResponse(e)
+	if errResp.Code == "NotImplemented" || errResp.StatusCode == http.StatusNotImplemented {
+		return true
+	}
+	if errResp.Code != "InvalidArgument" && errResp.Code != "InvalidRequest" {
+		return false
+	}
+	message := strings.ToLower(errResp.Message)
+	for _, parameter := range listV2Parameters {
+		if strings.Contains(message, parameter) {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *S3Client) statIncompleteUpload(ctx context.Context, bucket, object string) (*ClientContent, *probe.Error) {
@@ -1704,7 +2719,7 @@ func (c *S3Client) Stat(ctx context.Context, opts StatOptions) (*ClientContent,
 	// Start with a HEAD request first to return object metadata information.
 	// If the object is not found, continue to look for a directory marker or a prefix
 	if !strings.HasSuffix(path, string(c.targetURL.Separator)) && opts.timeRef.IsZero() {
-		o := minio.StatObjectOptions{ServerSideEncryption: opts.sse, VersionID: opts.versionID}
+		o := minio.StatObjectOptions{ServerSideEncryption: opts.sse, VersionID: opts.versionID, Checksum: opts.checksum}
 		if opts.isZip {
 			o.Set("x-minio-extract", "true")
 		}
@@ -1722,7 +2737,7 @@ func (c *S3Client) Stat(ctx context.Context, opts StatOptions) (*ClientContent,
 
 	nonRecursive := false
 	maxKeys := 1
-	for objectStat := range c.listObjectWrapper(ctx, bucket, path, nonRecursive, opts.timeRef, false, false, false, maxKeys, opts.isZip) {
+	for objectStat := range c.listObjectWrapper(ctx, bucket, path, "", nonRecursive, opts.timeRef, false, false, false, maxKeys, opts.isZip) {
 		if objectStat.Err != nil {
 			return nil, probe.NewError(objectStat.Err)
 		}
@@ -1739,11 +2754,44 @@ func (c *S3Client) Stat(ctx context.Context, opts StatOptions) (*ClientContent,
 	return nil, probe.NewError(ObjectMissing{opts.timeRef})
 }
 
+// sseCustomerKeyError - returns a clear error for reads of an object
+// encrypted with SSE-C rejected for a missing or wrong key, nil for
+// other errors. HEAD responses have no body, without a key a bad request
+// is only blamed on SSE-C when its headers or message tell the object
+// uses it.
+func sseCustomerKeyError(e error, sse encrypt.ServerSide, path string, headers http.Header) error {
+	errResponse := minio.ToErrorResponse(e)
+	if sse != nil && sse.Type() == encrypt.SSEC {
+		if errResponse.StatusCode == http.StatusForbidden {
+			return SSECKeyMismatch{Path: path}
+		}
+		return nil
+	}
+	if errResponse.StatusCode != http.StatusBadRequest {
+		return nil
+	}
+	message := strings.ToLower(errResponse.Message)
+	if headers.Get(encrypt.SseCustomerAlgorithm) != "" ||
+		strings.Contains(message, "customer key") || strings.Contains(message, "customer-key") ||
+		(strings.Contains(message, "server side encryption") && strings.Contains(message, "correct parameters")) {
+		return SSECKeyRequired{Path: path}
+	}
+	return nil
+}
+
 // getObjectStat returns the metadata of an object from a HEAD call.
 func (c *S3Client) getObjectStat(ctx context.Context, bucket, object string, opts minio.StatObjectOptions) (*ClientContent, *probe.Error) {
-	objectStat, e := c.api.StatObject(ctx, bucket, object, opts)
+	rawHeaders := make(http.Header)
+	objectStat, e := c.objectAPI(ctx, bucket).StatObject(context.WithValue(ctx, rawHeadersKey{}, rawHeaders), bucket, object, opts)
 	objectMetadata := c.objectInfo2ClientContent(bucket, objectStat)
+	objectMetadata.RawHeaders = make(map[string]string, len(rawHeaders))
+	for k, v := range rawHeaders {
+		objectMetadata.RawHeaders[k] = strings.Join(v, ",")
+	}
 	if e != nil {
+		if sseErr := sseCustomerKeyError(e, opts.ServerSideEncryption, c.targetURL.String(), rawHeaders); sseErr != nil {
+			return nil, probe.NewError(sseErr)
+		}
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse.Code == "AccessDenied" {
 			return nil, probe.NewError(PathInsufficientPermission{Path: c.targetURL.String()})
@@ -1806,6 +2854,54 @@ func isVirtualHostStyle(host string, lookup minio.BucketLookupType) bool {
 	return isAmazon(host) && !isAmazonChina(host) || isGoogle(host) || isAmazonAccelerated(host)
 }
 
+// virtualHostBuckets remembers whether buckets are reachable in virtual
+// host style, keyed by host and bucket name.
+var virtualHostBuckets sync.Map
+
+// virtualHostLookupTimeout bounds the DNS lookup of a bucket host name.
+const virtualHostLookupTimeout = 5 * time.Second
+
+// virtualHostReachable returns false if requests to bucket in virtual
+// host style are bound to fail: the bucket name is not a valid host
+// name, contains dots not covered by the wildcard certificate of a TLS
+// endpoint, the endpoint is an IP address or the bucket host name does
+// not resolve. The answer is remembered for the rest of the process.
+func virtualHostReachable(host, bucket string, useTLS bool) bool {
+	key := host + "/" + bucket
+	if reachable, ok := virtualHostBuckets.Load(key); ok {
+		return reachable.(bool)
+	}
+
+	hostname := host
+	if h, _, e := net.SplitHostPort(host); e == nil {
+		hostname = h
+	}
+	reason := ""
+	switch {
+	case s3utils.CheckValidBucketNameStrict(bucket) != nil:
+		reason = "is not a valid host name"
+	case useTLS && strings.Contains(bucket, "."):
+		reason = "contains dots, not covered by the TLS certificate"
+	case net.ParseIP(hostname) != nil:
+		reason = "is served by an IP address"
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), virtualHostLookupTimeout)
+		defer cancel()
+		// Only a missing host name is conclusive, other lookup errors
+		// leave the addressing style alone.
+		_, e := net.DefaultResolver.LookupHost(ctx, bucket+"."+hostname)
+		var dnsErr *net.DNSError
+		if errors.As(e, &dnsErr) && dnsErr.IsNotFound {
+			reason = "has no DNS record for `" + bucket + "." + hostname + "`"
+		}
+	}
+	if reason != "" && globalDebug {
+		console.Debugf("Bucket `%s` %s, switching to path style requests.\n", bucket, reason)
+	}
+	virtualHostBuckets.Store(key, reason == "")
+	return reason == ""
+}
+
 func url2BucketAndObject(u *ClientURL) (bucketName, objectName string) {
 	tokens := splitStr(u.Path, string(u.Separator), 3)
 	return tokens[1], tokens[2]
@@ -2087,6 +3183,7 @@ func (c *S3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan *
 					content.URL = url
 					content.Size = object.Size
 					content.Time = object.Initiated
+					content.UploadID = object.UploadID
 					content.Type = os.ModeTemporary
 				}
 				select {
@@ -2122,6 +3219,7 @@ func (c *S3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan *
 				content.URL = url
 				content.Size = object.Size
 				content.Time = object.Initiated
+				content.UploadID = object.UploadID
 				content.Type = os.ModeTemporary
 			}
 			select {
@@ -2176,6 +3274,7 @@ func (c *S3Client) listIncompleteRecursiveInRoutine(ctx context.Context, content
 				content.URL = url
 				content.Size = object.Size
 				content.Time = object.Initiated
+				content.UploadID = object.UploadID
 				content.Type = os.ModeTemporary
 				select {
 				case <-ctx.Done():
@@ -2212,6 +3311,7 @@ func (c *S3Client) listIncompleteRecursiveInRoutine(ctx context.Context, content
 			content.URL = url
 			content.Size = object.Size
 			content.Time = object.Initiated
+			content.UploadID = object.UploadID
 			content.Type = os.ModeTemporary
 			select {
 			case <-ctx.Done():
@@ -2292,6 +3392,7 @@ func (c *S3Client) objectInfo2ClientContent(bucket string, entry minio.ObjectInf
 	content.Tags = entry.UserTags
 
 	content.ReplicationStatus = entry.ReplicationStatus
+	content.ChecksumType, content.Checksum = storedChecksum(entry)
 	for k, v := range entry.UserMetadata {
 		content.UserMetadata[k] = v
 	}
@@ -2325,6 +3426,22 @@ func (c *S3Client) objectInfo2ClientContent(bucket string, entry minio.ObjectInf
 	return content
 }
 
+// storedChecksum returns the algorithm and the value of the additional
+// checksum stored with an object, empty if it has none.
+func storedChecksum(entry minio.ObjectInfo) (checksumType, checksum string) {
+	switch {
+	case entry.ChecksumCRC32C != "":
+		return "CRC32C", entry.ChecksumCRC32C
+	case entry.ChecksumCRC32 != "":
+		return "CRC32", entry.ChecksumCRC32
+	case entry.ChecksumSHA256 != "":
+		return "SHA256", entry.ChecksumSHA256
+	case entry.ChecksumSHA1 != "":
+		return "SHA1", entry.ChecksumSHA1
+	}
+	return "", ""
+}
+
 // Returns bucket stat info of current bucket.
 func (c *S3Client) bucketStat(ctx context.Context, bucket string) (*ClientContent, *probe.Error) {
 	exists, e := c.api.BucketExists(ctx, bucket)
@@ -2357,8 +3474,47 @@ func (c *S3Client) listInRoutine(ctx context.Context, contentCh chan *ClientCont
 			}
 			return
 		}
-		for _, bucket := range buckets {
-			contentCh <- c.bucketInfo2ClientContent(bucket)
+		// Details of the buckets are looked up in parallel, the
+		// buckets are sent in the listed order.
+		contents := make([]*ClientContent, len(buckets))
+		var wg sync.WaitGroup
+		workerCh := make(chan struct{}, bucketLookupWorkers)
+		for i, bucket := range buckets {
+			contents[i] = c.bucketInfo2ClientContent(bucket)
+			if !opts.BucketRegion && !opts.BucketPublic {
+				continue
+			}
+			wg.Add(1)
+			workerCh <- struct{}{}
+			go func(content *ClientContent, bucket string) {
+				defer wg.Done()
+				defer func() { <-workerCh }()
+				if opts.BucketRegion {
+					region, e := c.api.GetBucketLocation(ctx, bucket)
+					if e != nil {
+						content.Err = probe.NewError(e).Trace(bucket)
+						return
+					}
+					content.Region = region
+				}
+				if opts.BucketPublic {
+					policyStr, e := c.api.GetBucketPolicy(ctx, bucket)
+					if e == nil {
+						content.Public, content.PublicReason, e = bucketPolicyPublic(policyStr)
+					}
+					if e != nil {
+						content.Err = probe.NewError(e).Trace(bucket)
+					}
+				}
+			}(contents[i], bucket.Name)
+		}
+		wg.Wait()
+		for _, content := range contents {
+			if content.Err != nil {
+				contentCh <- &ClientContent{Err: content.Err}
+				continue
+			}
+			contentCh <- content
 		}
 	case b != "" && !strings.HasSuffix(c.targetURL.Path, string(c.targetURL.Separator)) && o == "":
 		content, err := c.bucketStat(ctx, b)
@@ -2369,7 +3525,7 @@ func (c *S3Client) listInRoutine(ctx context.Context, contentCh chan *ClientCont
 		contentCh <- content
 	default:
 		isRecursive := false
-		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
+		for object := range c.listObjectWrapper(ctx, b, o, "", isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
 			if object.Err != nil {
 				contentCh <- &ClientContent{
 					Err: probe.NewError(object.Err),
@@ -2422,7 +3578,7 @@ func (c *S3Client) listRecursiveInRoutine(ctx context.Context, contentCh chan *C
 			}
 
 			isRecursive := true
-			for object := range c.listObjectWrapper(ctx, bucket.Name, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
+			for object := range c.listObjectWrapper(ctx, bucket.Name, o, "", isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
 				if object.Err != nil {
 					contentCh <- &ClientContent{
 						Err: probe.NewError(object.Err),
@@ -2436,9 +3592,11 @@ func (c *S3Client) listRecursiveInRoutine(ctx context.Context, contentCh chan *C
 				contentCh <- c.bucketInfo2ClientContent(bucket)
 			}
 		}
+	case len(opts.ExcludePrefixes) > 0:
+		c.listRecursiveExcluding(ctx, contentCh, b, o, opts)
 	default:
 		isRecursive := true
-		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
+		for object := range c.listObjectWrapper(ctx, b, o, "", isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip) {
 			if object.Err != nil {
 				contentCh <- &ClientContent{
 					Err: probe.NewError(object.Err),
@@ -2450,6 +3608,42 @@ func (c *S3Client) listRecursiveInRoutine(ctx context.Context, contentCh chan *C
 	}
 }
 
+// lastKeyChar sorts after any other character of an object key.
+const lastKeyChar = "\U0010FFFF"
+
+// listRecursiveExcluding - lists the objects under o recursively without
+// listing the keys under opts.ExcludePrefixes, the listing is restarted
+// after the last possible key of an excluded prefix when one is met.
+func (c *S3Client) listRecursiveExcluding(ctx context.Context, contentCh chan *ClientContent, b, o string, opts ListOptions) {
+	folder := o[:strings.LastIndex(o, string(c.targetURL.Separator))+1]
+	isRecursive := true
+	var startAfter string
+	for {
+		listCtx, cancel := context.WithCancel(ctx)
+		objectCh := c.listObjectWrapper(listCtx, b, o, startAfter, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, opts.ListZip)
+		startAfter = ""
+		for object := range objectCh {
+			if object.Err != nil {
+				contentCh <- &ClientContent{
+					Err: probe.NewError(object.Err),
+				}
+				break
+			}
+			if prefix := matchExcludePrefix(strings.TrimPrefix(object.Key, folder), opts.ExcludePrefixes); prefix != "" {
+				startAfter = folder + prefix + lastKeyChar
+				break
+			}
+			contentCh <- c.objectInfo2ClientContent(b, object)
+		}
+		cancel()
+		for range objectCh {
+		}
+		if startAfter == "" {
+			return
+		}
+	}
+}
+
 // ShareDownload - get a usable presigned object url to share.
 func (c *S3Client) ShareDownload(ctx context.Context, versionID string, expires time.Duration) (string, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
@@ -3044,7 +4238,7 @@ func (c *S3Client) GetPart(ctx context.Context, part int) (io.ReadCloser, *probe
 	if part > 0 {
 		getOO.PartNumber = part
 	}
-	reader, e := c.api.GetObject(ctx, bucket, object, getOO)
+	reader, e := c.objectAPI(ctx, bucket).GetObject(ctx, bucket, object, getOO)
 	if e != nil {
 		return nil, probe.NewError(e)
 	}
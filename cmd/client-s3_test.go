@@ -21,12 +21,26 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
 	checkv1 "gopkg.in/check.v1"
 )
 
@@ -238,6 +252,269 @@ func (s *TestSuite) TestBucketOperations(c *checkv1.C) {
 	}
 }
 
+// listV1OnlyHandler is a bucketHandler rejecting ListObjectsV2 requests.
+type listV1OnlyHandler struct {
+	bucketHandler
+}
+
+func (h listV1OnlyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" && r.URL.Query().Get("list-type") == "2" {
+		response := []byte("<Error><Code>NotImplemented</Code><Message>A header you provided implies functionality that is not implemented</Message></Error>")
+		w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+		w.WriteHeader(http.StatusNotImplemented)
+		w.Write(response)
+		return
+	}
+	h.bucketHandler.ServeHTTP(w, r)
+}
+
+// Test listing falls back to ListObjects V1 when V2 is not implemented.
+func (s *TestSuite) TestListV1Fallback(c *checkv1.C) {
+	server := httptest.NewServer(listV1OnlyHandler{bucketHandler{resource: "/bucket/"}})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	var objects int
+	for content := range s3c.List(globalContext, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		c.Assert(content.Err, checkv1.IsNil)
+		objects++
+	}
+	c.Assert(objects, checkv1.Equals, 1)
+}
+
+// Test only the errors of servers not implementing ListObjectsV2 fall
+// back to V1.
+func (s *TestSuite) TestIsListV2Unsupported(c *checkv1.C) {
+	testCases := []struct {
+		err         minio.ErrorResponse
+		unsupported bool
+	}{
+		{minio.ErrorResponse{Code: "NotImplemented", StatusCode: http.StatusNotImplemented}, true},
+		{minio.ErrorResponse{StatusCode: http.StatusNotImplemented}, true},
+		{minio.ErrorResponse{Code: "InvalidArgument", Message: "Unknown query parameter list-type", StatusCode: http.StatusBadRequest}, true},
+		{minio.ErrorResponse{Code: "InvalidRequest", Message: "Invalid continuation-token", StatusCode: http.StatusBadRequest}, true},
+		{minio.ErrorResponse{Code: "InvalidArgument", Message: "Invalid prefix", StatusCode: http.StatusBadRequest}, false},
+		{minio.ErrorResponse{Code: "InvalidRequest", StatusCode: http.StatusBadRequest}, false},
+		{minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden}, false},
+	}
+	for i, testCase := range testCases {
+		c.Assert(isListV2Unsupported(testCase.err), checkv1.Equals, testCase.unsupported, checkv1.Commentf("Test %d", i+1))
+	}
+}
+
+// invalidListHandler is a bucketHandler rejecting the listings as bad
+// requests unrelated to ListObjectsV2.
+type invalidListHandler struct {
+	bucketHandler
+}
+
+func (h invalidListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" && r.URL.Query().Has("prefix") {
+		response := []byte("<Error><Code>InvalidArgument</Code><Message>Invalid prefix</Message></Error>")
+		w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(response)
+		return
+	}
+	h.bucketHandler.ServeHTTP(w, r)
+}
+
+// Test a bad listing request neither falls back to ListObjects V1 nor
+// makes later listings use it.
+func (s *TestSuite) TestListInvalidRequest(c *checkv1.C) {
+	server := httptest.NewServer(invalidListHandler{bucketHandler{resource: "/bucket/"}})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	var errs int
+	for content := range s3c.List(globalContext, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		c.Assert(content.Err, checkv1.NotNil)
+		errs++
+	}
+	c.Assert(errs, checkv1.Equals, 1)
+	_, ok := listV1Hosts.Load(s3c.GetURL().Host)
+	c.Assert(ok, checkv1.Equals, false)
+}
+
+// regionHandler is a bucketHandler rejecting requests not signed for its region.
+type regionHandler struct {
+	bucketHandler
+	region string
+}
+
+func (h regionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["location"]; !ok && !strings.Contains(r.Header.Get("Authorization"), "/"+h.region+"/") {
+		response := []byte("<Error><Code>AuthorizationHeaderMalformed</Code><Message>the region is wrong</Message><Region>" + h.region + "</Region></Error>")
+		w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(response)
+		return
+	}
+	h.bucketHandler.ServeHTTP(w, r)
+}
+
+// Test listing retries in the bucket's region when it differs from the client's.
+func (s *TestSuite) TestListRegionRetry(c *checkv1.C) {
+	server := httptest.NewServer(regionHandler{bucketHandler{resource: "/bucket/"}, "eu-west-1"})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	conf.Region = "us-west-2"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	var objects int
+	for content := range s3c.List(globalContext, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		c.Assert(content.Err, checkv1.IsNil)
+		objects++
+	}
+	c.Assert(objects, checkv1.Equals, 1)
+
+	region, ok := bucketRegions.Load(newClientURL(server.URL).Host + "/bucket")
+	c.Assert(ok, checkv1.Equals, true)
+	c.Assert(region, checkv1.Equals, "eu-west-1")
+}
+
+// keyStoreHandler is an in-memory bucket recording object sizes under their decoded keys.
+type keyStoreHandler struct {
+	sync.Mutex
+	objects map[string]int
+}
+
+func (h *keyStoreHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Lock()
+	defer h.Unlock()
+
+	// Keys must arrive encoded as S3 encodes them to check signatures.
+	if path, _, _ := strings.Cut(r.RequestURI, "?"); path != s3utils.EncodePath(r.URL.Path) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>SignatureDoesNotMatch</Code></Error>`))
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch r.Method {
+	case http.MethodPut:
+		// Streaming signed uploads carry the payload length separately.
+		length := r.Header.Get("X-Amz-Decoded-Content-Length")
+		if length == "" {
+			length = r.Header.Get("Content-Length")
+		}
+		size, e := strconv.Atoi(length)
+		if e != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		h.objects[key] = size
+		w.Header().Set("ETag", "9af2f8218b150c351ad802c6f3d66abe")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodHead:
+		size, ok := h.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		w.Header().Set("Last-Modified", UTCNow().Format(http.TimeFormat))
+		w.Header().Set("ETag", "9af2f8218b150c351ad802c6f3d66abe")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		if _, ok := r.URL.Query()["location"]; ok {
+			w.Write([]byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>"))
+			return
+		}
+		encode := func(key string) string { return key }
+		encodingType := r.URL.Query().Get("encoding-type")
+		if encodingType == "url" {
+			encode = url.QueryEscape
+		}
+		var response bytes.Buffer
+		response.WriteString("<ListBucketResult xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"><Name>bucket</Name><EncodingType>" + encodingType + "</EncodingType>")
+		for key, size := range h.objects {
+			response.WriteString("<Contents><Key>")
+			xml.EscapeText(&response, []byte(encode(key)))
+			response.WriteString("</Key><LastModified>2015-05-21T18:24:21.097Z</LastModified><ETag>9af2f8218b150c351ad802c6f3d66abe</ETag><Size>" + strconv.Itoa(size) + "</Size></Contents>")
+		}
+		response.WriteString("<IsTruncated>false</IsTruncated></ListBucketResult>")
+		w.Write(response.Bytes())
+	}
+}
+
+// Test keys needing URL encoding are uploaded, stated and listed under their logical name.
+func (s *TestSuite) TestObjectKeyEncoding(c *checkv1.C) {
+	keys := []string{
+		"with space",
+		"a+b",
+		"100%",
+		"50%25",
+		"a%2Fb",
+		"résumé.pdf",
+		"日本語/ファイル.txt",
+		"a?b#c",
+		"a&b=c;d",
+		"~tilde's (copy)",
+	}
+
+	handler := &keyStoreHandler{objects: map[string]int{}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	newS3Client := func(key string) Client {
+		conf := new(Config)
+		conf.HostURL = server.URL + "/bucket/" + key
+		conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+		conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+		conf.Signature = "S3v4"
+		s3c, err := S3New(conf)
+		c.Assert(err, checkv1.IsNil)
+		return s3c
+	}
+
+	for _, key := range keys {
+		s3c := newS3Client(key)
+		data := []byte(key)
+		n, err := s3c.Put(context.Background(), bytes.NewReader(data), int64(len(data)), nil, PutOptions{metadata: map[string]string{}})
+		c.Assert(err, checkv1.IsNil, checkv1.Commentf("put %q", key))
+		c.Assert(n, checkv1.Equals, int64(len(data)))
+		c.Assert(handler.objects[key], checkv1.Equals, len(data), checkv1.Commentf("stored %q", key))
+
+		content, err := s3c.Stat(context.Background(), StatOptions{})
+		c.Assert(err, checkv1.IsNil, checkv1.Commentf("stat %q", key))
+		c.Assert(content.Size, checkv1.Equals, int64(len(data)))
+	}
+
+	for _, key := range keys {
+		c.Assert(decodeEventKey(url.QueryEscape(key)), checkv1.Equals, key, checkv1.Commentf("event %q", key))
+	}
+
+	listed := map[string]bool{}
+	for content := range newS3Client("").List(context.Background(), ListOptions{Recursive: true, ShowDir: DirNone}) {
+		c.Assert(content.Err, checkv1.IsNil)
+		listed[strings.TrimPrefix(content.URL.Path, "/bucket/")] = true
+	}
+	for _, key := range keys {
+		c.Assert(listed[key], checkv1.Equals, true, checkv1.Commentf("list %q", key))
+	}
+}
+
 // Test all object operations.
 func (s *TestSuite) TestObjectOperations(c *checkv1.C) {
 	object := objectHandler{
@@ -275,6 +552,55 @@ func (s *TestSuite) TestObjectOperations(c *checkv1.C) {
 	}
 }
 
+// staleObjectHandler is an objectHandler answering the first stale
+// HEAD requests with not found, like an eventually consistent endpoint.
+type staleObjectHandler struct {
+	objectHandler
+	stale *int32
+}
+
+func (h staleObjectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodHead && atomic.AddInt32(h.stale, -1) >= 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	h.objectHandler.ServeHTTP(w, r)
+}
+
+// Test uploads wait for the object to become consistent.
+func (s *TestSuite) TestPutWaitConsistent(c *checkv1.C) {
+	stale := int32(3)
+	object := staleObjectHandler{
+		objectHandler: objectHandler{
+			resource: "/bucket/object",
+			data:     []byte("Hello, World"),
+		},
+		stale: &stale,
+	}
+	server := httptest.NewServer(object)
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + object.resource
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	opts := PutOptions{waitConsistent: waitConsistentOptions{timeout: 5 * time.Second, interval: 10 * time.Millisecond}}
+	_, err = s3c.Put(context.Background(), bytes.NewReader(object.data), int64(len(object.data)), nil, opts)
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(atomic.LoadInt32(&stale) < 0, checkv1.Equals, true)
+
+	atomic.StoreInt32(&stale, 1000)
+	opts.waitConsistent.timeout = 50 * time.Millisecond
+	_, err = s3c.Put(context.Background(), bytes.NewReader(object.data), int64(len(object.data)), nil, opts)
+	c.Assert(err, checkv1.NotNil)
+	_, ok := err.ToGoError().(ObjectNotConsistent)
+	c.Assert(ok, checkv1.Equals, true)
+}
+
 var testSelectCompressionTypeCases = []struct {
 	opts            SelectObjectOpts
 	object          string
@@ -299,3 +625,1149 @@ func (s *TestSuite) TestSelectCompressionType(c *checkv1.C) {
 		c.Assert(cType, checkv1.DeepEquals, test.compressionType)
 	}
 }
+
+// Test only MD5 like etags are compared when waiting for consistency.
+func (s *TestSuite) TestETagsMatch(c *checkv1.C) {
+	testCases := []struct {
+		uploaded, stat string
+		match          bool
+	}{
+		{"", "9af2f8218b150c351ad802c6f3d66abe", true},
+		{"9af2f8218b150c351ad802c6f3d66abe", "\"9AF2F8218B150C351AD802C6F3D66ABE\"", true},
+		{"9af2f8218b150c351ad802c6f3d66abe", "0af2f8218b150c351ad802c6f3d66abe", false},
+		{"9af2f8218b150c351ad802c6f3d66abe-3", "9af2f8218b150c351ad802c6f3d66abe-4", false},
+		{"W/\"gateway-1234\"", "gateway-5678", true},
+		{"9af2f8218b150c351ad802c6f3d66abe", "0x8DB4C5E7A1B2C3D", true},
+	}
+	for _, testCase := range testCases {
+		c.Assert(etagsMatch(testCase.uploaded, testCase.stat), checkv1.Equals, testCase.match,
+			checkv1.Commentf("%q %q", testCase.uploaded, testCase.stat))
+	}
+}
+
+// Test transfer acceleration is only set up for Amazon S3.
+func (s *TestSuite) TestAccelerateClient(c *checkv1.C) {
+	conf := new(Config)
+	conf.HostURL = "http://localhost:9000/bucket"
+	conf.Accelerate = true
+	_, err := S3New(conf)
+	c.Assert(err, checkv1.NotNil)
+
+	conf.HostURL = "https://s3.amazonaws.com/bucket"
+	clnt, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+	s3c := clnt.(*S3Client)
+	c.Assert(s3c.accelAPI, checkv1.NotNil)
+	c.Assert(s3c.objectAPI(context.Background(), "dotted.bucket"), checkv1.Equals, s3c.api)
+}
+
+// aclEnforcedHandler rejects writes carrying ACL headers, as buckets
+// enforcing bucket owner ownership do.
+type aclEnforcedHandler struct {
+	withACL, withoutACL *int32
+}
+
+func (h aclEnforcedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	if r.Header.Get("X-Amz-Grant-Read") != "" {
+		atomic.AddInt32(h.withACL, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`<Error><Code>AccessControlListNotSupported</Code><Message>The bucket does not allow ACLs</Message></Error>`))
+		return
+	}
+	atomic.AddInt32(h.withoutACL, 1)
+	w.Header().Set("ETag", `"65a8e27d8879283831b664bd8b7f0ad4"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *TestSuite) TestPutACLNotSupported(c *checkv1.C) {
+	var withACL, withoutACL int32
+	server := httptest.NewServer(aclEnforcedHandler{&withACL, &withoutACL})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.Region = "us-east-1"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	data := []byte("Hello, World")
+	opts := PutOptions{metadata: map[string]string{"X-Amz-Grant-Read": `id="owner"`}}
+	for i := 0; i < 2; i++ {
+		_, err = s3c.Put(context.Background(), bytes.NewReader(data), int64(len(data)), nil, opts)
+		c.Assert(err, checkv1.IsNil)
+	}
+	c.Assert(atomic.LoadInt32(&withACL), checkv1.Equals, int32(1))
+	c.Assert(atomic.LoadInt32(&withoutACL), checkv1.Equals, int32(2))
+}
+
+// pagedListHandler serves ListObjectsV2 two keys per page, honoring
+// start-after, and counts the keys returned.
+type pagedListHandler struct {
+	keys   []string // sorted
+	served *int32
+}
+
+func (h pagedListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if _, ok := query["location"]; ok {
+		w.Write([]byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>"))
+		return
+	}
+	after := query.Get("start-after")
+	if token := query.Get("continuation-token"); token != "" {
+		after = token
+	}
+	var page []string
+	for _, key := range h.keys {
+		if key > after && strings.HasPrefix(key, query.Get("prefix")) {
+			page = append(page, key)
+		}
+	}
+	truncated := len(page) > 2
+	if truncated {
+		page = page[:2]
+	}
+	atomic.AddInt32(h.served, int32(len(page)))
+
+	var response bytes.Buffer
+	response.WriteString("<ListBucketResult xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"><Name>bucket</Name>")
+	for _, key := range page {
+		response.WriteString("<Contents><Key>" + key + "</Key><LastModified>2015-05-21T18:24:21.097Z</LastModified><Size>1</Size></Contents>")
+	}
+	response.WriteString("<KeyCount>" + strconv.Itoa(len(page)) + "</KeyCount><IsTruncated>" + strconv.FormatBool(truncated) + "</IsTruncated>")
+	if truncated {
+		response.WriteString("<NextContinuationToken>" + page[len(page)-1] + "</NextContinuationToken>")
+	}
+	response.WriteString("</ListBucketResult>")
+	w.Write(response.Bytes())
+}
+
+// Test recursive listings skip excluded prefixes without listing them.
+func (s *TestSuite) TestListExcludePrefixes(c *checkv1.C) {
+	keys := []string{"a"}
+	for i := 0; i < 100; i++ {
+		keys = append(keys, "tmp/"+strconv.Itoa(1000+i))
+	}
+	keys = append(keys, "tmpx", "z")
+
+	var served int32
+	server := httptest.NewServer(pagedListHandler{keys: keys, served: &served})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.Region = "us-east-1"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	conf.ListVersion = 2
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	var listed []string
+	for content := range s3c.List(context.Background(), ListOptions{Recursive: true, ExcludePrefixes: []string{"tmp/"}}) {
+		c.Assert(content.Err, checkv1.IsNil)
+		listed = append(listed, strings.TrimPrefix(content.URL.Path, "/bucket/"))
+	}
+	c.Assert(listed, checkv1.DeepEquals, []string{"a", "tmpx", "z"})
+	c.Assert(atomic.LoadInt32(&served) < 10, checkv1.Equals, true)
+}
+
+// bucketRegionHandler serves a listing of buckets and their locations.
+type bucketRegionHandler struct {
+	regions map[string]string
+}
+
+func (h bucketRegionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["location"]; ok {
+		fmt.Fprintf(w, `<LocationConstraint>%s</LocationConstraint>`, h.regions[strings.Trim(r.URL.Path, "/")])
+		return
+	}
+	fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets>`)
+	for _, bucket := range []string{"east", "west"} {
+		fmt.Fprintf(w, `<Bucket><Name>%s</Name><CreationDate>2022-01-02T03:04:05.000Z</CreationDate></Bucket>`, bucket)
+	}
+	fmt.Fprint(w, `</Buckets></ListAllMyBucketsResult>`)
+}
+
+func (s *TestSuite) TestListBucketRegion(c *checkv1.C) {
+	server := httptest.NewServer(bucketRegionHandler{regions: map[string]string{"east": "", "west": "us-west-2"}})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	regions := make(map[string]string)
+	for content := range s3c.List(context.Background(), ListOptions{BucketRegion: true}) {
+		c.Assert(content.Err, checkv1.IsNil)
+		c.Assert(content.Time.Year(), checkv1.Equals, 2022)
+		regions[content.BucketName] = content.Region
+	}
+	c.Assert(regions, checkv1.DeepEquals, map[string]string{"east": "us-east-1", "west": "us-west-2"})
+}
+
+// crossRegionHandler serves a source and a target bucket of two regions,
+// rejecting requests not signed for the region of their bucket.
+type crossRegionHandler struct {
+	regions map[string]string
+	size    int64
+
+	mu         sync.Mutex
+	copies     int
+	partCopies int
+	completed  bool
+}
+
+func (h *crossRegionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bucket, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if !strings.Contains(r.Header.Get("Authorization"), "/"+h.regions[bucket]+"/") {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("<Error><Code>AuthorizationHeaderMalformed</Code><Region>" + h.regions[bucket] + "</Region></Error>"))
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodHead && bucket == "src":
+		w.Header().Set("Content-Length", strconv.FormatInt(h.size, 10))
+		w.Header().Set("ETag", `"0b1b0c1a4e0e2bd5d4f3e1d8e1c8d37c"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		w.Write([]byte("<InitiateMultipartUploadResult><Bucket>dst</Bucket><Key>obj</Key><UploadId>upload</UploadId></InitiateMultipartUploadResult>"))
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		h.partCopies++
+		w.Write([]byte(`<CopyPartResult><ETag>"etag"</ETag></CopyPartResult>`))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		h.completed = true
+		w.Write([]byte(`<CompleteMultipartUploadResult><Bucket>dst</Bucket><Key>obj</Key><ETag>"etag-1"</ETag></CompleteMultipartUploadResult>`))
+	case r.Method == http.MethodPut && r.Header.Get("x-amz-copy-source") != "":
+		h.copies++
+		w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Test server side copies sign the source and the target requests for
+// the regions set for their buckets.
+func (s *TestSuite) TestCopyAcrossRegions(c *checkv1.C) {
+	handler := &crossRegionHandler{regions: map[string]string{"src": "us-east-1", "dst": "eu-west-1"}, size: 6 * 1024 * 1024 * 1024}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	setBucketRegion(server.URL+"/src/", "us-east-1")
+	setBucketRegion(server.URL+"/dst/", "eu-west-1")
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/dst/obj"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	conf.Region = "us-east-1"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	// Objects too large for a single request are copied in parts.
+	err = s3c.Copy(context.Background(), "/src/obj", CopyOptions{size: handler.size}, nil)
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(handler.partCopies > 1, checkv1.Equals, true)
+	c.Assert(handler.completed, checkv1.Equals, true)
+
+	// Larger objects than ComposeObject is used for within a region
+	// are copied with a single request.
+	err = s3c.Copy(context.Background(), "/src/obj", CopyOptions{size: 100 * 1024 * 1024}, nil)
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(handler.copies, checkv1.Equals, 1)
+}
+
+func (s *TestSuite) TestMatchDeltaBlocks(c *checkv1.C) {
+	newContent := []byte("aaaabbbbccccdddd")
+	var blocks []deltaBlock
+	for i := 0; i < len(newContent); i += 4 {
+		block := newContent[i : i+4]
+		blocks = append(blocks, deltaBlock{weak: newRollingSum(block).sum(), strong: sha256.Sum256(block)})
+	}
+
+	// Blocks shifted by an insertion are found at their new offset,
+	// changed blocks are not found.
+	matches, e := matchDeltaBlocks(bytes.NewReader([]byte("xaaaabbbbXcccczzzz")), blocks, 4)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(matches, checkv1.DeepEquals, map[int]int64{0: 1, 1: 5, 2: 10})
+
+	// Old content shorter than a block matches nothing.
+	matches, e = matchDeltaBlocks(bytes.NewReader([]byte("aaa")), blocks, 4)
+	c.Assert(e, checkv1.IsNil)
+	c.Assert(matches, checkv1.HasLen, 0)
+}
+
+func (s *TestSuite) TestVirtualHostReachable(c *checkv1.C) {
+	c.Assert(virtualHostReachable("s3.example.com", "My_Bucket", false), checkv1.Equals, false)
+	c.Assert(virtualHostReachable("s3.example.com", "dotted.bucket", true), checkv1.Equals, false)
+	c.Assert(virtualHostReachable("127.0.0.1:9000", "bucket", false), checkv1.Equals, false)
+
+	// Clients forced to virtual host style fall back to path style.
+	conf := new(Config)
+	conf.HostURL = "http://127.0.0.1:9000/bucket/object"
+	conf.Lookup = minio.BucketLookupDNS
+	clnt, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(clnt.(*S3Client).virtualStyle, checkv1.Equals, false)
+	c.Assert(clnt.(*S3Client).config.Lookup, checkv1.Equals, minio.BucketLookupPath)
+}
+
+// ssecHandler serves a HEAD of an object encrypted with SSE-C, rejecting
+// requests without a key or with a key other than keyMD5.
+type ssecHandler struct {
+	keyMD5 string
+}
+
+func (h ssecHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("versionId") == "malformed" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	switch r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5") {
+	case "":
+		w.Header().Set("X-Amz-Server-Side-Encryption-Customer-Algorithm", "AES256")
+		w.WriteHeader(http.StatusBadRequest)
+	case h.keyMD5:
+		w.Header().Set("Content-Length", "1")
+		w.Header().Set("Last-Modified", UTCNow().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusForbidden)
+	}
+}
+
+// Test reads of SSE-C objects without their key or with a wrong key fail clearly.
+func (s *TestSuite) TestStatSSECKeyErrors(c *checkv1.C) {
+	key, e := encrypt.NewSSEC([]byte("32byteslongsecretkeymustbegiven1"))
+	c.Assert(e, checkv1.IsNil)
+	wrongKey, e := encrypt.NewSSEC([]byte("32byteslongsecretkeymustbegiven2"))
+	c.Assert(e, checkv1.IsNil)
+	header := make(http.Header)
+	key.Marshal(header)
+
+	server := httptest.NewTLSServer(ssecHandler{keyMD5: header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5")})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.Region = "us-east-1"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	conf.Insecure = true
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	_, err = s3c.Stat(context.Background(), StatOptions{})
+	c.Assert(err, checkv1.NotNil)
+	c.Assert(err.ToGoError(), checkv1.FitsTypeOf, SSECKeyRequired{})
+
+	// Other bad requests are not blamed on SSE-C.
+	_, err = s3c.Stat(context.Background(), StatOptions{versionID: "malformed"})
+	c.Assert(err, checkv1.NotNil)
+	_, isSSEC := err.ToGoError().(SSECKeyRequired)
+	c.Assert(isSSEC, checkv1.Equals, false)
+
+	_, err = s3c.Stat(context.Background(), StatOptions{sse: wrongKey})
+	c.Assert(err, checkv1.NotNil)
+	c.Assert(err.ToGoError(), checkv1.FitsTypeOf, SSECKeyMismatch{})
+
+	_, err = s3c.Stat(context.Background(), StatOptions{sse: key})
+	c.Assert(err, checkv1.IsNil)
+}
+
+// checksumHandler serves a HEAD of an object uploaded with a CRC32C
+// checksum, returned only when asked for with the checksum mode.
+type checksumHandler struct{}
+
+func (checksumHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Amz-Checksum-Mode") == "ENABLED" {
+		w.Header().Set("X-Amz-Checksum-Crc32c", "yZRlqg==")
+	}
+	w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+	w.Header().Set("ETag", "\"etag\"")
+	w.Header().Set("Content-Length", "11")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Test the stored checksum of an object is only fetched when asked for.
+func (s *TestSuite) TestStatChecksum(c *checkv1.C) {
+	server := httptest.NewServer(checksumHandler{})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.Region = "us-east-1"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	content, err := s3c.Stat(context.Background(), StatOptions{})
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(content.Checksum, checkv1.Equals, "")
+
+	content, err = s3c.Stat(context.Background(), StatOptions{checksum: true})
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(content.ChecksumType, checkv1.Equals, "CRC32C")
+	c.Assert(content.Checksum, checkv1.Equals, "yZRlqg==")
+}
+
+// Test bucket policies are public only if they allow anonymous requests
+// without conditions.
+func (s *TestSuite) TestBucketPolicyPublic(c *checkv1.C) {
+	testCases := []struct {
+		policy string
+		public bool
+	}{
+		{"", false},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]}]}`, true},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"],"Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}}]}`, false},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]}]}`, false},
+	}
+	for _, testCase := range testCases {
+		public, reason, e := bucketPolicyPublic(testCase.policy)
+		c.Assert(e, checkv1.IsNil)
+		c.Assert(public, checkv1.Equals, testCase.public, checkv1.Commentf("%s: %s", testCase.policy, reason))
+		c.Assert(reason, checkv1.Not(checkv1.Equals), "")
+	}
+}
+
+// uploadHandler records the upload requests of objects.
+type uploadHandler struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (h *uploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	h.mu.Lock()
+	h.requests = append(h.requests, r.Method+" "+r.URL.RawQuery)
+	h.mu.Unlock()
+	w.Header().Set("ETag", `"etag"`)
+}
+
+// Test local files of a known size fitting in a part are uploaded with
+// a single PUT.
+func (s *TestSuite) TestPutSinglePart(c *checkv1.C) {
+	handler := &uploadHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	conf.Region = "us-east-1"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	for _, size := range []int64{1024 * 1024, defaultMultipartPartSize} {
+		file, e := os.CreateTemp(c.MkDir(), "object")
+		c.Assert(e, checkv1.IsNil)
+		_, e = file.Write(bytes.Repeat([]byte("a"), int(size)))
+		c.Assert(e, checkv1.IsNil)
+		_, e = file.Seek(0, io.SeekStart)
+		c.Assert(e, checkv1.IsNil)
+		st, e := file.Stat()
+		c.Assert(e, checkv1.IsNil)
+
+		handler.requests = nil
+		_, err = s3c.Put(context.Background(), file, st.Size(), nil, PutOptions{})
+		file.Close()
+		c.Assert(err, checkv1.IsNil)
+		c.Assert(handler.requests, checkv1.DeepEquals, []string{"PUT "}, checkv1.Commentf("size %d", size))
+	}
+}
+
+// failedUploadHandler creates a multipart upload, fails its parts and
+// aborts the uploads not refused. Other uploads of the same object,
+// e.g. of another client, exist on the server.
+type failedUploadHandler struct {
+	refuse bool
+
+	mu      sync.Mutex
+	aborted []string
+}
+
+func (h *failedUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>failed</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodGet && query.Has("uploads"):
+		w.Write([]byte(`<ListMultipartUploadsResult><Bucket>bucket</Bucket>` +
+			`<Upload><Key>object</Key><UploadId>failed</UploadId></Upload>` +
+			`<Upload><Key>object</Key><UploadId>other</UploadId></Upload></ListMultipartUploadsResult>`))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		if h.refuse {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+			return
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for _, uploadID := range h.aborted {
+			if uploadID == query.Get("uploadId") {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>NoSuchUpload</Code></Error>`))
+				return
+			}
+		}
+		h.aborted = append(h.aborted, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+	}
+}
+
+// Test only the uploads created by a failed upload are aborted, and
+// reported when they cannot be.
+func (s *TestSuite) TestAbortOrphanedUploads(c *checkv1.C) {
+	handler := &failedUploadHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	conf.Region = "us-east-1"
+	conf.Alias = "s3"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	data := bytes.Repeat([]byte("a"), 2*minMultipartPartSize)
+	put := func() *uploadRecorder {
+		session := newUploadRecorder(context.Background(), "")
+		ctx := context.WithValue(context.Background(), uploadRecorderKey{}, session)
+		_, err := s3c.Put(ctx, bytes.NewReader(data), int64(len(data)), nil, PutOptions{multipartSize: minMultipartPartSize})
+		c.Assert(err, checkv1.NotNil)
+		return session
+	}
+
+	session := put()
+	c.Assert(handler.aborted, checkv1.DeepEquals, []string{"failed"})
+	c.Assert(session.list(), checkv1.HasLen, 0)
+
+	handler.refuse = true
+	session = put()
+	c.Assert(session.list(), checkv1.DeepEquals, map[string]string{"failed": "s3/bucket/object"})
+
+	uploads := newUploadRecorder(context.Background(), "s3/bucket/object")
+	uploads.add("failed", "s3/bucket/object")
+	orphaned := s3c.(*S3Client).abortOrphanedUploads("bucket", "object", uploads)
+	c.Assert(orphaned, checkv1.DeepEquals, []string{"failed"})
+	c.Assert(UploadNotAborted{URL: s3c.(*S3Client).aliasedURL("bucket", "object"), UploadIDs: orphaned}.Error(), checkv1.Equals,
+		"Multipart uploads `failed` of `s3/bucket/object` may be left with their parts stored, remove them with `mc rm --incomplete s3/bucket/object`.")
+}
+
+// trailerHandler records the uploads of parts with trailing checksums,
+// refusing them when unsupported.
+type trailerHandler struct {
+	unsupported bool
+	refused     int
+
+	mu        sync.Mutex
+	requests  []string
+	algorithm string
+	trailers  []string
+	bodies    []string
+}
+
+func (h *trailerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, r.Method+" "+r.URL.RawQuery)
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		h.algorithm = r.Header.Get("X-Amz-Checksum-Algorithm")
+		w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		if trailer := r.Header.Get("X-Amz-Trailer"); trailer != "" {
+			if h.unsupported {
+				h.refused++
+				w.WriteHeader(http.StatusNotImplemented)
+				w.Write([]byte(`<Error><Code>NotImplemented</Code></Error>`))
+				return
+			}
+			h.trailers = append(h.trailers, trailer)
+		}
+		h.bodies = append(h.bodies, string(body))
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		w.Write([]byte(`<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"etag-1"</ETag></CompleteMultipartUploadResult>`))
+	default:
+		w.Header().Set("ETag", `"etag"`)
+	}
+}
+
+// Test streams are uploaded with the checksum of their parts in the
+// request trailers, or without when the server does not support them.
+func (s *TestSuite) TestPutTrailerChecksum(c *checkv1.C) {
+	data := bytes.Repeat([]byte("a"), 1024)
+	for _, unsupported := range []bool{false, true} {
+		handler := &trailerHandler{unsupported: unsupported}
+		server := httptest.NewServer(handler)
+
+		conf := new(Config)
+		conf.HostURL = server.URL + "/bucket/object"
+		conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+		conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+		conf.Signature = "S3v4"
+		conf.Region = "us-east-1"
+		s3c, err := S3New(conf)
+		c.Assert(err, checkv1.IsNil)
+
+		n, err := s3c.Put(context.Background(), bytes.NewBuffer(data), -1, nil, PutOptions{trailerChecksum: minio.ChecksumCRC32C})
+		c.Assert(err, checkv1.IsNil)
+		c.Assert(n, checkv1.Equals, int64(len(data)))
+		if unsupported {
+			// The part read for the refused upload is uploaded again.
+			c.Assert(handler.trailers, checkv1.IsNil)
+			c.Assert(strings.Contains(handler.bodies[len(handler.bodies)-1], string(data)), checkv1.Equals, true)
+			// The next upload tries trailing checksums again.
+			_, err = s3c.Put(context.Background(), bytes.NewBuffer(data), -1, nil, PutOptions{trailerChecksum: minio.ChecksumCRC32C})
+			server.Close()
+			c.Assert(err, checkv1.IsNil)
+			c.Assert(handler.refused, checkv1.Equals, 2)
+			continue
+		}
+		server.Close()
+		c.Assert(handler.algorithm, checkv1.Equals, "CRC32C")
+		c.Assert(handler.trailers, checkv1.DeepEquals, []string{"x-amz-checksum-crc32c"})
+		sum := minio.ChecksumCRC32C.ChecksumBytes(data).Encoded()
+		c.Assert(strings.Contains(handler.bodies[0], "x-amz-checksum-crc32c:"+sum), checkv1.Equals, true, checkv1.Commentf("%q", handler.bodies[0]))
+		c.Assert(handler.requests[len(handler.requests)-1], checkv1.Equals, "POST uploadId=id")
+	}
+}
+
+// Test only the errors of servers not knowing trailing checksums fall
+// back to uploads without them.
+func (s *TestSuite) TestIsTrailerUnsupported(c *checkv1.C) {
+	testCases := []struct {
+		err         minio.ErrorResponse
+		unsupported bool
+	}{
+		{minio.ErrorResponse{Code: "NotImplemented", StatusCode: http.StatusNotImplemented}, true},
+		{minio.ErrorResponse{StatusCode: http.StatusNotImplemented}, true},
+		{minio.ErrorResponse{Code: "InvalidArgument", Message: "Unsupported header x-amz-trailer", StatusCode: http.StatusBadRequest}, true},
+		{minio.ErrorResponse{Code: "InvalidRequest", Message: "aws-chunked encoding is not supported", StatusCode: http.StatusBadRequest}, true},
+		{minio.ErrorResponse{Code: "InvalidArgument", Message: "Part number must be an integer", StatusCode: http.StatusBadRequest}, false},
+		{minio.ErrorResponse{Code: "BadDigest", Message: "The checksum did not match", StatusCode: http.StatusBadRequest}, false},
+		{minio.ErrorResponse{Code: "InvalidRequest", StatusCode: http.StatusBadRequest}, false},
+	}
+	for i, testCase := range testCases {
+		c.Assert(isTrailerUnsupported(testCase.err), checkv1.Equals, testCase.unsupported, checkv1.Commentf("Test %d", i+1))
+	}
+}
+
+// Test removing incomplete uploads aborts the listed uploads by their
+// upload id, and every upload of an object listed without one.
+func (s *TestSuite) TestRemoveIncomplete(c *checkv1.C) {
+	handler := newMemS3Handler()
+	for _, object := range []string{"bucket/dir/a", "bucket/dir/a", "bucket/dir/b", "bucket/other"} {
+		handler.uploadID++
+		handler.uploads["upload-"+strconv.Itoa(handler.uploadID)] = memS3Upload{object: object, initiated: time.Now().UTC()}
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/dir/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	conf.Region = "us-east-1"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		for content := range s3c.List(context.Background(), ListOptions{Recursive: true, Incomplete: true, ShowDir: DirNone}) {
+			contentCh <- content
+		}
+	}()
+	var aborted []string
+	for result := range s3c.Remove(context.Background(), true, false, false, false, contentCh) {
+		c.Assert(result.Err, checkv1.IsNil)
+		aborted = append(aborted, result.ObjectName+" "+result.UploadID)
+	}
+	sort.Strings(aborted)
+	c.Assert(aborted, checkv1.DeepEquals, []string{"dir/a upload-1", "dir/a upload-2", "dir/b upload-3"})
+	c.Assert(handler.uploads, checkv1.HasLen, 1)
+	c.Assert(handler.uploads["upload-4"].object, checkv1.Equals, "bucket/other")
+
+	// Without an upload id every upload of the object is aborted.
+	handler.uploads["upload-5"] = memS3Upload{object: "bucket/other", initiated: time.Now().UTC()}
+	contentCh = make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: *newClientURL("/bucket/other")}
+	close(contentCh)
+	for result := range s3c.Remove(context.Background(), true, false, false, false, contentCh) {
+		c.Assert(result.Err, checkv1.IsNil)
+		c.Assert(result.UploadID, checkv1.Equals, "")
+	}
+	c.Assert(handler.uploads, checkv1.HasLen, 0)
+}
+
+// memS3Handler is an in-memory S3 server of path style buckets, for the
+// tests running mc commands against object storage. Keys not encoded as
+// S3 encodes them to check signatures and requests matching fail are
+// denied, part copies are not implemented with noPartCopy and objects
+// are returned with their SHA256 checksum found in checksums. Every
+// bucket exists unless buckets lists the existing ones, HEAD bucket
+// requests are not implemented with noHeadBucket.
+type memS3Handler struct {
+	fail         func(r *http.Request) bool
+	noPartCopy   bool
+	noHeadBucket bool
+	checksums    map[string]string // bucket/key
+	buckets      map[string]bool
+
+	mu       sync.Mutex
+	requests []string
+	objects  map[string][]byte // bucket/key
+	uploads  map[string]memS3Upload
+	uploadID int
+}
+
+// memS3Upload is an incomplete multipart upload of memS3Handler.
+type memS3Upload struct {
+	object    string // bucket/key
+	initiated time.Time
+	parts     map[int][]byte
+}
+
+func newMemS3Handler() *memS3Handler {
+	return &memS3Handler{objects: make(map[string][]byte), uploads: make(map[string]memS3Upload)}
+}
+
+func memS3ETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (h *memS3Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("X-Amz-Content-Sha256") == "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" {
+		body = decodeAWSChunked(body)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+r.URL.RawQuery))
+	if path, _, _ := strings.Cut(r.RequestURI, "?"); path != s3utils.EncodePath(r.URL.Path) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>SignatureDoesNotMatch</Code></Error>`))
+		return
+	}
+	if h.fail != nil && h.fail(r) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	object := bucket + "/" + key
+	writeXML := func(v interface{}) {
+		out, _ := xml.Marshal(v)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(out)
+	}
+	notFound := func(code string) {
+		w.WriteHeader(http.StatusNotFound)
+		if r.Method != http.MethodHead {
+			w.Write([]byte(`<Error><Code>` + code + `</Code></Error>`))
+		}
+	}
+
+	if key == "" && r.Method == http.MethodHead && h.noHeadBucket {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	if h.buckets != nil && !h.buckets[bucket] {
+		if key == "" && r.Method == http.MethodPut {
+			h.buckets[bucket] = true
+			return
+		}
+		notFound("NoSuchBucket")
+		return
+	}
+
+	switch {
+	case key == "" && query.Has("location"):
+		w.Write([]byte(`<LocationConstraint></LocationConstraint>`))
+	case key == "" && r.Method == http.MethodGet && query.Has("uploads"):
+		type upload struct {
+			Key       string
+			UploadID  string `xml:"UploadId"`
+			Initiated time.Time
+		}
+		var result struct {
+			XMLName xml.Name `xml:"ListMultipartUploadsResult"`
+			Bucket  string
+			Uploads []upload `xml:"Upload"`
+		}
+		result.Bucket = bucket
+		for id, u := range h.uploads {
+			if strings.HasPrefix(u.object, bucket+"/"+query.Get("prefix")) {
+				result.Uploads = append(result.Uploads, upload{strings.TrimPrefix(u.object, bucket+"/"), id, u.initiated})
+			}
+		}
+		sort.Slice(result.Uploads, func(i, j int) bool {
+			return result.Uploads[i].Key+result.Uploads[i].UploadID < result.Uploads[j].Key+result.Uploads[j].UploadID
+		})
+		writeXML(result)
+	case key == "" && r.Method == http.MethodGet:
+		type content struct {
+			Key          string
+			LastModified time.Time
+			ETag         string
+			Size         int
+		}
+		type prefix struct {
+			Prefix string
+		}
+		var result struct {
+			XMLName        xml.Name `xml:"ListBucketResult"`
+			Name           string
+			Prefix         string
+			KeyCount       int
+			Contents       []content
+			CommonPrefixes []prefix
+		}
+		result.Name, result.Prefix = bucket, query.Get("prefix")
+		seen := make(map[string]bool)
+		for name, data := range h.objects {
+			if !strings.HasPrefix(name, bucket+"/"+result.Prefix) {
+				continue
+			}
+			k := strings.TrimPrefix(name, bucket+"/"+result.Prefix)
+			if i := strings.Index(k, query.Get("delimiter")); query.Get("delimiter") != "" && i >= 0 {
+				if p := result.Prefix + k[:i+1]; !seen[p] {
+					seen[p] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, prefix{p})
+				}
+				continue
+			}
+			result.Contents = append(result.Contents, content{result.Prefix + k, time.Now().UTC(), memS3ETag(data), len(data)})
+		}
+		sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+		sort.Slice(result.CommonPrefixes, func(i, j int) bool { return result.CommonPrefixes[i].Prefix < result.CommonPrefixes[j].Prefix })
+		result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+		writeXML(result)
+	case key == "" && r.Method == http.MethodPost && query.Has("delete"):
+		var request struct {
+			Objects []struct{ Key string } `xml:"Object"`
+		}
+		xml.Unmarshal(body, &request)
+		type deleted struct{ Key string }
+		var result struct {
+			XMLName xml.Name  `xml:"DeleteResult"`
+			Deleted []deleted `xml:"Deleted"`
+		}
+		for _, o := range request.Objects {
+			delete(h.objects, bucket+"/"+o.Key)
+			result.Deleted = append(result.Deleted, deleted{o.Key})
+		}
+		writeXML(result)
+	case key == "":
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		h.uploadID++
+		id := "upload-" + strconv.Itoa(h.uploadID)
+		h.uploads[id] = memS3Upload{object: object, initiated: time.Now().UTC(), parts: make(map[int][]byte)}
+		w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>` + bucket + `</Bucket><Key>` + key + `</Key><UploadId>` + id + `</UploadId></InitiateMultipartUploadResult>`))
+	case query.Has("uploadId"):
+		u, ok := h.uploads[query.Get("uploadId")]
+		if !ok || u.object != object {
+			notFound("NoSuchUpload")
+			return
+		}
+		switch n, _ := strconv.Atoi(query.Get("partNumber")); {
+		case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+			if h.noPartCopy {
+				w.WriteHeader(http.StatusNotImplemented)
+				w.Write([]byte(`<Error><Code>NotImplemented</Code></Error>`))
+				return
+			}
+			source, _ := url.PathUnescape(strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "/"))
+			var first, last int
+			fmt.Sscanf(r.Header.Get("X-Amz-Copy-Source-Range"), "bytes=%d-%d", &first, &last)
+			u.parts[n] = h.objects[source][first : last+1]
+			w.Write([]byte(`<CopyPartResult><ETag>` + memS3ETag(u.parts[n]) + `</ETag></CopyPartResult>`))
+		case r.Method == http.MethodPut:
+			u.parts[n] = body
+			w.Header().Set("ETag", memS3ETag(body))
+		case r.Method == http.MethodDelete:
+			delete(h.uploads, query.Get("uploadId"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			var data []byte
+			for n := 1; n <= len(u.parts); n++ {
+				data = append(data, u.parts[n]...)
+			}
+			h.objects[object] = data
+			delete(h.uploads, query.Get("uploadId"))
+			w.Write([]byte(`<CompleteMultipartUploadResult><Bucket>` + bucket + `</Bucket><Key>` + key + `</Key><ETag>` + memS3ETag(data) + `</ETag></CompleteMultipartUploadResult>`))
+		}
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "/"))
+		data, ok := h.objects[source]
+		if !ok {
+			notFound("NoSuchKey")
+			return
+		}
+		h.objects[object] = data
+		w.Write([]byte(`<CopyObjectResult><ETag>` + memS3ETag(data) + `</ETag><LastModified>` + time.Now().UTC().Format(time.RFC3339) + `</LastModified></CopyObjectResult>`))
+	case r.Method == http.MethodPut:
+		h.objects[object] = body
+		w.Header().Set("ETag", memS3ETag(body))
+	case r.Method == http.MethodDelete:
+		delete(h.objects, object)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := h.objects[object]
+		if !ok {
+			notFound("NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", memS3ETag(data))
+		if checksum, ok := h.checksums[object]; ok {
+			w.Header().Set("X-Amz-Checksum-Sha256", checksum)
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// decodeAWSChunked returns the payload of an aws-chunked request body.
+func decodeAWSChunked(body []byte) []byte {
+	var data []byte
+	for len(body) > 0 {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			break
+		}
+		sizeHex, _, _ := bytes.Cut(header, []byte(";"))
+		size, e := strconv.ParseInt(string(sizeHex), 16, 64)
+		if e != nil || size == 0 || int64(len(rest)) < size {
+			break
+		}
+		data = append(data, rest[:size]...)
+		body = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+	return data
+}
+
+// rawHeadersHandler answers the bucket location and object HEAD
+// requests with distinct headers.
+type rawHeadersHandler struct{}
+
+func (rawHeadersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("location") {
+		w.Header().Set("X-Location-Only", "location")
+		w.Write([]byte(`<LocationConstraint></LocationConstraint>`))
+		return
+	}
+	w.Header().Set("Content-Length", "4")
+	w.Header().Set("ETag", `"etag"`)
+	w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	w.Header().Add("X-Amz-Meta-Multi", "a")
+	w.Header().Add("X-Amz-Meta-Multi", "b")
+}
+
+// Test the raw headers of a stat are those of the object HEAD only.
+func (s *TestSuite) TestStatRawHeaders(c *checkv1.C) {
+	server := httptest.NewServer(rawHeadersHandler{})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, checkv1.IsNil)
+
+	content, err := s3c.Stat(context.Background(), StatOptions{})
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(content.RawHeaders["Etag"], checkv1.Equals, `"etag"`)
+	c.Assert(content.RawHeaders["Content-Length"], checkv1.Equals, "4")
+	c.Assert(content.RawHeaders["X-Amz-Meta-Multi"], checkv1.Equals, "a,b")
+	_, ok := content.RawHeaders["X-Location-Only"]
+	c.Assert(ok, checkv1.Equals, false)
+}
+
+// conditionalPutHandler stores single PUT objects, refusing to overwrite
+// them on "If-None-Match: *" or not implementing it.
+type conditionalPutHandler struct {
+	unsupported bool
+
+	mu       sync.Mutex
+	requests []string
+	objects  map[string]string
+}
+
+func (h *conditionalPutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("X-Amz-Content-Sha256") == "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" {
+		body = decodeAWSChunked(body)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, strings.TrimSpace(r.Method+" "+r.Header.Get("If-None-Match")))
+	_, exists := h.objects[r.URL.Path]
+	switch r.Method {
+	case http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(h.objects[r.URL.Path])))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+	case http.MethodPut:
+		if r.Header.Get("If-None-Match") != "" {
+			if h.unsupported {
+				w.WriteHeader(http.StatusNotImplemented)
+				w.Write([]byte(`<Error><Code>NotImplemented</Code></Error>`))
+				return
+			}
+			if exists {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				w.Write([]byte(`<Error><Code>PreconditionFailed</Code></Error>`))
+				return
+			}
+		}
+		h.objects[r.URL.Path] = string(body)
+		w.Header().Set("ETag", `"etag"`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// countingProgress counts the bytes reported as uploaded.
+type countingProgress struct {
+	n int64
+}
+
+func (p *countingProgress) Read(b []byte) (int, error) {
+	atomic.AddInt64(&p.n, int64(len(b)))
+	return len(b), nil
+}
+
+// Test no-clobber uploads are conditional writes, or a stat followed by
+// a write uploading the content and counting its progress once on
+// servers not implementing them.
+func (s *TestSuite) TestS3PutNoClobber(c *checkv1.C) {
+	for _, unsupported := range []bool{false, true} {
+		handler := &conditionalPutHandler{unsupported: unsupported, objects: make(map[string]string)}
+		server := httptest.NewServer(handler)
+
+		conf := new(Config)
+		conf.HostURL = server.URL + "/bucket/object"
+		conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+		conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+		conf.Signature = "S3v4"
+		conf.Region = "us-east-1"
+		s3c, err := S3New(conf)
+		c.Assert(err, checkv1.IsNil)
+
+		// The upload starts from the current offset of the reader.
+		reader := strings.NewReader("--content")
+		reader.Seek(2, io.SeekStart)
+		progress := &countingProgress{}
+		n, err := s3c.Put(context.Background(), reader, int64(reader.Len()), progress, PutOptions{noClobber: true})
+		c.Assert(err, checkv1.IsNil)
+		c.Assert(n, checkv1.Equals, int64(len("content")))
+		c.Assert(handler.objects["/bucket/object"], checkv1.Equals, "content")
+		c.Assert(progress.n, checkv1.Equals, int64(len("content")))
+
+		_, err = s3c.Put(context.Background(), strings.NewReader("other"), int64(len("other")), nil, PutOptions{noClobber: true})
+		server.Close()
+		c.Assert(err, checkv1.NotNil)
+		_, ok := err.ToGoError().(ObjectAlreadyExists)
+		c.Assert(ok, checkv1.Equals, true, checkv1.Commentf("%v", err))
+		c.Assert(handler.objects["/bucket/object"], checkv1.Equals, "content")
+		if unsupported {
+			c.Assert(handler.requests, checkv1.DeepEquals, []string{"PUT *", "HEAD", "PUT", "HEAD"})
+		} else {
+			c.Assert(handler.requests, checkv1.DeepEquals, []string{"PUT *", "PUT *"})
+		}
+	}
+}
+
+// Test delta uploads copy the blocks found in the object, report their
+// progress once and finish like other uploads, or fall back to a full
+// upload when parts cannot be copied.
+func (s *TestSuite) TestPutDelta(c *checkv1.C) {
+	for _, noPartCopy := range []bool{false, true} {
+		handler := newMemS3Handler()
+		handler.noPartCopy = noPartCopy
+		handler.objects["bucket/object"] = []byte("aaaabbbbcccc")
+		server := httptest.NewServer(handler)
+
+		conf := new(Config)
+		conf.HostURL = server.URL + "/bucket/object"
+		conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+		conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+		conf.Signature = "S3v4"
+		conf.Region = "us-east-1"
+		s3c, err := S3New(conf)
+		c.Assert(err, checkv1.IsNil)
+
+		// The first part is uploaded before the copy of the second.
+		data := "XXXXbbbbaaaacc"
+		progress := &countingProgress{}
+		n, err := s3c.Put(context.Background(), strings.NewReader(data), int64(len(data)), progress, PutOptions{
+			deltaBlockSize: 4,
+			waitConsistent: waitConsistentOptions{timeout: time.Second},
+		})
+		server.Close()
+		c.Assert(err, checkv1.IsNil)
+		c.Assert(n, checkv1.Equals, int64(len(data)))
+		c.Assert(string(handler.objects["bucket/object"]), checkv1.Equals, data)
+		c.Assert(progress.n, checkv1.Equals, int64(len(data)))
+		// The upload was waited for.
+		c.Assert(handler.requests[len(handler.requests)-1], checkv1.Equals, "HEAD /bucket/object")
+
+		var parts int
+		for _, request := range handler.requests {
+			if strings.HasPrefix(request, "PUT /bucket/object partNumber=") {
+				parts++
+			}
+		}
+		if noPartCopy {
+			c.Assert(handler.requests[len(handler.requests)-2], checkv1.Equals, "PUT /bucket/object")
+		} else {
+			// 2 parts copied, 2 uploaded.
+			c.Assert(parts, checkv1.Equals, 4)
+		}
+	}
+}
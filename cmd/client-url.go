@@ -135,6 +135,12 @@ func joinURLs(url1, url2 *ClientURL) *ClientURL {
 	return url1
 }
 
+// sourceKeySuffix returns sourcePath relative to sourcePrefix as a '/'
+// separated path, so that keys derived from Windows paths are portable.
+func sourceKeySuffix(sourcePath, sourcePrefix string) string {
+	return strings.TrimPrefix(filepath.ToSlash(sourcePath), filepath.ToSlash(sourcePrefix))
+}
+
 // Clone the url into a new object.
 func (u ClientURL) Clone() ClientURL {
 	return ClientURL{
@@ -43,12 +43,27 @@ const (
 	DirLast
 )
 
+// SymlinkOpt - filesystem symbolic link option.
+type SymlinkOpt int8
+
+const (
+	// SymlinkDefault - follow links to files and skip links to directories.
+	SymlinkDefault SymlinkOpt = iota
+	// SymlinkFollow - follow all links, including links to directories.
+	SymlinkFollow
+	// SymlinkPreserve - list links as they are, without following them.
+	SymlinkPreserve
+)
+
 // GetOptions holds options of the GET operation
 type GetOptions struct {
 	SSE        encrypt.ServerSide
 	VersionID  string
 	Zip        bool
 	RangeStart int64
+	// RangeLength limits the number of bytes read from
+	// RangeStart, zero reads until the end.
+	RangeLength int64
 }
 
 // PutOptions holds options for PUT operation
@@ -59,18 +74,48 @@ type PutOptions struct {
 	isPreserve            bool
 	storageClass          string
 	multipartSize         uint64
+	multipartThreshold    uint64
 	multipartThreads      uint
 	concurrentStream      bool
+	preserveXattr         bool
+	preallocate           bool
+	waitConsistent        waitConsistentOptions
+	noClobber             bool
+	// overwriteDirWithFile and overwriteFileWithDir replace a
+	// directory or a file in the way of an object on filesystem
+	// targets.
+	overwriteDirWithFile bool
+	overwriteFileWithDir bool
+	// preserveSymlink recreates links recorded in the
+	// metadata on filesystem targets.
+	preserveSymlink bool
+	// deltaBlockSize uploads only the blocks of this size which
+	// changed from an existing object, zero uploads everything.
+	deltaBlockSize int64
+	// trailerChecksum uploads in parts sending their checksum of this
+	// algorithm in the request trailers, see putTrailerChecksum.
+	trailerChecksum minio.ChecksumType
+}
+
+// waitConsistentOptions configures polling an uploaded object until
+// endpoints lacking read-after-write consistency return it, a zero
+// timeout disables polling.
+type waitConsistentOptions struct {
+	timeout          time.Duration
+	interval         time.Duration
+	disableETagCheck bool
 }
 
 // StatOptions holds options of the HEAD operation
 type StatOptions struct {
-	incomplete bool
-	preserve   bool
-	sse        encrypt.ServerSide
-	timeRef    time.Time
-	versionID  string
-	isZip      bool
+	incomplete    bool
+	preserve      bool
+	preserveXattr bool
+	sse           encrypt.ServerSide
+	timeRef       time.Time
+	versionID     string
+	isZip         bool
+	checksum      bool
 }
 
 // ListOptions holds options for listing operation
@@ -84,6 +129,20 @@ type ListOptions struct {
 	TimeRef           time.Time
 	ShowDir           DirOpt
 	Count             int
+	Symlinks          SymlinkOpt
+
+	// ExcludePrefixes are skipped by recursive listings without
+	// listing the keys below them, relative to the listed folder.
+	ExcludePrefixes []string
+
+	// BucketRegion looks up the region of each bucket listed at the
+	// root of an alias, costing one request per bucket.
+	BucketRegion bool
+
+	// BucketPublic checks whether the policy of each bucket listed at
+	// the root of an alias grants anonymous access, costing one
+	// request per bucket.
+	BucketPublic bool
 }
 
 // CopyOptions holds options for copying operation
@@ -95,6 +154,8 @@ type CopyOptions struct {
 	disableMultipart bool
 	isPreserve       bool
 	storageClass     string
+	replaceMetadata  bool
+	waitConsistent   waitConsistentOptions
 }
 
 // Client - client interface
@@ -185,6 +246,9 @@ type Client interface {
 type ClientContent struct {
 	URL          ClientURL
 	BucketName   string // only valid and set for client-type objectStorage
+	Region       string // only set for buckets listed with ListOptions.BucketRegion
+	Public       bool   // only set for buckets listed with ListOptions.BucketPublic
+	PublicReason string // only set for buckets listed with ListOptions.BucketPublic
 	Time         time.Time
 	Size         int64
 	Type         os.FileMode
@@ -201,6 +265,7 @@ type ClientContent struct {
 	RetentionEnabled  bool
 	RetentionMode     string
 	RetentionDuration string
+	RetainUntil       time.Time // only set by ls --lock-info
 	BypassGovernance  bool
 	LegalHoldEnabled  bool
 	LegalHold         string
@@ -211,6 +276,23 @@ type ClientContent struct {
 
 	Restore *minio.RestoreInfo
 
+	// The additional checksum stored with an object, only set by
+	// Stat with StatOptions.checksum.
+	ChecksumType string
+	Checksum     string
+
+	// Encryption is the server side encryption of an object, one of
+	// sse-s3, sse-kms, sse-c and none, only set by the enrichment of
+	// 'stat --policy'.
+	Encryption string
+
+	// UploadID is set for incomplete uploads only.
+	UploadID string
+
+	// RawHeaders holds the unparsed response headers of the
+	// HEAD request, only set for object storage.
+	RawHeaders map[string]string
+
 	Err *probe.Error
 }
 
@@ -230,8 +312,30 @@ type Config struct {
 	ConnReadDeadline  time.Duration
 	ConnWriteDeadline time.Duration
 	UploadLimit       int64
+	UploadLimitShared int64
 	DownloadLimit     int64
+	LimitBurst        int64
 	Transport         *http.Transport
+
+	// MultipartThreshold is the object size above which uploads
+	// switch to multipart, zero means the client default.
+	MultipartThreshold uint64
+
+	// ListVersion selects ListObjects (1) or ListObjectsV2 (2),
+	// zero uses V2 and falls back to V1 when unsupported.
+	ListVersion int
+
+	// Region overrides MC_REGION and AWS_REGION, it is set
+	// when the region of a bucket has been discovered.
+	Region string
+
+	// Accelerate sends object operations to the Amazon S3 transfer
+	// acceleration endpoint, bucket operations are not affected.
+	Accelerate bool
+
+	// RequestLimit is the maximum number of requests sent per
+	// second, zero means unlimited.
+	RequestLimit float64
 }
 
 // SelectObjectOpts - opts entered for select API
@@ -38,6 +38,7 @@ import (
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/minio/pkg/v2/env"
 )
 
@@ -166,8 +167,9 @@ func getSourceStreamMetadataFromURL(ctx context.Context, aliasedURL, versionID s
 
 type getSourceOpts struct {
 	GetOptions
-	fetchStat bool
-	preserve  bool
+	fetchStat     bool
+	preserve      bool
+	preserveXattr bool
 }
 
 // getSourceStreamFromURL gets a reader from URL.
@@ -260,7 +262,7 @@ func getSourceStream(ctx context.Context, alias, urlStr string, opts getSourceOp
 			}
 			st.ETag = oinfo.ETag
 		} else {
-			st, err = sourceClnt.Stat(ctx, StatOptions{preserve: opts.preserve, sse: opts.SSE})
+			st, err = sourceClnt.Stat(ctx, StatOptions{preserve: opts.preserve, preserveXattr: opts.preserveXattr, sse: opts.SSE})
 			if err != nil {
 				return nil, nil, err.Trace(alias, urlStr)
 			}
@@ -373,6 +375,22 @@ func copySourceToTargetURL(ctx context.Context, alias, urlStr, source, sourceVer
 	return nil
 }
 
+// statNoClobberTarget - fails with ObjectAlreadyExists when the target is present.
+func statNoClobberTarget(ctx context.Context, alias, urlStr string) *probe.Error {
+	targetClnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return err.Trace(alias, urlStr)
+	}
+	if _, err = targetClnt.Stat(ctx, StatOptions{}); err == nil {
+		return probe.NewError(ObjectAlreadyExists{Object: urlStr})
+	}
+	switch err.ToGoError().(type) {
+	case PathNotFound, ObjectMissing:
+		return nil
+	}
+	return err.Trace(alias, urlStr)
+}
+
 func filterMetadata(metadata map[string]string) map[string]string {
 	newMetadata := map[string]string{}
 	for k, v := range metadata {
@@ -388,6 +406,58 @@ func filterMetadata(metadata map[string]string) map[string]string {
 	return newMetadata
 }
 
+// getSourceACL - returns the headers granting the ACL of the source
+// object, filesystem sources have none.
+func getSourceACL(ctx context.Context, sourceAlias, sourceURLStr string) (map[string]string, *probe.Error) {
+	sourceClnt, err := newClientFromAlias(sourceAlias, sourceURLStr)
+	if err != nil {
+		return nil, err.Trace(sourceAlias, sourceURLStr)
+	}
+	s3Clnt, ok := sourceClnt.(*S3Client)
+	if !ok {
+		return nil, nil
+	}
+	return s3Clnt.getObjectACL(ctx)
+}
+
+// getSourceTags returns the tags of an object as the tagging header
+// setting the same tags on a copy, none for a file.
+func getSourceTags(ctx context.Context, sourceAlias, sourceURLStr, versionID string) (string, *probe.Error) {
+	sourceClnt, err := newClientFromAlias(sourceAlias, sourceURLStr)
+	if err != nil {
+		return "", err.Trace(sourceAlias, sourceURLStr)
+	}
+	s3Clnt, ok := sourceClnt.(*S3Client)
+	if !ok {
+		return "", nil
+	}
+	tagsMap, err := s3Clnt.GetTags(ctx, versionID)
+	if err != nil || len(tagsMap) == 0 {
+		return "", err
+	}
+	t, e := tags.MapToObjectTags(tagsMap)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return t.String(), nil
+}
+
+// dropUnpreservedAttrs removes the file attributes, or the extended
+// attributes of a file, left out by mirror --preserve from the
+// metadata of a preserved source.
+func dropUnpreservedAttrs(urls URLs, metadata map[string]string) {
+	if urls.skipFileAttrs {
+		delete(metadata, metadataKey)
+	}
+	if urls.skipXattrs && urls.SourceContent.URL.Type == fileSystem {
+		for k := range metadata {
+			if k != metadataKey && k != "Content-Type" {
+				delete(metadata, k)
+			}
+		}
+	}
+}
+
 // getAllMetadata - returns a map of user defined function
 // by combining the usermetadata of object and values passed by attr keyword
 func getAllMetadata(ctx context.Context, sourceAlias, sourceURLStr string, srcSSE encrypt.ServerSide, urls URLs) (map[string]string, *probe.Error) {
@@ -429,6 +499,13 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 	srcSSE := getSSE(sourcePath, encKeyDB[sourceAlias])
 	tgtSSE := getSSE(targetPath, encKeyDB[targetAlias])
 
+	if urls.MaxObjectSize > 0 && length > urls.MaxObjectSize {
+		if !urls.Split {
+			return urls.WithError(errObjectTooLarge(sourceURL.String(), length, urls.MaxObjectSize))
+		}
+		return splitSourceToTargetURL(ctx, urls, progress, srcSSE, tgtSSE)
+	}
+
 	var err *probe.Error
 	metadata := map[string]string{}
 	var mode, until, legalHold string
@@ -477,10 +554,28 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 		metadata[http.CanonicalHeaderKey(k)] = v
 	}
 
+	var aclHeaders map[string]string
+	if urls.PreserveACL {
+		aclHeaders, err = getSourceACL(ctx, sourceAlias, sourceURL.String())
+		if err != nil {
+			return urls.WithError(err.Trace(sourceURL.String()))
+		}
+	}
+
+	// Links listed with --no-dereference are uploaded as empty
+	// objects recording their target in the metadata.
+	isSymlink := urls.SourceContent.Type&os.ModeSymlink != 0
+
 	// Optimize for server side copy if the host is same.
-	if sourceAlias == targetAlias && !isZip {
+	if sourceAlias == targetAlias && !isZip && !isSymlink {
+		replaceMetadata := urls.MetadataDirective == "REPLACE"
+		if replaceMetadata {
+			// Only the metadata given for the target is kept.
+			metadata = map[string]string{}
+		}
+
 		// preserve new metadata and save existing ones.
-		if preserve {
+		if preserve && !replaceMetadata {
 			currentMetadata, err := getAllMetadata(ctx, sourceAlias, sourceURL.String(), srcSSE, urls)
 			if err != nil {
 				return urls.WithError(err.Trace(sourceURL.String()))
@@ -488,6 +583,7 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 			for k, v := range currentMetadata {
 				metadata[k] = v
 			}
+			dropUnpreservedAttrs(urls, metadata)
 		}
 
 		// Get metadata from target content as well
@@ -500,12 +596,23 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 			metadata[http.CanonicalHeaderKey(k)] = v
 		}
 
+		for k, v := range aclHeaders {
+			metadata[k] = v
+		}
+
 		sourcePath := filepath.ToSlash(sourceURL.Path)
 		if urls.SourceContent.RetentionEnabled {
 			err = putTargetRetention(ctx, targetAlias, targetURL.String(), metadata)
 			return urls.WithError(err.Trace(sourceURL.String()))
 		}
 
+		// Server-side copies cannot be made conditional, check the target instead.
+		if urls.NoClobber {
+			if err = statNoClobberTarget(ctx, targetAlias, targetURL.String()); err != nil {
+				return urls.WithError(err.Trace(targetURL.String()))
+			}
+		}
+
 		opts := CopyOptions{
 			srcSSE:           srcSSE,
 			tgtSSE:           tgtSSE,
@@ -513,6 +620,8 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 			disableMultipart: urls.DisableMultipart,
 			isPreserve:       preserve,
 			storageClass:     urls.TargetContent.StorageClass,
+			replaceMetadata:  replaceMetadata,
+			waitConsistent:   urls.waitConsistent,
 		}
 
 		err = copySourceToTargetURL(ctx, targetAlias, targetURL.String(), sourcePath, sourceVersion, mode, until,
@@ -545,21 +654,46 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 		}
 
 		var reader io.ReadCloser
-		// Proceed with regular stream copy.
-		reader, metadata, err = getSourceStream(ctx, sourceAlias, sourceURL.String(), getSourceOpts{
-			GetOptions: GetOptions{
-				VersionID: sourceVersion,
-				SSE:       srcSSE,
-				Zip:       isZip,
-			},
-			fetchStat: true,
-			preserve:  preserve,
-		})
-		if err != nil {
-			return urls.WithError(err.Trace(sourceURL.String()))
+		if isSymlink {
+			reader = io.NopCloser(strings.NewReader(""))
+		} else {
+			// Proceed with regular stream copy.
+			reader, metadata, err = getSourceStream(ctx, sourceAlias, sourceURL.String(), getSourceOpts{
+				GetOptions: GetOptions{
+					VersionID: sourceVersion,
+					SSE:       srcSSE,
+					Zip:       isZip,
+				},
+				fetchStat:     true,
+				preserve:      preserve,
+				preserveXattr: urls.PreserveXattr,
+			})
+			if err != nil {
+				return urls.WithError(err.Trace(sourceURL.String()))
+			}
+			if preserve {
+				dropUnpreservedAttrs(urls, metadata)
+			}
 		}
 		defer reader.Close()
 
+		if urls.PreserveTags {
+			var tagging string
+			if tagging, err = getSourceTags(ctx, sourceAlias, sourceURL.String(), sourceVersion); err != nil {
+				return urls.WithError(err.Trace(sourceURL.String()))
+			}
+			if tagging != "" {
+				metadata["X-Amz-Tagging"] = tagging
+			}
+		}
+
+		// Content types mapped by extension override the guessed one,
+		// but not a Content-Type given explicitly with --attr.
+		if contentType, ok := urls.contentTypes[strings.ToLower(filepath.Ext(targetURL.Path))]; ok {
+			metadata["Content-Type"] = contentType
+		}
+		urls.contentHeaders.apply(metadata, filepath.Ext(targetURL.Path))
+
 		// Get metadata from target content as well
 		for k, v := range urls.TargetContent.Metadata {
 			metadata[http.CanonicalHeaderKey(k)] = v
@@ -570,6 +704,24 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 			metadata[http.CanonicalHeaderKey(k)] = v
 		}
 
+		for k, v := range aclHeaders {
+			metadata[k] = v
+		}
+
+		// Compressible objects are gzipped with --auto-compress and the
+		// objects of gzip rules of --deploy-manifest, the progress counts
+		// the bytes read from the source instead.
+		compress := urls.Gzip || (urls.AutoCompress && length >= urls.AutoCompressMin && isCompressibleContentType(metadata["Content-Type"]))
+		if compress && !isSymlink && targetURL.Type == objectStorage && metadata["Content-Encoding"] == "" {
+			file, size, err := gzipToTempFile(io.LimitReader(reader, length), progress)
+			if err != nil {
+				return urls.WithError(err.Trace(sourceURL.String()))
+			}
+			defer removeTempFile(file)
+			reader, length, progress = file, size, nil
+			metadata["Content-Encoding"] = "gzip"
+		}
+
 		var e error
 		var multipartSize uint64
 		if v := env.Get("MC_UPLOAD_MULTIPART_SIZE", ""); v != "" {
@@ -593,6 +745,17 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 			isPreserve:       preserve,
 			multipartSize:    multipartSize,
 			multipartThreads: uint(multipartThreads),
+
+			multipartThreshold:   urls.MultipartThreshold,
+			preserveXattr:        urls.PreserveXattr,
+			preallocate:          urls.Preallocate,
+			waitConsistent:       urls.waitConsistent,
+			noClobber:            urls.NoClobber,
+			overwriteDirWithFile: urls.OverwriteDirWithFile,
+			overwriteFileWithDir: urls.OverwriteFileWithDir,
+			preserveSymlink:      urls.NoDereference,
+			deltaBlockSize:       urls.DeltaBlockSize,
+			trailerChecksum:      urls.TrailerChecksum,
 		}
 
 		if isReadAt(reader) {
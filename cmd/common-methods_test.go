@@ -69,3 +69,28 @@ func TestGetDecodedKey(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePreserveAttrs(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected preserveAttrs
+		fail     bool
+	}{
+		{"", preserveAttrs{}, false},
+		{"true", preserveAttrs{metadata: true, timestamps: true, bucketConfig: true}, false},
+		{"false", preserveAttrs{}, false},
+		{"all", preserveAttrs{metadata: true, tags: true, acl: true, timestamps: true, bucketConfig: true}, false},
+		{"metadata,tags,timestamps", preserveAttrs{metadata: true, tags: true, timestamps: true}, false},
+		{"ACL", preserveAttrs{acl: true}, false},
+		{"metadata,owner", preserveAttrs{}, true},
+	}
+	for _, testCase := range testCases {
+		p, err := parsePreserveAttrs(testCase.value)
+		if (err != nil) != testCase.fail {
+			t.Fatalf("%q: unexpected error %v", testCase.value, err)
+		}
+		if p != testCase.expected {
+			t.Fatalf("%q: expected %+v, got %+v", testCase.value, testCase.expected, p)
+		}
+	}
+}
@@ -46,6 +46,32 @@ type aliasConfigV10 struct {
 	Path         string `json:"path"`
 	License      string `json:"license,omitempty"`
 	APIKey       string `json:"apiKey,omitempty"`
+
+	// MultipartThreshold is the default object size above which
+	// uploads to this alias use multipart, e.g. "64MiB".
+	MultipartThreshold string `json:"multipartThreshold,omitempty"`
+
+	// ListVersion is the listing API version used with this
+	// alias, "1" or "2", auto-detected when empty.
+	ListVersion string `json:"listVersion,omitempty"`
+
+	// ResumeThreshold is the transfer size above which copies
+	// involving this alias are resumable by default, e.g. "5GiB".
+	ResumeThreshold string `json:"resumeThreshold,omitempty"`
+
+	// Accelerate routes object uploads and downloads of this Amazon
+	// S3 alias through the transfer acceleration endpoint.
+	Accelerate bool `json:"accelerate,omitempty"`
+
+	// LimitUpload and LimitDownload limit the transfer rates of
+	// this alias, e.g. "10MiB", unless --limit-upload and
+	// --limit-download are given.
+	LimitUpload   string `json:"limitUpload,omitempty"`
+	LimitDownload string `json:"limitDownload,omitempty"`
+
+	// RequestsPerSecond limits the rate of requests sent to this
+	// alias, unless --limit-requests is given.
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
 }
 
 // configV10 config version.
@@ -20,6 +20,8 @@ package cmd
 import (
 	"fmt"
 	"strings"
+
+	"github.com/dustin/go-humanize"
 )
 
 // Check if version of the config is valid
@@ -62,5 +64,41 @@ func validateConfigHost(host aliasConfigV10) (bool, []string) {
 		validationSuccessful = false
 		hostErrors = append(hostErrors, errInvalidURL(host.URL).ToGoError().Error())
 	}
+	if host.MultipartThreshold != "" {
+		if _, err := parseMultipartThreshold(host.MultipartThreshold); err != nil {
+			validationSuccessful = false
+			hostErrors = append(hostErrors, fmt.Sprintf("Invalid multipart threshold `%s` for `%s`: %s",
+				host.MultipartThreshold, host.URL, err.ToGoError()))
+		}
+	}
+	if host.ListVersion != "" {
+		if _, e := parseListVersion(host.ListVersion); e != nil {
+			validationSuccessful = false
+			hostErrors = append(hostErrors, fmt.Sprintf("Invalid list version `%s` for `%s`: %s",
+				host.ListVersion, host.URL, e))
+		}
+	}
+	if host.ResumeThreshold != "" {
+		if _, e := humanize.ParseBytes(host.ResumeThreshold); e != nil {
+			validationSuccessful = false
+			hostErrors = append(hostErrors, fmt.Sprintf("Invalid resume threshold `%s` for `%s`: %s",
+				host.ResumeThreshold, host.URL, e))
+		}
+	}
+	for name, limit := range map[string]string{"upload": host.LimitUpload, "download": host.LimitDownload} {
+		if limit == "" {
+			continue
+		}
+		if _, e := humanize.ParseBytes(limit); e != nil {
+			validationSuccessful = false
+			hostErrors = append(hostErrors, fmt.Sprintf("Invalid %s limit `%s` for `%s`: %s",
+				name, limit, host.URL, e))
+		}
+	}
+	if host.RequestsPerSecond < 0 {
+		validationSuccessful = false
+		hostErrors = append(hostErrors, fmt.Sprintf("Invalid requests per second `%v` for `%s`: must not be negative",
+			host.RequestsPerSecond, host.URL))
+	}
 	return validationSuccessful, hostErrors
 }
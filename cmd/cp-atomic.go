@@ -0,0 +1,152 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// atomicStagingURL - returns the folder a copy with --atomic-prefix
+// uploads to before promoting it to targetURL. The folder is a hidden
+// sibling of the target prefix, or a hidden folder of a target bucket.
+func atomicStagingURL(targetURL string) string {
+	target := strings.TrimSuffix(targetURL, "/")
+	staging := ".mc-staging-" + strconv.FormatInt(time.Now().UnixNano(), 36) + "/"
+	if strings.Count(target, "/") < 2 {
+		// alias/bucket
+		return target + "/" + staging
+	}
+	dir, name := target[:strings.LastIndex(target, "/")+1], target[strings.LastIndex(target, "/")+1:]
+	return dir + "." + name + staging
+}
+
+// doCopyAtomicPrefix - copies the sources into a staging folder and only
+// once all of them were copied, copies the staged objects server side to
+// the target prefix. The staging folder is removed when the copy fails,
+// it is kept when the promotion fails so that it can be finished.
+//
+// Only the upload is atomic: the staged objects are promoted one by one,
+// a reader of the target prefix may see some of them before the others.
+func doCopyAtomicPrefix(ctx context.Context, cancelCopy context.CancelFunc, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) error {
+	args := cliCtx.Args()
+	targetURL := args[len(args)-1]
+	stagingURL := atomicStagingURL(targetURL)
+
+	// A fatal error or a signal exits without returning here.
+	removeHook := atExit(func() { removeAtomicStaging(context.Background(), stagingURL) })
+	e := doCopySession(ctx, cancelCopy, cliCtx, nil, encKeyDB, false, stagingURL)
+	removeHook()
+	if e != nil {
+		// The context may be canceled already.
+		removeAtomicStaging(context.Background(), stagingURL)
+		return e
+	}
+
+	stagingKept := atomicStagingKept{stagingURL: stagingURL, targetURL: targetURL}
+	removeHook = atExit(func() { errorIf(probe.NewError(stagingKept), "The promotion to `"+targetURL+"` is not finished.") })
+	promoted, err := promoteAtomicStaging(ctx, stagingURL, targetURL, encKeyDB)
+	removeHook()
+	if err != nil {
+		errorIf(err.Trace(stagingURL, targetURL), "Unable to promote the staged objects to `"+targetURL+"`, %d object(s) were promoted.", promoted)
+		errorIf(probe.NewError(stagingKept), "The promotion to `"+targetURL+"` is not finished.")
+		return exitStatus(globalErrorExitStatus)
+	}
+	removeAtomicStaging(ctx, stagingURL)
+	if !globalQuiet && !globalJSON {
+		console.Infof("Promoted %d staged object(s) to `%s`.\n", promoted, targetURL)
+	}
+	return nil
+}
+
+// atomicStagingKept - the staging folder of a promotion that did not
+// finish, with the commands finishing it.
+type atomicStagingKept struct {
+	stagingURL, targetURL string
+}
+
+func (e atomicStagingKept) Error() string {
+	return fmt.Sprintf("The staged objects are kept in `%s`, finish the promotion with `mc cp --recursive %s %s` then `mc rm --recursive --force %s`.",
+		e.stagingURL, e.stagingURL, strings.TrimSuffix(e.targetURL, "/")+"/", e.stagingURL)
+}
+
+// promoteAtomicStaging - copies every object of stagingURL server side to
+// the same relative key below targetURL, returns the promoted objects.
+func promoteAtomicStaging(ctx context.Context, stagingURL, targetURL string, encKeyDB map[string][]prefixSSEPair) (promoted int, err *probe.Error) {
+	alias, _, _ := mustExpandAlias(stagingURL)
+	stagingClnt, err := newClient(stagingURL)
+	if err != nil {
+		return 0, err.Trace(stagingURL)
+	}
+	stagingPath := stagingClnt.GetURL().Path
+	for content := range stagingClnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return promoted, content.Err.Trace(stagingURL)
+		}
+		sourcePath := filepath.ToSlash(content.URL.Path)
+		objectURL := strings.TrimSuffix(targetURL, "/") + "/" + strings.TrimPrefix(sourcePath, stagingPath)
+		targetClnt, err := newClient(objectURL)
+		if err != nil {
+			return promoted, err.Trace(objectURL)
+		}
+		opts := CopyOptions{
+			size:   content.Size,
+			srcSSE: getSSE(filepath.ToSlash(filepath.Join(alias, sourcePath)), encKeyDB[alias]),
+			tgtSSE: getSSE(objectURL, encKeyDB[alias]),
+		}
+		if err = targetClnt.Copy(ctx, sourcePath, opts, nil); err != nil {
+			return promoted, err.Trace(sourcePath, objectURL)
+		}
+		promoted++
+	}
+	return promoted, nil
+}
+
+// removeAtomicStaging - removes the staging folder of --atomic-prefix.
+func removeAtomicStaging(ctx context.Context, stagingURL string) {
+	clnt, err := newClient(stagingURL)
+	if err != nil {
+		errorIf(err.Trace(stagingURL), "Unable to remove the staging folder `"+stagingURL+"`.")
+		return
+	}
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		for content := range clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+			if content.Err != nil {
+				// Nothing was staged.
+				if _, ok := content.Err.ToGoError().(ObjectMissing); !ok {
+					errorIf(content.Err.Trace(stagingURL), "Unable to list the staging folder `"+stagingURL+"`.")
+				}
+				continue
+			}
+			contentCh <- content
+		}
+	}()
+	for result := range clnt.Remove(ctx, false, false, false, false, contentCh) {
+		errorIf(result.Err.Trace(stagingURL), "Unable to remove the staging folder `"+stagingURL+"`.")
+	}
+}
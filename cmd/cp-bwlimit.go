@@ -0,0 +1,74 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/probe"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// parseDestinationLimits reads a YAML --bwlimit-file mapping destination
+// aliases or hosts to the rate their uploads are limited to, e.g.
+//
+//	remote-dr: 5MiB
+//	minio.local:9000: 1GiB/s
+func parseDestinationLimits(file string) (map[string]uint64, *probe.Error) {
+	data, e := os.ReadFile(file)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	var entries map[string]string
+	if e = yaml.UnmarshalStrict(data, &entries); e != nil {
+		return nil, probe.NewError(e)
+	}
+	limits := make(map[string]uint64, len(entries))
+	for destination, rate := range entries {
+		limit, e := humanize.ParseBytes(strings.TrimSuffix(strings.TrimSpace(rate), "/s"))
+		if e != nil {
+			return nil, probe.NewError(fmt.Errorf("%s: %w", destination, e))
+		}
+		if limit == 0 {
+			return nil, probe.NewError(fmt.Errorf("%s: limit must be positive", destination))
+		}
+		limits[destination] = limit
+	}
+	return limits, nil
+}
+
+// destinationLimit returns the upload limit of --bwlimit-file for an
+// alias, else for the host of urlStr with or without its port, zero for
+// unlisted destinations which are unlimited.
+func destinationLimit(limits map[string]uint64, alias, urlStr string) uint64 {
+	if limit, ok := limits[alias]; ok && alias != "" {
+		return limit
+	}
+	host := newClientURL(urlStr).Host
+	if limit, ok := limits[host]; ok && host != "" {
+		return limit
+	}
+	if hostname, _, e := net.SplitHostPort(host); e == nil {
+		return limits[hostname]
+	}
+	return 0
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// copyContentHashes are the algorithms of 'cp --content-hash'.
+var copyContentHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// copyContentMaxFanout is the largest number of levels of folders named
+// after the leading hex digits of a hash, e.g. two for sha256/ab/cd/abcd...
+const copyContentMaxFanout = 4
+
+// copyContentEntry maps the path of a source, relative to the target
+// prefix, to the content addressed object storing it.
+type copyContentEntry struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// copyContentManifest lists the sources stored by a content addressed
+// copy, to find their objects again by their paths.
+type copyContentManifest struct {
+	Version   string             `json:"version"`
+	Algorithm string             `json:"algorithm"`
+	Entries   []copyContentEntry `json:"entries"`
+}
+
+// copyContentStore copies the sources of 'cp --content-addressed' to
+// keys derived from their content hash below the target prefix, sources
+// with the content of an existing object are not uploaded again.
+type copyContentStore struct {
+	targetURL   string
+	prefix      string
+	algorithm   string
+	fanout      int
+	manifestURL string
+	encKeyDB    map[string][]prefixSSEPair
+
+	mu       sync.Mutex
+	manifest copyContentManifest
+	stored   int64
+	skipped  int64
+}
+
+// newCopyContentStore - returns a store for the sources copied to targetURL.
+func newCopyContentStore(targetURL, algorithm string, fanout int, manifestName string, encKeyDB map[string][]prefixSSEPair) (*copyContentStore, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	prefix := filepath.ToSlash(clnt.GetURL().Path)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	targetURL = strings.TrimSuffix(targetURL, "/") + "/"
+	return &copyContentStore{
+		targetURL:   targetURL,
+		prefix:      prefix,
+		algorithm:   algorithm,
+		fanout:      fanout,
+		manifestURL: targetURL + manifestName,
+		encKeyDB:    encKeyDB,
+		manifest:    copyContentManifest{Version: "1", Algorithm: algorithm},
+	}, nil
+}
+
+// key - returns the key of a content hash, relative to the target prefix.
+func (s *copyContentStore) key(sum string) string {
+	var b strings.Builder
+	b.WriteString(s.algorithm)
+	b.WriteString("/")
+	for i := 0; i < s.fanout; i++ {
+		b.WriteString(sum[2*i : 2*i+2])
+		b.WriteString("/")
+	}
+	b.WriteString(sum)
+	return b.String()
+}
+
+// hash - reads a source to compute its content hash.
+func (s *copyContentStore) hash(ctx context.Context, cpURLs URLs) (string, *probe.Error) {
+	source := cpURLs.SourceContent
+	sourcePath := filepath.ToSlash(filepath.Join(cpURLs.SourceAlias, source.URL.Path))
+	reader, _, err := getSourceStream(ctx, cpURLs.SourceAlias, source.URL.String(), getSourceOpts{
+		GetOptions: GetOptions{SSE: getSSE(sourcePath, s.encKeyDB[cpURLs.SourceAlias])},
+	})
+	if err != nil {
+		return "", err.Trace(source.URL.String())
+	}
+	defer reader.Close()
+	h := copyContentHashes[s.algorithm]()
+	if _, e := io.Copy(h, reader); e != nil {
+		return "", probe.NewError(e).Trace(source.URL.String())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copy - hashes a source then copies it with doCopy to the key of its
+// hash, unless an object already exists at that key. The source is read
+// twice, the key must be known before the upload starts for duplicates
+// not to be transferred.
+func (s *copyContentStore) copy(ctx context.Context, cpURLs URLs, pg ProgressReader, doCopy func(URLs) URLs) URLs {
+	if cpURLs.Error != nil || cpURLs.SourceContent.Type.IsDir() {
+		return doCopyFake(cpURLs, pg)
+	}
+	relPath := strings.TrimPrefix(filepath.ToSlash(cpURLs.TargetContent.URL.Path), s.prefix)
+	sum, err := s.hash(ctx, cpURLs)
+	if err != nil {
+		return cpURLs.WithError(err)
+	}
+	key := s.key(sum)
+	targetURL := s.targetURL + key
+	alias, urlStr, _, err := expandAlias(targetURL)
+	if err != nil {
+		return cpURLs.WithError(err.Trace(targetURL))
+	}
+	cpURLs.TargetAlias = alias
+	cpURLs.TargetContent.URL = *newClientURL(urlStr)
+
+	targetClnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return cpURLs.WithError(err.Trace(targetURL))
+	}
+	_, err = targetClnt.Stat(ctx, StatOptions{sse: getSSE(targetURL, s.encKeyDB[alias])})
+	exists := err == nil
+	if exists {
+		cpURLs = doCopyFake(cpURLs, pg)
+	} else {
+		cpURLs = doCopy(cpURLs)
+		if cpURLs.Error != nil {
+			return cpURLs
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if exists {
+		s.skipped++
+	} else {
+		s.stored++
+	}
+	s.manifest.Entries = append(s.manifest.Entries, copyContentEntry{
+		Path: relPath,
+		Hash: sum,
+		Key:  key,
+		Size: cpURLs.SourceContent.Size,
+	})
+	return cpURLs
+}
+
+// writeManifest - uploads the manifest of the sources stored, sorted by
+// path.
+func (s *copyContentStore) writeManifest(ctx context.Context) *probe.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sort.Slice(s.manifest.Entries, func(i, j int) bool {
+		return s.manifest.Entries[i].Path < s.manifest.Entries[j].Path
+	})
+	data, e := json.MarshalIndent(s.manifest, "", " ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	alias, urlStr, _, err := expandAlias(s.manifestURL)
+	if err != nil {
+		return err.Trace(s.manifestURL)
+	}
+	_, err = putTargetStream(ctx, alias, urlStr, "", "", "", bytes.NewReader(data), int64(len(data)), nil, PutOptions{
+		metadata: map[string]string{"Content-Type": "application/json"},
+		sse:      getSSE(s.manifestURL, s.encKeyDB[alias]),
+	})
+	return err.Trace(s.manifestURL)
+}
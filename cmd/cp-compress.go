@@ -0,0 +1,78 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"os"
+
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// isCompressibleContentType returns true if objects of contentType are
+// worth compressing with --auto-compress. Images, videos and archives
+// are already compressed.
+func isCompressibleContentType(contentType string) bool {
+	if isTextContentType(contentType) {
+		return true
+	}
+	mediaType, _, e := mime.ParseMediaType(contentType)
+	if e != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/wasm", "application/yaml", "application/x-yaml", "application/x-ndjson", "application/x-sh", "application/x-tar":
+		return true
+	}
+	return false
+}
+
+// gzipToTempFile compresses reader into a temporary file, counting the
+// bytes read with progress, and returns the file rewound with its size.
+// The file is removed once closed by the caller with removeTempFile.
+func gzipToTempFile(reader, progress io.Reader) (*os.File, int64, *probe.Error) {
+	file, e := os.CreateTemp("", "mc-gzip-")
+	if e != nil {
+		return nil, 0, probe.NewError(e)
+	}
+	gz := gzip.NewWriter(file)
+	if _, e = io.Copy(gz, hookreader.NewHook(reader, progress)); e == nil {
+		e = gz.Close()
+	}
+	var size int64
+	if e == nil {
+		size, e = file.Seek(0, io.SeekCurrent)
+	}
+	if e == nil {
+		_, e = file.Seek(0, io.SeekStart)
+	}
+	if e != nil {
+		removeTempFile(file)
+		return nil, 0, probe.NewError(e)
+	}
+	return file, size, nil
+}
+
+// removeTempFile closes and removes a temporary file.
+func removeTempFile(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/wildcard"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// deployHeaders are the headers a --deploy-manifest sets on uploads.
+type deployHeaders struct {
+	CacheControl    string `yaml:"cache-control"`
+	ContentType     string `yaml:"content-type"`
+	ContentEncoding string `yaml:"content-encoding"`
+	Gzip            bool   `yaml:"gzip"`
+}
+
+// deployRule sets headers on the files matching a pattern. Patterns
+// without a slash match the file name, others match the whole path
+// relative to the target, their * also matching slashes.
+type deployRule struct {
+	Match         string `yaml:"match"`
+	deployHeaders `yaml:",inline"`
+}
+
+// deployManifest is a --deploy-manifest, the first rule matching a file
+// gives its headers, files matched by no rule get the defaults.
+type deployManifest struct {
+	Defaults deployHeaders `yaml:"defaults"`
+	Rules    []deployRule  `yaml:"rules"`
+}
+
+// validate checks the headers do not ask both to gzip objects and to
+// set another encoding.
+func (h deployHeaders) validate() error {
+	if h.Gzip && h.ContentEncoding != "" {
+		return fmt.Errorf("gzip and content-encoding cannot be used together")
+	}
+	return nil
+}
+
+// parseDeployManifest reads a YAML --deploy-manifest file.
+func parseDeployManifest(file string) (*deployManifest, *probe.Error) {
+	data, e := os.ReadFile(file)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	manifest := &deployManifest{}
+	if e = yaml.UnmarshalStrict(data, manifest); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if e = manifest.Defaults.validate(); e != nil {
+		return nil, probe.NewError(fmt.Errorf("defaults: %w", e))
+	}
+	for i, rule := range manifest.Rules {
+		if rule.Match == "" {
+			return nil, probe.NewError(fmt.Errorf("rule %d: match is empty", i+1))
+		}
+		if _, e = path.Match(rule.Match, ""); e != nil {
+			return nil, probe.NewError(fmt.Errorf("rule %d: invalid pattern `%s`", i+1, rule.Match))
+		}
+		if e = rule.validate(); e != nil {
+			return nil, probe.NewError(fmt.Errorf("rule %d: %w", i+1, e))
+		}
+	}
+	return manifest, nil
+}
+
+// headers returns the headers of the file at key, a slash separated
+// path relative to the target.
+func (m *deployManifest) headers(key string) deployHeaders {
+	for _, rule := range m.Rules {
+		if !strings.Contains(rule.Match, "/") {
+			if ok, _ := path.Match(rule.Match, path.Base(key)); ok {
+				return rule.deployHeaders
+			}
+			continue
+		}
+		if wildcard.Match(strings.TrimPrefix(rule.Match, "/"), key) {
+			return rule.deployHeaders
+		}
+	}
+	return m.Defaults
+}
+
+// apply sets the headers on the target of urls.
+func (h deployHeaders) apply(urls *URLs) {
+	if h.CacheControl != "" {
+		urls.TargetContent.Metadata["Cache-Control"] = h.CacheControl
+	}
+	if h.ContentType != "" {
+		urls.TargetContent.Metadata["Content-Type"] = h.ContentType
+	}
+	if h.ContentEncoding != "" {
+		urls.TargetContent.Metadata["Content-Encoding"] = h.ContentEncoding
+	}
+	urls.Gzip = h.Gzip
+}
+
+// deployKey returns the path of target relative to the expanded target
+// argument of cp, or its name when copying to an object.
+func deployKey(targetPrefix string, target ClientURL) string {
+	key := strings.TrimPrefix(filepath.ToSlash(target.String()), filepath.ToSlash(targetPrefix))
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		key = path.Base(filepath.ToSlash(target.Path))
+	}
+	return key
+}
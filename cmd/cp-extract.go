@@ -0,0 +1,222 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Archive formats supported by 'cp --extract'.
+const (
+	archiveUnknown = iota
+	archiveTar
+	archiveTarGzip
+	archiveZip
+)
+
+// archiveFormat guesses the format of an archive from its name.
+func archiveFormat(name string) int {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return archiveTarGzip
+	case strings.HasSuffix(name, ".zip"):
+		return archiveZip
+	}
+	return archiveUnknown
+}
+
+// extractOptions select the archive members uploaded by 'cp --extract'
+// and how they are named.
+type extractOptions struct {
+	include         []string
+	stripComponents int
+}
+
+// memberKey returns the key of an archive member relative to the target
+// prefix, or false when the member is filtered out. Like tar, a member
+// with no more than stripComponents path components is skipped.
+func (o extractOptions) memberKey(name string) (string, bool) {
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "", false
+	}
+	components := strings.Split(name, "/")
+	if len(components) <= o.stripComponents {
+		return "", false
+	}
+	key := strings.Join(components[o.stripComponents:], "/")
+	if len(o.include) == 0 {
+		return key, true
+	}
+	for _, pattern := range o.include {
+		// Patterns match the full key or only its base name.
+		if ok, _ := path.Match(pattern, key); ok {
+			return key, true
+		}
+		if ok, _ := path.Match(pattern, path.Base(key)); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// extractMember is called for every regular file of an archive, in order.
+type extractMember func(name string, reader io.Reader, size int64) *probe.Error
+
+// walkTar calls fn for every regular file of a tar stream. Data of the
+// members fn is not called for is skipped by the tar reader, which seeks
+// over it when the stream allows.
+func walkTar(reader io.Reader, o extractOptions, fn extractMember) *probe.Error {
+	tr := tar.NewReader(reader)
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		key, ok := o.memberKey(hdr.Name)
+		if !ok {
+			continue
+		}
+		if err := fn(key, tr, hdr.Size); err != nil {
+			return err
+		}
+	}
+}
+
+// walkZip calls fn for every regular file of a zip archive. Members are
+// located through the central directory, those filtered out are never read.
+func walkZip(reader io.ReaderAt, size int64, o extractOptions, fn extractMember) *probe.Error {
+	zr, e := zip.NewReader(reader, size)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	for _, file := range zr.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		key, ok := o.memberKey(file.Name)
+		if !ok {
+			continue
+		}
+		rc, e := file.Open()
+		if e != nil {
+			return probe.NewError(e).Trace(file.Name)
+		}
+		err := fn(key, rc, int64(file.UncompressedSize64))
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// doCopyExtract uploads the members of the source archive as objects
+// under the target prefix, streaming the archive member by member.
+func doCopyExtract(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair, userMetaMap map[string]string) error {
+	sourceURL := cliCtx.Args().Get(0)
+	targetURL := cliCtx.Args().Get(1)
+	if !strings.HasSuffix(targetURL, "/") {
+		targetURL += "/"
+	}
+	o := extractOptions{
+		include:         cliCtx.StringSlice("extract-include"),
+		stripComponents: cliCtx.Int("extract-strip-components"),
+	}
+
+	reader, err := getSourceStreamFromURL(ctx, sourceURL, encKeyDB, getSourceOpts{})
+	fatalIf(err.Trace(sourceURL), "Unable to read archive `"+sourceURL+"`.")
+	defer reader.Close()
+
+	contentTypes, _ := parseContentTypeMap(cliCtx.String("content-type-map"))
+	headers, _ := parseContentHeaders(cliCtx)
+
+	var totalCount, totalSize int64
+	upload := func(key string, member io.Reader, size int64) *probe.Error {
+		objectURL := targetURL + key
+		alias, urlStr, _, err := expandAlias(objectURL)
+		if err != nil {
+			return err.Trace(objectURL)
+		}
+		metadata := make(map[string]string, len(userMetaMap))
+		if contentType, ok := contentTypes[strings.ToLower(path.Ext(key))]; ok {
+			metadata["Content-Type"] = contentType
+		}
+		headers.apply(metadata, path.Ext(key))
+		for k, v := range userMetaMap {
+			metadata[k] = v
+		}
+		if _, err = putTargetStream(ctx, alias, urlStr, "", "", "", member, size, nil, PutOptions{
+			metadata:     metadata,
+			sse:          getSSE(objectURL, encKeyDB[alias]),
+			storageClass: cliCtx.String("storage-class"),
+		}); err != nil {
+			return err.Trace(sourceURL, objectURL)
+		}
+		totalCount++
+		totalSize += size
+		printMsg(copyMessage{
+			Source:     sourceURL + ":" + key,
+			Target:     objectURL,
+			Size:       size,
+			TotalCount: totalCount,
+			TotalSize:  totalSize,
+		})
+		return nil
+	}
+
+	switch archiveFormat(sourceURL) {
+	case archiveTar:
+		err = walkTar(reader, o, upload)
+	case archiveTarGzip:
+		gzr, e := gzip.NewReader(reader)
+		fatalIf(probe.NewError(e).Trace(sourceURL), "Unable to read archive `"+sourceURL+"`.")
+		err = walkTar(gzr, o, upload)
+	case archiveZip:
+		readerAt, ok := reader.(io.ReaderAt)
+		if !ok {
+			fatalIf(errInvalidArgument().Trace(sourceURL), "Zip archive `"+sourceURL+"` does not support random access.")
+		}
+		var content *ClientContent
+		_, content, err = url2Stat(ctx, sourceURL, "", false, encKeyDB, time.Time{}, false)
+		fatalIf(err.Trace(sourceURL), "Unable to stat archive `"+sourceURL+"`.")
+		err = walkZip(readerAt, content.Size, o, upload)
+	}
+	fatalIf(err.Trace(sourceURL), "Unable to extract archive `"+sourceURL+"`.")
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// fanOutChunkSize is the size of the chunks of the source read by
+// 'cp --fan-out' and queued to every target.
+const fanOutChunkSize = 1024 * 1024
+
+// fanOutQueueLength bounds the chunks queued to a target, reading the
+// source stalls once a target is that far behind the others.
+const fanOutQueueLength = 16
+
+// fanOutTarget is a target of 'cp --fan-out', receiving the chunks of
+// the source through a pipe read by its upload.
+type fanOutTarget struct {
+	url    string
+	chunks chan []byte
+	pr     *io.PipeReader
+	pw     *io.PipeWriter
+	err    *probe.Error
+}
+
+// fanOutTargetURL - returns the object URL a source is copied to,
+// named after the source when the target is a folder.
+func fanOutTargetURL(ctx context.Context, sourceURL, targetURL string, encKeyDB map[string][]prefixSSEPair) string {
+	if !strings.HasSuffix(targetURL, "/") {
+		_, content, err := url2Stat(ctx, targetURL, "", false, encKeyDB, time.Time{}, false)
+		if err != nil || !content.Type.IsDir() {
+			return targetURL
+		}
+		targetURL += "/"
+	}
+	return targetURL + path.Base(filepath.ToSlash(sourceURL))
+}
+
+// writeChunks - writes the queued chunks to the pipe of the upload,
+// discarding them once the upload failed, until the source was read.
+func (t *fanOutTarget) writeChunks(srcErr *error) {
+	var e error
+	for chunk := range t.chunks {
+		if e == nil {
+			_, e = t.pw.Write(chunk)
+		}
+	}
+	t.pw.CloseWithError(*srcErr)
+}
+
+// doCopyFanOut reads a single source once and uploads it concurrently to
+// every target, each failing or succeeding independently.
+func doCopyFanOut(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) error {
+	args := cliCtx.Args()
+	sourceURL := args[0]
+
+	_, content, err := url2Stat(ctx, sourceURL, cliCtx.String("version-id"), false, encKeyDB, time.Time{}, false)
+	fatalIf(err.Trace(sourceURL), "Unable to stat `"+sourceURL+"`.")
+	if content.Type.IsDir() {
+		fatalIf(errInvalidArgument().Trace(sourceURL), "--fan-out copies a single object, `"+sourceURL+"` is a folder.")
+	}
+	reader, err := getSourceStreamFromURL(ctx, sourceURL, encKeyDB, getSourceOpts{GetOptions: GetOptions{VersionID: cliCtx.String("version-id")}})
+	fatalIf(err.Trace(sourceURL), "Unable to read `"+sourceURL+"`.")
+	defer reader.Close()
+
+	var pg ProgressReader
+	if !globalQuiet && !globalJSON {
+		pg = newProgressBar(content.Size * int64(len(args)-1)).SetCaption(sourceURL + ":")
+	} else {
+		pg = newAccounter(content.Size * int64(len(args)-1))
+	}
+
+	contentType := content.Metadata["Content-Type"]
+	if contentType == "" {
+		contentType = guessURLContentType(sourceURL)
+	}
+
+	// The error reading the source, if any, set before the chunk
+	// queues are closed.
+	var srcErr error
+	var wg sync.WaitGroup
+	targets := make([]*fanOutTarget, 0, len(args)-1)
+	for _, targetURL := range args[1:] {
+		t := &fanOutTarget{
+			url:    fanOutTargetURL(ctx, sourceURL, targetURL, encKeyDB),
+			chunks: make(chan []byte, fanOutQueueLength),
+		}
+		t.pr, t.pw = io.Pipe()
+		targets = append(targets, t)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			t.writeChunks(&srcErr)
+		}()
+		go func() {
+			defer wg.Done()
+			alias, urlStr, _, err := expandAlias(t.url)
+			if err == nil {
+				_, err = putTargetStream(ctx, alias, urlStr, "", "", "", t.pr, content.Size, pg, PutOptions{
+					metadata:     map[string]string{"Content-Type": contentType},
+					sse:          getSSE(t.url, encKeyDB[alias]),
+					storageClass: cliCtx.String("storage-class"),
+				})
+			}
+			if err != nil {
+				t.err = err.Trace(sourceURL, t.url)
+			}
+			// Unblock the chunk writer of a failed upload.
+			t.pr.CloseWithError(io.ErrClosedPipe)
+		}()
+	}
+
+	for {
+		chunk := make([]byte, fanOutChunkSize)
+		n, e := io.ReadFull(reader, chunk)
+		if n > 0 {
+			for _, t := range targets {
+				t.chunks <- chunk[:n]
+			}
+		}
+		if e == io.EOF || e == io.ErrUnexpectedEOF {
+			break
+		}
+		if e != nil {
+			srcErr = e
+			break
+		}
+	}
+	for _, t := range targets {
+		close(t.chunks)
+	}
+	wg.Wait()
+
+	if progressReader, ok := pg.(*progressBar); ok {
+		progressReader.ProgressBar.Finish()
+	}
+	if srcErr != nil {
+		fatalIf(probe.NewError(srcErr).Trace(sourceURL), "Unable to read `"+sourceURL+"`.")
+	}
+
+	var retErr error
+	var totalCount, totalSize int64
+	for _, t := range targets {
+		if t.err != nil {
+			errorIf(t.err, "Failed to copy `"+sourceURL+"` to `"+t.url+"`.")
+			retErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		totalCount++
+		totalSize += content.Size
+		if _, ok := pg.(*progressBar); !ok {
+			printMsg(copyMessage{
+				Source:     sourceURL,
+				Target:     t.url,
+				Size:       content.Size,
+				TotalCount: totalCount,
+				TotalSize:  totalSize,
+			})
+		}
+	}
+	return retErr
+}
@@ -23,9 +23,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
@@ -33,7 +37,10 @@ import (
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/minio/pkg/v2/console"
+	"github.com/minio/pkg/v2/mimedb"
 )
 
 // cp command flags.
@@ -79,10 +86,241 @@ var (
 			Name:  "disable-multipart",
 			Usage: "disable multipart upload feature",
 		},
+		cli.BoolFlag{
+			Name:  "preserve-xattr",
+			Usage: "preserve extended attributes of local files as object metadata and restore them on download",
+		},
+		cli.StringFlag{
+			Name:  "require-tag",
+			Usage: "copy only source objects carrying all these tags, e.g. \"ready=true\"",
+		},
+		cli.IntFlag{
+			Name:  "workers",
+			Usage: "number of parallel tag lookups with --require-tag",
+			Value: 8,
+		},
+		cli.StringFlag{
+			Name:  "normalize-keys",
+			Usage: "normalize target keys of a recursive copy, choose one of [lower, nfc]",
+		},
+		cli.IntFlag{
+			Name:  "max-key-length",
+			Usage: "skip and report objects whose target key is longer than this many bytes, 0 disables the check",
+			Value: defaultMaxKeyLength,
+		},
+		cli.BoolFlag{
+			Name:  "wait-consistent",
+			Usage: "after each upload wait until the object is returned with the expected etag and size",
+		},
+		cli.BoolFlag{
+			Name:  "disable-etag-check",
+			Usage: "do not compare etags when waiting for uploads to become consistent, for gateways returning unstable etags",
+		},
+		cli.DurationFlag{
+			Name:  "wait-consistent-timeout",
+			Usage: "maximum time to wait for an uploaded object to become consistent",
+			Value: 30 * time.Second,
+		},
+		cli.DurationFlag{
+			Name:  "wait-consistent-interval",
+			Usage: "interval between consistency checks of an uploaded object",
+			Value: time.Second,
+		},
+		cli.DurationFlag{
+			Name:  "checkpoint-interval",
+			Usage: "minimum time between saves of the progress of a resumable copy session, 0 saves after every object",
+		},
+		cli.BoolFlag{
+			Name:  "atomic-prefix",
+			Usage: "upload a recursive copy to a staging folder first, copy it server side to the target prefix only if all objects were copied, the objects are switched one by one",
+		},
+		cli.StringFlag{
+			Name:  "metrics-file",
+			Usage: "write transfer metrics in the Prometheus text format to a file, for the node_exporter textfile collector",
+		},
+		cli.DurationFlag{
+			Name:  "metrics-interval",
+			Usage: "also write --metrics-file periodically during the copy, 0 writes it only at the end",
+		},
+		cli.StringFlag{
+			Name:  "notify-webhook",
+			Usage: "post a JSON summary of the finished copy to a webhook URL, also when it fails",
+		},
+		cli.BoolFlag{
+			Name:  "notify-desktop",
+			Usage: "show a desktop notification when the copy finishes, with notify-send on Linux and the Notification Center on macOS",
+		},
+		cli.StringFlag{
+			Name:  "source-region",
+			Usage: "region of the source bucket(s), overriding the alias region and region auto-detection",
+		},
+		cli.StringFlag{
+			Name:  "dest-region",
+			Usage: "region of the target bucket, overriding the alias region and region auto-detection",
+		},
+		cli.BoolFlag{
+			Name:  "create-bucket",
+			Usage: "create the target bucket if it does not exist",
+		},
+		cli.StringFlag{
+			Name:  "region",
+			Usage: "region of the bucket created with --create-bucket, defaults to --dest-region or 'us-east-1'",
+		},
+		cli.BoolFlag{
+			Name:  "preallocate",
+			Usage: "reserve the full object size on the local filesystem before downloading",
+		},
+		cli.BoolFlag{
+			Name:  "if-not-exists",
+			Usage: "skip objects already present in the target, recursive copies list the target once instead of checking each object",
+		},
+		cli.BoolFlag{
+			Name:  "no-overwrite-newer",
+			Usage: "skip objects whose target was modified after the source, unlike --if-not-exists older targets are overwritten",
+		},
+		cli.BoolFlag{
+			Name:  "no-clobber",
+			Usage: "never overwrite an existing target, using conditional writes where supported",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite-dir-with-file",
+			Usage: "on filesystem targets, remove a directory in the way of a copied file, with all its contents",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite-file-with-dir",
+			Usage: "on filesystem targets, remove a file in the way of the directory of a copied file",
+		},
+		cli.BoolFlag{
+			Name:  "accelerate",
+			Usage: "upload and download objects through the Amazon S3 transfer acceleration endpoint, when enabled on the bucket",
+		},
+		cli.BoolFlag{
+			Name:  "dereference",
+			Usage: "follow symbolic links when copying local folders, including links to folders",
+		},
+		cli.BoolFlag{
+			Name:  "no-dereference",
+			Usage: "copy symbolic links in local folders as empty objects recording the link target, and recreate them on download",
+		},
+		cli.BoolFlag{
+			Name:  "preserve-acl",
+			Usage: "apply the ACL grants of each source object to its copy, skipped for buckets enforcing bucket owner ownership",
+		},
+		cli.BoolFlag{
+			Name:  "remove-source",
+			Usage: "remove each source once its copy is verified to match it by size and etag, or by content when etags cannot be compared",
+		},
+		cli.StringFlag{
+			Name:  "metadata-directive",
+			Usage: "retain source metadata (COPY) or keep only --attr (REPLACE) in server-side copies, choose one of [COPY, REPLACE]",
+			Value: "COPY",
+		},
+		cli.StringFlag{
+			Name:  "content-type-map",
+			Usage: "JSON file mapping file extensions to the content type of uploaded objects, overriding the built-in guess",
+		},
+		cli.StringFlag{
+			Name:  "content-language",
+			Usage: "set the Content-Language of uploaded objects, e.g. en or fr-CA",
+		},
+		cli.StringFlag{
+			Name:  "charset",
+			Usage: "add a charset to the Content-Type of uploaded text objects, e.g. utf-8",
+		},
+		cli.StringFlag{
+			Name:  "charset-map",
+			Usage: "JSON file mapping file extensions to the charset of uploaded objects, overriding --charset",
+		},
+		cli.BoolFlag{
+			Name:  "auto-compress",
+			Usage: "gzip the uploaded objects of compressible content types, e.g. text/html, setting their Content-Encoding",
+		},
+		cli.StringFlag{
+			Name:  "auto-compress-min",
+			Usage: "size below which --auto-compress leaves objects uncompressed",
+			Value: "1KiB",
+		},
+		cli.StringFlag{
+			Name:  "deploy-manifest",
+			Usage: "YAML file of ordered rules setting the cache-control, content-type and content-encoding of uploaded objects, or gzipping them, by path pattern",
+		},
+		cli.StringFlag{
+			Name:  "start-at",
+			Usage: "wait until a time of day, e.g. 02:00, or a date and time, e.g. 2024-06-01T22:30, to start copying",
+		},
+		cli.StringFlag{
+			Name:  "deadline",
+			Usage: "abort the copy if not finished by a time of day or a date and time, resumable with --continue",
+		},
+		cli.StringFlag{
+			Name:  "max-object-size",
+			Usage: "fail copying objects larger than this size (e.g. 5GB), see --split",
+		},
+		cli.BoolFlag{
+			Name:  "delta",
+			Usage: "upload only the blocks of local files which changed from the existing objects, copying the others server side",
+		},
+		cli.StringFlag{
+			Name:  "delta-block-size",
+			Usage: "size of the blocks compared by --delta, at least 5MiB",
+			Value: "8MiB",
+		},
+		cli.BoolFlag{
+			Name:  "pack-small",
+			Usage: "upload the files smaller than --pack-threshold of a recursive copy together as tar pack objects with an index, restored with --unpack",
+		},
+		cli.StringFlag{
+			Name:  "pack-threshold",
+			Usage: "size below which files are packed by --pack-small",
+			Value: "64KiB",
+		},
+		cli.BoolFlag{
+			Name:  "content-addressed",
+			Usage: "store each object below the target prefix under a key derived from its content hash, skipping contents already stored, with a manifest of the source paths",
+		},
+		cli.StringFlag{
+			Name:  "content-hash",
+			Usage: "hash algorithm of --content-addressed, one of [sha256, sha512, sha1, md5]",
+			Value: "sha256",
+		},
+		cli.IntFlag{
+			Name:  "content-fanout",
+			Usage: "levels of folders named after the leading digits of the hash in the keys of --content-addressed, from 0 to 4",
+			Value: 2,
+		},
+		cli.StringFlag{
+			Name:  "content-manifest",
+			Usage: "name of the manifest of --content-addressed mapping the source paths to their hashes, below the target prefix",
+			Value: "manifest.json",
+		},
+		cli.BoolFlag{
+			Name:  "fan-out",
+			Usage: "copy the first argument to every other argument, reading the source object once",
+		},
+		cli.BoolFlag{
+			Name:  "unpack",
+			Usage: "restore the files packed by --pack-small below the source prefix to the target prefix",
+		},
+		cli.BoolFlag{
+			Name:  "split",
+			Usage: "copy objects larger than --max-object-size as numbered parts plus a manifest, reassembled with 'mc cat --reassemble'",
+		},
+		cli.StringFlag{
+			Name:  "multipart-threshold",
+			Usage: "upload objects larger than this size using multipart (e.g. 64MiB, max 5GiB)",
+		},
 		cli.BoolFlag{
 			Name:  "md5",
 			Usage: "force all upload(s) to calculate md5sum checksum",
 		},
+		cli.StringFlag{
+			Name:  "bwlimit-file",
+			Usage: "YAML file mapping destination aliases or hosts to the rate their uploads are limited to, e.g. 'remote: 5MiB', unlisted destinations are unlimited",
+		},
+		cli.StringFlag{
+			Name:  "trailer-checksum",
+			Usage: "upload in parts sending a checksum computed while streaming in the request trailers, one of [crc32c, crc32, sha1, sha256]",
+		},
 		cli.StringFlag{
 			Name:  "tags",
 			Usage: "apply one or more tags to the uploaded objects",
@@ -103,6 +341,18 @@ var (
 			Name:  "zip",
 			Usage: "Extract from remote zip file (MinIO server source only)",
 		},
+		cli.BoolFlag{
+			Name:  "extract",
+			Usage: "upload the files of a tar, tar.gz or zip archive as separate objects under the target prefix",
+		},
+		cli.StringSliceFlag{
+			Name:  "extract-include",
+			Usage: "upload only archive members matching this wildcard pattern, may be repeated, requires --extract",
+		},
+		cli.IntFlag{
+			Name:  "extract-strip-components",
+			Usage: "strip this many leading path components from archive member names, requires --extract",
+		},
 	}
 )
 
@@ -198,6 +448,127 @@ EXAMPLES:
   20. Set tags to the uploaded objects
       {{.Prompt}} {{.HelpName}} -r --tags "category=prod&type=backup" ./data/ play/another-bucket/
 
+  21. Upload objects up to 256MiB with a single PUT, larger ones using multipart.
+      {{.Prompt}} {{.HelpName}} -r --multipart-threshold 256MiB ./data/ play/mybucket/
+
+  22. Copy local files and their extended attributes, restore them when copying back.
+      {{.Prompt}} {{.HelpName}} -r --preserve-xattr ./data/ play/mybucket/data/
+      {{.Prompt}} {{.HelpName}} -r --preserve-xattr play/mybucket/data/ ./restored/
+
+  23. Copy a folder recursively with all target keys lowercased, colliding keys are reported as errors.
+      {{.Prompt}} {{.HelpName}} -r --normalize-keys lower ./Photos/ play/mybucket/photos/
+
+  24. Copy only the objects tagged as ready, looking up tags with 16 parallel workers.
+      {{.Prompt}} {{.HelpName}} -r --require-tag "ready=true" --workers 16 s3/src/ s3/dst/
+
+  25. Download large objects with their disk space reserved upfront to reduce fragmentation.
+      {{.Prompt}} {{.HelpName}} -r --preallocate play/mybucket/videos/ /mnt/data/videos/
+
+  26. Upload to an eventually consistent endpoint, waiting up to 2 minutes for each object to become visible.
+      {{.Prompt}} {{.HelpName}} -r --wait-consistent --wait-consistent-timeout 2m ./data/ legacy/mybucket/
+
+  27. Copy a folder, making the copy resumable like --continue when it adds up to more than 10GiB.
+      {{.Prompt}} {{.HelpName}} --recursive --resume-threshold 10GiB ./backups/ s3/mybucket/backups/
+
+  28. Copy to a gateway limited to 5GB objects, splitting larger objects into parts plus a manifest.
+      {{.Prompt}} {{.HelpName}} --recursive --max-object-size 5GB --split s3/images/ gateway/images/
+
+  29. Rewrite the content type of an object in place with a server-side copy, without re-uploading it.
+      {{.Prompt}} {{.HelpName}} --metadata-directive REPLACE --attr "Content-Type=text/html" s3/site/index s3/site/index
+
+  30. Resume an interrupted recursive upload, skipping the files already present in the bucket.
+      {{.Prompt}} {{.HelpName}} --recursive --if-not-exists ./photos/ s3/mybucket/photos/
+
+  31. Deploy only the images of a build artifact, dropping its top-level folder from the object names.
+      {{.Prompt}} {{.HelpName}} --extract --extract-include "*.jpg" --extract-strip-components=1 build.tar.gz s3/mybucket/site/
+
+  32. Deploy a static site with content types for modern file types taken from a map, e.g. {".wasm": "application/wasm"}.
+      {{.Prompt}} {{.HelpName}} --recursive --content-type-map types.json dist/ s3/site/
+
+  33. Add audit logs to an append-only bucket, never overwriting a log already uploaded.
+      {{.Prompt}} {{.HelpName}} --recursive --no-clobber ./logs/ s3/audit/logs/
+
+  34. Move a folder to another bucket, removing each object only after its copy is verified.
+      {{.Prompt}} {{.HelpName}} --recursive --remove-source s3/mybucket/archive/ s3/coldbucket/archive/
+
+  35. Ingest an object shared with a presigned URL, the URL is used as is without adding credentials.
+      {{.Prompt}} {{.HelpName}} "https://partner.example.com/exports/data.csv?X-Amz-Algorithm=AWS4-HMAC-SHA256&..." s3/mybucket/
+
+  36. Upload a large file to a distant Amazon S3 bucket through its transfer acceleration endpoint.
+      {{.Prompt}} {{.HelpName}} --accelerate ./backup.tar s3/faraway-bucket/
+
+  37. Back up a folder keeping its symbolic links as links, and restore them later.
+      {{.Prompt}} {{.HelpName}} --recursive --no-dereference ~/projects/ s3/backups/projects/
+      {{.Prompt}} {{.HelpName}} --recursive --no-dereference s3/backups/projects/ ~/projects/
+
+  38. Migrate a bucket to another account keeping the ACL grants and attributes of each object.
+      {{.Prompt}} {{.HelpName}} --recursive --preserve-acl --preserve s3/oldbucket/ s3-other/newbucket/
+
+  39. Migrate a deeply nested folder to a gateway limited to 255 byte keys, reporting the files left behind.
+      {{.Prompt}} {{.HelpName}} --recursive --max-key-length 255 --normalize-keys nfc ~/archive/ gateway/archive/
+
+  40. Archive a folder to a vault bucket, locking every object in governance mode for 365 days as it is written.
+      {{.Prompt}} {{.HelpName}} --recursive --retention-mode governance --retention-duration 365d src/ s3/vault/
+
+  41. Copy millions of small files in a resumable session, saving its progress at most every 10 seconds.
+      {{.Prompt}} {{.HelpName}} --recursive --continue --checkpoint-interval 10s dir/ play/mybucket
+
+  42. Back up a folder nightly, exposing its transfer metrics to the node_exporter textfile collector.
+      {{.Prompt}} {{.HelpName}} --recursive --metrics-file /var/lib/node_exporter/mc.prom backup/ s3/backups/
+
+  43. Copy a file into the folder "reports/", a target ending with "/" is always a folder and fails if a file of
+      that name exists, without the trailing "/" a missing target is the new name of the file.
+      {{.Prompt}} {{.HelpName}} report.pdf s3/mybucket/reports/
+
+  44. Deploy a static website, its prefix is only written once the whole folder was uploaded.
+      {{.Prompt}} {{.HelpName}} --recursive --atomic-prefix public/ s3/site/www/
+
+  45. Back up a large VM image again, uploading only the 16MiB blocks which changed since the last backup.
+      {{.Prompt}} {{.HelpName}} --delta --delta-block-size 16MiB disk.qcow2 s3/backups/
+
+  46. Upload a dataset of millions of tiny files as a few pack objects, then restore it elsewhere.
+      {{.Prompt}} {{.HelpName}} --recursive --pack-small --pack-threshold 64KiB dataset/ s3/mybucket/dataset/
+      {{.Prompt}} {{.HelpName}} --unpack s3/mybucket/dataset/ restored/
+
+  47. Replicate a release archive to buckets in three regions, downloading it only once.
+      {{.Prompt}} {{.HelpName}} --fan-out s3/builds/release.tar.gz us/releases/ eu/releases/ ap/releases/
+
+  48. Deploy a French static site served with the right language and charset, e.g. text/html; charset=utf-8.
+      {{.Prompt}} {{.HelpName}} --recursive --content-language fr --charset utf-8 dist/ s3/site/
+
+  49. Copy a large dataset overnight, starting at 2 AM and stopping at 6 AM, resuming the next night.
+      {{.Prompt}} {{.HelpName}} --recursive --continue --start-at 02:00 --deadline 06:00 dataset/ s3/mybucket/dataset/
+
+  50. Deploy a static site, gzipping its HTML, CSS and JavaScript files of at least 4KiB but not its images.
+      {{.Prompt}} {{.HelpName}} --recursive --auto-compress --auto-compress-min 4KiB dist/ s3/site/
+
+  51. Deploy a static site with the headers of a manifest, e.g. with rules for 'index.html' and 'assets/*'.
+      {{.Prompt}} {{.HelpName}} --recursive --deploy-manifest deploy.yaml dist/ s3/site/
+
+  52. Push local edits to a shared bucket without overwriting objects changed there since.
+      {{.Prompt}} {{.HelpName}} --recursive --no-overwrite-newer ~/docs/ s3/team/docs/
+
+  53. Run an unattended backup and post its outcome to a chat webhook when it finishes or fails.
+      {{.Prompt}} {{.HelpName}} --recursive --notify-webhook https://hooks.example.com/backups ~/data/ s3/backups/
+
+  54. Copy objects server side between buckets of two regions with the same credentials.
+      {{.Prompt}} {{.HelpName}} --recursive --source-region us-east-1 --dest-region eu-west-1 s3/bucket-us/data/ s3/bucket-eu/data/
+
+  55. Copy a folder to a new bucket, creating the bucket in eu-west-1 if it does not exist.
+      {{.Prompt}} {{.HelpName}} --recursive --create-bucket --region eu-west-1 ~/data/ s3/new-bucket/data/
+
+  56. Download a bucket in which "logs" is both an object and a prefix, keeping the folder "logs".
+      {{.Prompt}} {{.HelpName}} --recursive --overwrite-file-with-dir s3/mybucket/ ~/mybucket/
+
+  57. Upload a file having the server verify a CRC32C checksum of each part computed while it is sent.
+      {{.Prompt}} {{.HelpName}} --trailer-checksum crc32c backup.tar s3/backups/
+
+  58. Store build artifacts once per content under sha256/ab/cd/abcd... keys, with a manifest of their paths.
+      {{.Prompt}} {{.HelpName}} --recursive --content-addressed --content-manifest builds/1234.json dist/ s3/cas/
+
+  59. Copy to a slow remote and a fast local destination, limiting uploads per destination with 'remote: 5MiB' in limits.yaml.
+      {{.Prompt}} {{.HelpName}} --fan-out --bwlimit-file limits.yaml backup.tar remote/backups/ local/backups/
+
 `,
 }
 
@@ -270,10 +641,121 @@ func doCopy(ctx context.Context, cpURLs URLs, pg ProgressReader, encKeyDB map[st
 	if isMvCmd && urls.Error == nil {
 		rmManager.add(ctx, sourceAlias, sourceURL.String())
 	}
+	if cpURLs.RemoveSource && urls.Error == nil {
+		urls.Error = removeCopiedSource(ctx, cpURLs, encKeyDB)
+	}
 
 	return urls
 }
 
+// copiedETagsComparable returns true when the etags of a source and its
+// copy are both the MD5 sum of a single part object. Filesystem sources
+// have no etag and the etags of multipart uploads depend on the part size.
+func copiedETagsComparable(source, target string) bool {
+	return source != "" && target != "" && !strings.Contains(source, "-") && !strings.Contains(target, "-")
+}
+
+// isFullObjectChecksum returns true for an additional checksum of the
+// whole content, not for the checksum of the checksums of the parts of
+// a multipart upload, which ends with -N.
+func isFullObjectChecksum(checksum string) bool {
+	return checksum != "" && !strings.Contains(checksum, "-")
+}
+
+// verifyCopied - checks that the target of a finished copy matches its
+// source by size and, cheapest first, by etag, by the additional
+// checksum stored with both objects or, when neither can be compared,
+// by the SHA-256 of their contents read in full.
+func verifyCopied(ctx context.Context, cpURLs URLs, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	source := cpURLs.SourceContent
+	targetURL := cpURLs.TargetContent.URL
+	sourcePath := filepath.ToSlash(filepath.Join(cpURLs.SourceAlias, source.URL.Path))
+	targetPath := filepath.ToSlash(filepath.Join(cpURLs.TargetAlias, targetURL.Path))
+
+	targetClnt, err := newClientFromAlias(cpURLs.TargetAlias, targetURL.String())
+	if err != nil {
+		return err.Trace(targetURL.String())
+	}
+	target, err := targetClnt.Stat(ctx, StatOptions{sse: getSSE(targetPath, encKeyDB[cpURLs.TargetAlias]), checksum: true})
+	if err != nil {
+		return err.Trace(targetURL.String())
+	}
+	mismatch := probe.NewError(ChecksumMismatch{
+		Source: source.URL.String(),
+		Target: targetURL.String(),
+	})
+	if target.Size != source.Size {
+		return mismatch
+	}
+	if copiedETagsComparable(source.ETag, target.ETag) {
+		if !etagsMatch(source.ETag, target.ETag) {
+			return mismatch
+		}
+		return nil
+	}
+	if isFullObjectChecksum(target.Checksum) {
+		sourceClnt, err := newClientFromAlias(cpURLs.SourceAlias, source.URL.String())
+		if err != nil {
+			return err.Trace(source.URL.String())
+		}
+		st, err := sourceClnt.Stat(ctx, StatOptions{
+			versionID: source.VersionID,
+			sse:       getSSE(sourcePath, encKeyDB[cpURLs.SourceAlias]),
+			checksum:  true,
+		})
+		if err != nil {
+			return err.Trace(source.URL.String())
+		}
+		if st.ChecksumType == target.ChecksumType && isFullObjectChecksum(st.Checksum) {
+			if st.Checksum != target.Checksum {
+				return mismatch
+			}
+			return nil
+		}
+	}
+
+	sourceSum, err := hashObject(ctx, cpURLs.SourceAlias, source.URL.String(), GetOptions{
+		VersionID: source.VersionID,
+		SSE:       getSSE(sourcePath, encKeyDB[cpURLs.SourceAlias]),
+	})
+	if err != nil {
+		return err.Trace(source.URL.String())
+	}
+	targetSum, err := hashObject(ctx, cpURLs.TargetAlias, targetURL.String(), GetOptions{
+		SSE: getSSE(targetPath, encKeyDB[cpURLs.TargetAlias]),
+	})
+	if err != nil {
+		return err.Trace(targetURL.String())
+	}
+	if sourceSum != targetSum {
+		return mismatch
+	}
+	return nil
+}
+
+// removeCopiedSource - removes the source of a finished copy, only after
+// checking that the target matches it, see verifyCopied.
+func removeCopiedSource(ctx context.Context, cpURLs URLs, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	if err := verifyCopied(ctx, cpURLs, encKeyDB); err != nil {
+		return err
+	}
+
+	source := cpURLs.SourceContent
+	sourceClnt, err := newClientFromAlias(cpURLs.SourceAlias, source.URL.String())
+	if err != nil {
+		return err.Trace(source.URL.String())
+	}
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: source.URL}
+	close(contentCh)
+	for result := range sourceClnt.Remove(ctx, false, false, false, false, contentCh) {
+		if result.Err != nil {
+			return result.Err.Trace(source.URL.String())
+		}
+	}
+	return nil
+}
+
 // doCopyFake - Perform a fake copy to update the progress bar appropriately.
 func doCopyFake(cpURLs URLs, pg Progress) URLs {
 	if progressReader, ok := pg.(*progressBar); ok {
@@ -298,6 +780,10 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 	newerThan := session.Header.CommandStringFlags["newer-than"]
 	encryptKeys := session.Header.CommandStringFlags["encrypt-key"]
 	encrypt := session.Header.CommandStringFlags["encrypt"]
+	normalizeKeys := session.Header.CommandStringFlags["normalize-keys"]
+	requireTags, _ := parseRequireTags(session.Header.CommandStringFlags["require-tag"])
+	workers, _ := strconv.Atoi(session.Header.CommandStringFlags["workers"])
+	maxKeyLength, _ := strconv.Atoi(session.Header.CommandStringFlags["max-key-length"])
 	encKeyDB, err := parseAndValidateEncryptionKeys(encryptKeys, encrypt)
 	fatalIf(err, "Unable to parse encryption keys.")
 
@@ -310,18 +796,26 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 	}
 
 	opts := prepareCopyURLsOpts{
-		sourceURLs:  sourceURLs,
-		targetURL:   targetURL,
-		isRecursive: isRecursive,
-		encKeyDB:    encKeyDB,
-		olderThan:   olderThan,
-		newerThan:   newerThan,
-		timeRef:     parseRewindFlag(rewind),
-		versionID:   versionID,
+		sourceURLs:       sourceURLs,
+		targetURL:        targetURL,
+		isRecursive:      isRecursive,
+		encKeyDB:         encKeyDB,
+		olderThan:        olderThan,
+		newerThan:        newerThan,
+		timeRef:          parseRewindFlag(rewind),
+		versionID:        versionID,
+		normalizeKeys:    normalizeKeys,
+		requireTags:      requireTags,
+		workers:          workers,
+		ifNotExists:      session.Header.CommandBoolFlags["if-not-exists"],
+		noOverwriteNewer: session.Header.CommandBoolFlags["no-overwrite-newer"],
+		symlinks:         cpSymlinkOpt(session.Header.CommandBoolFlags["dereference"], session.Header.CommandBoolFlags["no-dereference"]),
+		maxKeyLength:     maxKeyLength,
 	}
 
 	URLsCh := prepareCopyURLs(ctx, opts)
 	done := false
+	var skippedLongKeys int
 	for !done {
 		select {
 		case cpURLs, ok := <-URLsCh:
@@ -332,6 +826,9 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 
 			if cpURLs.Error != nil {
 				printCopyURLsError(&cpURLs)
+				if _, ok := cpURLs.Error.ToGoError().(keyTooLongErr); ok {
+					skippedLongKeys++
+				}
 				errSeen = true
 				break
 			}
@@ -357,10 +854,15 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 				console.Eraseline()
 			}
 			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
+			runExitHooks()
 			os.Exit(0)
 		}
 	}
 
+	if skippedLongKeys > 0 && !globalQuiet && !globalJSON {
+		console.Infof("Skipped %d object(s) with target keys longer than %d bytes.\n", skippedLongKeys, maxKeyLength)
+	}
+
 	session.Header.TotalBytes = totalBytes
 	session.Header.TotalObjects = totalObjects
 	session.Save()
@@ -384,9 +886,151 @@ func printCopyURLsError(cpURLs *URLs) {
 	}
 }
 
-func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.Context, session *sessionV8, encKeyDB map[string][]prefixSSEPair, isMvCmd bool) error {
+// parseRequireTags parses the tags a source object must carry to be copied.
+func parseRequireTags(requireTag string) (map[string]string, *probe.Error) {
+	if requireTag == "" {
+		return nil, nil
+	}
+	t, e := tags.Parse(requireTag, true)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return t.ToMap(), nil
+}
+
+// parseContentTypeMap reads a JSON object mapping file extensions to the
+// content type of uploaded objects, e.g. {".wasm": "application/wasm"}.
+// Extensions are matched case insensitively, the leading dot is optional.
+func parseContentTypeMap(mapFile string) (map[string]string, *probe.Error) {
+	return parseExtensionMap(mapFile)
+}
+
+// parseExtensionMap reads a JSON object mapping file extensions to
+// values, extensions are lower cased and prefixed with a dot.
+func parseExtensionMap(mapFile string) (map[string]string, *probe.Error) {
+	if mapFile == "" {
+		return nil, nil
+	}
+	data, e := os.ReadFile(mapFile)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	var extMap map[string]string
+	if e = json.Unmarshal(data, &extMap); e != nil {
+		return nil, probe.NewError(e)
+	}
+	values := make(map[string]string, len(extMap))
+	for ext, value := range extMap {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" || value == "" {
+			return nil, errInvalidArgument().Trace(ext, value)
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		values[ext] = value
+	}
+	return values, nil
+}
+
+// contentHeaders - the language and charset of uploaded objects set with
+// --content-language, --charset and --charset-map.
+type contentHeaders struct {
+	language string
+	charset  string
+	charsets map[string]string
+}
+
+// parseContentHeaders reads the content headers flags of cp.
+func parseContentHeaders(cliCtx *cli.Context) (contentHeaders, *probe.Error) {
+	charsets, err := parseExtensionMap(cliCtx.String("charset-map"))
+	if err != nil {
+		return contentHeaders{}, err.Trace(cliCtx.String("charset-map"))
+	}
+	return contentHeaders{
+		language: cliCtx.String("content-language"),
+		charset:  cliCtx.String("charset"),
+		charsets: charsets,
+	}, nil
+}
+
+// apply sets the Content-Language in metadata and adds the charset to its
+// Content-Type, guessed from ext when missing. A charset mapped to ext
+// applies to any content type, --charset only to textual ones.
+func (h contentHeaders) apply(metadata map[string]string, ext string) {
+	if h.language != "" {
+		metadata["Content-Language"] = h.language
+	}
+	contentType := metadata["Content-Type"]
+	if contentType == "" {
+		contentType = mimedb.TypeByExtension(ext)
+	}
+	charset, ok := h.charsets[strings.ToLower(ext)]
+	if !ok && isTextContentType(contentType) {
+		charset = h.charset
+	}
+	if charset == "" {
+		return
+	}
+	mediaType, params, e := mime.ParseMediaType(contentType)
+	if e != nil {
+		return
+	}
+	params["charset"] = charset
+	if contentType = mime.FormatMediaType(mediaType, params); contentType != "" {
+		metadata["Content-Type"] = contentType
+	}
+}
+
+// isTextContentType returns true if contentType is text, which browsers
+// decode with a charset.
+func isTextContentType(contentType string) bool {
+	mediaType, _, e := mime.ParseMediaType(contentType)
+	if e != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/javascript", "application/ecmascript", "application/json", "application/xml":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "+json")
+}
+
+// parseMultipartThreshold parses the object size above which uploads use
+// multipart, it cannot exceed the maximum size of a single PUT.
+func parseMultipartThreshold(threshold string) (uint64, *probe.Error) {
+	if threshold == "" {
+		return 0, nil
+	}
+	size, e := humanize.ParseBytes(threshold)
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	if size > maxSinglePutSize {
+		return 0, probe.NewError(fmt.Errorf("multipart threshold cannot exceed %s", humanize.IBytes(maxSinglePutSize)))
+	}
+	return size, nil
+}
+
+// cpSymlinkOpt - returns the policy for symbolic links in local
+// folders chosen with --dereference or --no-dereference.
+func cpSymlinkOpt(dereference, noDereference bool) SymlinkOpt {
+	switch {
+	case dereference:
+		return SymlinkFollow
+	case noDereference:
+		return SymlinkPreserve
+	}
+	return SymlinkDefault
+}
+
+// doCopySession - copies the sources of the command line to targetURL,
+// the last argument unless copying to the staging folder of --atomic-prefix.
+func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.Context, session *sessionV8, encKeyDB map[string][]prefixSSEPair, isMvCmd bool, targetURL string) error {
 	var isCopied func(string) bool
 	var totalObjects, totalBytes int64
+	// Sources skipped for target keys over --max-key-length.
+	var skippedLongKeys int64
 
 	cpURLsCh := make(chan URLs, 10000)
 	errSeen := false
@@ -395,19 +1039,83 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 	var pg ProgressReader
 
 	// Enable progress bar reader only during default mode.
-	if !globalQuiet && !globalJSON { // set up progress bar
+	if !globalQuiet && !globalJSON && !globalDryRun { // set up progress bar
 		pg = newProgressBar(totalBytes)
 	} else {
 		pg = newAccounter(totalBytes)
 	}
 
 	sourceURLs := cli.Args()[:len(cli.Args())-1]
-	targetURL := cli.Args()[len(cli.Args())-1] // Last one is target
 
 	// Check if the target path has object locking enabled
-	withLock, _ := isBucketLockEnabled(ctx, targetURL)
+	withLock, lockErr := isBucketLockEnabled(ctx, targetURL)
+
+	// Retention is applied on write, check the target bucket can
+	// hold it before copying anything.
+	if !withLock && (cli.String(rmFlag) != "" || cli.String(lhFlag) != "") {
+		fatalIf(lockErr.Trace(targetURL), "Unable to get the object lock configuration of `"+targetURL+"`.")
+		fatalIf(errInvalidArgument().Trace(targetURL), "Object lock is not enabled on the bucket of `"+targetURL+"`, required by --retention-mode and --legal-hold.")
+	}
+
+	// Already validated in checkCopySyntax.
+	multipartThreshold, _ := parseMultipartThreshold(cli.String("multipart-threshold"))
+	maxObjectSize, _ := humanize.ParseBytes(cli.String("max-object-size"))
+	deltaBlockSize, _ := humanize.ParseBytes(cli.String("delta-block-size"))
+	trailerChecksum, _ := parseTrailerChecksum(cli.String("trailer-checksum"))
+
+	// Small files of --pack-small are packed while preparing the copy.
+	var packer *copyPacker
+	var packThreshold int64
+	if cli.Bool("pack-small") {
+		threshold, _ := humanize.ParseBytes(cli.String("pack-threshold"))
+		packThreshold = int64(threshold)
+		var err *probe.Error
+		packer, err = newCopyPacker(targetURL, encKeyDB)
+		fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+	}
+
+	// Objects of --content-addressed are stored by their hash.
+	var store *copyContentStore
+	if cli.Bool("content-addressed") {
+		var err *probe.Error
+		store, err = newCopyContentStore(targetURL, strings.ToLower(cli.String("content-hash")), cli.Int("content-fanout"), cli.String("content-manifest"), encKeyDB)
+		fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+	}
+	contentTypes, _ := parseContentTypeMap(cli.String("content-type-map"))
+	headers, _ := parseContentHeaders(cli)
+	autoCompressMin, _ := humanize.ParseBytes(cli.String("auto-compress-min"))
+
+	var manifest *deployManifest
+	var manifestTarget string
+	if manifestFile := cli.String("deploy-manifest"); manifestFile != "" {
+		manifest, _ = parseDeployManifest(manifestFile)
+		_, manifestTarget, _ = mustExpandAlias(targetURL)
+	}
+
+	var waitConsistent waitConsistentOptions
+	if cli.Bool("wait-consistent") {
+		waitConsistent = waitConsistentOptions{
+			timeout:          cli.Duration("wait-consistent-timeout"),
+			interval:         cli.Duration("wait-consistent-interval"),
+			disableETagCheck: cli.Bool("disable-etag-check"),
+		}
+	}
+
+	requireTags, _ := parseRequireTags(cli.String("require-tag"))
+	if len(requireTags) > 0 && !globalQuiet && !globalJSON {
+		console.Infoln("[Warn] --require-tag fetches the tags of every source object, one extra API call per object.")
+	}
+
+	preserveXattr := cli.Bool("preserve-xattr")
+	if preserveXattr && !xattrSupported {
+		errorIf(errDummy().Trace(), "Extended attributes are not supported on this platform, --preserve-xattr is ignored.")
+		preserveXattr = false
+	}
 
 	if session != nil {
+		// Record the multipart uploads for resume gc --sessions.
+		ctx = session.recordUploads(ctx)
+
 		// isCopied returns true if an object has been already copied
 		// or not. This is useful when we resume from a session.
 		isCopied = isLastFactory(session.Header.LastCopied)
@@ -451,29 +1159,58 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 		go func() {
 			totalBytes := int64(0)
 			opts := prepareCopyURLsOpts{
-				sourceURLs:  sourceURLs,
-				targetURL:   targetURL,
-				isRecursive: isRecursive,
-				encKeyDB:    encKeyDB,
-				olderThan:   olderThan,
-				newerThan:   newerThan,
-				timeRef:     parseRewindFlag(rewind),
-				versionID:   versionID,
-				isZip:       cli.Bool("zip"),
+				sourceURLs:       sourceURLs,
+				targetURL:        targetURL,
+				isRecursive:      isRecursive,
+				encKeyDB:         encKeyDB,
+				olderThan:        olderThan,
+				newerThan:        newerThan,
+				timeRef:          parseRewindFlag(rewind),
+				versionID:        versionID,
+				isZip:            cli.Bool("zip"),
+				normalizeKeys:    cli.String("normalize-keys"),
+				requireTags:      requireTags,
+				workers:          cli.Int("workers"),
+				ifNotExists:      cli.Bool("if-not-exists"),
+				noOverwriteNewer: cli.Bool("no-overwrite-newer"),
+				symlinks:         cpSymlinkOpt(cli.Bool("dereference"), cli.Bool("no-dereference")),
+				maxKeyLength:     cli.Int("max-key-length"),
 			}
 
 			for cpURLs := range prepareCopyURLs(ctx, opts) {
 				if cpURLs.Error != nil {
 					errSeen = true
 					printCopyURLsError(&cpURLs)
+					// A symlink cycle met with --dereference only skips the looping link.
+					if _, ok := cpURLs.Error.ToGoError().(TooManyLevelsSymlink); ok {
+						continue
+					}
+					if _, ok := cpURLs.Error.ToGoError().(keyTooLongErr); ok {
+						atomic.AddInt64(&skippedLongKeys, 1)
+						continue
+					}
 					break
 				}
 
+				if packer != nil && !cpURLs.SourceContent.Type.IsDir() && cpURLs.SourceContent.Size < packThreshold {
+					if err := packer.add(ctx, cpURLs); err != nil {
+						errorIf(err.Trace(cpURLs.SourceContent.URL.String()), "Unable to pack `"+cpURLs.SourceContent.URL.String()+"`.")
+						packer.errors++
+					}
+					continue
+				}
+
 				totalBytes += cpURLs.SourceContent.Size
 				pg.SetTotal(totalBytes)
 				totalObjects++
 				cpURLsCh <- cpURLs
 			}
+			if packer != nil {
+				if err := packer.flush(ctx); err != nil {
+					errorIf(err.Trace(targetURL), "Unable to upload a pack to `"+targetURL+"`.")
+					packer.errors++
+				}
+			}
 			close(cpURLsCh)
 		}()
 	}
@@ -545,15 +1282,52 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 
 				cpURLs.MD5 = cli.Bool("md5") || withLock
 				cpURLs.DisableMultipart = cli.Bool("disable-multipart")
+				cpURLs.MultipartThreshold = multipartThreshold
+				cpURLs.PreserveXattr = preserveXattr
+				cpURLs.Preallocate = cli.Bool("preallocate")
+				cpURLs.NoClobber = cli.Bool("no-clobber")
+				cpURLs.OverwriteDirWithFile = cli.Bool("overwrite-dir-with-file")
+				cpURLs.OverwriteFileWithDir = cli.Bool("overwrite-file-with-dir")
+				cpURLs.RemoveSource = cli.Bool("remove-source")
+				cpURLs.NoDereference = cli.Bool("no-dereference")
+				cpURLs.PreserveACL = cli.Bool("preserve-acl")
+				cpURLs.MaxObjectSize = int64(maxObjectSize)
+				cpURLs.Split = cli.Bool("split")
+				if cli.Bool("delta") {
+					cpURLs.DeltaBlockSize = int64(deltaBlockSize)
+				}
+				cpURLs.TrailerChecksum = trailerChecksum
+				cpURLs.MetadataDirective = strings.ToUpper(cli.String("metadata-directive"))
+				cpURLs.contentTypes = contentTypes
+				cpURLs.contentHeaders = headers
+				cpURLs.AutoCompress = cli.Bool("auto-compress")
+				cpURLs.AutoCompressMin = int64(autoCompressMin)
+				if manifest != nil {
+					manifest.headers(deployKey(manifestTarget, cpURLs.TargetContent.URL)).apply(&cpURLs)
+				}
+				cpURLs.waitConsistent = waitConsistent
 
 				// Verify if previously copied, notify progress bar.
-				if isCopied != nil && isCopied(cpURLs.SourceContent.URL.String()) {
+				if globalDryRun {
+					action := "copy"
+					if isMvCmd {
+						action = "move"
+					}
+					printMsg(dryRunMessage{
+						Action: action,
+						Source: cpURLs.SourceContent.URL.String(),
+						Target: cpURLs.TargetContent.URL.String(),
+					})
+					parallel.queueTask(func() URLs {
+						return doCopyFake(cpURLs, pg)
+					}, 0)
+				} else if isCopied != nil && isCopied(cpURLs.SourceContent.URL.String()) {
 					parallel.queueTask(func() URLs {
 						return doCopyFake(cpURLs, pg)
 					}, 0)
 				} else {
 					// Print the copy resume summary once in start
-					if startContinue && cli.Bool("continue") {
+					if startContinue && session != nil {
 						if pb, ok := pg.(*progressBar); ok {
 							startSize := humanize.IBytes(uint64(pb.Start().Get()))
 							totalSize := humanize.IBytes(uint64(pb.Total))
@@ -562,6 +1336,11 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 						startContinue = false
 					}
 					parallel.queueTask(func() URLs {
+						if store != nil {
+							return store.copy(ctx, cpURLs, pg, func(cpURLs URLs) URLs {
+								return doCopy(ctx, cpURLs, pg, encKeyDB, isMvCmd, preserve, isZip)
+							})
+						}
 						return doCopy(ctx, cpURLs, pg, encKeyDB, isMvCmd, preserve, isZip)
 					}, cpURLs.SourceContent.Size)
 				}
@@ -572,6 +1351,32 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 	var retErr error
 	cpAllFilesErr := true
 
+	// Targets found to already exist with --no-clobber.
+	var skippedExisting int64
+
+	// Progress of a session is saved at most every --checkpoint-interval,
+	// closing the session on interrupts and errors always saves it.
+	checkpointInterval := cli.Duration("checkpoint-interval")
+	var lastCheckpoint time.Time
+
+	// Transfer metrics written with --metrics-file.
+	var metrics *copyMetrics
+	metricsFile := cli.String("metrics-file")
+	if metricsFile != "" {
+		metrics = newCopyMetrics(sourceURLs, targetURL)
+		if interval := cli.Duration("metrics-interval"); interval > 0 {
+			metricsDoneCh := make(chan struct{})
+			defer close(metricsDoneCh)
+			go metrics.writePeriodically(metricsFile, interval, metricsDoneCh)
+		}
+	}
+
+	// Notifications of the finished copy, never sent for dry runs.
+	var notifier *copyNotifier
+	if !globalDryRun {
+		notifier = newCopyNotifier(cli.String("notify-webhook"), cli.Bool("notify-desktop"), sourceURLs, targetURL)
+	}
+
 loop:
 	for {
 		select {
@@ -582,6 +1387,12 @@ loop:
 			if !globalQuiet && !globalJSON {
 				console.Eraseline()
 			}
+			if metrics != nil {
+				errorIf(metrics.write(metricsFile), "Unable to write the copy metrics.")
+			}
+			if notifier != nil {
+				notifier.notify("copy interrupted")
+			}
 			if session != nil {
 				session.CloseAndDie()
 			}
@@ -591,10 +1402,25 @@ loop:
 			if !ok {
 				break loop
 			}
+			if cpURLs.Error != nil && cpURLs.NoClobber {
+				if _, ok := cpURLs.Error.ToGoError().(ObjectAlreadyExists); ok {
+					skippedExisting++
+					cpURLs.Error = nil
+				}
+			}
+			if metrics != nil {
+				metrics.done(cpURLs.SourceContent.Size, cpURLs.Error != nil)
+			}
+			if notifier != nil {
+				notifier.done(cpURLs.SourceContent.Size, cpURLs.Error)
+			}
 			if cpURLs.Error == nil {
 				if session != nil {
 					session.Header.LastCopied = cpURLs.SourceContent.URL.String()
-					session.Save()
+					if checkpointInterval <= 0 || time.Since(lastCheckpoint) >= checkpointInterval {
+						session.Save()
+						lastCheckpoint = time.Now()
+					}
 				}
 				cpAllFilesErr = false
 			} else {
@@ -602,6 +1428,11 @@ loop:
 				// Set exit status for any copy error
 				retErr = exitStatus(globalErrorExitStatus)
 
+				// Copies aborted by --deadline are reported once by mainCopy.
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					continue loop
+				}
+
 				// Print in new line and adjust to top so that we
 				// don't print over the ongoing progress bar.
 				if !globalQuiet && !globalJSON {
@@ -631,6 +1462,9 @@ loop:
 					// For critical errors we should exit. Session
 					// can be resumed after the user figures out
 					// the  problem.
+					if notifier != nil && session.Header.CommandBoolFlags["session"] {
+						notifier.notify("copy terminated, resume it with the same command")
+					}
 					session.copyCloseAndDie(session.Header.CommandBoolFlags["session"])
 				}
 			}
@@ -653,20 +1487,105 @@ loop:
 				if !globalQuiet && !globalJSON {
 					console.Eraseline()
 				}
-			} else {
+			} else if !globalDryRun {
 				printMsg(accntReader.Stat())
 			}
 		}
 	}
 
+	if skippedExisting > 0 && !globalQuiet && !globalJSON {
+		console.Infof("Skipped %d existing object(s) not overwritten with --no-clobber.\n", skippedExisting)
+	}
+	if skipped := atomic.LoadInt64(&skippedLongKeys); skipped > 0 && !globalQuiet && !globalJSON {
+		console.Infof("Skipped %d object(s) with target keys longer than %d bytes.\n", skipped, cli.Int("max-key-length"))
+	}
+
+	if packer != nil {
+		if packer.errors > 0 {
+			retErr = exitStatus(globalErrorExitStatus)
+		}
+		if !globalQuiet && !globalJSON {
+			console.Infof("Packed %d small file(s) into %d pack object(s).\n", packer.objects, packer.packs)
+		}
+	}
+
+	if store != nil {
+		if err := store.writeManifest(ctx); err != nil {
+			errorIf(err.Trace(store.manifestURL), "Unable to upload the manifest `"+store.manifestURL+"`.")
+			retErr = exitStatus(globalErrorExitStatus)
+		} else if !globalQuiet && !globalJSON {
+			console.Infof("Stored %d object(s) by content hash, skipped %d already stored, manifest `%s`.\n", store.stored, store.skipped, store.manifestURL)
+		}
+	}
+
+	if metrics != nil {
+		errorIf(metrics.write(metricsFile), "Unable to write the copy metrics.")
+	}
+
 	// Source has error
 	if errSeen && totalObjects == 0 && retErr == nil {
 		retErr = exitStatus(globalErrorExitStatus)
 	}
 
+	if notifier != nil {
+		var failed string
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			failed = "copy not finished by --deadline"
+		case retErr != nil && notifier.errors == 0:
+			failed = "copy finished with errors"
+		}
+		notifier.notify(failed)
+	}
+
 	return retErr
 }
 
+// checkCopyTargetBucket - fails before copying anything if the bucket of
+// the target does not exist, or creates it in region with create. Other
+// errors checking the bucket, like gateways not implementing HEAD bucket
+// requests, are warned about and left to the copy.
+func checkCopyTargetBucket(ctx context.Context, targetURL string, create bool, region string) {
+	alias, urlStr, hostCfg := mustExpandAlias(targetURL)
+	if hostCfg == nil {
+		return
+	}
+	bucket, _ := url2BucketAndObject(newClientURL(urlStr))
+	if bucket == "" {
+		return
+	}
+	bucketURL := alias + "/" + bucket
+	clnt, err := newClient(bucketURL)
+	fatalIf(err.Trace(bucketURL), "Unable to initialize `"+bucketURL+"`.")
+
+	_, err = clnt.Stat(ctx, StatOptions{})
+	if err == nil {
+		return
+	}
+	switch err.ToGoError().(type) {
+	case BucketDoesNotExist:
+	case PathInsufficientPermission:
+		// Credentials allowed to upload but not to list are denied
+		// checking the bucket, the copy itself reports missing access.
+		return
+	default:
+		if minio.ToErrorResponse(err.ToGoError()).Code != "AccessDenied" && !globalQuiet && !globalJSON {
+			console.Infof("[Warn] Unable to check the target bucket `%s`, copying anyway: %v\n", bucketURL, err.ToGoError())
+		}
+		return
+	}
+	if !create {
+		fatalIf(err.Trace(bucketURL), "Target bucket `"+bucketURL+"` does not exist, create it with --create-bucket or `mc mb`.")
+	}
+	if skipDryRun(dryRunMessage{Action: "create bucket", Target: bucketURL}) {
+		return
+	}
+	fatalIf(clnt.MakeBucket(ctx, region, true, false).Trace(bucketURL), "Unable to create the target bucket `"+bucketURL+"`.")
+	if !globalQuiet && !globalJSON {
+		console.Infof("Created target bucket `%s`.\n", bucketURL)
+	}
+}
+
 // mainCopy is the entry point for cp command.
 func mainCopy(cliCtx *cli.Context) error {
 	ctx, cancelCopy := context.WithCancel(globalContext)
@@ -683,10 +1602,62 @@ func mainCopy(cliCtx *cli.Context) error {
 		fatalIf(err, "Unable to parse attribute %v", cliCtx.String("attr"))
 	}
 
+	// Destination limits apply to the clients created after they are
+	// set, before the first one checking the arguments.
+	if file := cliCtx.String("bwlimit-file"); file != "" {
+		globalDestinationLimits, err = parseDestinationLimits(file)
+		fatalIf(err.Trace(file), "Unable to parse --bwlimit-file.")
+	}
+
 	// check 'copy' cli arguments.
 	checkCopySyntax(cliCtx)
+	globalAccelerate = cliCtx.Bool("accelerate")
+
+	// Requests to the source and target buckets are signed for the
+	// regions set, server side copies between regions included.
+	if region := cliCtx.String("source-region"); region != "" {
+		for _, srcURL := range cliCtx.Args()[:cliCtx.NArg()-1] {
+			_, urlStr, _ := mustExpandAlias(srcURL)
+			setBucketRegion(urlStr, region)
+		}
+	}
+	if region := cliCtx.String("dest-region"); region != "" {
+		_, urlStr, _ := mustExpandAlias(cliCtx.Args().Get(cliCtx.NArg() - 1))
+		setBucketRegion(urlStr, region)
+	}
+
+	region := cliCtx.String("region")
+	if region == "" {
+		region = cliCtx.String("dest-region")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	checkCopyTargetBucket(ctx, cliCtx.Args().Get(cliCtx.NArg()-1), cliCtx.Bool("create-bucket"), region)
 	// Additional command specific theme customization.
 	console.SetColor("Copy", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Skip", color.New(color.FgYellow))
+
+	startAt, deadline, _ := parseCopySchedule(cliCtx.String("start-at"), cliCtx.String("deadline"))
+	fatalIf(waitCopyStart(ctx, startAt), "Unable to wait for --start-at.")
+	if !deadline.IsZero() {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+		defer cancelDeadline()
+	}
+
+	if cliCtx.Bool("extract") {
+		return doCopyExtract(ctx, cliCtx, encKeyDB, userMetaMap)
+	}
+	if cliCtx.Bool("fan-out") {
+		return doCopyFanOut(ctx, cliCtx, encKeyDB)
+	}
+	if cliCtx.Bool("unpack") {
+		return doCopyUnpack(ctx, cliCtx.Args().Get(0), cliCtx.Args().Get(1), encKeyDB)
+	}
+	if isPresignedURL(cliCtx.Args().Get(0)) {
+		return doCopyPresigned(ctx, cliCtx, encKeyDB, userMetaMap)
+	}
 
 	recursive := cliCtx.Bool("recursive")
 	rewind := cliCtx.String("rewind")
@@ -709,10 +1680,15 @@ func mainCopy(cliCtx *cli.Context) error {
 	}
 	sse := cliCtx.String("encrypt")
 
+	if cliCtx.Bool("atomic-prefix") {
+		return doCopyAtomicPrefix(ctx, cancelCopy, cliCtx, encKeyDB)
+	}
+
 	var session *sessionV8
 
-	if cliCtx.Bool("continue") {
-		sessionID := getHash("cp", os.Args[1:])
+	args := cliCtx.Args()
+	sessionID := getHash("cp", os.Args[1:])
+	if !globalDryRun && (cliCtx.Bool("continue") || isResumeNeeded(ctx, sessionID, args[:len(args)-1], args[len(args)-1], recursive)) {
 		if isSessionExists(sessionID) {
 			session, err = loadSessionV8(sessionID)
 			fatalIf(err.Trace(sessionID), "Unable to load session.")
@@ -731,7 +1707,7 @@ func mainCopy(cliCtx *cli.Context) error {
 			session.Header.CommandStringFlags[lhFlag] = legalHold
 			session.Header.CommandStringFlags["encrypt-key"] = sseKeys
 			session.Header.CommandStringFlags["encrypt"] = sse
-			session.Header.CommandBoolFlags["session"] = cliCtx.Bool("continue")
+			session.Header.CommandBoolFlags["session"] = true
 
 			if cliCtx.Bool("preserve") {
 				session.Header.CommandBoolFlags["preserve"] = cliCtx.Bool("preserve")
@@ -739,6 +1715,40 @@ func mainCopy(cliCtx *cli.Context) error {
 			session.Header.UserMetaData = userMetaMap
 			session.Header.CommandBoolFlags["md5"] = cliCtx.Bool("md5")
 			session.Header.CommandBoolFlags["disable-multipart"] = cliCtx.Bool("disable-multipart")
+			session.Header.CommandStringFlags["multipart-threshold"] = cliCtx.String("multipart-threshold")
+			session.Header.CommandStringFlags["normalize-keys"] = cliCtx.String("normalize-keys")
+			session.Header.CommandStringFlags["require-tag"] = cliCtx.String("require-tag")
+			session.Header.CommandStringFlags["workers"] = strconv.Itoa(cliCtx.Int("workers"))
+			session.Header.CommandStringFlags["max-key-length"] = strconv.Itoa(cliCtx.Int("max-key-length"))
+			session.Header.CommandBoolFlags["preserve-xattr"] = cliCtx.Bool("preserve-xattr")
+			session.Header.CommandBoolFlags["preallocate"] = cliCtx.Bool("preallocate")
+			session.Header.CommandStringFlags["max-object-size"] = cliCtx.String("max-object-size")
+			session.Header.CommandBoolFlags["split"] = cliCtx.Bool("split")
+			session.Header.CommandBoolFlags["delta"] = cliCtx.Bool("delta")
+			session.Header.CommandStringFlags["delta-block-size"] = cliCtx.String("delta-block-size")
+			session.Header.CommandStringFlags["trailer-checksum"] = cliCtx.String("trailer-checksum")
+			session.Header.CommandBoolFlags["if-not-exists"] = cliCtx.Bool("if-not-exists")
+			session.Header.CommandBoolFlags["no-overwrite-newer"] = cliCtx.Bool("no-overwrite-newer")
+			session.Header.CommandBoolFlags["no-clobber"] = cliCtx.Bool("no-clobber")
+			session.Header.CommandBoolFlags["overwrite-dir-with-file"] = cliCtx.Bool("overwrite-dir-with-file")
+			session.Header.CommandBoolFlags["overwrite-file-with-dir"] = cliCtx.Bool("overwrite-file-with-dir")
+			session.Header.CommandBoolFlags["remove-source"] = cliCtx.Bool("remove-source")
+			session.Header.CommandBoolFlags["dereference"] = cliCtx.Bool("dereference")
+			session.Header.CommandBoolFlags["no-dereference"] = cliCtx.Bool("no-dereference")
+			session.Header.CommandBoolFlags["preserve-acl"] = cliCtx.Bool("preserve-acl")
+			session.Header.CommandBoolFlags["accelerate"] = cliCtx.Bool("accelerate")
+			session.Header.CommandStringFlags["metadata-directive"] = cliCtx.String("metadata-directive")
+			session.Header.CommandStringFlags["content-type-map"] = cliCtx.String("content-type-map")
+			session.Header.CommandStringFlags["content-language"] = cliCtx.String("content-language")
+			session.Header.CommandStringFlags["charset"] = cliCtx.String("charset")
+			session.Header.CommandStringFlags["charset-map"] = cliCtx.String("charset-map")
+			session.Header.CommandBoolFlags["auto-compress"] = cliCtx.Bool("auto-compress")
+			session.Header.CommandStringFlags["auto-compress-min"] = cliCtx.String("auto-compress-min")
+			session.Header.CommandStringFlags["deploy-manifest"] = cliCtx.String("deploy-manifest")
+			session.Header.CommandBoolFlags["wait-consistent"] = cliCtx.Bool("wait-consistent")
+			session.Header.CommandStringFlags["wait-consistent-timeout"] = cliCtx.Duration("wait-consistent-timeout").String()
+			session.Header.CommandStringFlags["wait-consistent-interval"] = cliCtx.Duration("wait-consistent-interval").String()
+			session.Header.CommandBoolFlags["disable-etag-check"] = cliCtx.Bool("disable-etag-check")
 
 			var e error
 			if session.Header.RootPath, e = os.Getwd(); e != nil {
@@ -751,7 +1761,15 @@ func mainCopy(cliCtx *cli.Context) error {
 		}
 	}
 
-	e := doCopySession(ctx, cancelCopy, cliCtx, session, encKeyDB, false)
+	e := doCopySession(ctx, cancelCopy, cliCtx, session, encKeyDB, false, args[len(args)-1])
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		errorIf(probe.NewError(ctx.Err()), "Copy not finished by --deadline "+deadline.Format(printDate)+".")
+		// Keep the session to resume the copy later.
+		if session != nil {
+			session.CloseAndDie()
+		}
+		return exitStatus(globalErrorExitStatus)
+	}
 	if session != nil {
 		session.Delete()
 	}
@@ -18,8 +18,29 @@
 package cmd
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
 )
 
 func TestParseMetaData(t *testing.T) {
@@ -74,3 +95,1061 @@ func TestParseMetaData(t *testing.T) {
 		}
 	}
 }
+
+func TestParseMultipartThreshold(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected uint64
+		success  bool
+	}{
+		{"", 0, true},
+		{"64MiB", 64 << 20, true},
+		{"5GiB", 5 << 30, true},
+		{"6GiB", 0, false},
+		{"notasize", 0, false},
+	}
+
+	for idx, testCase := range testCases {
+		threshold, err := parseMultipartThreshold(testCase.input)
+		if testCase.success && err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", idx+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Fatalf("Test %d: expected an error for `%s`", idx+1, testCase.input)
+		}
+		if threshold != testCase.expected {
+			t.Fatalf("Test %d: expected %d, found %d", idx+1, testCase.expected, threshold)
+		}
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	testCases := []struct {
+		key, normalizeKeys, expected string
+	}{
+		{"/Photos/IMG_01.JPG", "", "/Photos/IMG_01.JPG"},
+		{"/Photos/IMG_01.JPG", "lower", "/photos/img_01.jpg"},
+		{"/cafe\u0301.txt", "nfc", "/caf\u00e9.txt"},
+		{"/caf\u00e9.txt", "nfc", "/caf\u00e9.txt"},
+	}
+
+	for idx, testCase := range testCases {
+		if key := normalizeKey(testCase.key, testCase.normalizeKeys); key != testCase.expected {
+			t.Fatalf("Test %d: expected %q, found %q", idx+1, testCase.expected, key)
+		}
+	}
+}
+
+func TestCheckTargetKeyLength(t *testing.T) {
+	testCases := []struct {
+		target       string
+		maxKeyLength int
+		tooLong      bool
+	}{
+		{"https://s3.amazonaws.com/bucket/0123456789", 10, false},
+		{"https://s3.amazonaws.com/bucket/0123456789a", 10, true},
+		{"https://s3.amazonaws.com/bucket/0123456789a", 0, false},
+		{"/tmp/bucket/0123456789a", 10, false},
+	}
+
+	for idx, testCase := range testCases {
+		cpURLs := URLs{TargetContent: &ClientContent{URL: *newClientURL(testCase.target)}}
+		err := checkTargetKeyLength(cpURLs, testCase.maxKeyLength)
+		if tooLong := err != nil; tooLong != testCase.tooLong {
+			t.Fatalf("Test %d: expected too long %v, found %v", idx+1, testCase.tooLong, tooLong)
+		}
+		if err != nil {
+			if _, ok := err.ToGoError().(keyTooLongErr); !ok {
+				t.Fatalf("Test %d: unexpected error %v", idx+1, err)
+			}
+		}
+	}
+}
+
+func TestCopyMetricsWrite(t *testing.T) {
+	metrics := &copyMetrics{source: "local", target: `s3"x`, start: time.Now()}
+	metrics.done(100, false)
+	metrics.done(50, false)
+	metrics.done(10, true)
+
+	file := filepath.Join(t.TempDir(), "mc.prom")
+	if err := metrics.write(file); err != nil {
+		t.Fatal(err)
+	}
+	data, e := os.ReadFile(file)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for _, line := range []string{
+		"# TYPE mc_cp_transferred_bytes_total counter",
+		`mc_cp_transferred_bytes_total{source="local",target="s3\"x"} 150`,
+		`mc_cp_objects_total{source="local",target="s3\"x"} 2`,
+		`mc_cp_errors_total{source="local",target="s3\"x"} 1`,
+	} {
+		if !strings.Contains(string(data), line+"\n") {
+			t.Fatalf("expected line %q in\n%s", line, data)
+		}
+	}
+	entries, e := os.ReadDir(filepath.Dir(file))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the metrics file, found %d entries", len(entries))
+	}
+}
+
+func TestCheckTargetTrailingSeparator(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	if e := os.WriteFile(filepath.Join(dir, "file"), []byte("x"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	testCases := []struct {
+		target   string
+		notAFile bool
+	}{
+		{filepath.Join(dir, "file") + "/", false},
+		{filepath.Join(dir, "file"), true},
+		{filepath.Join(dir, "missing") + "/", true},
+		{dir + "/", true},
+	}
+	for idx, testCase := range testCases {
+		err := checkTargetTrailingSeparator(context.Background(), testCase.target, nil)
+		if notAFile := err == nil; notAFile != testCase.notAFile {
+			t.Fatalf("Test %d: expected %v, found error %v", idx+1, testCase.notAFile, err)
+		}
+	}
+}
+
+func TestCopyPackUnpack(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	sourceDir, targetDir, restoredDir := filepath.Join(dir, "src"), filepath.Join(dir, "dst"), filepath.Join(dir, "restored")
+	packer, err := newCopyPacker(targetDir+"/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"a/one.txt": "one", "a/b/two.txt": "two two", "three.txt": ""}
+	for name, data := range files {
+		sourcePath := filepath.Join(sourceDir, name)
+		if e := os.MkdirAll(filepath.Dir(sourcePath), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(sourcePath, []byte(data), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		cpURLs := URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(sourcePath), Size: int64(len(data))},
+			TargetContent: &ClientContent{URL: *newClientURL(filepath.Join(targetDir, name))},
+		}
+		if err = packer.add(context.Background(), cpURLs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = packer.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if packer.packs != 1 || packer.objects != int64(len(files)) {
+		t.Fatalf("expected %d files in 1 pack, found %d in %d", len(files), packer.objects, packer.packs)
+	}
+
+	if e := doCopyUnpack(context.Background(), targetDir, restoredDir, nil); e != nil {
+		t.Fatal(e)
+	}
+	for name, data := range files {
+		restored, e := os.ReadFile(filepath.Join(restoredDir, name))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if string(restored) != data {
+			t.Errorf("%s: expected %q, found %q", name, data, restored)
+		}
+	}
+}
+
+func TestCopyContentAddressed(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	sourceDir, targetDir := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	store, err := newCopyContentStore(targetDir+"/", "sha256", 2, "manifest.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stores the copied sources like doCopy, counting the uploads.
+	var uploads int
+	doCopy := func(cpURLs URLs) URLs {
+		uploads++
+		target := cpURLs.TargetContent.URL.Path
+		if e := os.MkdirAll(filepath.Dir(target), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(target, nil, 0o644); e != nil {
+			t.Fatal(e)
+		}
+		return cpURLs
+	}
+	for _, name := range []string{"a.txt", "b/a-copy.txt"} {
+		sourcePath := filepath.Join(sourceDir, name)
+		if e := os.MkdirAll(filepath.Dir(sourcePath), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(sourcePath, []byte("abc"), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		cpURLs := URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(sourcePath), Size: 3},
+			TargetContent: &ClientContent{URL: *newClientURL(filepath.Join(targetDir, name))},
+		}
+		if cpURLs = store.copy(context.Background(), cpURLs, newAccounter(0), doCopy); cpURLs.Error != nil {
+			t.Fatal(cpURLs.Error)
+		}
+	}
+	if uploads != 1 || store.stored != 1 || store.skipped != 1 {
+		t.Fatalf("expected 1 upload and 1 duplicate skipped, found %d uploads, %d stored, %d skipped", uploads, store.stored, store.skipped)
+	}
+	const key = "sha256/ba/78/ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	for _, entry := range store.manifest.Entries {
+		if entry.Key != key {
+			t.Errorf("%s: expected key %s, found %s", entry.Path, key, entry.Key)
+		}
+	}
+	if err = store.writeManifest(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, e := os.Stat(filepath.Join(targetDir, "manifest.json")); e != nil {
+		t.Fatal(e)
+	}
+}
+
+func TestFanOutTargetURL(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	testCases := map[string]string{
+		dir + "/folder/":     dir + "/folder/src.bin",
+		dir:                  dir + "/src.bin",
+		dir + "/renamed.bin": dir + "/renamed.bin",
+	}
+	for target, expected := range testCases {
+		if found := fanOutTargetURL(context.Background(), "s3/bucket/src.bin", target, nil); found != expected {
+			t.Errorf("%s: expected %s, found %s", target, expected, found)
+		}
+	}
+}
+
+func TestAtomicStagingURL(t *testing.T) {
+	testCases := []struct {
+		target string
+		prefix string
+	}{
+		{"s3/bucket/site/", "s3/bucket/.site.mc-staging-"},
+		{"s3/bucket/a/site", "s3/bucket/a/.site.mc-staging-"},
+		{"s3/bucket", "s3/bucket/.mc-staging-"},
+		{"s3/bucket/", "s3/bucket/.mc-staging-"},
+	}
+	for idx, testCase := range testCases {
+		staging := atomicStagingURL(testCase.target)
+		if !strings.HasPrefix(staging, testCase.prefix) || !strings.HasSuffix(staging, "/") {
+			t.Fatalf("Test %d: expected a folder with prefix %q, found %q", idx+1, testCase.prefix, staging)
+		}
+	}
+}
+
+func TestRequireTags(t *testing.T) {
+	requireTags, err := parseRequireTags("ready=true&stage=final")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		tags     map[string]string
+		expected bool
+	}{
+		{map[string]string{"ready": "true", "stage": "final"}, true},
+		{map[string]string{"ready": "true", "stage": "final", "owner": "etl"}, true},
+		{map[string]string{"ready": "true"}, false},
+		{map[string]string{"ready": "false", "stage": "final"}, false},
+		{nil, false},
+	}
+
+	for idx, testCase := range testCases {
+		if ok := hasRequiredTags(testCase.tags, requireTags); ok != testCase.expected {
+			t.Fatalf("Test %d: expected %v, found %v", idx+1, testCase.expected, ok)
+		}
+	}
+}
+
+// Test local files, which carry no tags, are skipped by --require-tag
+// without fetching their tags.
+func TestFilterCopyURLsByTagsLocal(t *testing.T) {
+	requireTags, _ := parseRequireTags("ready=true")
+	source := filepath.Join(t.TempDir(), "file")
+	if e := os.WriteFile(source, []byte("data"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	copyURLsCh := make(chan URLs, 1)
+	copyURLsCh <- URLs{SourceContent: &ClientContent{URL: *newClientURL(source)}, TargetContent: &ClientContent{URL: *newClientURL("fake/bucket/file")}}
+	close(copyURLsCh)
+	for cpURLs := range filterCopyURLsByTags(context.Background(), copyURLsCh, prepareCopyURLsOpts{requireTags: requireTags, workers: 2}) {
+		t.Errorf("expected the local file to be skipped, got %v", cpURLs.Error)
+	}
+}
+
+func TestExtractMemberKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     extractOptions
+		key      string
+		included bool
+	}{
+		{"build/img/a.jpg", extractOptions{}, "build/img/a.jpg", true},
+		{"./build/img/a.jpg", extractOptions{stripComponents: 1}, "img/a.jpg", true},
+		{"build/top.jpg", extractOptions{stripComponents: 2}, "", false},
+		{"build/img/a.jpg", extractOptions{include: []string{"*.jpg"}}, "build/img/a.jpg", true},
+		{"build/img/b.png", extractOptions{include: []string{"*.jpg"}}, "", false},
+		{"build/img/b.png", extractOptions{include: []string{"*.jpg", "img/*"}, stripComponents: 1}, "img/b.png", true},
+		{"../../etc/passwd", extractOptions{}, "etc/passwd", true},
+	}
+
+	for idx, testCase := range testCases {
+		key, included := testCase.opts.memberKey(testCase.name)
+		if key != testCase.key || included != testCase.included {
+			t.Fatalf("Test %d: expected (%q, %v), found (%q, %v)", idx+1, testCase.key, testCase.included, key, included)
+		}
+	}
+}
+
+func TestParseContentTypeMap(t *testing.T) {
+	mapFile := filepath.Join(t.TempDir(), "types.json")
+	data := `{".wasm": "application/wasm", "WebManifest": "application/manifest+json"}`
+	if e := os.WriteFile(mapFile, []byte(data), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	contentTypes, err := parseContentTypeMap(mapFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		".wasm":        "application/wasm",
+		".webmanifest": "application/manifest+json",
+	}
+	if !reflect.DeepEqual(contentTypes, expected) {
+		t.Fatalf("expected %v, found %v", expected, contentTypes)
+	}
+
+	if e := os.WriteFile(mapFile, []byte(`{".wasm": ""}`), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if _, err = parseContentTypeMap(mapFile); err == nil {
+		t.Fatal("expected an error for an empty content type")
+	}
+}
+
+func TestContentHeadersApply(t *testing.T) {
+	headers := contentHeaders{
+		language: "fr",
+		charset:  "utf-8",
+		charsets: map[string]string{".csv": "iso-8859-1"},
+	}
+	testCases := []struct {
+		contentType, ext string
+		expected         string
+	}{
+		{"text/html", ".html", "text/html; charset=utf-8"},
+		{"", ".html", "text/html; charset=utf-8"},
+		{"text/html; charset=latin1", ".html", "text/html; charset=utf-8"},
+		{"application/json", ".json", "application/json; charset=utf-8"},
+		{"image/png", ".png", "image/png"},
+		{"application/octet-stream", ".csv", "application/octet-stream; charset=iso-8859-1"},
+	}
+	for idx, testCase := range testCases {
+		metadata := map[string]string{}
+		if testCase.contentType != "" {
+			metadata["Content-Type"] = testCase.contentType
+		}
+		headers.apply(metadata, testCase.ext)
+		if metadata["Content-Type"] != testCase.expected || metadata["Content-Language"] != "fr" {
+			t.Fatalf("Test %d: expected %q, found %v", idx+1, testCase.expected, metadata)
+		}
+	}
+}
+
+func TestCopiedETagsComparable(t *testing.T) {
+	testCases := []struct {
+		source, target string
+		comparable     bool
+	}{
+		{"5d41402abc4b2a76b9719d911017c592", "\"5d41402abc4b2a76b9719d911017c592\"", true},
+		{"5d41402abc4b2a76b9719d911017c592", "7d793037a0760186574b0282f2f435e7", true},
+		{"5d41402abc4b2a76b9719d911017c592", "7d793037a0760186574b0282f2f435e7-2", false},
+		{"", "7d793037a0760186574b0282f2f435e7", false},
+	}
+	for i, testCase := range testCases {
+		if comparable := copiedETagsComparable(testCase.source, testCase.target); comparable != testCase.comparable {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.comparable, comparable)
+		}
+	}
+}
+
+func TestRemoveCopiedSource(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	source, target := filepath.Join(dir, "source"), filepath.Join(dir, "target")
+	copyFile := func(sourceData, targetData string) *probe.Error {
+		if e := os.WriteFile(source, []byte(sourceData), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(target, []byte(targetData), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		return removeCopiedSource(context.Background(), URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(source), Size: int64(len(sourceData))},
+			TargetContent: &ClientContent{URL: *newClientURL(target)},
+			RemoveSource:  true,
+		}, nil)
+	}
+
+	// Sizes match but not contents, the source has no etag.
+	err := copyFile("content", "CONTENT")
+	if _, ok := err.ToGoError().(ChecksumMismatch); !ok {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if _, e := os.Stat(source); e != nil {
+		t.Fatalf("expected the source to be kept, %v", e)
+	}
+
+	if err = copyFile("content", "content"); err != nil {
+		t.Fatal(err)
+	}
+	if _, e := os.Stat(source); !os.IsNotExist(e) {
+		t.Fatal("expected the source to be removed")
+	}
+}
+
+// Test a copy is verified by etag or checksum without reading the
+// objects back when either can be compared, and by contents otherwise.
+func TestVerifyCopied(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	handler := newMemS3Handler()
+	handler.checksums = make(map[string]string)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	local := filepath.Join(t.TempDir(), "local")
+	verify := func(source, etag, target string) (*probe.Error, []string) {
+		handler.requests = nil
+		sourceURL, sourceAlias := newClientURL(server.URL+"/bucket/"+source), "fake"
+		if source == "local" {
+			sourceURL, sourceAlias = newClientURL(local), ""
+		}
+		err := verifyCopied(context.Background(), URLs{
+			SourceAlias:   sourceAlias,
+			SourceContent: &ClientContent{URL: *sourceURL, Size: 7, ETag: etag},
+			TargetAlias:   "fake",
+			TargetContent: &ClientContent{URL: *newClientURL(server.URL + "/bucket/" + target)},
+		}, nil)
+		var gets []string
+		for _, request := range handler.requests {
+			if strings.HasPrefix(request, "GET /bucket/") && !strings.HasPrefix(request, "GET /bucket/ ") {
+				gets = append(gets, request)
+			}
+		}
+		return err, gets
+	}
+	isMismatch := func(err *probe.Error) bool {
+		if err == nil {
+			return false
+		}
+		_, ok := err.ToGoError().(ChecksumMismatch)
+		return ok
+	}
+
+	handler.objects["bucket/src"] = []byte("content")
+	handler.objects["bucket/same"] = []byte("content")
+	handler.objects["bucket/other"] = []byte("CONTENT")
+
+	// Single part etags are compared.
+	if err, gets := verify("src", memS3ETag([]byte("content")), "same"); err != nil || len(gets) != 0 {
+		t.Errorf("expected the etags to match without reading the objects, got %v after %v", err, gets)
+	}
+	if err, gets := verify("src", memS3ETag([]byte("content")), "other"); !isMismatch(err) || len(gets) != 0 {
+		t.Errorf("expected the etags to mismatch without reading the objects, got %v after %v", err, gets)
+	}
+
+	// Checksums are compared when the etags of multipart uploads are not.
+	handler.checksums["bucket/src"] = "checksum"
+	handler.checksums["bucket/same"] = "checksum"
+	handler.checksums["bucket/other"] = "other"
+	if err, gets := verify("src", "etag-2", "same"); err != nil || len(gets) != 0 {
+		t.Errorf("expected the checksums to match without reading the objects, got %v after %v", err, gets)
+	}
+	if err, gets := verify("src", "etag-2", "other"); !isMismatch(err) || len(gets) != 0 {
+		t.Errorf("expected the checksums to mismatch without reading the objects, got %v after %v", err, gets)
+	}
+
+	// Local files have neither, their content is compared.
+	if e := os.WriteFile(local, []byte("content"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	if err, gets := verify("local", "", "same"); err != nil || len(gets) != 1 {
+		t.Errorf("expected the contents to match reading the target, got %v after %v", err, gets)
+	}
+	if err, gets := verify("local", "", "other"); !isMismatch(err) || len(gets) != 1 {
+		t.Errorf("expected the contents to mismatch reading the target, got %v after %v", err, gets)
+	}
+}
+
+func TestGetPresignedObject(t *testing.T) {
+	const query = "X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=AKIA%2F20230504%2Fus-east-1%2Fs3%2Faws4_request" +
+		"&X-Amz-Expires=3600&X-Amz-SignedHeaders=host&X-Amz-Signature=0a1b2c"
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	urlStr := server.URL + "/bucket/data.csv?" + query
+	if !isPresignedURL(urlStr) || isPresignedURL(server.URL+"/bucket/data.csv") {
+		t.Fatal("presigned URL not detected")
+	}
+	resp, err := getPresignedObject(context.Background(), urlStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if rawQuery != query {
+		t.Errorf("query string changed, expected %q, got %q", query, rawQuery)
+	}
+	if content, _ := io.ReadAll(resp.Body); string(content) != "content" {
+		t.Errorf("unexpected content %q", content)
+	}
+}
+
+func TestParseCopyTime(t *testing.T) {
+	ref := time.Date(2024, 6, 1, 10, 30, 0, 0, time.Local)
+	testCases := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"22:00", time.Date(2024, 6, 1, 22, 0, 0, 0, time.Local)},
+		{"02:00", time.Date(2024, 6, 2, 2, 0, 0, 0, time.Local)},
+		{"10:30", time.Date(2024, 6, 2, 10, 30, 0, 0, time.Local)},
+		{"10:30:15", time.Date(2024, 6, 1, 10, 30, 15, 0, time.Local)},
+		{"2024-05-01T08:00", time.Date(2024, 5, 1, 8, 0, 0, 0, time.Local)},
+		{"25:00", time.Time{}},
+		{"tonight", time.Time{}},
+	}
+	for idx, testCase := range testCases {
+		got, err := parseCopyTime(testCase.value, ref)
+		if testCase.expected.IsZero() {
+			if err == nil {
+				t.Fatalf("Test %d: expected %q to fail, found %v", idx+1, testCase.value, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(testCase.expected) {
+			t.Fatalf("Test %d: expected %v, found %v, %v", idx+1, testCase.expected, got, err)
+		}
+	}
+}
+
+func TestGzipToTempFile(t *testing.T) {
+	for contentType, compressible := range map[string]bool{
+		"text/html; charset=utf-8": true,
+		"application/json":         true,
+		"image/svg+xml":            true,
+		"image/png":                false,
+		"video/mp4":                false,
+		"application/zip":          false,
+		"":                         false,
+	} {
+		if isCompressibleContentType(contentType) != compressible {
+			t.Errorf("%q: expected compressible to be %v", contentType, compressible)
+		}
+	}
+
+	data := strings.Repeat("<p>hello</p>\n", 1000)
+	var read int64
+	file, size, err := gzipToTempFile(strings.NewReader(data), readCounter(func(n int) { read += int64(n) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeTempFile(file)
+	if read != int64(len(data)) || size >= int64(len(data)) {
+		t.Fatalf("expected %d bytes read and compressed, found %d read and %d compressed", len(data), read, size)
+	}
+	gz, e := gzip.NewReader(io.LimitReader(file, size))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if uncompressed, e := io.ReadAll(gz); e != nil || string(uncompressed) != data {
+		t.Fatalf("unexpected uncompressed content, %v", e)
+	}
+}
+
+// readCounter is a progress reader calling its function with the
+// number of bytes read.
+type readCounter func(n int)
+
+func (r readCounter) Read(p []byte) (int, error) {
+	r(len(p))
+	return len(p), nil
+}
+
+func TestAdaptiveThrottle(t *testing.T) {
+	if !isSlowDown(probe.NewError(minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable})) {
+		t.Fatal("expected SlowDown to be detected")
+	}
+	if isSlowDown(probe.NewError(minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden})) {
+		t.Fatal("unexpected AccessDenied detected as SlowDown")
+	}
+
+	throttle := newAdaptiveThrottle()
+	for i := 0; i < 8; i++ {
+		throttle.acquire()
+	}
+	throttle.slowDown()
+	if throttle.limit != 4 {
+		t.Fatalf("expected a limit of 4 after a SlowDown, got %d", throttle.limit)
+	}
+	throttle.slowDown()
+	if throttle.limit != 2 {
+		t.Fatalf("expected a limit of 2 after two SlowDowns, got %d", throttle.limit)
+	}
+	for i := 0; i < 8; i++ {
+		throttle.release()
+	}
+
+	// The limit grows by one every limit successes.
+	for _, expect := range []int{3, 4, 5} {
+		for i := 0; i < throttle.limit; i++ {
+			throttle.succeeded(6)
+		}
+		if throttle.limit != expect {
+			t.Fatalf("expected a limit of %d, got %d", expect, throttle.limit)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		throttle.succeeded(6)
+	}
+	if throttle.limit != 0 {
+		t.Fatalf("expected throttling to stop, got a limit of %d", throttle.limit)
+	}
+}
+
+func TestDeployManifest(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deploy.yaml")
+	manifest := `defaults:
+  cache-control: max-age=60
+rules:
+  - match: "*.html"
+    cache-control: no-cache
+    content-type: text/html; charset=utf-8
+  - match: "assets/*"
+    cache-control: max-age=31536000, immutable
+    gzip: true
+`
+	if e := os.WriteFile(file, []byte(manifest), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	m, err := parseDeployManifest(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		key    string
+		expect deployHeaders
+	}{
+		{"index.html", deployHeaders{CacheControl: "no-cache", ContentType: "text/html; charset=utf-8"}},
+		{"docs/about.html", deployHeaders{CacheControl: "no-cache", ContentType: "text/html; charset=utf-8"}},
+		{"assets/js/app.js", deployHeaders{CacheControl: "max-age=31536000, immutable", Gzip: true}},
+		{"robots.txt", deployHeaders{CacheControl: "max-age=60"}},
+	}
+	for i, testCase := range testCases {
+		if got := m.headers(testCase.key); got != testCase.expect {
+			t.Errorf("Test %d: expected %+v, got %+v", i+1, testCase.expect, got)
+		}
+	}
+
+	if key := deployKey("/tmp/site/", *newClientURL("/tmp/site/assets/app.js")); key != "assets/app.js" {
+		t.Errorf("expected assets/app.js, got %s", key)
+	}
+
+	invalid := "rules:\n  - match: \"*.js\"\n    gzip: true\n    content-encoding: br\n"
+	if e := os.WriteFile(file, []byte(invalid), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if _, err := parseDeployManifest(file); err == nil {
+		t.Fatal("expected gzip with content-encoding to be rejected")
+	}
+}
+
+func TestCopyNotifier(t *testing.T) {
+	var received copyNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e := json.NewDecoder(r.Body).Decode(&received); e != nil {
+			t.Errorf("unable to decode the notification: %v", e)
+		}
+	}))
+	defer server.Close()
+
+	n := newCopyNotifier(server.URL, false, []string{"backup/"}, "s3/backups/")
+	n.done(10, nil)
+	n.done(5, probe.NewError(errors.New("access denied")))
+	n.done(20, nil)
+	n.notify("")
+	if received.Version != copyNotificationVersion || received.Status != "failure" || received.Objects != 2 ||
+		received.Bytes != 30 || received.Errors != 1 || received.Error != "access denied" || received.Target != "s3/backups/" {
+		t.Fatalf("unexpected notification %+v", received)
+	}
+	if newCopyNotifier("", false, nil, "") != nil {
+		t.Fatal("expected no notifier without a webhook or desktop notification")
+	}
+	if isWebhookURL("hooks.example.com/x") || !isWebhookURL("https://hooks.example.com/x") {
+		t.Fatal("unexpected webhook URL validation")
+	}
+}
+
+func TestDestinationLimits(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "limits.yaml")
+	if e := os.WriteFile(file, []byte("remote: 5MiB\nminio.local: 1GiB/s\n"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	limits, err := parseDestinationLimits(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		alias, urlStr string
+		limit         uint64
+	}{
+		{"remote", "https://remote.example.com/bucket", 5 << 20},
+		{"local", "http://minio.local:9000/bucket", 1 << 30},
+		{"fast", "http://fast.example.com/bucket", 0},
+	}
+	for i, testCase := range testCases {
+		if limit := destinationLimit(limits, testCase.alias, testCase.urlStr); limit != testCase.limit {
+			t.Errorf("Test %d: expected %d, got %d", i+1, testCase.limit, limit)
+		}
+	}
+
+	if e := os.WriteFile(file, []byte("remote: fast\n"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	if _, err = parseDestinationLimits(file); err == nil {
+		t.Fatal("expected an invalid limit to be rejected")
+	}
+}
+
+// Test a failed promotion of --atomic-prefix keeps the staged objects
+// and a failed copy removes them.
+func TestAtomicPrefixPromoteFailure(t *testing.T) {
+	defer func(configDir string, quiet bool) {
+		mcCustomConfigDir, globalQuiet = configDir, quiet
+	}(mcCustomConfigDir, globalQuiet)
+	defer func(exiter func(int)) { cli.OsExiter = exiter }(cli.OsExiter)
+	var exitCode int
+	cli.OsExiter = func(code int) { exitCode = code }
+
+	handler := newMemS3Handler()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	if e := os.MkdirAll(filepath.Join(src, "dir"), 0o755); e != nil {
+		t.Fatal(e)
+	}
+	for _, name := range []string{"a", "dir/b"} {
+		if e := os.WriteFile(filepath.Join(src, name), []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	run := func() error {
+		exitCode = 0
+		return registerApp("mc").Run([]string{"mc", "--config-dir", filepath.Join(root, "config"), "--quiet", "cp", "--recursive", "--atomic-prefix", src + "/", "fake/bucket/www/"})
+	}
+	objects := func() (keys []string) {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		for key := range handler.objects {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	// The promotion of dir/b fails.
+	handler.fail = func(r *http.Request) bool {
+		return r.Method == http.MethodPut && r.URL.Path == "/bucket/www/dir/b"
+	}
+	if e := run(); e == nil || exitCode == 0 {
+		t.Fatal("expected the promotion to fail")
+	}
+	keys := objects()
+	staged := 0
+	for _, key := range keys {
+		if strings.HasPrefix(key, "bucket/.www.mc-staging-") {
+			staged++
+		}
+	}
+	if staged != 2 {
+		t.Errorf("expected the staged objects to be kept, found %v", keys)
+	}
+
+	// The copy of dir/b fails.
+	handler.objects = make(map[string][]byte)
+	handler.fail = func(r *http.Request) bool {
+		return r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/dir/b") && r.Header.Get("X-Amz-Copy-Source") == ""
+	}
+	if e := run(); e == nil || exitCode == 0 {
+		t.Fatal("expected the copy to fail")
+	}
+	if keys := objects(); len(keys) != 0 {
+		t.Errorf("expected the staged objects to be removed, found %v", keys)
+	}
+}
+
+// Test the exit hooks run once, last registered first.
+func TestExitHooks(t *testing.T) {
+	var ran []string
+	atExit(func() { ran = append(ran, "first") })
+	remove := atExit(func() { ran = append(ran, "removed") })
+	atExit(func() { ran = append(ran, "last") })
+	remove()
+	runExitHooks()
+	runExitHooks()
+	if !reflect.DeepEqual(ran, []string{"last", "first"}) {
+		t.Errorf("expected the hooks to run last first, ran %v", ran)
+	}
+}
+
+// Test the target of --if-not-exists is listed into a set, and that
+// listing it ends when returning early.
+func TestListExistingTargetKeys(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+	defer func(max int) { maxExistingTargetKeys = max }(maxExistingTargetKeys)
+
+	dir := t.TempDir()
+	if e := os.MkdirAll(filepath.Join(dir, "dir"), 0o755); e != nil {
+		t.Fatal(e)
+	}
+	var expected []string
+	for _, name := range []string{"a", "b", "dir/c"} {
+		if e := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		expected = append(expected, filepath.ToSlash(filepath.Join(dir, name)))
+	}
+	existing, listed := listExistingTargetKeys(context.Background(), dir)
+	var keys []string
+	for key := range existing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if !listed || !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v to be listed, got %v", expected, keys)
+	}
+
+	handler := newMemS3Handler()
+	handler.fail = func(*http.Request) bool { return true }
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	// Too large and failed listings are given up.
+	for i := 0; i < 20; i++ {
+		if e := os.WriteFile(filepath.Join(dir, "dir", strconv.Itoa(i)), nil, 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	goroutines := runtime.NumGoroutine()
+	maxExistingTargetKeys = 1
+	if _, listed = listExistingTargetKeys(context.Background(), dir); listed {
+		t.Error("expected a target over the limit not to be listed")
+	}
+	if _, listed = listExistingTargetKeys(context.Background(), "fake/bucket"); listed {
+		t.Error("expected a failed listing to be given up")
+	}
+	server.CloseClientConnections()
+	for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > goroutines; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the listings to end, %d goroutines are left of %d", runtime.NumGoroutine(), goroutines)
+		}
+	}
+}
+
+// Test local files named with characters needing URL encoding are
+// uploaded and downloaded by mc cp under their own name.
+func TestCopyObjectKeyEncoding(t *testing.T) {
+	defer func(configDir string, quiet bool) {
+		mcCustomConfigDir, globalQuiet = configDir, quiet
+	}(mcCustomConfigDir, globalQuiet)
+	defer func(exiter func(int)) { cli.OsExiter = exiter }(cli.OsExiter)
+	var exitCode int
+	cli.OsExiter = func(code int) { exitCode = code }
+
+	handler := newMemS3Handler()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	names := []string{"with space", "a+b", "100%", "50%25", "a%2Fb", "résumé.pdf", "日本語/ファイル.txt", "a?b#c", "a&b=c;d", "~tilde's (copy)"}
+	root := t.TempDir()
+	src, dst := filepath.Join(root, "src"), filepath.Join(root, "dst")
+	for _, name := range names {
+		if e := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(filepath.Join(src, name), []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	run := func(source, target string) {
+		exitCode = 0
+		if e := registerApp("mc").Run([]string{"mc", "--config-dir", filepath.Join(root, "config"), "--quiet", "cp", "--recursive", source, target}); e != nil || exitCode != 0 {
+			t.Fatalf("unable to copy %s to %s, exit code %d: %v", source, target, exitCode, e)
+		}
+	}
+
+	run(src+"/", "fake/bucket/")
+	for _, name := range names {
+		if data, ok := handler.objects["bucket/"+name]; !ok || string(data) != name {
+			t.Errorf("expected %q to be uploaded under its name", name)
+		}
+	}
+	run("fake/bucket/", dst+"/")
+	for _, name := range names {
+		if data, e := os.ReadFile(filepath.Join(dst, name)); e != nil || string(data) != name {
+			t.Errorf("expected %q to be downloaded under its name: %v", name, e)
+		}
+	}
+}
+
+// Test a missing target bucket fails the copy before it starts unless
+// created with --create-bucket, other errors checking it do not.
+func TestCheckCopyTargetBucket(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+	defer func(quiet, dryRun bool) { globalQuiet, globalDryRun = quiet, dryRun }(globalQuiet, globalDryRun)
+	globalQuiet = true
+	defer func(f func(...interface{})) { fatalln = f }(fatalln)
+	type fatal struct{ msg string }
+	fatalln = func(data ...interface{}) { panic(fatal{fmt.Sprint(data...)}) }
+	check := func(create bool) (msg string) {
+		defer func() {
+			if r := recover(); r != nil {
+				f, ok := r.(fatal)
+				if !ok {
+					panic(r)
+				}
+				msg = f.msg
+			}
+		}()
+		checkCopyTargetBucket(context.Background(), "fake/bucket/dir/", create, "us-east-1")
+		return ""
+	}
+
+	handler := newMemS3Handler()
+	handler.buckets = map[string]bool{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	if msg := check(false); !strings.Contains(msg, "does not exist") {
+		t.Fatalf("expected the missing bucket to be fatal, got %q", msg)
+	}
+	globalDryRun = true
+	if msg := check(true); msg != "" || handler.buckets["bucket"] {
+		t.Fatalf("expected --dry-run to leave the bucket missing, got %q", msg)
+	}
+	globalDryRun = false
+	if msg := check(true); msg != "" || !handler.buckets["bucket"] {
+		t.Fatalf("expected --create-bucket to create the bucket, got %q", msg)
+	}
+	if msg := check(false); msg != "" {
+		t.Fatalf("expected the existing bucket to be accepted, got %q", msg)
+	}
+
+	handler.noHeadBucket = true
+	delete(handler.buckets, "bucket")
+	if msg := check(false); msg != "" {
+		t.Fatalf("expected a HEAD bucket not implemented to be ignored, got %q", msg)
+	}
+}
+
+// Test --no-overwrite-newer only copies over targets older than their
+// source, the sources of newer targets are reported skipped.
+func TestFilterNewerTargetCopyURLs(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+	defer func(quiet bool, output io.Writer) { globalQuiet, color.Output = quiet, output }(globalQuiet, color.Output)
+	var out bytes.Buffer
+	globalQuiet, color.Output = true, &out
+
+	root := t.TempDir()
+	sourceTime := time.Now().Add(-time.Hour)
+	files := map[string]time.Time{
+		"src/old":   sourceTime,
+		"src/new":   sourceTime,
+		"src/fresh": sourceTime,
+		"dst/old":   sourceTime.Add(-time.Minute),
+		"dst/new":   sourceTime.Add(time.Minute),
+	}
+	for name, mtime := range files {
+		path := filepath.Join(root, name)
+		if e := os.MkdirAll(filepath.Dir(path), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.Chtimes(path, mtime, mtime); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	copyURLsCh := make(chan URLs, 3)
+	for _, name := range []string{"old", "new", "fresh"} {
+		source := filepath.Join(root, "src", name)
+		copyURLsCh <- URLs{
+			SourceContent: &ClientContent{URL: *newClientURL(source), Time: sourceTime},
+			TargetContent: &ClientContent{URL: *newClientURL(filepath.Join(root, "dst", name))},
+		}
+	}
+	close(copyURLsCh)
+	var copied []string
+	for cpURLs := range filterNewerTargetCopyURLs(context.Background(), copyURLsCh, prepareCopyURLsOpts{workers: 2}) {
+		if cpURLs.Error != nil {
+			t.Fatal(cpURLs.Error)
+		}
+		copied = append(copied, filepath.Base(cpURLs.SourceContent.URL.Path))
+	}
+	sort.Strings(copied)
+	if !reflect.DeepEqual(copied, []string{"fresh", "old"}) {
+		t.Fatalf("expected the missing and the older targets to be copied, got %v", copied)
+	}
+	if skipped := out.String(); strings.Count(skipped, "Skipped") != 1 || !strings.Contains(skipped, filepath.Join(root, "src", "new")) {
+		t.Fatalf("expected the source of the newer target to be reported skipped, got %q", skipped)
+	}
+}
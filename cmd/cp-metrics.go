@@ -0,0 +1,139 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// copyMetrics - counters of a copy written as Prometheus metrics with
+// cp --metrics-file, for the textfile collector of node_exporter.
+type copyMetrics struct {
+	source, target string
+	start          time.Time
+
+	bytes   int64
+	objects int64
+	errors  int64
+}
+
+// newCopyMetrics - returns the metrics of a copy labelled with the
+// aliases of its sources and target, "local" for local paths.
+func newCopyMetrics(sourceURLs []string, targetURL string) *copyMetrics {
+	metricsAlias := func(url string) string {
+		if alias, _, _ := mustExpandAlias(url); alias != "" {
+			return alias
+		}
+		return "local"
+	}
+	var sources []string
+	seen := make(map[string]bool)
+	for _, url := range sourceURLs {
+		if alias := metricsAlias(url); !seen[alias] {
+			seen[alias] = true
+			sources = append(sources, alias)
+		}
+	}
+	return &copyMetrics{
+		source: strings.Join(sources, ","),
+		target: metricsAlias(targetURL),
+		start:  time.Now(),
+	}
+}
+
+// done counts a copied object of size bytes, or a failed copy.
+func (m *copyMetrics) done(size int64, failed bool) {
+	if failed {
+		atomic.AddInt64(&m.errors, 1)
+		return
+	}
+	atomic.AddInt64(&m.bytes, size)
+	atomic.AddInt64(&m.objects, 1)
+}
+
+// escapePromLabel escapes a label value of the Prometheus text format.
+func escapePromLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// format - returns the metrics in the Prometheus text exposition format.
+func (m *copyMetrics) format(now time.Time) []byte {
+	labels := fmt.Sprintf(`{source="%s",target="%s"}`, escapePromLabel(m.source), escapePromLabel(m.target))
+	var b bytes.Buffer
+	for _, metric := range []struct {
+		name, help, kind string
+		value            interface{}
+	}{
+		{"mc_cp_transferred_bytes_total", "Bytes of the objects copied.", "counter", atomic.LoadInt64(&m.bytes)},
+		{"mc_cp_objects_total", "Number of objects copied.", "counter", atomic.LoadInt64(&m.objects)},
+		{"mc_cp_errors_total", "Number of objects which failed to copy.", "counter", atomic.LoadInt64(&m.errors)},
+		{"mc_cp_duration_seconds", "Time spent copying.", "gauge", now.Sub(m.start).Seconds()},
+		{"mc_cp_last_update_timestamp_seconds", "Time the metrics were written.", "gauge", now.Unix()},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s%s %v\n", metric.name, metric.help, metric.name, metric.kind, metric.name, labels, metric.value)
+	}
+	return b.Bytes()
+}
+
+// write - atomically replaces file with the current metrics, the
+// textfile collector must never read a partially written file.
+func (m *copyMetrics) write(file string) *probe.Error {
+	tmp, e := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if e != nil {
+		return probe.NewError(e).Trace(file)
+	}
+	defer os.Remove(tmp.Name())
+	if _, e = tmp.Write(m.format(time.Now())); e != nil {
+		tmp.Close()
+		return probe.NewError(e).Trace(file)
+	}
+	if e = tmp.Close(); e != nil {
+		return probe.NewError(e).Trace(file)
+	}
+	// Readable by the collector like a file created with os.Create.
+	if e = os.Chmod(tmp.Name(), 0o644); e != nil {
+		return probe.NewError(e).Trace(file)
+	}
+	if e = os.Rename(tmp.Name(), file); e != nil {
+		return probe.NewError(e).Trace(file)
+	}
+	return nil
+}
+
+// writePeriodically - writes the metrics to file every interval until
+// doneCh is closed.
+func (m *copyMetrics) writePeriodically(file string, interval time.Duration, doneCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-doneCh:
+			return
+		case <-ticker.C:
+			errorIf(m.write(file), "Unable to write the copy metrics.")
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// copyNotificationVersion is the version of the payload posted with
+// --notify-webhook, increased only on incompatible changes.
+const copyNotificationVersion = 1
+
+// copyNotificationTimeout bounds sending a notification, a slow
+// webhook must not keep a finished copy running.
+const copyNotificationTimeout = 10 * time.Second
+
+// copyNotification - summary of a finished copy, posted as JSON with
+// --notify-webhook:
+//
+//	{
+//	  "version": 1,
+//	  "event": "cp.finished",
+//	  "status": "success" | "failure",
+//	  "sources": ["backup/"],
+//	  "target": "s3/backups/",
+//	  "objects": 120,         // objects copied
+//	  "bytes": 5242880,       // bytes copied
+//	  "errors": 0,            // objects which failed to copy
+//	  "error": "...",         // first failure, omitted on success
+//	  "startTime": "2023-06-01T10:00:00Z",
+//	  "endTime": "2023-06-01T10:05:00Z",
+//	  "durationSeconds": 300
+//	}
+type copyNotification struct {
+	Version  int       `json:"version"`
+	Event    string    `json:"event"`
+	Status   string    `json:"status"`
+	Sources  []string  `json:"sources"`
+	Target   string    `json:"target"`
+	Objects  int64     `json:"objects"`
+	Bytes    int64     `json:"bytes"`
+	Errors   int64     `json:"errors"`
+	Error    string    `json:"error,omitempty"`
+	Start    time.Time `json:"startTime"`
+	End      time.Time `json:"endTime"`
+	Duration float64   `json:"durationSeconds"`
+}
+
+// copyNotifier - counts the outcome of a copy to notify its
+// completion with --notify-webhook and --notify-desktop, only used by
+// the goroutine receiving the copy statuses.
+type copyNotifier struct {
+	webhook string
+	desktop bool
+
+	sources []string
+	target  string
+	start   time.Time
+
+	objects  int64
+	bytes    int64
+	errors   int64
+	firstErr string
+}
+
+// newCopyNotifier - returns nil if no notification was requested.
+func newCopyNotifier(webhook string, desktop bool, sourceURLs []string, targetURL string) *copyNotifier {
+	if webhook == "" && !desktop {
+		return nil
+	}
+	return &copyNotifier{
+		webhook: webhook,
+		desktop: desktop,
+		sources: sourceURLs,
+		target:  targetURL,
+		start:   time.Now(),
+	}
+}
+
+// done counts a copied object of size bytes, or a failed copy.
+func (n *copyNotifier) done(size int64, err *probe.Error) {
+	if err != nil {
+		if n.errors == 0 {
+			n.firstErr = err.ToGoError().Error()
+		}
+		n.errors++
+		return
+	}
+	n.bytes += size
+	n.objects++
+}
+
+// summary - returns the notification of the copy finished at end,
+// failed is set for copies which stopped before copying all objects.
+func (n *copyNotifier) summary(end time.Time, failed string) copyNotification {
+	msg := copyNotification{
+		Version:  copyNotificationVersion,
+		Event:    "cp.finished",
+		Status:   "success",
+		Sources:  n.sources,
+		Target:   n.target,
+		Objects:  n.objects,
+		Bytes:    n.bytes,
+		Errors:   n.errors,
+		Error:    n.firstErr,
+		Start:    n.start.UTC(),
+		End:      end.UTC(),
+		Duration: end.Sub(n.start).Seconds(),
+	}
+	if failed != "" {
+		msg.Error = failed
+	}
+	if msg.Errors > 0 || msg.Error != "" {
+		msg.Status = "failure"
+	}
+	return msg
+}
+
+// notify - sends the notifications of the finished copy, failing to
+// notify is reported but does not fail the copy.
+func (n *copyNotifier) notify(failed string) {
+	msg := n.summary(time.Now(), failed)
+	ctx, cancel := context.WithTimeout(context.Background(), copyNotificationTimeout)
+	defer cancel()
+	if n.webhook != "" {
+		errorIf(postCopyNotification(ctx, n.webhook, msg).Trace(n.webhook), "Unable to notify the webhook of the finished copy.")
+	}
+	if n.desktop {
+		errorIf(showDesktopNotification(ctx, msg), "Unable to show a desktop notification of the finished copy.")
+	}
+}
+
+// isWebhookURL returns true for an absolute http or https URL.
+func isWebhookURL(webhook string) bool {
+	u, e := url.Parse(webhook)
+	return e == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// postCopyNotification - posts msg as JSON to the webhook.
+func postCopyNotification(ctx context.Context, webhook string, msg copyNotification) *probe.Error {
+	body, e := json.Marshal(msg)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	req, e := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if e != nil {
+		return probe.NewError(e)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MinIO mc/"+ReleaseTag)
+	client := http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				RootCAs:            globalRootCAs,
+				InsecureSkipVerify: globalInsecure,
+			},
+		},
+	}
+	resp, e := client.Do(req)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return probe.NewError(fmt.Errorf("webhook responded with %s", resp.Status))
+	}
+	return nil
+}
+
+// showDesktopNotification - shows msg with the notification service of
+// the desktop, notify-send on Linux and the Notification Center on macOS.
+func showDesktopNotification(ctx context.Context, msg copyNotification) *probe.Error {
+	title := "mc cp finished"
+	if msg.Status != "success" {
+		title = "mc cp failed"
+	}
+	text := fmt.Sprintf("Copied %d object(s), %s to %s in %s.", msg.Objects,
+		humanize.IBytes(uint64(msg.Bytes)), msg.Target,
+		time.Duration(msg.Duration*float64(time.Second)).Round(time.Second))
+	if msg.Status != "success" {
+		text += fmt.Sprintf(" %d object(s) failed: %s", msg.Errors, msg.Error)
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "notify-send", title, text)
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(text), appleScriptString(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	default:
+		return probe.NewError(APINotImplemented{API: "desktop notifications", APIType: runtime.GOOS})
+	}
+	if out, e := cmd.CombinedOutput(); e != nil {
+		if len(out) > 0 {
+			e = fmt.Errorf("%w: %s", e, strings.TrimSpace(string(out)))
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// appleScriptString - quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// copyPackSize is the size at which the memory buffer of a pack object
+// of 'cp --pack-small' is uploaded.
+const copyPackSize = 64 * humanize.MiByte
+
+// copyPackFolder is the folder below the target prefix holding the pack
+// objects of 'cp --pack-small' and their indexes.
+const copyPackFolder = ".mc-pack/"
+
+// copyPackIndexSuffix is appended to the key of a pack object to name
+// its index.
+const copyPackIndexSuffix = ".index.json"
+
+// copyPackEntry locates a packed file in its pack object.
+type copyPackEntry struct {
+	Path    string    `json:"path"`
+	Offset  int64     `json:"offset"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// copyPackIndex maps the paths of the files of a pack object, relative
+// to the target prefix, to their offsets within it.
+type copyPackIndex struct {
+	Version string          `json:"version"`
+	Pack    string          `json:"pack"`
+	Entries []copyPackEntry `json:"entries"`
+}
+
+// copyPacker groups the small files of a recursive copy into tar pack
+// objects, buffered in memory, each uploaded with its index.
+type copyPacker struct {
+	targetURL string
+	prefix    string
+	encKeyDB  map[string][]prefixSSEPair
+	runID     string
+	seq       int
+
+	buf   bytes.Buffer
+	tw    *tar.Writer
+	index copyPackIndex
+
+	packs   int
+	objects int64
+	errors  int64
+}
+
+// newCopyPacker - returns a packer for the sources copied to targetURL.
+func newCopyPacker(targetURL string, encKeyDB map[string][]prefixSSEPair) (*copyPacker, *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	prefix := filepath.ToSlash(clnt.GetURL().Path)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &copyPacker{
+		targetURL: strings.TrimSuffix(targetURL, "/") + "/",
+		prefix:    prefix,
+		encKeyDB:  encKeyDB,
+		runID:     strconv.FormatInt(time.Now().UnixNano(), 36),
+	}, nil
+}
+
+// packURL - returns the URL of the current pack object.
+func (p *copyPacker) packURL() string {
+	return p.targetURL + copyPackFolder + fmt.Sprintf("%s-%05d.tar", p.runID, p.seq)
+}
+
+// add - reads a small source into the current pack, uploading the pack
+// first when the source would grow it past copyPackSize.
+func (p *copyPacker) add(ctx context.Context, cpURLs URLs) *probe.Error {
+	source := cpURLs.SourceContent
+	relPath := strings.TrimPrefix(filepath.ToSlash(cpURLs.TargetContent.URL.Path), p.prefix)
+	if p.tw != nil && int64(p.buf.Len())+source.Size+3*512 > copyPackSize {
+		if err := p.flush(ctx); err != nil {
+			return err
+		}
+	}
+	if p.tw == nil {
+		p.seq++
+		p.buf.Reset()
+		p.tw = tar.NewWriter(&p.buf)
+		p.index = copyPackIndex{Version: "1", Pack: path.Base(p.packURL())}
+	}
+
+	sourcePath := filepath.ToSlash(filepath.Join(cpURLs.SourceAlias, source.URL.Path))
+	reader, _, err := getSourceStream(ctx, cpURLs.SourceAlias, source.URL.String(), getSourceOpts{
+		GetOptions: GetOptions{SSE: getSSE(sourcePath, p.encKeyDB[cpURLs.SourceAlias])},
+	})
+	if err != nil {
+		return err.Trace(source.URL.String())
+	}
+	defer reader.Close()
+
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     relPath,
+		Size:     source.Size,
+		Mode:     0o644,
+		ModTime:  source.Time,
+	}
+	if e := p.tw.WriteHeader(hdr); e != nil {
+		return probe.NewError(e).Trace(source.URL.String())
+	}
+	// The header was written to the buffer, the data follows it.
+	offset := int64(p.buf.Len())
+	if _, e := io.CopyN(p.tw, reader, source.Size); e != nil {
+		return probe.NewError(e).Trace(source.URL.String())
+	}
+	p.index.Entries = append(p.index.Entries, copyPackEntry{
+		Path:    relPath,
+		Offset:  offset,
+		Size:    source.Size,
+		ModTime: source.Time,
+	})
+	p.objects++
+	return nil
+}
+
+// flush - uploads the current pack object, then its index.
+func (p *copyPacker) flush(ctx context.Context) *probe.Error {
+	if p.tw == nil {
+		return nil
+	}
+	defer func() { p.tw = nil }()
+	if e := p.tw.Close(); e != nil {
+		return probe.NewError(e)
+	}
+	packURL := p.packURL()
+	if err := p.put(ctx, packURL, p.buf.Bytes(), "application/x-tar"); err != nil {
+		return err.Trace(packURL)
+	}
+	indexBytes, e := json.Marshal(p.index)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if err := p.put(ctx, packURL+copyPackIndexSuffix, indexBytes, "application/json"); err != nil {
+		return err.Trace(packURL + copyPackIndexSuffix)
+	}
+	p.packs++
+	return nil
+}
+
+// put - uploads data to objectURL.
+func (p *copyPacker) put(ctx context.Context, objectURL string, data []byte, contentType string) *probe.Error {
+	alias, urlStr, _, err := expandAlias(objectURL)
+	if err != nil {
+		return err
+	}
+	_, err = putTargetStream(ctx, alias, urlStr, "", "", "", bytes.NewReader(data), int64(len(data)), nil, PutOptions{
+		metadata: map[string]string{"Content-Type": contentType},
+		sse:      getSSE(objectURL, p.encKeyDB[alias]),
+	})
+	return err
+}
+
+// doCopyUnpack restores the files packed by 'cp --pack-small' below the
+// source prefix to the target prefix, reading each pack object once and
+// slicing its files out at the offsets of its index.
+func doCopyUnpack(ctx context.Context, sourceURL, targetURL string, encKeyDB map[string][]prefixSSEPair) error {
+	packsURL := strings.TrimSuffix(sourceURL, "/") + "/" + copyPackFolder
+	targetURL = strings.TrimSuffix(targetURL, "/") + "/"
+
+	clnt, err := newClient(packsURL)
+	fatalIf(err.Trace(packsURL), "Unable to initialize `"+packsURL+"`.")
+
+	var totalCount, totalSize int64
+	var retErr error
+	for content := range clnt.List(ctx, ListOptions{ShowDir: DirNone}) {
+		if content.Err != nil {
+			fatalIf(content.Err.Trace(packsURL), "Unable to list the packs of `"+sourceURL+"`.")
+		}
+		name := path.Base(filepath.ToSlash(content.URL.Path))
+		if !strings.HasSuffix(name, copyPackIndexSuffix) {
+			continue
+		}
+		indexURL := packsURL + name
+		index, err := readCopyPackIndex(ctx, indexURL, encKeyDB)
+		if err != nil {
+			errorIf(err.Trace(indexURL), "Unable to read the pack index `"+indexURL+"`.")
+			retErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		packURL := packsURL + index.Pack
+		pack, err := readCopyPackObject(ctx, packURL, encKeyDB)
+		if err != nil {
+			errorIf(err.Trace(packURL), "Unable to read the pack `"+packURL+"`.")
+			retErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		for _, entry := range index.Entries {
+			if entry.Offset < 0 || entry.Size < 0 || entry.Offset+entry.Size > int64(len(pack)) {
+				errorIf(errInvalidArgument().Trace(packURL, entry.Path), "Invalid offset of `"+entry.Path+"` in the pack index `"+indexURL+"`.")
+				retErr = exitStatus(globalErrorExitStatus)
+				continue
+			}
+			objectURL := targetURL + strings.TrimPrefix(path.Clean("/"+entry.Path), "/")
+			alias, urlStr, _, err := expandAlias(objectURL)
+			if err == nil {
+				_, err = putTargetStream(ctx, alias, urlStr, "", "", "", bytes.NewReader(pack[entry.Offset:entry.Offset+entry.Size]), entry.Size, nil, PutOptions{
+					metadata: map[string]string{"Content-Type": guessURLContentType(objectURL)},
+					sse:      getSSE(objectURL, encKeyDB[alias]),
+				})
+			}
+			if err != nil {
+				errorIf(err.Trace(packURL, objectURL), "Unable to restore `"+entry.Path+"` to `"+objectURL+"`.")
+				retErr = exitStatus(globalErrorExitStatus)
+				continue
+			}
+			totalCount++
+			totalSize += entry.Size
+			printMsg(copyMessage{
+				Source:     packURL + ":" + entry.Path,
+				Target:     objectURL,
+				Size:       entry.Size,
+				TotalCount: totalCount,
+				TotalSize:  totalSize,
+			})
+		}
+	}
+	return retErr
+}
+
+// readCopyPackIndex - reads the index of a pack object.
+func readCopyPackIndex(ctx context.Context, indexURL string, encKeyDB map[string][]prefixSSEPair) (index copyPackIndex, err *probe.Error) {
+	data, err := readCopyPackObject(ctx, indexURL, encKeyDB)
+	if err != nil {
+		return index, err
+	}
+	if e := json.Unmarshal(data, &index); e != nil {
+		return index, probe.NewError(e)
+	}
+	if index.Version != "1" || index.Pack == "" || strings.Contains(index.Pack, "/") {
+		return index, errInvalidArgument().Trace(indexURL)
+	}
+	return index, nil
+}
+
+// readCopyPackObject - reads a pack object or an index in memory.
+func readCopyPackObject(ctx context.Context, objectURL string, encKeyDB map[string][]prefixSSEPair) ([]byte, *probe.Error) {
+	reader, err := getSourceStreamFromURL(ctx, objectURL, encKeyDB, getSourceOpts{})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, e := io.ReadAll(io.LimitReader(reader, 2*copyPackSize))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return data, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// isPresignedURL returns true for http(s) URLs carrying a query string
+// signature, of S3 signature V4 or V2.
+func isPresignedURL(urlStr string) bool {
+	u, e := url.Parse(urlStr)
+	if e != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	q := u.Query()
+	return q.Get("X-Amz-Signature") != "" || (q.Get("Signature") != "" && q.Get("AWSAccessKeyId") != "")
+}
+
+// presignedDisplayURL returns a presigned URL without its query string,
+// to not print the signature.
+func presignedDisplayURL(urlStr string) string {
+	if i := strings.IndexByte(urlStr, '?'); i >= 0 {
+		return urlStr[:i]
+	}
+	return urlStr
+}
+
+// getPresignedObject - sends a GET request to a presigned URL. The URL,
+// and so its query string signature, is sent exactly as given.
+func getPresignedObject(ctx context.Context, urlStr string) (*http.Response, *probe.Error) {
+	displayURL := presignedDisplayURL(urlStr)
+	req, e := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(displayURL)
+	}
+	clnt := &http.Client{Transport: getTransportForConfig(NewS3Config("", urlStr, nil), false)}
+	resp, e := clnt.Do(req)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(displayURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, probe.NewError(fmt.Errorf("unexpected response `%s`", resp.Status)).Trace(displayURL)
+	}
+	return resp, nil
+}
+
+// doCopyPresigned - streams the object a presigned GET URL points at into
+// the target, a target folder gets the object under its name.
+func doCopyPresigned(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair, userMetaMap map[string]string) error {
+	sourceURL := cliCtx.Args().Get(0)
+	targetURL := cliCtx.Args().Get(1)
+	displayURL := presignedDisplayURL(sourceURL)
+
+	if !strings.HasSuffix(targetURL, "/") {
+		if clnt, err := newClient(targetURL); err == nil {
+			if st, err := clnt.Stat(ctx, StatOptions{}); err == nil && st.Type.IsDir() {
+				targetURL += "/"
+			}
+		}
+	}
+	if strings.HasSuffix(targetURL, "/") {
+		u, _ := url.Parse(sourceURL)
+		targetURL += path.Base(u.Path)
+	}
+
+	resp, err := getPresignedObject(ctx, sourceURL)
+	fatalIf(err, "Unable to read from `"+displayURL+"`.")
+	defer resp.Body.Close()
+
+	metadata := make(map[string]string, len(userMetaMap)+1)
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		metadata["Content-Type"] = contentType
+	}
+	for k, v := range userMetaMap {
+		metadata[k] = v
+	}
+
+	alias, urlStr, _, err := expandAlias(targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+
+	var reader io.Reader = resp.Body
+	size := resp.ContentLength
+	if size >= 0 {
+		reader = io.LimitReader(resp.Body, size)
+	}
+	n, err := putTargetStream(ctx, alias, urlStr, "", "", "", reader, size, nil, PutOptions{
+		metadata:     metadata,
+		sse:          getSSE(targetURL, encKeyDB[alias]),
+		storageClass: cliCtx.String("storage-class"),
+	})
+	fatalIf(err.Trace(displayURL, targetURL), "Unable to copy `"+displayURL+"` to `"+targetURL+"`.")
+
+	printMsg(copyMessage{
+		Source:     displayURL,
+		Target:     targetURL,
+		Size:       n,
+		TotalCount: 1,
+		TotalSize:  n,
+	})
+	return nil
+}
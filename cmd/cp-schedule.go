@@ -0,0 +1,100 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// copyClockLayouts are the layouts of --start-at and --deadline which
+// refer to the next occurrence of a time of day.
+var copyClockLayouts = []string{"15:04", "15:04:05"}
+
+// copyDateLayouts are the layouts of --start-at and --deadline which
+// refer to a date and time, in the local time zone unless given.
+var copyDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// parseCopyTime parses the value of --start-at or --deadline, a time of
+// day refers to its first occurrence after ref.
+func parseCopyTime(value string, ref time.Time) (time.Time, *probe.Error) {
+	for _, layout := range copyClockLayouts {
+		clock, e := time.ParseInLocation(layout, value, time.Local)
+		if e != nil {
+			continue
+		}
+		t := time.Date(ref.Year(), ref.Month(), ref.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, time.Local)
+		if !t.After(ref) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	for _, layout := range copyDateLayouts {
+		if t, e := time.ParseInLocation(layout, value, time.Local); e == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, probe.NewError(fmt.Errorf("unsupported time %q, use HH:MM, HH:MM:SS or YYYY-MM-DDTHH:MM[:SS]", value))
+}
+
+// parseCopySchedule parses --start-at and --deadline, the time of day of
+// the deadline refers to its first occurrence after the start.
+func parseCopySchedule(startAtValue, deadlineValue string) (startAt, deadline time.Time, err *probe.Error) {
+	now := time.Now()
+	if startAtValue != "" {
+		if startAt, err = parseCopyTime(startAtValue, now); err != nil {
+			return startAt, deadline, err.Trace(startAtValue)
+		}
+	}
+	if deadlineValue != "" {
+		ref := now
+		if startAt.After(now) {
+			ref = startAt
+		}
+		if deadline, err = parseCopyTime(deadlineValue, ref); err != nil {
+			return startAt, deadline, err.Trace(deadlineValue)
+		}
+		if !deadline.After(ref) {
+			return startAt, deadline, errInvalidArgument().Trace(deadlineValue)
+		}
+	}
+	return startAt, deadline, nil
+}
+
+// waitCopyStart blocks until startAt, or until ctx is canceled.
+func waitCopyStart(ctx context.Context, startAt time.Time) *probe.Error {
+	wait := time.Until(startAt)
+	if wait <= 0 {
+		return nil
+	}
+	if !globalQuiet && !globalJSON {
+		console.Infof("Waiting until %s to start copying, in %s.\n", startAt.Format(printDate), timeDurationToHumanizedDuration(wait).StringShort())
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return probe.NewError(ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
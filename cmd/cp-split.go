@@ -0,0 +1,123 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+const (
+	// splitPartFormat names the parts of a split object after its key.
+	splitPartFormat = "%s.part%04d"
+	// splitManifestSuffix is appended to the key of a split object
+	// to name its manifest.
+	splitManifestSuffix = ".manifest"
+)
+
+// splitPart describes one part of a split object.
+type splitPart struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// splitManifest describes an object split by 'cp --split' into parts
+// no larger than PartSize, in order.
+type splitManifest struct {
+	Version  int         `json:"version"`
+	Key      string      `json:"key"`
+	Size     int64       `json:"size"`
+	PartSize int64       `json:"partSize"`
+	Parts    []splitPart `json:"parts"`
+}
+
+// splitSourceToTargetURL copies the source object as numbered parts of
+// at most urls.MaxObjectSize bytes, each read with a ranged read, and
+// writes a manifest recording their sizes and SHA-256 sums last.
+func splitSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader, srcSSE, tgtSSE encrypt.ServerSide) URLs {
+	sourceURL := urls.SourceContent.URL.String()
+	targetURL := urls.TargetContent.URL.String()
+	length := urls.SourceContent.Size
+	partSize := urls.MaxObjectSize
+
+	manifest := splitManifest{
+		Version:  1,
+		Key:      path.Base(filepath.ToSlash(urls.TargetContent.URL.Path)),
+		Size:     length,
+		PartSize: partSize,
+	}
+	for offset, partNumber := int64(0), 1; offset < length; offset, partNumber = offset+partSize, partNumber+1 {
+		size := partSize
+		if length-offset < size {
+			size = length - offset
+		}
+		reader, _, err := getSourceStream(ctx, urls.SourceAlias, sourceURL, getSourceOpts{
+			GetOptions: GetOptions{
+				VersionID:   urls.SourceContent.VersionID,
+				SSE:         srcSSE,
+				RangeStart:  offset,
+				RangeLength: size,
+			},
+		})
+		if err != nil {
+			return urls.WithError(err.Trace(sourceURL))
+		}
+
+		hash := sha256.New()
+		partURL := fmt.Sprintf(splitPartFormat, targetURL, partNumber)
+		_, err = putTargetStream(ctx, urls.TargetAlias, partURL, "", "", "",
+			io.TeeReader(io.LimitReader(reader, size), hash), size, progress, PutOptions{
+				metadata:           map[string]string{},
+				sse:                tgtSSE,
+				storageClass:       urls.TargetContent.StorageClass,
+				disableMultipart:   urls.DisableMultipart,
+				multipartThreshold: urls.MultipartThreshold,
+			})
+		reader.Close()
+		if err != nil {
+			return urls.WithError(err.Trace(sourceURL, partURL))
+		}
+		manifest.Parts = append(manifest.Parts, splitPart{
+			Key:    fmt.Sprintf(splitPartFormat, manifest.Key, partNumber),
+			Size:   size,
+			SHA256: hex.EncodeToString(hash.Sum(nil)),
+		})
+	}
+
+	manifestBytes, e := json.MarshalIndent(manifest, "", " ")
+	if e != nil {
+		return urls.WithError(probe.NewError(e))
+	}
+	manifestURL := targetURL + splitManifestSuffix
+	if _, err := putTargetStream(ctx, urls.TargetAlias, manifestURL, "", "", "", bytes.NewReader(manifestBytes),
+		int64(len(manifestBytes)), nil, PutOptions{metadata: map[string]string{"Content-Type": "application/json"}, sse: tgtSSE}); err != nil {
+		return urls.WithError(err.Trace(manifestURL))
+	}
+	return urls.WithError(nil)
+}
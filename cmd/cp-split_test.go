@@ -0,0 +1,95 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Test an object split in parts, the last one shorter, is reassembled
+// from its manifest and a corrupted part is detected.
+func TestSplitReassemble(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	source, target := filepath.Join(dir, "source"), filepath.Join(dir, "target", "disk.img")
+	data := make([]byte, 2500)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	if e := os.WriteFile(source, data, 0o644); e != nil {
+		t.Fatal(e)
+	}
+
+	urls := splitSourceToTargetURL(context.Background(), URLs{
+		SourceContent: &ClientContent{URL: *newClientURL(source), Size: int64(len(data))},
+		TargetContent: &ClientContent{URL: *newClientURL(target)},
+		MaxObjectSize: 1000,
+		Split:         true,
+	}, nil, nil, nil)
+	if urls.Error != nil {
+		t.Fatal(urls.Error)
+	}
+
+	manifestBytes, e := os.ReadFile(target + splitManifestSuffix)
+	if e != nil {
+		t.Fatal(e)
+	}
+	var manifest splitManifest
+	if e = json.Unmarshal(manifestBytes, &manifest); e != nil {
+		t.Fatal(e)
+	}
+	if manifest.Key != "disk.img" || manifest.Size != 2500 || manifest.PartSize != 1000 || len(manifest.Parts) != 3 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	for i, size := range []int64{1000, 1000, 500} {
+		part := manifest.Parts[i]
+		st, e := os.Stat(filepath.Join(dir, "target", part.Key))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if part.Size != size || st.Size() != size {
+			t.Errorf("expected part %d of %d bytes, the manifest records %d and %d were written", i+1, size, part.Size, st.Size())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := catSplitManifest(context.Background(), target+splitManifestSuffix, nil, catOpts{stdout: &out}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("expected the reassembled object to match the source, got %d bytes", out.Len())
+	}
+
+	// The last part is corrupted.
+	if e = os.WriteFile(filepath.Join(dir, "target", manifest.Parts[2].Key), make([]byte, 500), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	out.Reset()
+	if err := catSplitManifest(context.Background(), target+splitManifestSuffix, nil, catOpts{stdout: &out}); err == nil {
+		t.Fatal("expected the corrupted part to be detected")
+	}
+}
@@ -20,8 +20,13 @@ package cmd
 import (
 	"fmt"
 	"runtime"
+	"strconv"
+	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
 )
 
 func checkCopySyntax(cliCtx *cli.Context) {
@@ -44,6 +49,19 @@ func checkCopySyntax(cliCtx *cli.Context) {
 		fatalIf(errDummy().Trace(cliCtx.Args()...), "Unable to pass --version flag with multiple copy sources arguments.")
 	}
 
+	if isPresignedURL(srcURLs[0]) {
+		if len(srcURLs) != 1 {
+			fatalIf(errInvalidArgument().Trace(), "A presigned URL can only be copied as a single source.")
+		}
+		if cliCtx.Bool("recursive") || isZip || versionID != "" || cliCtx.String("rewind") != "" || cliCtx.Bool("extract") || cliCtx.Bool("remove-source") {
+			fatalIf(errInvalidArgument().Trace(), "A presigned URL source cannot be used with --recursive, --zip, --version-id, --rewind, --extract or --remove-source.")
+		}
+	}
+
+	if globalDryRun && (isPresignedURL(srcURLs[0]) || cliCtx.Bool("extract") || cliCtx.Bool("fan-out") || cliCtx.Bool("unpack") || cliCtx.Bool("atomic-prefix") || cliCtx.Bool("pack-small")) {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--dry-run cannot be used with a presigned URL source, --extract, --fan-out, --unpack, --atomic-prefix or --pack-small.")
+	}
+
 	if isZip && cliCtx.String("rewind") != "" {
 		fatalIf(errDummy().Trace(cliCtx.Args()...), "--zip and --rewind cannot be used together")
 	}
@@ -64,6 +82,237 @@ func checkCopySyntax(cliCtx *cli.Context) {
 		fatalIf(errInvalidArgument().Trace(), fmt.Sprintf("Both object retention flags `--%s` and `--%s` are required.\n", rdFlag, rmFlag))
 	}
 
+	// Reject invalid retention before any object is written without it.
+	if mode := cliCtx.String(rmFlag); mode != "" {
+		if !minio.RetentionMode(strings.ToUpper(mode)).IsValid() {
+			fatalIf(errInvalidArgument().Trace(mode), "Invalid --retention-mode value, choose one of [governance, compliance].")
+		}
+		_, _, err := parseRetentionValidity(cliCtx.String(rdFlag))
+		fatalIf(err.Trace(cliCtx.String(rdFlag)), "Unable to parse --retention-duration.")
+	}
+	switch legalHold := strings.ToUpper(cliCtx.String(lhFlag)); minio.LegalHoldStatus(legalHold) {
+	case "", minio.LegalHoldEnabled, minio.LegalHoldDisabled:
+	default:
+		fatalIf(errInvalidArgument().Trace(legalHold), "Invalid --legal-hold value, choose one of [on, off].")
+	}
+
+	if threshold := cliCtx.String("multipart-threshold"); threshold != "" {
+		if cliCtx.Bool("disable-multipart") {
+			fatalIf(errInvalidArgument().Trace(), "--multipart-threshold and --disable-multipart cannot be used together.")
+		}
+		_, err := parseMultipartThreshold(threshold)
+		fatalIf(err.Trace(threshold), "Unable to parse --multipart-threshold.")
+	}
+
+	if maxObjectSize := cliCtx.String("max-object-size"); maxObjectSize != "" {
+		size, e := humanize.ParseBytes(maxObjectSize)
+		fatalIf(probe.NewError(e).Trace(maxObjectSize), "Unable to parse --max-object-size.")
+		if size == 0 {
+			fatalIf(errInvalidArgument().Trace(maxObjectSize), "--max-object-size must be greater than zero.")
+		}
+	} else if cliCtx.Bool("split") {
+		fatalIf(errInvalidArgument().Trace(), "--split requires --max-object-size.")
+	}
+
+	if cliCtx.Bool("delta") {
+		blockSize, e := humanize.ParseBytes(cliCtx.String("delta-block-size"))
+		fatalIf(probe.NewError(e).Trace(cliCtx.String("delta-block-size")), "Unable to parse --delta-block-size.")
+		if blockSize < minMultipartPartSize || blockSize > maxSinglePutSize {
+			fatalIf(errInvalidArgument().Trace(cliCtx.String("delta-block-size")), "--delta-block-size must be between 5MiB and 5GiB.")
+		}
+		if cliCtx.Bool("no-clobber") || cliCtx.Bool("disable-multipart") {
+			fatalIf(errInvalidArgument().Trace(), "--delta cannot be used with --no-clobber or --disable-multipart.")
+		}
+	}
+
+	if checksum := cliCtx.String("trailer-checksum"); checksum != "" {
+		_, err := parseTrailerChecksum(checksum)
+		fatalIf(err, "Invalid --trailer-checksum value, choose one of [crc32c, crc32, sha1, sha256].")
+		if cliCtx.Bool("md5") || cliCtx.Bool("disable-multipart") || cliCtx.Bool("no-clobber") || cliCtx.Bool("delta") {
+			fatalIf(errInvalidArgument().Trace(), "--trailer-checksum cannot be used with --md5, --disable-multipart, --no-clobber or --delta.")
+		}
+	}
+
+	if cliCtx.Bool("auto-compress") {
+		_, e := humanize.ParseBytes(cliCtx.String("auto-compress-min"))
+		fatalIf(probe.NewError(e).Trace(cliCtx.String("auto-compress-min")), "Unable to parse --auto-compress-min.")
+		if cliCtx.Bool("remove-source") || cliCtx.Bool("delta") {
+			fatalIf(errInvalidArgument().Trace(), "--auto-compress cannot be used with --remove-source or --delta.")
+		}
+	}
+
+	if mapFile := cliCtx.String("content-type-map"); mapFile != "" {
+		_, err := parseContentTypeMap(mapFile)
+		fatalIf(err.Trace(mapFile), "Unable to parse --content-type-map.")
+	}
+
+	if _, _, err := parseCopySchedule(cliCtx.String("start-at"), cliCtx.String("deadline")); err != nil {
+		fatalIf(err, "Invalid --start-at or --deadline.")
+	}
+
+	if manifestFile := cliCtx.String("deploy-manifest"); manifestFile != "" {
+		_, err := parseDeployManifest(manifestFile)
+		fatalIf(err.Trace(manifestFile), "Unable to parse --deploy-manifest.")
+	}
+
+	if mapFile := cliCtx.String("charset-map"); mapFile != "" {
+		_, err := parseExtensionMap(mapFile)
+		fatalIf(err.Trace(mapFile), "Unable to parse --charset-map.")
+	}
+
+	if requireTag := cliCtx.String("require-tag"); requireTag != "" {
+		_, err := parseRequireTags(requireTag)
+		fatalIf(err.Trace(requireTag), "Unable to parse --require-tag.")
+	}
+
+	if cliCtx.Bool("wait-consistent") && cliCtx.Duration("wait-consistent-timeout") <= 0 {
+		fatalIf(errInvalidArgument().Trace(), "--wait-consistent-timeout must be a positive duration.")
+	}
+
+	if cliCtx.IsSet("workers") && cliCtx.Int("workers") <= 0 {
+		fatalIf(errInvalidArgument().Trace(), "--workers must be a positive number.")
+	}
+
+	if cliCtx.Bool("extract") {
+		if len(srcURLs) != 1 {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--extract requires a single archive source.")
+		}
+		if archiveFormat(srcURLs[0]) == archiveUnknown {
+			fatalIf(errInvalidArgument().Trace(srcURLs[0]), "--extract only supports .tar, .tar.gz, .tgz and .zip archives.")
+		}
+		if cliCtx.Bool("recursive") || isZip {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--extract cannot be used with --recursive or --zip.")
+		}
+		if cliCtx.Int("extract-strip-components") < 0 {
+			fatalIf(errInvalidArgument().Trace(), "--extract-strip-components cannot be negative.")
+		}
+	} else if len(cliCtx.StringSlice("extract-include")) > 0 || cliCtx.IsSet("extract-strip-components") {
+		fatalIf(errInvalidArgument().Trace(), "--extract-include and --extract-strip-components require --extract.")
+	}
+
+	if cliCtx.Bool("remove-source") {
+		if isZip || versionID != "" || cliCtx.String("rewind") != "" {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--remove-source cannot be used with --zip, --version-id or --rewind.")
+		}
+		if cliCtx.Bool("split") || cliCtx.Bool("extract") {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--remove-source cannot be used with --split or --extract.")
+		}
+	}
+
+	if cliCtx.Bool("atomic-prefix") {
+		if alias, _, _ := mustExpandAlias(tgtURL); !cliCtx.Bool("recursive") || alias == "" {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--atomic-prefix requires --recursive and an object storage target.")
+		}
+		if cliCtx.Bool("continue") || cliCtx.Bool("remove-source") || cliCtx.Bool("extract") || cliCtx.String(rmFlag) != "" || cliCtx.String(lhFlag) != "" {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--atomic-prefix cannot be used with --continue, --remove-source, --extract, --retention-mode or --legal-hold.")
+		}
+	}
+
+	if cliCtx.Bool("pack-small") {
+		threshold, e := humanize.ParseBytes(cliCtx.String("pack-threshold"))
+		fatalIf(probe.NewError(e).Trace(cliCtx.String("pack-threshold")), "Unable to parse --pack-threshold.")
+		if threshold == 0 || threshold > copyPackSize {
+			fatalIf(errInvalidArgument().Trace(cliCtx.String("pack-threshold")), "--pack-threshold must be between 1B and 64MiB.")
+		}
+		if !cliCtx.Bool("recursive") {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--pack-small requires --recursive.")
+		}
+		if cliCtx.Bool("continue") || cliCtx.Bool("remove-source") || cliCtx.Bool("extract") || cliCtx.Bool("atomic-prefix") || isZip || cliCtx.String(rmFlag) != "" || cliCtx.String(lhFlag) != "" {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--pack-small cannot be used with --continue, --remove-source, --extract, --atomic-prefix, --zip, --retention-mode or --legal-hold.")
+		}
+	}
+
+	if cliCtx.Bool("content-addressed") {
+		if _, ok := copyContentHashes[strings.ToLower(cliCtx.String("content-hash"))]; !ok {
+			fatalIf(errInvalidArgument().Trace(cliCtx.String("content-hash")), "Invalid --content-hash value, choose one of [sha256, sha512, sha1, md5].")
+		}
+		if fanout := cliCtx.Int("content-fanout"); fanout < 0 || fanout > copyContentMaxFanout {
+			fatalIf(errInvalidArgument().Trace(strconv.Itoa(fanout)), "--content-fanout must be between 0 and 4.")
+		}
+		if cliCtx.String("content-manifest") == "" {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--content-addressed requires a --content-manifest name.")
+		}
+		if cliCtx.Bool("continue") || cliCtx.Bool("pack-small") || cliCtx.Bool("split") || cliCtx.Bool("extract") || cliCtx.Bool("atomic-prefix") || cliCtx.Bool("fan-out") || cliCtx.Bool("unpack") || isZip || globalDryRun {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--content-addressed cannot be used with --continue, --pack-small, --split, --extract, --atomic-prefix, --fan-out, --unpack, --zip or --dry-run.")
+		}
+	}
+
+	if cliCtx.Bool("fan-out") {
+		if len(URLs) < 3 {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--fan-out requires a source and at least two targets.")
+		}
+		if cliCtx.Bool("recursive") || cliCtx.Bool("continue") || cliCtx.Bool("remove-source") || cliCtx.Bool("extract") || cliCtx.Bool("unpack") || cliCtx.Bool("pack-small") || cliCtx.Bool("atomic-prefix") || isZip || isPresignedURL(srcURLs[0]) {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--fan-out cannot be used with --recursive, --continue, --remove-source, --extract, --unpack, --pack-small, --atomic-prefix, --zip or a presigned URL.")
+		}
+	}
+
+	if cliCtx.Bool("unpack") {
+		if len(srcURLs) != 1 {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--unpack requires a single source prefix.")
+		}
+		if cliCtx.Bool("pack-small") || cliCtx.Bool("extract") || cliCtx.Bool("continue") || cliCtx.Bool("remove-source") || isZip {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--unpack cannot be used with --pack-small, --extract, --continue, --remove-source or --zip.")
+		}
+	}
+
+	if cliCtx.Bool("preserve-acl") && (isZip || cliCtx.Bool("extract")) {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--preserve-acl cannot be used with --zip or --extract.")
+	}
+
+	if cliCtx.Bool("dereference") && cliCtx.Bool("no-dereference") {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--dereference and --no-dereference cannot be used together.")
+	}
+
+	switch directive := strings.ToUpper(cliCtx.String("metadata-directive")); directive {
+	case "", "COPY", "REPLACE":
+	default:
+		fatalIf(errInvalidArgument().Trace(directive), "Invalid --metadata-directive value, choose one of [COPY, REPLACE].")
+	}
+
+	if cliCtx.Duration("checkpoint-interval") < 0 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Duration("checkpoint-interval").String()), "Invalid --checkpoint-interval value, it cannot be negative.")
+	}
+
+	if cliCtx.Duration("metrics-interval") < 0 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Duration("metrics-interval").String()), "Invalid --metrics-interval value, it cannot be negative.")
+	}
+	if cliCtx.IsSet("metrics-interval") && cliCtx.String("metrics-file") == "" {
+		fatalIf(errInvalidArgument(), "--metrics-interval requires --metrics-file.")
+	}
+
+	if webhook := cliCtx.String("notify-webhook"); webhook != "" {
+		if !isWebhookURL(webhook) {
+			fatalIf(errInvalidArgument().Trace(webhook), "Invalid --notify-webhook value, it must be an http or https URL.")
+		}
+	}
+
+	if cliCtx.String("source-region") != "" {
+		for _, srcURL := range srcURLs {
+			if _, _, hostCfg := mustExpandAlias(srcURL); hostCfg == nil || isPresignedURL(srcURL) {
+				fatalIf(errInvalidArgument().Trace(srcURL), "--source-region requires sources on an alias.")
+			}
+		}
+	}
+	if cliCtx.String("dest-region") != "" {
+		if _, _, hostCfg := mustExpandAlias(tgtURL); hostCfg == nil {
+			fatalIf(errInvalidArgument().Trace(tgtURL), "--dest-region requires a target on an alias.")
+		}
+	}
+
+	if cliCtx.Bool("no-clobber") && (cliCtx.Bool("overwrite-dir-with-file") || cliCtx.Bool("overwrite-file-with-dir")) {
+		fatalIf(errInvalidArgument().Trace(), "--no-clobber cannot be used with --overwrite-dir-with-file or --overwrite-file-with-dir.")
+	}
+
+	if cliCtx.Int("max-key-length") < 0 {
+		fatalIf(errInvalidArgument().Trace(strconv.Itoa(cliCtx.Int("max-key-length"))), "Invalid --max-key-length value, it cannot be negative.")
+	}
+
+	switch normalizeKeys := cliCtx.String("normalize-keys"); normalizeKeys {
+	case "", "lower", "nfc":
+	default:
+		fatalIf(errInvalidArgument().Trace(normalizeKeys), "Invalid --normalize-keys value, choose one of [lower, nfc].")
+	}
+
 	// Preserve functionality not supported for windows
 	if cliCtx.Bool("preserve") && runtime.GOOS == "windows" {
 		fatalIf(errInvalidArgument().Trace(), "Permissions are not preserved on windows platform.")
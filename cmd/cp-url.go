@@ -19,11 +19,18 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+	"golang.org/x/text/unicode/norm"
 )
 
 type copyURLsType uint8
@@ -43,6 +50,13 @@ type copyURLsType uint8
 //   copy(d..., f)
 //   copy([](f|d)..., f)
 
+//   * TARGET RULES
+//   =========================
+//   A target ending with a separator is always a folder: A becomes B,
+//   and it is an error if a file of that name exists. A target without
+//   a trailing separator is a folder only if it already exists as one,
+//   otherwise it is the exact name of the copied file.
+
 const (
 	copyURLsTypeInvalid copyURLsType = iota
 	copyURLsTypeA
@@ -109,6 +123,24 @@ func guessCopyURLType(ctx context.Context, o prepareCopyURLsOpts) (*copyURLsCont
 	return cc, errInvalidArgument().Trace()
 }
 
+// checkTargetTrailingSeparator - a target ending with a separator
+// names a folder to copy into, fails if a file of that name exists
+// instead of copying below it or replacing it.
+func checkTargetTrailingSeparator(ctx context.Context, targetURL string, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	trimmedURL := strings.TrimRight(targetURL, "/"+string(filepath.Separator))
+	if trimmedURL == targetURL || trimmedURL == "" {
+		return nil
+	}
+	if alias, _, _ := mustExpandAlias(trimmedURL); alias == trimmedURL {
+		return nil
+	}
+	_, content, err := url2Stat(ctx, trimmedURL, "", false, encKeyDB, time.Time{}, false)
+	if err == nil && !content.Type.IsDir() {
+		return errTargetIsNotDir(targetURL).Trace(targetURL)
+	}
+	return nil
+}
+
 // SINGLE SOURCE - Type A: copy(f, f) -> copy(f, f)
 // prepareCopyURLsTypeA - prepares target and source clientURLs for copying.
 func prepareCopyURLsTypeA(ctx context.Context, cc copyURLsContent, o prepareCopyURLsOpts) URLs {
@@ -228,14 +260,14 @@ func prepareCopyURLsTypeC(ctx context.Context, cc copyURLsContent, o prepareCopy
 	go func(sourceClient Client, cc copyURLsContent, o prepareCopyURLsOpts, copyURLsCh chan URLs) {
 		defer close(copyURLsCh)
 
-		for sourceContent := range sourceClient.List(ctx, ListOptions{Recursive: o.isRecursive, TimeRef: o.timeRef, ShowDir: DirNone, ListZip: o.isZip}) {
+		for sourceContent := range sourceClient.List(ctx, ListOptions{Recursive: o.isRecursive, TimeRef: o.timeRef, ShowDir: DirNone, ListZip: o.isZip, Symlinks: o.symlinks}) {
 			if sourceContent.Err != nil {
 				// Listing failed.
 				copyURLsCh <- URLs{Error: sourceContent.Err.Trace(sourceClient.GetURL().String())}
 				continue
 			}
 
-			if !sourceContent.Type.IsRegular() {
+			if !sourceContent.Type.IsRegular() && sourceContent.Type&os.ModeSymlink == 0 {
 				// Source is not a regular file. Skip it for copy.
 				continue
 			}
@@ -244,7 +276,7 @@ func prepareCopyURLsTypeC(ctx context.Context, cc copyURLsContent, o prepareCopy
 			newCC := cc
 			newCC.sourceContent = sourceContent
 			// All OK.. We can proceed. Type B: source is a file, target is a folder and exists.
-			copyURLsCh <- makeCopyContentTypeC(newCC, sourceClient.GetURL())
+			copyURLsCh <- makeCopyContentTypeC(newCC, sourceClient.GetURL(), o.normalizeKeys)
 		}
 	}(c, cc, o, copyURLsCh)
 
@@ -252,19 +284,33 @@ func prepareCopyURLsTypeC(ctx context.Context, cc copyURLsContent, o prepareCopy
 }
 
 // makeCopyContentTypeC - CopyURLs content for copying.
-func makeCopyContentTypeC(cc copyURLsContent, sourceClientURL ClientURL) URLs {
+func makeCopyContentTypeC(cc copyURLsContent, sourceClientURL ClientURL, normalizeKeys string) URLs {
 	newSourceURL := cc.sourceContent.URL
-	pathSeparatorIndex := strings.LastIndex(sourceClientURL.Path, string(sourceClientURL.Separator))
+	// Compare '/' separated paths, Windows sources may be given with
+	// either separator and keys must never contain backslashes.
+	sourceClientPath := filepath.ToSlash(sourceClientURL.Path)
+	pathSeparatorIndex := strings.LastIndex(sourceClientPath, "/")
 	newSourceSuffix := filepath.ToSlash(newSourceURL.Path)
 	if pathSeparatorIndex > 1 {
-		sourcePrefix := filepath.ToSlash(sourceClientURL.Path[:pathSeparatorIndex])
-		newSourceSuffix = strings.TrimPrefix(newSourceSuffix, sourcePrefix)
+		newSourceSuffix = sourceKeySuffix(newSourceSuffix, sourceClientPath[:pathSeparatorIndex])
 	}
+	newSourceSuffix = normalizeKey(newSourceSuffix, normalizeKeys)
 	newTargetURL := urlJoinPath(cc.targetURL, newSourceSuffix)
 	cc.targetURL = newTargetURL
 	return makeCopyContentTypeA(cc)
 }
 
+// normalizeKey - transforms the relative target key according to --normalize-keys.
+func normalizeKey(key, normalizeKeys string) string {
+	switch normalizeKeys {
+	case "lower":
+		return strings.ToLower(key)
+	case "nfc":
+		return norm.NFC.String(key)
+	}
+	return key
+}
+
 // MULTI-SOURCE - Type D: copy([](f|d...), d) -> []B
 // prepareCopyURLsTypeE - prepares target and source clientURLs for copying.
 func prepareCopyURLsTypeD(ctx context.Context, cc copyURLsContent, o prepareCopyURLsOpts) <-chan URLs {
@@ -315,8 +361,18 @@ type prepareCopyURLsOpts struct {
 	timeRef              time.Time
 	versionID            string
 	isZip                bool
+	normalizeKeys        string
+	requireTags          map[string]string
+	workers              int
+	ifNotExists          bool
+	noOverwriteNewer     bool
+	symlinks             SymlinkOpt
+	maxKeyLength         int
 }
 
+// defaultMaxKeyLength is the longest object key accepted by Amazon S3.
+const defaultMaxKeyLength = 1024
+
 type copyURLsContent struct {
 	targetContent   *ClientContent
 	targetAlias     string
@@ -333,6 +389,10 @@ func prepareCopyURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
 	copyURLsCh := make(chan URLs)
 	go func(o prepareCopyURLsOpts) {
 		defer close(copyURLsCh)
+		if err := checkTargetTrailingSeparator(ctx, o.targetURL, o.encKeyDB); err != nil {
+			copyURLsCh <- URLs{Error: err}
+			return
+		}
 		copyURLsContent, err := guessCopyURLType(ctx, o)
 		if err != nil {
 			copyURLsCh <- URLs{Error: errUnableToGuess().Trace(o.sourceURLs...)}
@@ -360,6 +420,8 @@ func prepareCopyURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
 	finalCopyURLsCh := make(chan URLs)
 	go func() {
 		defer close(finalCopyURLsCh)
+		// Normalized target keys seen so far, mapped to their source.
+		normalizedTargets := make(map[string]string)
 		for cpURLs := range copyURLsCh {
 			if cpURLs.Error != nil {
 				finalCopyURLsCh <- cpURLs
@@ -375,9 +437,282 @@ func prepareCopyURLs(ctx context.Context, o prepareCopyURLsOpts) chan URLs {
 				continue
 			}
 
+			// Two distinct sources must not be normalized into the same target key
+			if o.normalizeKeys != "" {
+				sourceURL := cpURLs.SourceContent.URL.String()
+				targetURL := cpURLs.TargetContent.URL.String()
+				if prevSourceURL, ok := normalizedTargets[targetURL]; ok && prevSourceURL != sourceURL {
+					finalCopyURLsCh <- URLs{Error: errTargetKeyCollision(prevSourceURL, sourceURL, targetURL).Trace(sourceURL)}
+					continue
+				}
+				normalizedTargets[targetURL] = sourceURL
+			}
+
+			// Report keys too long for the target instead of failing mid transfer.
+			if err := checkTargetKeyLength(cpURLs, o.maxKeyLength); err != nil {
+				finalCopyURLsCh <- URLs{Error: err.Trace(cpURLs.SourceContent.URL.String())}
+				continue
+			}
+
 			finalCopyURLsCh <- cpURLs
 		}
 	}()
 
-	return finalCopyURLsCh
+	filteredCopyURLsCh := finalCopyURLsCh
+	if o.ifNotExists {
+		filteredCopyURLsCh = filterExistingCopyURLs(ctx, filteredCopyURLsCh, o)
+	}
+	if o.noOverwriteNewer {
+		filteredCopyURLsCh = filterNewerTargetCopyURLs(ctx, filteredCopyURLsCh, o)
+	}
+	if len(o.requireTags) > 0 {
+		return filterCopyURLsByTags(ctx, filteredCopyURLsCh, o)
+	}
+	return filteredCopyURLsCh
+}
+
+// checkTargetKeyLength - returns an error if the target object key is
+// longer than maxKeyLength bytes, local targets are not checked.
+func checkTargetKeyLength(cpURLs URLs, maxKeyLength int) *probe.Error {
+	if maxKeyLength <= 0 || cpURLs.TargetContent.URL.Type != objectStorage {
+		return nil
+	}
+	if _, key := url2BucketAndObject(&cpURLs.TargetContent.URL); len(key) > maxKeyLength {
+		return errKeyTooLong(cpURLs.TargetContent.URL.String(), len(key), maxKeyLength)
+	}
+	return nil
+}
+
+// maxExistingTargetKeys is the largest number of target keys listed by
+// --if-not-exists before falling back to a Stat per object.
+var maxExistingTargetKeys = 1000000
+
+// listExistingTargetKeys - lists the target of a recursive copy once and
+// returns the paths of the objects found, or false if the target is too
+// large or could not be listed.
+func listExistingTargetKeys(ctx context.Context, targetURL string) (map[string]struct{}, bool) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	contentCh := clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone})
+	defer func() {
+		cancel()
+		// Local listings ignore ctx, drain them to let them end
+		// when returning early.
+		go func() {
+			for range contentCh {
+			}
+		}()
+	}()
+
+	existing := make(map[string]struct{})
+	for content := range contentCh {
+		if content.Err != nil {
+			switch content.Err.ToGoError().(type) {
+			case PathNotFound, ObjectMissing:
+				// Nothing copied yet.
+				continue
+			}
+			return nil, false
+		}
+		if len(existing) == maxExistingTargetKeys {
+			return nil, false
+		}
+		existing[filepath.ToSlash(content.URL.Path)] = struct{}{}
+	}
+	return existing, true
+}
+
+// filterExistingCopyURLs - skips the sources already present in the
+// target. The target of a recursive copy is listed once into a set,
+// other copies and too large targets Stat each target object instead.
+func filterExistingCopyURLs(ctx context.Context, copyURLsCh <-chan URLs, o prepareCopyURLsOpts) chan URLs {
+	filteredCopyURLsCh := make(chan URLs)
+	go func() {
+		defer close(filteredCopyURLsCh)
+
+		var existing map[string]struct{}
+
+		listed := false
+		if o.isRecursive {
+			if existing, listed = listExistingTargetKeys(ctx, o.targetURL); !listed && !globalQuiet && !globalJSON {
+				console.Infof("[Warn] Unable to list `%s` in one go, checking each object with a Stat request instead.\n", o.targetURL)
+			}
+		}
+
+		var skipped int64
+		for cpURLs := range copyURLsCh {
+			if cpURLs.Error != nil {
+				filteredCopyURLsCh <- cpURLs
+				continue
+			}
+			targetURL := cpURLs.TargetContent.URL
+			if listed {
+				if _, ok := existing[filepath.ToSlash(targetURL.Path)]; ok {
+					skipped++
+					continue
+				}
+			} else if clnt, err := newClientFromAlias(cpURLs.TargetAlias, targetURL.String()); err == nil {
+				if _, err := clnt.Stat(ctx, StatOptions{}); err == nil {
+					skipped++
+					continue
+				}
+			}
+			filteredCopyURLsCh <- cpURLs
+		}
+
+		if skipped > 0 && !globalQuiet && !globalJSON {
+			console.Infof("Skipped %d object(s) already present in the target.\n", skipped)
+		}
+	}()
+	return filteredCopyURLsCh
+}
+
+// copySkipMessage - a source not copied, with the reason why.
+type copySkipMessage struct {
+	Status string `json:"status"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+func (s copySkipMessage) String() string {
+	return console.Colorize("Skip", fmt.Sprintf("Skipped `%s`, %s.", s.Source, s.Reason))
+}
+
+func (s copySkipMessage) JSON() string {
+	s.Status = "skipped"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// isTargetNewer returns true if the target is strictly newer than the source.
+func isTargetNewer(source, target *ClientContent) bool {
+	return target != nil && target.Time.After(source.Time)
+}
+
+// filterNewerTargetCopyURLs - skips the sources whose target exists and
+// was modified after them, Stat-ing the targets with o.workers in parallel.
+func filterNewerTargetCopyURLs(ctx context.Context, copyURLsCh <-chan URLs, o prepareCopyURLsOpts) chan URLs {
+	filteredCopyURLsCh := make(chan URLs)
+
+	workers := o.workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cpURLs := range copyURLsCh {
+				if cpURLs.Error != nil {
+					filteredCopyURLsCh <- cpURLs
+					continue
+				}
+
+				targetURL := cpURLs.TargetContent.URL.String()
+				clnt, err := newClientFromAlias(cpURLs.TargetAlias, targetURL)
+				if err != nil {
+					filteredCopyURLsCh <- URLs{Error: err.Trace(targetURL)}
+					continue
+				}
+				// Missing targets are copied, other errors are met again by the copy.
+				target, err := clnt.Stat(ctx, StatOptions{})
+				if err == nil && isTargetNewer(cpURLs.SourceContent, target) {
+					if !globalQuiet && !globalJSON {
+						console.Eraseline()
+					}
+					printMsg(copySkipMessage{
+						Source: cpURLs.SourceContent.URL.String(),
+						Target: targetURL,
+						Reason: fmt.Sprintf("target `%s` modified %s is newer than the source modified %s",
+							targetURL, target.Time.Format(printDate), cpURLs.SourceContent.Time.Format(printDate)),
+					})
+					continue
+				}
+				filteredCopyURLsCh <- cpURLs
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(filteredCopyURLsCh)
+	}()
+
+	return filteredCopyURLsCh
+}
+
+// filterCopyURLsByTags - only lets through the source objects carrying all
+// the tags required by --require-tag, fetching the tags with o.workers in parallel.
+// Local files carry no tags, they are skipped without fetching them.
+func filterCopyURLsByTags(ctx context.Context, copyURLsCh <-chan URLs, o prepareCopyURLsOpts) chan URLs {
+	filteredCopyURLsCh := make(chan URLs)
+
+	workers := o.workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var skipped int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cpURLs := range copyURLsCh {
+				if cpURLs.Error != nil {
+					filteredCopyURLsCh <- cpURLs
+					continue
+				}
+				if cpURLs.SourceContent.URL.Type == fileSystem {
+					atomic.AddInt64(&skipped, 1)
+					continue
+				}
+
+				sourceURL := cpURLs.SourceContent.URL.String()
+				clnt, err := newClientFromAlias(cpURLs.SourceAlias, sourceURL)
+				if err != nil {
+					filteredCopyURLsCh <- URLs{Error: err.Trace(sourceURL)}
+					continue
+				}
+				tagsMap, err := clnt.GetTags(ctx, cpURLs.SourceContent.VersionID)
+				if err != nil {
+					filteredCopyURLsCh <- URLs{Error: err.Trace(sourceURL)}
+					continue
+				}
+				if !hasRequiredTags(tagsMap, o.requireTags) {
+					atomic.AddInt64(&skipped, 1)
+					continue
+				}
+				filteredCopyURLsCh <- cpURLs
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		if skipped := atomic.LoadInt64(&skipped); skipped > 0 && !globalQuiet && !globalJSON {
+			console.Infof("Skipped %d object(s) not carrying the tags required by --require-tag.\n", skipped)
+		}
+		close(filteredCopyURLsCh)
+	}()
+
+	return filteredCopyURLsCh
+}
+
+// hasRequiredTags - returns true if all required tags are present with the same value.
+func hasRequiredTags(tagsMap, requireTags map[string]string) bool {
+	for k, v := range requireTags {
+		if value, ok := tagsMap[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
 }
@@ -18,11 +18,16 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
@@ -32,7 +37,25 @@ import (
 
 // diff specific flags.
 var (
-	diffFlags = []cli.Flag{}
+	diffFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "sample",
+			Usage: "compare sampled ranges (head, middle, tail) of objects with equal size",
+		},
+		cli.StringFlag{
+			Name:  "sample-size",
+			Usage: "number of bytes read per sample",
+			Value: "64KiB",
+		},
+		cli.BoolFlag{
+			Name:  "emit-script",
+			Usage: "print a shell script of 'mc cp' and 'mc rm' commands that make TARGET match SOURCE",
+		},
+		cli.StringFlag{
+			Name:  "only",
+			Usage: "only show objects in a category, one of 'first', 'second' or 'changed'",
+		},
+	}
 )
 
 // Compute differences in object name, size, and date between two buckets.
@@ -53,12 +76,14 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
 DESCRIPTION:
-  Diff only calculates differences in object name, size and time. It *DOES NOT* compare objects' contents.
+  Diff only calculates differences in object name, size and time. It *DOES NOT* compare objects' contents,
+  unless --sample is specified to compare a few ranges read from objects with equal size.
 
 LEGEND:
   < - object is only in source.
   > - object is only in destination.
   ! - newer object is in source.
+  ~ - sampled content differs.
 
 EXAMPLES:
   1. Compare a local folder with a folder on Amazon S3 cloud storage.
@@ -66,6 +91,15 @@ EXAMPLES:
 
   2. Compare two folders on a local filesystem.
      {{.Prompt}} {{.HelpName}} ~/Photos /Media/Backup/Photos
+
+  3. Compare two buckets, also comparing 1MiB samples of objects with equal size.
+     {{.Prompt}} {{.HelpName}} --sample --sample-size 1MiB s3/mybucket play/mybucket
+
+  4. Write a script that syncs a backup bucket with its source, to be reviewed and run later.
+     {{.Prompt}} {{.HelpName}} --emit-script s3/mybucket play/backup > sync.sh
+
+  5. List objects missing from a backup bucket as JSON, one per line.
+     {{.Prompt}} {{.HelpName}} --only first --json s3/mybucket play/backup
 `,
 }
 
@@ -96,6 +130,8 @@ func (d diffMessage) String() string {
 		msg = console.Colorize("DiffMetadata", "! "+d.SecondURL)
 	case differInAASourceMTime:
 		msg = console.Colorize("DiffMMSourceMTime", "! "+d.SecondURL)
+	case differInContent:
+		msg = console.Colorize("DiffContent", "~ "+d.SecondURL)
 	case differInNone:
 		msg = console.Colorize("DiffInNone", "= "+d.FirstURL)
 	default:
@@ -123,6 +159,12 @@ func checkDiffSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Unable to validate empty argument.")
 		}
 	}
+	switch cliCtx.String("only") {
+	case "", "first", "second", "changed":
+	default:
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("only")), "--only must be one of 'first', 'second' or 'changed'.")
+	}
+
 	URLs := cliCtx.Args()
 	firstURL := URLs[0]
 	secondURL := URLs[1]
@@ -155,8 +197,137 @@ func checkDiffSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 	}
 }
 
+type diffOptions struct {
+	sampleSize int64
+	emitScript bool
+	only       string
+	encKeyDB   map[string][]prefixSSEPair
+}
+
+// shellSingleQuote wraps s in single quotes for a POSIX shell, unlike
+// shellQuote this also protects glob characters. Single quotes inside s
+// are closed, escaped and reopened.
+func shellSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellCommentQuote quotes s for a shell comment, control characters
+// like newlines are escaped to keep the comment on a single line.
+func shellCommentQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+// diffScriptHeader returns the header of a script emitted by
+// 'diff --emit-script'.
+func diffScriptHeader(source, target string) string {
+	return "#!/bin/sh\n" +
+		"# Generated by 'mc diff --emit-script' on " + UTCNow().Format(time.RFC3339) + "\n" +
+		"# Makes " + shellCommentQuote(target) + " match " + shellCommentQuote(source) + ", review before running.\n" +
+		"set -e\n"
+}
+
+// diffScriptLine returns the command fixing a single difference, source
+// and target are the aliased URLs the diff was started with, firstURL
+// and secondURL their expanded forms.
+func diffScriptLine(d diffMessage, source, target, firstURL, secondURL string) string {
+	src := source + strings.TrimPrefix(d.FirstURL, firstURL)
+	dst := target + strings.TrimPrefix(d.SecondURL, secondURL)
+	switch d.Diff {
+	case differInFirst:
+		dst = target + strings.TrimPrefix(d.FirstURL, firstURL)
+		return "mc cp " + shellSingleQuote(src) + " " + shellSingleQuote(dst)
+	case differInSecond:
+		return "mc rm " + shellSingleQuote(dst)
+	case differInSize, differInMetadata, differInAASourceMTime, differInContent:
+		return "mc cp " + shellSingleQuote(src) + " " + shellSingleQuote(dst)
+	case differInType:
+		return "# " + shellCommentQuote(src) + " and " + shellCommentQuote(dst) + " differ in type, resolve manually."
+	}
+	return ""
+}
+
+// sampleOffsets returns the offsets of the head, middle and tail
+// samples of an object, a single sample is enough for objects no larger
+// than a sample. Samples of objects up to three samples large overlap
+// and cover them in full.
+func sampleOffsets(size, sampleSize int64) []int64 {
+	if size <= sampleSize {
+		return []int64{0}
+	}
+	return []int64{0, (size - sampleSize) / 2, size - sampleSize}
+}
+
+// readSample reads a range of an object.
+func readSample(ctx context.Context, alias string, content *ClientContent, offset, length int64, encKeyDB map[string][]prefixSSEPair) ([]byte, *probe.Error) {
+	urlStr := content.URL.String()
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return nil, err.Trace(alias, urlStr)
+	}
+	sse := getSSE(filepath.ToSlash(filepath.Join(alias, content.URL.Path)), encKeyDB[alias])
+	reader, err := clnt.Get(ctx, GetOptions{
+		SSE:         sse,
+		VersionID:   content.VersionID,
+		RangeStart:  offset,
+		RangeLength: length,
+	})
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	defer reader.Close()
+
+	buf := make([]byte, length)
+	if _, e := io.ReadFull(reader, buf); e != nil {
+		return nil, probe.NewError(e).Trace(urlStr)
+	}
+	return buf, nil
+}
+
+// sampleDifference compares sampled ranges of two objects with equal size.
+func sampleDifference(ctx context.Context, firstAlias, secondAlias string, d diffMessage, opts diffOptions) diffMessage {
+	size := d.firstContent.Size
+	for _, offset := range sampleOffsets(size, opts.sampleSize) {
+		length := opts.sampleSize
+		if offset+length > size {
+			length = size - offset
+		}
+		if length <= 0 {
+			break
+		}
+		first, err := readSample(ctx, firstAlias, d.firstContent, offset, length, opts.encKeyDB)
+		if err != nil {
+			d.Error = err
+			return d
+		}
+		second, err := readSample(ctx, secondAlias, d.secondContent, offset, length, opts.encKeyDB)
+		if err != nil {
+			d.Error = err
+			return d
+		}
+		if !bytes.Equal(first, second) {
+			d.Diff = differInContent
+			return d
+		}
+	}
+	return d
+}
+
+// diffOnlyMatches reports whether a difference belongs to the category
+// selected by --only, an empty category matches every difference.
+func diffOnlyMatches(only string, diff differType) bool {
+	switch only {
+	case "first":
+		return diff == differInFirst
+	case "second":
+		return diff == differInSecond
+	case "changed":
+		return diff != differInNone && diff != differInFirst && diff != differInSecond
+	}
+	return true
+}
+
 // doDiffMain runs the diff.
-func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
+func doDiffMain(ctx context.Context, firstURL, secondURL string, opts diffOptions) error {
 	// Source and targets are always directories
 	sourceSeparator := string(newClientURL(firstURL).Separator)
 	if !strings.HasSuffix(firstURL, sourceSeparator) {
@@ -167,6 +338,8 @@ func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
 		secondURL = secondURL + targetSeparator
 	}
 
+	source, target := firstURL, secondURL
+
 	// Expand aliased urls.
 	firstAlias, firstURL, _ := mustExpandAlias(firstURL)
 	secondAlias, secondURL, _ := mustExpandAlias(secondURL)
@@ -183,13 +356,40 @@ func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
 			fmt.Sprintf("Failed to diff '%s' and '%s'", firstURL, secondURL))
 	}
 
+	if opts.emitScript {
+		console.Print(diffScriptHeader(source, target))
+	}
+
 	// Diff first and second urls.
-	for diffMsg := range objectDifference(ctx, firstClient, secondClient, true) {
+	isSample := opts.sampleSize > 0
+	for diffMsg := range objectDifference(ctx, firstClient, secondClient, true, isSample) {
 		if diffMsg.Error != nil {
 			errorIf(diffMsg.Error, "Unable to calculate objects difference.")
 			// Ignore error and proceed to next object.
 			continue
 		}
+		if diffMsg.Diff == differInNone {
+			if !diffMsg.firstContent.Type.IsRegular() {
+				continue
+			}
+			diffMsg = sampleDifference(ctx, firstAlias, secondAlias, diffMsg, opts)
+			if diffMsg.Error != nil {
+				errorIf(diffMsg.Error, "Unable to compare samples of `%s` and `%s`.", diffMsg.FirstURL, diffMsg.SecondURL)
+				continue
+			}
+			if diffMsg.Diff == differInNone {
+				continue
+			}
+		}
+		if !diffOnlyMatches(opts.only, diffMsg.Diff) {
+			continue
+		}
+		if opts.emitScript {
+			if line := diffScriptLine(diffMsg, source, target, firstURL, secondURL); line != "" {
+				console.Println(line)
+			}
+			continue
+		}
 		printMsg(diffMsg)
 	}
 
@@ -216,10 +416,21 @@ func mainDiff(cliCtx *cli.Context) error {
 	console.SetColor("DiffSize", color.New(color.FgYellow, color.Bold))
 	console.SetColor("DiffMetadata", color.New(color.FgYellow, color.Bold))
 	console.SetColor("DiffMMSourceMTime", color.New(color.FgYellow, color.Bold))
+	console.SetColor("DiffContent", color.New(color.FgYellow, color.Bold))
 
 	URLs := cliCtx.Args()
 	firstURL := URLs.Get(0)
 	secondURL := URLs.Get(1)
 
-	return doDiffMain(ctx, firstURL, secondURL)
+	opts := diffOptions{encKeyDB: encKeyDB, emitScript: cliCtx.Bool("emit-script"), only: cliCtx.String("only")}
+	if cliCtx.Bool("sample") {
+		sampleSize, e := humanize.ParseBytes(cliCtx.String("sample-size"))
+		fatalIf(probe.NewError(e), "Unable to parse --sample-size.")
+		if sampleSize == 0 {
+			fatalIf(errInvalidArgument().Trace(cliCtx.String("sample-size")), "--sample-size must be greater than zero.")
+		}
+		opts.sampleSize = int64(sampleSize)
+	}
+
+	return doDiffMain(ctx, firstURL, secondURL, opts)
 }
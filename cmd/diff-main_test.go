@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestSampleOffsets(t *testing.T) {
+	testCases := []struct {
+		size, sampleSize int64
+		offsets          []int64
+	}{
+		{0, 4, []int64{0}},
+		{3, 4, []int64{0}},
+		{4, 4, []int64{0}},
+		{5, 4, []int64{0, 0, 1}},
+		{8, 4, []int64{0, 2, 4}},
+		{12, 4, []int64{0, 4, 8}},
+		{20, 4, []int64{0, 8, 16}},
+	}
+	for _, tc := range testCases {
+		if got := sampleOffsets(tc.size, tc.sampleSize); !reflect.DeepEqual(got, tc.offsets) {
+			t.Errorf("size %d, sample size %d: expected %v, got %v", tc.size, tc.sampleSize, tc.offsets, got)
+		}
+	}
+}
+
+// Test the head, the middle and the tail of local files are compared.
+func TestSampleDifference(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+	root := t.TempDir()
+	content := func(name string, data []byte) *ClientContent {
+		path := filepath.Join(root, name)
+		if e := os.WriteFile(path, data, 0o644); e != nil {
+			t.Fatal(e)
+		}
+		return &ClientContent{URL: *newClientURL(path), Size: int64(len(data))}
+	}
+	data := []byte("0123456789abcdefghij")
+	testCases := []struct {
+		name      string
+		size      int
+		changedAt int
+		diff      differType
+	}{
+		{"same", 20, -1, differInNone},
+		{"head", 20, 1, differInContent},
+		{"middle", 20, 9, differInContent},
+		{"tail", 20, 19, differInContent},
+		{"unsampled", 20, 5, differInNone},
+		{"two-samples-tail", 8, 7, differInContent},
+		{"three-samples-middle", 12, 6, differInContent},
+	}
+	for _, tc := range testCases {
+		first := append([]byte{}, data[:tc.size]...)
+		second := append([]byte{}, first...)
+		if tc.changedAt >= 0 {
+			second[tc.changedAt] = '-'
+		}
+		d := diffMessage{
+			Diff:          differInNone,
+			firstContent:  content(tc.name+"-first", first),
+			secondContent: content(tc.name+"-second", second),
+		}
+		d = sampleDifference(context.Background(), "", "", d, diffOptions{sampleSize: 4})
+		if d.Error != nil {
+			t.Fatalf("%s: %v", tc.name, d.Error)
+		}
+		if d.Diff != tc.diff {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.diff, d.Diff)
+		}
+	}
+}
@@ -42,6 +42,7 @@ const (
 	differInFirst                    // only in source (FIRST)
 	differInSecond                   // only in target (SECOND)
 	differInAASourceMTime            // differs in active-active source modtime
+	differInContent                  // differs in sampled content
 )
 
 func (d differType) String() string {
@@ -60,6 +61,8 @@ func (d differType) String() string {
 		return "only-in-first"
 	case differInSecond:
 		return "only-in-second"
+	case differInContent:
+		return "content"
 	}
 	return "unknown"
 }
@@ -161,14 +164,14 @@ func metadataEqual(m1, m2 map[string]string) bool {
 	return true
 }
 
-func objectDifference(ctx context.Context, sourceClnt, targetClnt Client, isMetadata bool) (diffCh chan diffMessage) {
+func objectDifference(ctx context.Context, sourceClnt, targetClnt Client, isMetadata, returnSimilar bool) (diffCh chan diffMessage) {
 	sourceURL := sourceClnt.GetURL().String()
 	sourceCh := sourceClnt.List(ctx, ListOptions{Recursive: true, WithMetadata: isMetadata, ShowDir: DirNone})
 
 	targetURL := targetClnt.GetURL().String()
 	targetCh := targetClnt.List(ctx, ListOptions{Recursive: true, WithMetadata: isMetadata, ShowDir: DirNone})
 
-	return difference(sourceURL, sourceCh, targetURL, targetCh, isMetadata, false)
+	return difference(sourceURL, sourceCh, targetURL, targetCh, isMetadata, returnSimilar)
 }
 
 func bucketDifference(ctx context.Context, sourceClnt, targetClnt Client) (diffCh chan diffMessage) {
@@ -315,6 +318,7 @@ func differenceInternal(sourceURL string, srcCh <-chan *ClientContent, targetURL
 				}
 				continue
 			}
+			differs := true
 			if srcSize != tgtSize {
 				// Regular files differing in size.
 				diffCh <- diffMessage{
@@ -344,10 +348,12 @@ func differenceInternal(sourceURL string, srcCh <-chan *ClientContent, targetURL
 					firstContent:  srcCtnt,
 					secondContent: tgtCtnt,
 				}
+			} else {
+				differs = false
 			}
 
 			// No differ
-			if returnSimilar {
+			if !differs && returnSimilar {
 				diffCh <- diffMessage{
 					FirstURL:      srcCtnt.URL.String(),
 					SecondURL:     tgtCtnt.URL.String(),
@@ -18,6 +18,10 @@
 package cmd
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -47,3 +51,81 @@ func TestExcludeOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestDiffScriptLine(t *testing.T) {
+	first, second := "/tmp/src/", "http://localhost:9000/bucket/"
+	testCases := []struct {
+		diff   diffMessage
+		expect string
+	}{
+		{diffMessage{FirstURL: first + "a/b c", Diff: differInFirst}, `mc cp 'src/a/b c' 'play/bucket/a/b c'`},
+		{diffMessage{SecondURL: second + "it's", Diff: differInSecond}, `mc rm 'play/bucket/it'\''s'`},
+		{diffMessage{FirstURL: first + "*", SecondURL: second + "*", Diff: differInSize}, `mc cp 'src/*' 'play/bucket/*'`},
+		{diffMessage{FirstURL: first + "x", SecondURL: second + "x", Diff: differInNone}, ""},
+	}
+	for i, testCase := range testCases {
+		line := diffScriptLine(testCase.diff, "src/", "play/bucket/", first, second)
+		if line != testCase.expect {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expect, line)
+		}
+	}
+}
+
+func TestDiffScriptInjection(t *testing.T) {
+	if _, e := exec.LookPath("sh"); e != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	// A fake mc recording its arguments, one per line.
+	fakeMc := "#!/bin/sh\nfor a in \"$@\"; do printf '%s\\0' \"$a\"; done >> " + shellSingleQuote(filepath.Join(dir, "args")) + "\n"
+	if e := os.WriteFile(filepath.Join(dir, "mc"), []byte(fakeMc), 0o755); e != nil {
+		t.Fatal(e)
+	}
+
+	pwned := filepath.Join(dir, "pwned")
+	key := "a\n$(touch " + pwned + ")\n`touch " + pwned + "`'; touch " + pwned + "; '"
+	first, second := "/tmp/src/", "http://localhost:9000/bucket/"
+	script := diffScriptHeader("src/\ntouch "+pwned+"\n", "play/bucket/") +
+		diffScriptLine(diffMessage{FirstURL: first + key, SecondURL: second + key, Diff: differInType}, "src/", "play/bucket/", first, second) + "\n" +
+		diffScriptLine(diffMessage{FirstURL: first + key, Diff: differInFirst}, "src/", "play/bucket/", first, second) + "\n"
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Env = append(os.Environ(), "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if out, e := cmd.CombinedOutput(); e != nil {
+		t.Fatalf("%v: %s", e, out)
+	}
+	if _, e := os.Stat(pwned); !os.IsNotExist(e) {
+		t.Fatal("a key ran a command of the script")
+	}
+	args, e := os.ReadFile(filepath.Join(dir, "args"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	expect := []string{"cp", "src/" + key, "play/bucket/" + key, ""}
+	if got := strings.Split(string(args), "\x00"); strings.Join(got, "|") != strings.Join(expect, "|") {
+		t.Fatalf("expected mc %q, got %q", expect, got)
+	}
+}
+
+func TestDiffOnlyMatches(t *testing.T) {
+	testCases := []struct {
+		only   string
+		diff   differType
+		expect bool
+	}{
+		{"", differInFirst, true},
+		{"", differInSize, true},
+		{"first", differInFirst, true},
+		{"first", differInSecond, false},
+		{"second", differInSecond, true},
+		{"second", differInContent, false},
+		{"changed", differInSize, true},
+		{"changed", differInType, true},
+		{"changed", differInFirst, false},
+		{"changed", differInNone, false},
+	}
+	for i, testCase := range testCases {
+		if got := diffOnlyMatches(testCase.only, testCase.diff); got != testCase.expect {
+			t.Errorf("Test %d: expected %t, got %t", i+1, testCase.expect, got)
+		}
+	}
+}
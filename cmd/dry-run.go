@@ -0,0 +1,126 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// dryRunMessage is printed in place of a mutating operation skipped
+// with --dry-run.
+type dryRunMessage struct {
+	Status string `json:"status"`
+	Action string `json:"action"`
+	Target string `json:"target"`
+	Source string `json:"source,omitempty"`
+	Value  string `json:"value,omitempty"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// String colorized dry run message.
+func (d dryRunMessage) String() string {
+	var msg string
+	switch {
+	case d.Source != "":
+		msg = fmt.Sprintf("Would %s `%s` to `%s`.", d.Action, d.Source, d.Target)
+	case d.Value != "":
+		msg = fmt.Sprintf("Would %s `%s` on `%s`.", d.Action, d.Value, d.Target)
+	default:
+		msg = fmt.Sprintf("Would %s `%s`.", d.Action, d.Target)
+	}
+	return console.Colorize("DryRun", msg)
+}
+
+// JSON jsonified dry run message.
+func (d dryRunMessage) JSON() string {
+	d.Status = "success"
+	d.DryRun = true
+	jsonMessageBytes, e := json.MarshalIndent(d, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// skipDryRun prints msg and returns true when --dry-run is set, callers
+// must then skip the operation described by msg.
+func skipDryRun(msg dryRunMessage) bool {
+	if !globalDryRun {
+		return false
+	}
+	printMsg(msg)
+	return true
+}
+
+// dryRunCommands are the commands honoring --dry-run by their full name,
+// the others refuse to run with it rather than make their changes.
+var dryRunCommands = map[string]bool{
+	"admin config reset":    true,
+	"admin config set":      true,
+	"admin heal":            true,
+	"admin service restart": true,
+	"admin update":          true,
+	"alias remove":          true,
+	"alias set":             true,
+	"anonymous":             true,
+	"cp":                    true,
+	"mirror":                true,
+	"mv":                    true,
+	"rb":                    true,
+	"resume clean":          true,
+	"resume gc":             true,
+	"rm":                    true,
+	"tag remove":            true,
+	"tag set":               true,
+	"undo":                  true,
+}
+
+// guardDryRun returns commands whose actions, but the ones of
+// dryRunCommands and of commands with subcommands, fail when --dry-run
+// is set.
+func guardDryRun(commands []cli.Command, parent string) []cli.Command {
+	guarded := make([]cli.Command, len(commands))
+	for i, command := range commands {
+		name := command.Name
+		if parent != "" {
+			name = parent + " " + name
+		}
+		if len(command.Subcommands) > 0 {
+			command.Subcommands = guardDryRun(command.Subcommands, name)
+		} else if !dryRunCommands[name] && command.Action != nil {
+			command.Action = dryRunUnsupported(name, command.Action)
+		}
+		guarded[i] = command
+	}
+	return guarded
+}
+
+// dryRunUnsupported wraps the action of a command not honoring --dry-run.
+func dryRunUnsupported(name string, action interface{}) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		if globalDryRun {
+			errorIf(errInvalidArgument().Trace(name), "`mc "+name+"` does not support --dry-run.")
+			return exitStatus(globalErrorExitStatus)
+		}
+		return cli.HandleAction(action, ctx)
+	}
+}
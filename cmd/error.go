@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/minio/cli"
@@ -53,6 +54,14 @@ func fatalIf(err *probe.Error, msg string, data ...interface{}) {
 	fatal(err, msg, data...)
 }
 
+// fatalln prints a fatal error and exits mc once the exit hooks ran.
+// find --exec-mc replaces it to survive the fatal errors of the commands
+// it runs.
+var fatalln = func(data ...interface{}) {
+	runExitHooks()
+	console.Fatalln(data...)
+}
+
 func fatal(err *probe.Error, msg string, data ...interface{}) {
 	if globalJSON {
 		errorMsg := errorMessage{
@@ -75,10 +84,15 @@ func fatal(err *probe.Error, msg string, data ...interface{}) {
 			Error:  errorMsg,
 		}, "", " ")
 		if e != nil {
-			console.Fatalln(probe.NewError(e))
+			fatalln(probe.NewError(e))
+		}
+		if globalJSONArray {
+			// The array is closed by the exit hooks.
+			printJSONArrayElement(string(json))
+		} else {
+			console.Println(string(json))
 		}
-		console.Println(string(json))
-		console.Fatalln()
+		fatalln()
 	}
 
 	msg = fmt.Sprintf(msg, data...)
@@ -115,7 +129,43 @@ func fatal(err *probe.Error, msg string, data ...interface{}) {
 		}
 	}
 
-	console.Fatalln(fmt.Sprintf("%s %s", msg, errmsg))
+	fatalln(fmt.Sprintf("%s %s", msg, errmsg))
+}
+
+var (
+	exitHooksMu sync.Mutex
+	exitHooks   []*func()
+)
+
+// atExit registers hook to run when mc exits without returning to its
+// caller, on a fatal error or a signal. The hooks run in the reverse
+// order of their registration. It returns a function unregistering hook.
+func atExit(hook func()) (remove func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	h := &hook
+	exitHooks = append(exitHooks, h)
+	return func() {
+		exitHooksMu.Lock()
+		defer exitHooksMu.Unlock()
+		for i := range exitHooks {
+			if exitHooks[i] == h {
+				exitHooks = append(exitHooks[:i], exitHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// runExitHooks runs and unregisters the hooks registered with atExit.
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitHooksMu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		(*hooks[i])()
+	}
 }
 
 // Exit coder wraps cli new exit error with a
@@ -153,9 +203,13 @@ func errorIf(err *probe.Error, msg string, data ...interface{}) {
 			Error:  errorMsg,
 		}, "", " ")
 		if e != nil {
-			console.Fatalln(probe.NewError(e))
+			fatalln(probe.NewError(e))
+		}
+		if globalJSONArray {
+			printJSONArrayElement(string(json))
+		} else {
+			console.Println(string(json))
 		}
-		console.Println(string(json))
 		return
 	}
 	msg = fmt.Sprintf(msg, data...)
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Results of the commands run by exec-mc-batch.
+const (
+	execMcSucceeded = "ok"
+	execMcFailed    = "failed"
+)
+
+// execMcBatchCmd runs the commands of find --exec-mc in a child process
+// of find, see flushExecMc.
+var execMcBatchCmd = cli.Command{
+	Name:            "exec-mc-batch",
+	Usage:           "run the commands of find --exec-mc",
+	Action:          mainExecMcBatch,
+	OnUsageError:    onUsageError,
+	Hidden:          true,
+	HideHelpCommand: true,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} COMMANDS-FILE RESULTS-FILE
+
+COMMANDS-FILE holds the arguments of one mc command per line as a JSON
+array, RESULTS-FILE is appended the result of each command which ran to
+its end, "ok" or "failed", one per line.
+`,
+}
+
+// mainExecMcBatch runs the commands of a file one after another, the
+// global flags set by a command being restored before the next one.
+// A command failing with a fatal error or an exit status exits.
+func mainExecMcBatch(cliCtx *cli.Context) error {
+	if len(cliCtx.Args()) != 2 {
+		showCommandHelpAndExit(cliCtx, globalErrorExitStatus)
+	}
+	commandsFile, resultsFile := cliCtx.Args().Get(0), cliCtx.Args().Get(1)
+	commands, e := os.ReadFile(commandsFile)
+	fatalIf(probe.NewError(e).Trace(commandsFile), "Unable to read the commands.")
+	results, e := os.OpenFile(resultsFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	fatalIf(probe.NewError(e).Trace(resultsFile), "Unable to open the results.")
+	defer results.Close()
+
+	// The configuration was already loaded and checked for this command.
+	app := *cliCtx.App
+	app.Before = nil
+
+	dec := json.NewDecoder(bytes.NewReader(commands))
+	for {
+		var args []string
+		e = dec.Decode(&args)
+		if errors.Is(e, io.EOF) {
+			return nil
+		}
+		fatalIf(probe.NewError(e).Trace(commandsFile), "Unable to read the commands.")
+
+		flags := saveGlobalFlags()
+		result := execMcSucceeded
+		if e = app.Run(append([]string{app.Name}, args...)); e != nil {
+			result = execMcFailed
+		}
+		flags.restore()
+		_, e = results.WriteString(result + "\n")
+		fatalIf(probe.NewError(e).Trace(resultsFile), "Unable to write the results.")
+	}
+}
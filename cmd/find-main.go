@@ -37,6 +37,10 @@ var (
 			Name:  "exec",
 			Usage: "spawn an external process for each matching object (see FORMAT)",
 		},
+		cli.StringFlag{
+			Name:  "exec-mc",
+			Usage: "run an mc command for each matching object, e.g. \"cp {} dst/\", in batches sharing one mc process (see FORMAT)",
+		},
 		cli.StringFlag{
 			Name:  "ignore",
 			Usage: "exclude objects matching the wildcard pattern",
@@ -57,6 +61,18 @@ var (
 			Name:  "older-than",
 			Usage: "match all objects older than value in duration string (e.g. 7d10h31s)",
 		},
+		cli.StringFlag{
+			Name:  "mtime",
+			Usage: "match all objects modified '+D' more or '-D' less than the duration D ago (e.g. +7d)",
+		},
+		cli.StringFlag{
+			Name:  "atime",
+			Usage: "match all files accessed '+D' more or '-D' less than the duration D ago, filesystem only",
+		},
+		cli.StringFlag{
+			Name:  "ctime",
+			Usage: "match all files whose status changed '+D' more or '-D' less than the duration D ago, filesystem only",
+		},
 		cli.StringFlag{
 			Name:  "path",
 			Usage: "match directory names matching wildcard pattern",
@@ -65,6 +81,10 @@ var (
 			Name:  "print",
 			Usage: "print in custom format to STDOUT (see FORMAT)",
 		},
+		cli.StringFlag{
+			Name:  "printf",
+			Usage: "print with directives like the -printf of GNU find, e.g. '%p %s %TY-%Tm-%Td\\n' (see PRINTF)",
+		},
 		cli.StringFlag{
 			Name:  "regex",
 			Usage: "match directory and object name with RE2 regex pattern",
@@ -77,6 +97,10 @@ var (
 			Name:  "smaller",
 			Usage: "match all objects smaller than specified size in units (see UNITS)",
 		},
+		cli.StringFlag{
+			Name:  "size",
+			Usage: "match all objects with size '+N' larger than, '-N' smaller than, 'N-M' between or 'N' equal to N (see UNITS)",
+		},
 		cli.UintFlag{
 			Name:  "maxdepth",
 			Usage: "limit directory navigation to specified depth",
@@ -93,6 +117,14 @@ var (
 			Name:  "tags",
 			Usage: "match tags with RE2 regex pattern. Specify each with key=regex. MinIO server only.",
 		},
+		cli.BoolFlag{
+			Name:  "empty",
+			Usage: "match zero-byte object(s)",
+		},
+		cli.BoolFlag{
+			Name:  "empty-dirs",
+			Usage: "match folder(s) and prefix marker(s) without any children",
+		},
 	}
 )
 
@@ -113,7 +145,7 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
 UNITS
-  --smaller, --larger flags accept human-readable case-insensitive number
+  --smaller, --larger, --size flags accept human-readable case-insensitive number
   suffixes such as "k", "m", "g" and "t" referring to the metric units KB,
   MB, GB and TB respectively. Adding an "i" to these prefixes, uses the IEC
   units, so that "gi" refers to "gibibyte" or "GiB". A "b" at the end is
@@ -122,6 +154,11 @@ UNITS
   --older-than, --newer-than flags accept the string for days, hours and minutes 
   i.e. 1d2h30m states 1 day, 2 hours and 30 minutes.
 
+  --mtime, --atime, --ctime flags accept the same durations prefixed with "+"
+  for times longer ago or "-" for more recent times, i.e. +30d matches times
+  older than 30 days. Objects only have a modification time, --atime and
+  --ctime apply to filesystem targets only.
+
 FORMAT
   Support string substitutions with special interpretations for following keywords.
   Keywords supported if target is filesystem or object storage:
@@ -137,6 +174,20 @@ FORMAT
 
      {url} --> Substitutes to a shareable URL of the path.
 
+PRINTF
+  Directives of --printf, no newline is printed unless the format ends with \n:
+
+     %p        --> Path.
+     %f        --> Basename of path.
+     %h        --> Dirname of path.
+     %s        --> Size in bytes.
+     %y        --> Type, "f" for objects and "d" for folders.
+     %e        --> ETag.
+     %v        --> Version identifier.
+     %t        --> Modified time.
+     %Tk       --> Component k of modified time, one of Y m d H M S T F + or @ for epoch seconds.
+     %%        --> A percent sign, \n, \t and \\ are a newline, a tab and a backslash.
+
 EXAMPLES:
   01. Find all "foo.jpg" in all buckets under "s3" account.
       {{.Prompt}} {{.HelpName}} s3 --name "foo.jpg"
@@ -171,6 +222,27 @@ EXAMPLES:
 
   11. Copy all versions of all objects in bucket in the local machine
       {{.Prompt}} {{.HelpName}} s3/bucket --versions --exec "mc cp --version-id {version} {} /tmp/dir/{}.{version}"
+
+  12. Find and remove all zero-byte objects under "s3/bucket".
+      {{.Prompt}} {{.HelpName}} s3/bucket --empty --exec "mc rm {}"
+
+  13. Find all stray prefix markers without any objects below them under "s3/bucket".
+      {{.Prompt}} {{.HelpName}} s3/bucket --empty-dirs
+
+  14. Find all ".log" objects over 100MiB and older than 30 days under "s3/logs".
+      {{.Prompt}} {{.HelpName}} s3/logs --name "*.log" --size +100MiB --older-than 30d
+
+  15. Find all objects between 5MiB and 20MiB in size under "s3/bucket".
+      {{.Prompt}} {{.HelpName}} s3/bucket --size 5MiB-20MiB
+
+  16. Copy all ".log" objects to "dst/" running "mc cp" within find, without a new process per object.
+      {{.Prompt}} {{.HelpName}} src/ --name "*.log" --exec-mc "cp {} dst/"
+
+  17. Print the path, size in bytes and modified date of all ".jpg" objects under "s3/mybucket".
+      {{.Prompt}} {{.HelpName}} s3/mybucket --name "*.jpg" --printf '%p %s %TY-%Tm-%Td\n'
+
+  18. Find files under "~/archive" that were not read during the last 90 days.
+      {{.Prompt}} {{.HelpName}} ~/archive --atime +90d
 `,
 }
 
@@ -208,22 +280,33 @@ func checkFindSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 type findContext struct {
 	*cli.Context
 	execCmd           string
+	execMcArgs        []string
 	ignorePattern     string
 	namePattern       string
 	pathPattern       string
 	regexPattern      *regexp.Regexp
 	maxDepth          uint
 	printFmt          string
+	printf            findPrintf
 	olderThan         string
 	newerThan         string
+	mtime             *findTimeFilter
+	atime             *findTimeFilter
+	ctime             *findTimeFilter
 	largerSize        uint64
 	smallerSize       uint64
+	sizeRange         *sizeRange
 	watch             bool
+	empty             bool
+	emptyDirs         bool
 	withOlderVersions bool
 	matchMeta         map[string]*regexp.Regexp
 	matchTags         map[string]*regexp.Regexp
 
 	// Internal values
+	execMcQueue   [][]string
+	execMcRuns    int
+	execMcErrors  int
 	targetAlias   string
 	targetURL     string
 	targetFullURL string
@@ -279,6 +362,27 @@ func mainFind(cliCtx *cli.Context) error {
 		fatalIf(probe.NewError(e).Trace(cliCtx.String("smaller")), "Unable to parse input bytes.")
 	}
 
+	var sizeMatch *sizeRange
+	if cliCtx.String("size") != "" {
+		sizeMatch, e = parseSizeRange(cliCtx.String("size"))
+		fatalIf(probe.NewError(e).Trace(cliCtx.String("size")), "Unable to parse size expression.")
+	}
+
+	var mtime, atime, ctime *findTimeFilter
+	for _, filter := range []struct {
+		name string
+		dst  **findTimeFilter
+	}{{"mtime", &mtime}, {"atime", &atime}, {"ctime", &ctime}} {
+		if expr := cliCtx.String(filter.name); expr != "" {
+			*filter.dst, e = parseFindTimeFilter(expr)
+			fatalIf(probe.NewError(e).Trace(expr), "Unable to parse --"+filter.name+" expression.")
+		}
+	}
+	if (atime != nil || ctime != nil) && clnt.GetURL().Type != fileSystem {
+		errorIf(errInvalidArgument().Trace(args[0]), "--atime and --ctime are not supported for object storage, objects only have a modification time. They are ignored.")
+		atime, ctime = nil, nil
+	}
+
 	// Get --versions flag
 	withVersions := cliCtx.Bool("versions")
 
@@ -289,6 +393,24 @@ func mainFind(cliCtx *cli.Context) error {
 	if hostCfg != nil {
 		targetFullURL = hostCfg.URL
 	}
+	var execMcArgs []string
+	if execMc := cliCtx.String("exec-mc"); execMc != "" {
+		if cliCtx.String("exec") != "" {
+			fatalIf(errInvalidArgument().Trace(execMc), "--exec and --exec-mc cannot be used together.")
+		}
+		execMcArgs, e = parseExecMc(cliCtx.App, execMc)
+		fatalIf(probe.NewError(e).Trace(execMc), "Unable to parse --exec-mc.")
+	}
+
+	var printf findPrintf
+	if format := cliCtx.String("printf"); format != "" {
+		if cliCtx.String("print") != "" {
+			fatalIf(errInvalidArgument().Trace(format), "--print and --printf cannot be used together.")
+		}
+		printf, e = parseFindPrintf(format)
+		fatalIf(probe.NewError(e).Trace(format), "Unable to parse --printf.")
+	}
+
 	var regMatch *regexp.Regexp
 	if cliCtx.String("regex") != "" {
 		regMatch = regexp.MustCompile(cliCtx.String("regex"))
@@ -298,7 +420,9 @@ func mainFind(cliCtx *cli.Context) error {
 		Context:           cliCtx,
 		maxDepth:          cliCtx.Uint("maxdepth"),
 		execCmd:           cliCtx.String("exec"),
+		execMcArgs:        execMcArgs,
 		printFmt:          cliCtx.String("print"),
+		printf:            printf,
 		namePattern:       cliCtx.String("name"),
 		pathPattern:       cliCtx.String("path"),
 		regexPattern:      regMatch,
@@ -306,9 +430,15 @@ func mainFind(cliCtx *cli.Context) error {
 		withOlderVersions: withVersions,
 		olderThan:         olderThan,
 		newerThan:         newerThan,
+		mtime:             mtime,
+		atime:             atime,
+		ctime:             ctime,
 		largerSize:        largerSize,
 		smallerSize:       smallerSize,
+		sizeRange:         sizeMatch,
 		watch:             cliCtx.Bool("watch"),
+		empty:             cliCtx.Bool("empty"),
+		emptyDirs:         cliCtx.Bool("empty-dirs"),
 		targetAlias:       targetAlias,
 		targetURL:         args[0],
 		targetFullURL:     targetFullURL,
@@ -0,0 +1,168 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/minio/pkg/v2/console"
+)
+
+// findPrintfTimeLayouts - layouts of the modified time components of
+// --printf, formatted with %T followed by the component.
+var findPrintfTimeLayouts = map[byte]string{
+	'Y': "2006",
+	'm': "01",
+	'd': "02",
+	'H': "15",
+	'M': "04",
+	'S': "05",
+	'T': "15:04:05",
+	'F': "2006-01-02",
+	'+': "2006-01-02+15:04:05",
+}
+
+// findPrintfSegment - a literal text or a directive of --printf.
+type findPrintfSegment struct {
+	literal   string
+	directive byte
+	component byte
+}
+
+// findPrintf - a parsed --printf format.
+type findPrintf []findPrintfSegment
+
+// parseFindPrintf parses a --printf format, like the -printf of GNU find:
+//
+//	%p path, %f base name, %h directory, %s size in bytes, %y type (f or d),
+//	%e etag, %v version identifier, %t modified time, %T<k> a component
+//	of the modified time with k one of Y m d H M S T F + or @ for the
+//	seconds since the epoch, %% a percent sign, \n \t and \\ escapes.
+func parseFindPrintf(format string) (findPrintf, error) {
+	var (
+		f       findPrintf
+		literal strings.Builder
+	)
+	flush := func() {
+		if literal.Len() > 0 {
+			f = append(f, findPrintfSegment{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(format); i++ {
+		switch c := format[i]; c {
+		case '\\':
+			if i+1 == len(format) {
+				return nil, fmt.Errorf("incomplete escape at the end of %q", format)
+			}
+			i++
+			switch format[i] {
+			case 'n':
+				literal.WriteByte('\n')
+			case 't':
+				literal.WriteByte('\t')
+			case '\\':
+				literal.WriteByte('\\')
+			default:
+				return nil, fmt.Errorf("unknown escape \\%c", format[i])
+			}
+		case '%':
+			if i+1 == len(format) {
+				return nil, fmt.Errorf("incomplete directive at the end of %q", format)
+			}
+			i++
+			switch d := format[i]; d {
+			case '%':
+				literal.WriteByte('%')
+			case 'p', 'f', 'h', 's', 'y', 'e', 'v', 't':
+				flush()
+				f = append(f, findPrintfSegment{directive: d})
+			case 'T':
+				if i+1 == len(format) {
+					return nil, fmt.Errorf("incomplete directive %%T at the end of %q", format)
+				}
+				i++
+				if _, ok := findPrintfTimeLayouts[format[i]]; !ok && format[i] != '@' {
+					return nil, fmt.Errorf("unknown directive %%T%c", format[i])
+				}
+				flush()
+				f = append(f, findPrintfSegment{directive: d, component: format[i]})
+			default:
+				return nil, fmt.Errorf("unknown directive %%%c", d)
+			}
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return f, nil
+}
+
+// format formats a matching object.
+func (f findPrintf) format(fileContent contentMessage) string {
+	var b strings.Builder
+	for _, s := range f {
+		switch s.directive {
+		case 0:
+			b.WriteString(s.literal)
+		case 'p':
+			b.WriteString(fileContent.Key)
+		case 'f':
+			b.WriteString(path.Base(fileContent.Key))
+		case 'h':
+			b.WriteString(path.Dir(fileContent.Key))
+		case 's':
+			b.WriteString(strconv.FormatInt(fileContent.Size, 10))
+		case 'y':
+			if fileContent.Filetype == "folder" {
+				b.WriteString("d")
+			} else {
+				b.WriteString("f")
+			}
+		case 'e':
+			b.WriteString(fileContent.ETag)
+		case 'v':
+			b.WriteString(fileContent.VersionID)
+		case 't':
+			b.WriteString(fileContent.Time.Format(printDate))
+		case 'T':
+			if s.component == '@' {
+				b.WriteString(strconv.FormatInt(fileContent.Time.Unix(), 10))
+			} else {
+				b.WriteString(fileContent.Time.Format(findPrintfTimeLayouts[s.component]))
+			}
+		}
+	}
+	return b.String()
+}
+
+// printFindf prints a matching object formatted with --printf as is, a
+// newline is only printed if the format ends with one. With --json the
+// formatted string is the key of the usual message.
+func printFindf(f findPrintf, fileContent contentMessage) {
+	formatted := f.format(fileContent)
+	if globalJSON {
+		fileContent.Key = formatted
+		printMsg(findMessage{fileContent})
+		return
+	}
+	console.Print(formatted)
+}
@@ -20,7 +20,10 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -33,6 +36,7 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/google/shlex"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/disk"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/console"
 
@@ -129,6 +133,7 @@ func execFind(ctx context.Context, args string, fileContent contentMessage) {
 	split, err := shlex.Split(args)
 	if err != nil {
 		console.Println(console.Colorize("FindExecErr", "Unable to parse --exec: "+err.Error()))
+		runExitHooks()
 		os.Exit(getExitStatus(err))
 	}
 	if len(split) == 0 {
@@ -148,11 +153,137 @@ func execFind(ctx context.Context, args string, fileContent contentMessage) {
 		}
 		console.Println(console.Colorize("FindExecErr", err.Error()))
 		// Return exit status of the command run
+		runExitHooks()
 		os.Exit(getExitStatus(err))
 	}
 	console.PrintC(out.String())
 }
 
+// parseExecMc parses the mc command line of --exec-mc, with or without
+// a leading "mc", into the arguments of one of the commands of app.
+func parseExecMc(app *cli.App, args string) ([]string, error) {
+	split, e := shlex.Split(args)
+	if e != nil {
+		return nil, e
+	}
+	if len(split) > 0 && split[0] == "mc" {
+		split = split[1:]
+	}
+	if len(split) == 0 {
+		return nil, errors.New("missing mc command")
+	}
+	if app.Command(split[0]) == nil || split[0] == execMcBatchCmd.Name {
+		return nil, fmt.Errorf("unknown mc command `%s`", split[0])
+	}
+	return split, nil
+}
+
+// execMcBatchSize is the number of matches whose --exec-mc commands run
+// in one mc process.
+var execMcBatchSize = 100
+
+// execMcFind queues the mc command of --exec-mc for a match, queued
+// commands run once execMcBatchSize of them are queued.
+func execMcFind(ctx context.Context, findCtx *findContext, fileContent contentMessage) {
+	args := make([]string, 0, len(findCtx.execMcArgs))
+	for _, arg := range findCtx.execMcArgs {
+		args = append(args, stringsReplace(ctx, arg, fileContent))
+	}
+	findCtx.execMcQueue = append(findCtx.execMcQueue, args)
+	if len(findCtx.execMcQueue) >= execMcBatchSize {
+		flushExecMc(ctx, findCtx)
+	}
+}
+
+// flushExecMc runs the queued --exec-mc commands one after another in an
+// mc child process, which reuses its configuration and connections from
+// one command to the next. A command failing with a fatal error or an
+// exit status stops that process, the commands after it run in a new
+// one. Errors of the commands are printed by the commands and counted.
+func flushExecMc(ctx context.Context, findCtx *findContext) {
+	queue := findCtx.execMcQueue
+	findCtx.execMcQueue = nil
+	for len(queue) > 0 && ctx.Err() == nil {
+		results, err := runExecMcBatch(ctx, queue)
+		if err != nil {
+			errorIf(err, "Unable to run --exec-mc.")
+			findCtx.execMcRuns += len(queue)
+			findCtx.execMcErrors += len(queue)
+			return
+		}
+		for _, ok := range results {
+			findCtx.execMcRuns++
+			if !ok {
+				findCtx.execMcErrors++
+			}
+		}
+		queue = queue[len(results):]
+		if len(queue) > 0 {
+			// The process stopped on this command.
+			findCtx.execMcRuns++
+			findCtx.execMcErrors++
+			queue = queue[1:]
+		}
+	}
+}
+
+// runExecMcBatch runs the commands of batch with the exec-mc-batch
+// command of an mc child process, sharing the standard output and
+// error of this one. It returns whether each command which ran to its
+// end succeeded, in order.
+func runExecMcBatch(ctx context.Context, batch [][]string) ([]bool, *probe.Error) {
+	dir, e := os.MkdirTemp("", "mc-exec-mc-")
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer os.RemoveAll(dir)
+
+	var commands bytes.Buffer
+	enc := json.NewEncoder(&commands)
+	for _, args := range batch {
+		if e = enc.Encode(args); e != nil {
+			return nil, probe.NewError(e)
+		}
+	}
+	commandsFile := filepath.Join(dir, "commands")
+	resultsFile := filepath.Join(dir, "results")
+	if e = os.WriteFile(commandsFile, commands.Bytes(), 0o600); e != nil {
+		return nil, probe.NewError(e).Trace(commandsFile)
+	}
+
+	exe, e := os.Executable()
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	var args []string
+	if mcCustomConfigDir != "" {
+		args = append(args, "--config-dir", mcCustomConfigDir)
+	}
+	if globalInsecure {
+		args = append(args, "--insecure")
+	}
+	args = append(args, execMcBatchCmd.Name, commandsFile, resultsFile)
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if e = cmd.Run(); e != nil {
+		// A failing command stops the process with its exit status.
+		var exitErr *exec.ExitError
+		if !errors.As(e, &exitErr) {
+			return nil, probe.NewError(e).Trace(exe)
+		}
+	}
+
+	data, e := os.ReadFile(resultsFile)
+	if e != nil && !os.IsNotExist(e) {
+		return nil, probe.NewError(e).Trace(resultsFile)
+	}
+	var results []bool
+	for _, result := range strings.Fields(string(data)) {
+		results = append(results, result == execMcSucceeded)
+	}
+	return results, nil
+}
+
 // watchFind - enables listening on the input path, listens for all file/object
 // created actions. Asynchronously executes the input command line, also allows
 // formatting for the command line in accordance with subsititution arguments.
@@ -193,6 +324,7 @@ func watchFind(ctxCtx context.Context, ctx *findContext) {
 					Size: event.Size,
 				})
 			}
+			flushExecMc(ctxCtx, ctx)
 		case err, ok := <-watchObj.Errors():
 			if !ok {
 				return
@@ -256,6 +388,14 @@ func find(ctxCtx context.Context, ctx *findContext, fileContent contentMessage)
 		execFind(ctxCtx, ctx.execCmd, fileContent)
 		return
 	}
+	if len(ctx.execMcArgs) > 0 {
+		execMcFind(ctxCtx, ctx, fileContent)
+		return
+	}
+	if ctx.printf != nil {
+		printFindf(ctx.printf, fileContent)
+		return
+	}
 	if ctx.printFmt != "" {
 		fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
 	}
@@ -278,6 +418,11 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 		WithMetadata:      len(ctx.matchMeta) > 0 || len(ctx.matchTags) > 0,
 	}
 
+	// Folder waiting for the next listed entry to know whether it has
+	// children, listing is sorted so children follow their folder.
+	var emptyDirCandidate *contentMessage
+	separator := string(ctx.clnt.GetURL().Separator)
+
 	// iterate over all content which is within the given directory
 	for content := range ctx.clnt.List(globalContext, lstOptions) {
 		if content.Err != nil {
@@ -309,9 +454,35 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 			VersionID: content.VersionID,
 			Time:      content.Time.Local(),
 			Size:      content.Size,
+			ETag:      content.ETag,
 			Metadata:  content.UserMetadata,
 			Tags:      content.Tags,
 		}
+		if content.Type.IsDir() {
+			fileContent.Filetype = "folder"
+		} else {
+			fileContent.Filetype = "file"
+		}
+
+		if ctx.emptyDirs && emptyDirCandidate != nil {
+			dirPrefix := strings.TrimSuffix(emptyDirCandidate.Key, separator) + separator
+			if !strings.HasPrefix(fileKeyName, dirPrefix) {
+				find(ctxCtx, ctx, *emptyDirCandidate)
+			}
+			emptyDirCandidate = nil
+		}
+
+		if ctx.empty || ctx.emptyDirs {
+			if content.Type.IsDir() {
+				if ctx.emptyDirs {
+					emptyDirCandidate = &fileContent
+				}
+				continue
+			}
+			if !ctx.empty {
+				continue
+			}
+		}
 
 		// Match the incoming content, didn't match return.
 		if !matchFind(ctx, fileContent) {
@@ -323,6 +494,14 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 			execFind(ctxCtx, ctx.execCmd, fileContent)
 			continue
 		}
+		if len(ctx.execMcArgs) > 0 {
+			execMcFind(ctxCtx, ctx, fileContent)
+			continue
+		}
+		if ctx.printf != nil {
+			printFindf(ctx.printf, fileContent)
+			continue
+		}
 		if ctx.printFmt != "" {
 			fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
 		}
@@ -330,6 +509,17 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 		printMsg(findMessage{fileContent})
 	}
 
+	if emptyDirCandidate != nil {
+		find(ctxCtx, ctx, *emptyDirCandidate)
+	}
+	flushExecMc(ctxCtx, ctx)
+
+	if ctx.execMcErrors > 0 {
+		errorIf(probe.NewError(fmt.Errorf("%d of %d commands failed", ctx.execMcErrors, ctx.execMcRuns)),
+			"Unable to run --exec-mc on all matches.")
+		return exitStatus(globalErrorExitStatus)
+	}
+
 	// Success, notice watch will execute in defer only if enabled and this call
 	// will return after watch is canceled.
 	return nil
@@ -419,12 +609,30 @@ func matchFind(ctx *findContext, fileContent contentMessage) (match bool) {
 	if match && ctx.newerThan != "" {
 		match = !isNewer(fileContent.Time, ctx.newerThan)
 	}
+	if match && ctx.mtime != nil {
+		match = ctx.mtime.matches(fileContent.Time, time.Now())
+	}
+	if match && (ctx.atime != nil || ctx.ctime != nil) {
+		atime, ctime := fsFileTimes(fileContent.Key)
+		if ctx.atime != nil {
+			match = ctx.atime.matches(atime, time.Now())
+		}
+		if match && ctx.ctime != nil {
+			match = ctx.ctime.matches(ctime, time.Now())
+		}
+	}
 	if match && ctx.largerSize > 0 {
 		match = int64(ctx.largerSize) < fileContent.Size
 	}
 	if match && ctx.smallerSize > 0 {
 		match = int64(ctx.smallerSize) > fileContent.Size
 	}
+	if match && ctx.sizeRange != nil {
+		match = ctx.sizeRange.contains(fileContent.Size)
+	}
+	if match && ctx.empty && fileContent.Filetype != "folder" {
+		match = fileContent.Size == 0
+	}
 	if match && len(ctx.matchMeta) > 0 {
 		match = matchRegexMaps(ctx.matchMeta, fileContent.Metadata)
 	}
@@ -434,6 +642,108 @@ func matchFind(ctx *findContext, fileContent contentMessage) (match bool) {
 	return match
 }
 
+// findTimeFilter is a --mtime, --atime or --ctime expression, "+D"
+// matches times more than the duration D ago and "-D" less than D ago.
+type findTimeFilter struct {
+	older bool
+	age   time.Duration
+}
+
+// parseFindTimeFilter parses a --mtime, --atime or --ctime expression.
+func parseFindTimeFilter(expr string) (*findTimeFilter, error) {
+	if !strings.HasPrefix(expr, "+") && !strings.HasPrefix(expr, "-") {
+		return nil, fmt.Errorf("`%s` must start with '+' for older or '-' for newer times", expr)
+	}
+	age, e := ParseDuration(expr[1:])
+	if e != nil {
+		return nil, e
+	}
+	return &findTimeFilter{older: expr[0] == '+', age: time.Duration(age)}, nil
+}
+
+// matches returns true if t matches the filter at now, unknown zero
+// times never match.
+func (f findTimeFilter) matches(t, now time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	if f.older {
+		return now.Sub(t) > f.age
+	}
+	return now.Sub(t) < f.age
+}
+
+// fsFileTimes returns the access and status change times of a local
+// file, zero times if they are unknown.
+func fsFileTimes(path string) (atime, ctime time.Time) {
+	fi, e := os.Stat(path)
+	if e != nil {
+		return atime, ctime
+	}
+	return disk.FileTimes(fi)
+}
+
+// sizeRange is an inclusive range of object sizes in bytes.
+type sizeRange struct {
+	min, max uint64
+}
+
+func (r sizeRange) contains(size int64) bool {
+	return size >= 0 && uint64(size) >= r.min && uint64(size) <= r.max
+}
+
+// parseSizeRange parses a --size expression, "+N" matches sizes larger
+// than N, "-N" sizes smaller than N, "N-M" sizes from N to M inclusive
+// and a plain "N" exactly N bytes.
+func parseSizeRange(expr string) (*sizeRange, error) {
+	parseSize := func(s string) (uint64, error) {
+		if s == "" {
+			return 0, fmt.Errorf("missing size in `%s`", expr)
+		}
+		return humanize.ParseBytes(s)
+	}
+	switch {
+	case strings.HasPrefix(expr, "+"):
+		size, e := parseSize(expr[1:])
+		if e != nil {
+			return nil, e
+		}
+		if size == math.MaxUint64 {
+			return nil, fmt.Errorf("no size is larger than `%s`", expr[1:])
+		}
+		return &sizeRange{min: size + 1, max: math.MaxUint64}, nil
+	case strings.HasPrefix(expr, "-"):
+		size, e := parseSize(expr[1:])
+		if e != nil {
+			return nil, e
+		}
+		if size == 0 {
+			return nil, fmt.Errorf("no size is smaller than `%s`", expr[1:])
+		}
+		return &sizeRange{min: 0, max: size - 1}, nil
+	case strings.Contains(expr, "-"):
+		lower, upper, _ := strings.Cut(expr, "-")
+		min, e := parseSize(lower)
+		if e != nil {
+			return nil, e
+		}
+		max, e := parseSize(upper)
+		if e != nil {
+			return nil, e
+		}
+		if min > max {
+			return nil, fmt.Errorf("lower bound of `%s` is larger than its upper bound", expr)
+		}
+		return &sizeRange{min: min, max: max}, nil
+	default:
+		size, e := parseSize(expr)
+		if e != nil {
+			return nil, e
+		}
+		return &sizeRange{min: size, max: size}, nil
+	}
+}
+
 // 7 days in seconds.
 var defaultSevenDays = time.Duration(604800) * time.Second
 
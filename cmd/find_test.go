@@ -19,12 +19,19 @@ package cmd
 
 import (
 	"context"
+	"math"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
 )
 
 // Tests match find function with all supported inputs on
@@ -448,3 +455,223 @@ func TestGetExitStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSizeRange(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected *sizeRange
+	}{
+		{"+10M", &sizeRange{min: 10000001, max: math.MaxUint64}},
+		{"-1k", &sizeRange{min: 0, max: 999}},
+		{"5MiB-20MiB", &sizeRange{min: 5 << 20, max: 20 << 20}},
+		{"512", &sizeRange{min: 512, max: 512}},
+		{"-0", nil},
+		{"20M-5M", nil},
+		{"5M-", nil},
+		{"+", nil},
+		{"abc", nil},
+	}
+	for i, testCase := range testCases {
+		r, e := parseSizeRange(testCase.expr)
+		if testCase.expected == nil {
+			if e == nil {
+				t.Errorf("Test %d: expected %q to fail, got %+v", i+1, testCase.expr, r)
+			}
+			continue
+		}
+		if e != nil {
+			t.Errorf("Test %d: unexpected error for %q: %v", i+1, testCase.expr, e)
+			continue
+		}
+		if *r != *testCase.expected {
+			t.Errorf("Test %d: expected %+v, got %+v", i+1, *testCase.expected, *r)
+		}
+	}
+	if r, _ := parseSizeRange("1k-2k"); !r.contains(1000) || !r.contains(2000) || r.contains(999) || r.contains(2001) {
+		t.Errorf("range 1k-2k matched the wrong sizes")
+	}
+}
+
+func TestParseExecMc(t *testing.T) {
+	app := &cli.App{Commands: []cli.Command{{Name: "cp"}, {Name: "rm"}}}
+	testCases := []struct {
+		args     string
+		expected []string
+	}{
+		{"cp {} dst/", []string{"cp", "{}", "dst/"}},
+		{`mc rm --force "{}"`, []string{"rm", "--force", "{}"}},
+		{"mc", nil},
+		{"", nil},
+		{"cpx {}", nil},
+	}
+	for i, testCase := range testCases {
+		args, e := parseExecMc(app, testCase.args)
+		if testCase.expected == nil {
+			if e == nil {
+				t.Errorf("Test %d: expected %q to fail, got %q", i+1, testCase.args, args)
+			}
+			continue
+		}
+		if e != nil || !reflect.DeepEqual(args, testCase.expected) {
+			t.Errorf("Test %d: expected %q, got %q, %v", i+1, testCase.expected, args, e)
+		}
+	}
+}
+
+func TestFindPrintf(t *testing.T) {
+	fileContent := contentMessage{
+		Key:       "s3/bucket/photos/cat.jpg",
+		Filetype:  "file",
+		Size:      1024,
+		Time:      time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC),
+		VersionID: "v1",
+	}
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{`%p %s %TY-%Tm-%Td\n`, "s3/bucket/photos/cat.jpg 1024 2024-03-09\n"},
+		{`%f\t%h %y %v`, "cat.jpg\ts3/bucket/photos f v1"},
+		{`100%% %T@ %T+`, "100% 1709993100 2024-03-09+14:05:00"},
+		{`%q`, ""},
+		{`%Tq`, ""},
+		{`%`, ""},
+		{`\x`, ""},
+	}
+	for i, testCase := range testCases {
+		f, e := parseFindPrintf(testCase.format)
+		if testCase.expected == "" {
+			if e == nil {
+				t.Errorf("Test %d: expected %q to fail", i+1, testCase.format)
+			}
+			continue
+		}
+		if e != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, e)
+		}
+		if got := f.format(fileContent); got != testCase.expected {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestFindTimeFilter(t *testing.T) {
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		expr    string
+		t       time.Time
+		matches bool
+	}{
+		{"+7d", now.Add(-8 * 24 * time.Hour), true},
+		{"+7d", now.Add(-6 * 24 * time.Hour), false},
+		{"-1h", now.Add(-30 * time.Minute), true},
+		{"-1h", now.Add(-2 * time.Hour), false},
+		{"+1h", time.Time{}, false},
+	}
+	for i, testCase := range testCases {
+		f, e := parseFindTimeFilter(testCase.expr)
+		if e != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, e)
+		}
+		if got := f.matches(testCase.t, now); got != testCase.matches {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.matches, got)
+		}
+	}
+	for _, expr := range []string{"7d", "+", "-x"} {
+		if _, e := parseFindTimeFilter(expr); e == nil {
+			t.Errorf("expected %q to fail", expr)
+		}
+	}
+}
+
+// TestMain runs mc instead of the tests in the processes which the tests
+// start as mc child processes, with MC_TEST_RUN_MC set.
+func TestMain(m *testing.M) {
+	if os.Getenv("MC_TEST_RUN_MC") != "" {
+		if e := Main(os.Args); e != nil {
+			os.Exit(globalErrorExitStatus)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// captureStdout returns what f prints on the standard output, including
+// the output of the child processes it starts.
+func captureStdout(t *testing.T, f func()) string {
+	out, e := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer out.Close()
+	defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+	os.Stdout = out
+	f()
+	data, e := os.ReadFile(out.Name())
+	if e != nil {
+		t.Fatal(e)
+	}
+	return string(data)
+}
+
+// Test a fatal error of a command run by --exec-mc fails that run only,
+// the commands after it run in a new process.
+func TestExecMcFind(t *testing.T) {
+	t.Setenv("MC_TEST_RUN_MC", "1")
+	defer func(configDir string, batchSize int) {
+		mcCustomConfigDir, execMcBatchSize = configDir, batchSize
+	}(mcCustomConfigDir, execMcBatchSize)
+	root := t.TempDir()
+	mcCustomConfigDir, execMcBatchSize = filepath.Join(root, "config"), 2
+	for _, name := range []string{"b", "bx", "cx"} {
+		if e := os.WriteFile(filepath.Join(root, name), []byte(name+"\n"), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	findCtx := &findContext{
+		Context:    cli.NewContext(registerApp("mc"), nil, nil),
+		execMcArgs: []string{"cat", "{}x"},
+	}
+	out := captureStdout(t, func() {
+		for _, name := range []string{"a", "b", "bx", "c"} {
+			execMcFind(context.Background(), findCtx, contentMessage{Key: filepath.Join(root, name)})
+		}
+		flushExecMc(context.Background(), findCtx)
+	})
+	if findCtx.execMcRuns != 4 || findCtx.execMcErrors != 2 {
+		t.Errorf("expected 2 failed runs of 4, found %d of %d", findCtx.execMcErrors, findCtx.execMcRuns)
+	}
+	if out != "bx\ncx\n" {
+		t.Errorf("expected the output of the succeeding commands, got %q", out)
+	}
+}
+
+// Test the global flags set by a command run by exec-mc-batch do not
+// apply to the next one.
+func TestExecMcBatchGlobalFlags(t *testing.T) {
+	t.Setenv("MC_TEST_RUN_MC", "1")
+	defer func(configDir string) { mcCustomConfigDir = configDir }(mcCustomConfigDir)
+	root := t.TempDir()
+	mcCustomConfigDir = filepath.Join(root, "config")
+	file := filepath.Join(root, "file")
+	if e := os.WriteFile(file, []byte("data"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+
+	var results []bool
+	var err *probe.Error
+	out := captureStdout(t, func() {
+		results, err = runExecMcBatch(context.Background(), [][]string{{"ls", "--json", file}, {"ls", file}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, []bool{true, true}) {
+		t.Fatalf("expected both commands to succeed, got %v", results)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "{") || !strings.HasPrefix(lines[1], "[") {
+		t.Fatalf("expected a JSON listing then a plain one, got %q", out)
+	}
+}
@@ -51,9 +51,19 @@ var globalFlags = []cli.Flag{
 	},
 	cli.BoolFlag{
 		Name:   "json",
-		Usage:  "enable JSON lines formatted output",
+		Usage:  "enable JSON lines formatted output, --json=array prints a single JSON array instead",
 		EnvVar: envPrefix + "JSON",
 	},
+	cli.BoolFlag{
+		Name:   "strict",
+		Usage:  "fail instead of printing JSON records which cannot represent their content exactly, e.g. keys which are not valid UTF-8",
+		EnvVar: envPrefix + "STRICT",
+	},
+	cli.BoolFlag{
+		Name:   "dry-run",
+		Usage:  "print the changes a command would make without making them, commands not supporting it fail",
+		EnvVar: envPrefix + "DRY_RUN",
+	},
 	cli.BoolFlag{
 		Name:   "debug",
 		Usage:  "enable debug output",
@@ -66,14 +76,44 @@ var globalFlags = []cli.Flag{
 	},
 	cli.StringFlag{
 		Name:   "limit-upload",
-		Usage:  "limits uploads to a maximum rate in KiB/s, MiB/s, GiB/s. (default: unlimited)",
+		Usage:  "limits uploads to a maximum rate in KiB/s, MiB/s, GiB/s. (default: per alias, unlimited)",
 		EnvVar: envPrefix + "LIMIT_UPLOAD",
 	},
+	cli.StringFlag{
+		Name:   "limit-upload-shared",
+		Usage:  "limits uploads of all mc processes of this user on this host to a combined maximum rate in KiB/s, MiB/s, GiB/s. (default: unlimited)",
+		EnvVar: envPrefix + "LIMIT_UPLOAD_SHARED",
+	},
 	cli.StringFlag{
 		Name:   "limit-download",
-		Usage:  "limits downloads to a maximum rate in KiB/s, MiB/s, GiB/s. (default: unlimited)",
+		Usage:  "limits downloads to a maximum rate in KiB/s, MiB/s, GiB/s. (default: per alias, unlimited)",
 		EnvVar: envPrefix + "LIMIT_DOWNLOAD",
 	},
+	cli.StringFlag{
+		Name:   "list-version",
+		Usage:  "listing API version to use, choose one of [1, 2]. (default: 2, falling back to 1 when unsupported)",
+		EnvVar: envPrefix + "LIST_VERSION",
+	},
+	cli.StringFlag{
+		Name:   "resume-threshold",
+		Usage:  "make copies larger than this size resumable, as with --continue, in KiB, MiB, GiB. (default: per alias, disabled)",
+		EnvVar: envPrefix + "RESUME_THRESHOLD",
+	},
+	cli.BoolFlag{
+		Name:   "no-resume",
+		Usage:  "disable resumable copies enabled by --resume-threshold",
+		EnvVar: envPrefix + "NO_RESUME",
+	},
+	cli.StringFlag{
+		Name:   "limit-requests",
+		Usage:  "limits requests to a maximum number per second. (default: per alias, unlimited)",
+		EnvVar: envPrefix + "LIMIT_REQUESTS",
+	},
+	cli.StringFlag{
+		Name:   "limit-burst",
+		Usage:  "maximum burst size allowed by --limit-upload and --limit-download in KiB, MiB, GiB. (default: one second worth of the rate)",
+		EnvVar: envPrefix + "LIMIT_BURST",
+	},
 	cli.DurationFlag{
 		Name:   "conn-read-deadline",
 		Usage:  "custom connection READ deadline",
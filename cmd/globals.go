@@ -21,12 +21,16 @@ package cmd
 import (
 	"context"
 	"crypto/x509"
+	"fmt"
 	"net/url"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/madmin-go/v3"
 	"github.com/minio/pkg/v2/console"
@@ -65,7 +69,10 @@ var (
 	globalQuiet          = false               // Quiet flag set via command line
 	globalJSON           = false               // Json flag set via command line
 	globalJSONLine       = false               // Print json as single line.
+	globalJSONArray      = false               // Print json records as a single array.
 	globalDebug          = false               // Debug flag set via command line
+	globalDryRun         = false               // Dry run flag set via command line
+	globalStrictJSON     = false               // Strict flag set via command line
 	globalNoColor        = false               // No Color flag set via command line
 	globalInsecure       = false               // Insecure flag set via command line
 	globalDevMode        = false               // dev flag set via command line
@@ -76,8 +83,23 @@ var (
 	globalConnReadDeadline  time.Duration
 	globalConnWriteDeadline time.Duration
 
-	globalLimitUpload   uint64
-	globalLimitDownload uint64
+	globalLimitUpload       uint64
+	globalLimitUploadShared uint64
+	globalLimitDownload     uint64
+	globalLimitBurst        uint64
+	globalLimitRequests     float64
+
+	globalListVersion int
+
+	globalResumeThreshold uint64
+	globalNoResume        bool
+
+	// Set by 'cp --accelerate'.
+	globalAccelerate bool
+
+	// Upload limits per destination alias or host, set by
+	// 'cp --bwlimit-file'.
+	globalDestinationLimits map[string]uint64
 
 	globalContext, globalCancel = context.WithCancel(context.Background())
 )
@@ -87,6 +109,7 @@ var (
 	globalTermWidth, globalTermHeight int
 
 	globalDisablePagerFlag  = "--disable-pager"
+	globalJSONArrayFlags    = []string{"--json=array", "-json=array"}
 	globalPagerDisabled     = false
 	globalHelpPager         *termPager
 	globalPagerEnabledTerms = map[string]bool{
@@ -114,6 +137,26 @@ func parsePagerDisableFlag(args []string) {
 	}
 }
 
+// parseJSONArrayFlag enables --json=array and returns the arguments with
+// it replaced by the plain --json boolean flag. The arguments after "--"
+// are not flags and are left as they are.
+func parseJSONArrayFlag(args []string) []string {
+	parsed := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(parsed, args[i:]...)
+		}
+		for _, flag := range globalJSONArrayFlags {
+			if arg == flag {
+				globalJSONArray = true
+				arg = "--json"
+			}
+		}
+		parsed = append(parsed, arg)
+	}
+	return parsed
+}
+
 // Set global states. NOTE: It is deliberately kept monolithic to ensure we dont miss out any flags.
 func setGlobalsFromContext(ctx *cli.Context) error {
 	quiet := ctx.IsSet("quiet") || ctx.GlobalIsSet("quiet")
@@ -123,6 +166,10 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	insecure := ctx.IsSet("insecure") || ctx.GlobalIsSet("insecure")
 	devMode := ctx.IsSet("dev") || ctx.GlobalIsSet("dev")
 	airgapped := ctx.IsSet("airgap") || ctx.GlobalIsSet("airgap")
+	dryRun := ctx.IsSet("dry-run") || ctx.GlobalIsSet("dry-run")
+	strict := ctx.IsSet("strict") || ctx.GlobalIsSet("strict")
+
+	jsonHostArgs = ctx.Args()
 
 	globalQuiet = globalQuiet || quiet
 	globalDebug = globalDebug || debug
@@ -132,6 +179,10 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	globalInsecure = globalInsecure || insecure
 	globalDevMode = globalDevMode || devMode
 	globalAirgapped = globalAirgapped || airgapped
+	globalDryRun = globalDryRun || dryRun
+	globalStrictJSON = globalStrictJSON || strict
+
+	console.SetColor("DryRun", color.New(color.FgYellow))
 
 	// Disable colorified messages if requested.
 	if globalNoColor || globalQuiet {
@@ -161,6 +212,18 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 		}
 	}
 
+	limitUploadSharedStr := ctx.String("limit-upload-shared")
+	if limitUploadSharedStr == "" {
+		limitUploadSharedStr = ctx.GlobalString("limit-upload-shared")
+	}
+	if limitUploadSharedStr != "" {
+		var e error
+		globalLimitUploadShared, e = humanize.ParseBytes(limitUploadSharedStr)
+		if e != nil {
+			return e
+		}
+	}
+
 	limitDownloadStr := ctx.String("limit-download")
 	if limitDownloadStr == "" {
 		limitDownloadStr = ctx.GlobalString("limit-download")
@@ -174,5 +237,141 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 		}
 	}
 
+	listVersionStr := ctx.String("list-version")
+	if listVersionStr == "" {
+		listVersionStr = ctx.GlobalString("list-version")
+	}
+	if listVersionStr != "" {
+		var e error
+		globalListVersion, e = parseListVersion(listVersionStr)
+		if e != nil {
+			return e
+		}
+	}
+
+	globalNoResume = globalNoResume || ctx.IsSet("no-resume") || ctx.GlobalIsSet("no-resume")
+
+	resumeThresholdStr := ctx.String("resume-threshold")
+	if resumeThresholdStr == "" {
+		resumeThresholdStr = ctx.GlobalString("resume-threshold")
+	}
+	if resumeThresholdStr != "" {
+		var e error
+		globalResumeThreshold, e = humanize.ParseBytes(resumeThresholdStr)
+		if e != nil {
+			return e
+		}
+	}
+
+	limitRequestsStr := ctx.String("limit-requests")
+	if limitRequestsStr == "" {
+		limitRequestsStr = ctx.GlobalString("limit-requests")
+	}
+	if limitRequestsStr != "" {
+		var e error
+		globalLimitRequests, e = strconv.ParseFloat(limitRequestsStr, 64)
+		if e != nil {
+			return e
+		}
+		if globalLimitRequests <= 0 {
+			return fmt.Errorf("invalid --limit-requests value `%s`, must be positive", limitRequestsStr)
+		}
+	}
+
+	limitBurstStr := ctx.String("limit-burst")
+	if limitBurstStr == "" {
+		limitBurstStr = ctx.GlobalString("limit-burst")
+	}
+	if limitBurstStr != "" {
+		var e error
+		globalLimitBurst, e = humanize.ParseBytes(limitBurstStr)
+		if e != nil {
+			return e
+		}
+	}
+
 	return nil
 }
+
+// globalFlagValues are the globals set from the flags of a command, saved
+// before and restored after running one of several commands in a row
+// within a process.
+type globalFlagValues struct {
+	quiet, json, jsonLine, debug, dryRun, strictJSON, noColor bool
+	insecure, devMode, airgapped, noResume, accelerate        bool
+	colorOff                                                  bool
+	colorProfile                                              termenv.Profile
+	jsonHostArgs                                              []string
+	connReadDeadline, connWriteDeadline                       time.Duration
+	limitUpload, limitUploadShared, limitDownload             uint64
+	limitBurst, resumeThreshold                               uint64
+	limitRequests                                             float64
+	listVersion                                               int
+	destinationLimits                                         map[string]uint64
+}
+
+// saveGlobalFlags returns the current globals set from the flags.
+func saveGlobalFlags() globalFlagValues {
+	return globalFlagValues{
+		quiet:             globalQuiet,
+		json:              globalJSON,
+		jsonLine:          globalJSONLine,
+		debug:             globalDebug,
+		dryRun:            globalDryRun,
+		strictJSON:        globalStrictJSON,
+		noColor:           globalNoColor,
+		insecure:          globalInsecure,
+		devMode:           globalDevMode,
+		airgapped:         globalAirgapped,
+		noResume:          globalNoResume,
+		accelerate:        globalAccelerate,
+		colorOff:          color.NoColor,
+		colorProfile:      lipgloss.ColorProfile(),
+		jsonHostArgs:      jsonHostArgs,
+		connReadDeadline:  globalConnReadDeadline,
+		connWriteDeadline: globalConnWriteDeadline,
+		limitUpload:       globalLimitUpload,
+		limitUploadShared: globalLimitUploadShared,
+		limitDownload:     globalLimitDownload,
+		limitBurst:        globalLimitBurst,
+		resumeThreshold:   globalResumeThreshold,
+		limitRequests:     globalLimitRequests,
+		listVersion:       globalListVersion,
+		destinationLimits: globalDestinationLimits,
+	}
+}
+
+// restore sets the globals back to the saved flags.
+func (f globalFlagValues) restore() {
+	globalQuiet = f.quiet
+	globalJSON = f.json
+	globalJSONLine = f.jsonLine
+	globalDebug = f.debug
+	globalDryRun = f.dryRun
+	globalStrictJSON = f.strictJSON
+	globalNoColor = f.noColor
+	globalInsecure = f.insecure
+	globalDevMode = f.devMode
+	globalAirgapped = f.airgapped
+	globalNoResume = f.noResume
+	globalAccelerate = f.accelerate
+	if f.colorOff {
+		console.SetColorOff()
+	} else {
+		console.SetColorOn()
+	}
+	lipgloss.SetColorProfile(f.colorProfile)
+	// The host of the JSON messages is looked up again from the
+	// restored arguments.
+	jsonHostArgs, jsonHostOnce, jsonHostName = f.jsonHostArgs, sync.Once{}, ""
+	globalConnReadDeadline = f.connReadDeadline
+	globalConnWriteDeadline = f.connWriteDeadline
+	globalLimitUpload = f.limitUpload
+	globalLimitUploadShared = f.limitUploadShared
+	globalLimitDownload = f.limitDownload
+	globalLimitBurst = f.limitBurst
+	globalResumeThreshold = f.resumeThreshold
+	globalLimitRequests = f.limitRequests
+	globalListVersion = f.listVersion
+	globalDestinationLimits = f.destinationLimits
+}
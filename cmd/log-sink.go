@@ -0,0 +1,167 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// logSinkBatchSize is the number of records posted at once to an
+// HTTP log sink.
+const logSinkBatchSize = 100
+
+// logSink receives a JSON record for every message printed with
+// printMsg, in addition to or instead of the regular output.
+type logSink interface {
+	Write(record string) error
+	Close() error
+}
+
+var (
+	globalLogSink     logSink
+	globalLogSinkOnly bool // do not print to stdout when a log sink is set.
+	logSinkMu         sync.Mutex
+)
+
+// newLogSink opens the log sink given by target, one of `syslog`,
+// `unix:///path/to/socket` or an http(s) URL.
+func newLogSink(target string) (logSink, *probe.Error) {
+	if target == "syslog" {
+		return newSyslogSink()
+	}
+	u, e := url.Parse(target)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	switch u.Scheme {
+	case "unix":
+		conn, e := net.Dial("unix", u.Path)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return &socketSink{conn: conn}, nil
+	case "http", "https":
+		return &httpSink{
+			url:    target,
+			client: &http.Client{Transport: getTransportForConfig(NewS3Config("", target, nil), false)},
+		}, nil
+	}
+	return nil, probe.NewError(fmt.Errorf("unsupported log sink `%s`, use syslog, unix:///path or an http(s) URL", target))
+}
+
+// openLogSink opens the log sink given by target for the messages of
+// the command. closeLogSink flushes it when the command returns, it is
+// called on a fatal error or a signal too.
+func openLogSink(target string, only bool) *probe.Error {
+	sink, err := newLogSink(target)
+	if err != nil {
+		return err.Trace(target)
+	}
+	logSinkMu.Lock()
+	globalLogSink, globalLogSinkOnly = sink, only
+	logSinkMu.Unlock()
+	atExit(closeLogSink)
+	return nil
+}
+
+// sendToLogSink writes the JSON form of msg to the log sink, whatever
+// the output format of the terminal. Records that cannot be delivered
+// are fatal rather than silently lost.
+func sendToLogSink(msg message) {
+	logSinkMu.Lock()
+	var e error
+	if globalLogSink != nil {
+		e = globalLogSink.Write(compactJSON(withJSONHost(msg, msg.JSON())))
+	}
+	// Unlocked for closeLogSink to run when exiting.
+	logSinkMu.Unlock()
+	fatalIf(probe.NewError(e), "Unable to write to the log sink.")
+}
+
+// closeLogSink flushes and closes the log sink, if any.
+func closeLogSink() {
+	logSinkMu.Lock()
+	if globalLogSink == nil {
+		logSinkMu.Unlock()
+		return
+	}
+	e := globalLogSink.Close()
+	globalLogSink = nil
+	logSinkMu.Unlock()
+	fatalIf(probe.NewError(e), "Unable to write to the log sink.")
+}
+
+// socketSink writes newline delimited records to a Unix socket.
+type socketSink struct {
+	conn net.Conn
+}
+
+func (s *socketSink) Write(record string) error {
+	_, e := s.conn.Write([]byte(record + "\n"))
+	return e
+}
+
+func (s *socketSink) Close() error {
+	return s.conn.Close()
+}
+
+// httpSink posts records to an HTTP endpoint as newline delimited
+// JSON, logSinkBatchSize records per request.
+type httpSink struct {
+	url     string
+	client  *http.Client
+	pending []string
+}
+
+func (s *httpSink) Write(record string) error {
+	s.pending = append(s.pending, record)
+	if len(s.pending) < logSinkBatchSize {
+		return nil
+	}
+	return s.flush()
+}
+
+func (s *httpSink) Close() error {
+	return s.flush()
+}
+
+func (s *httpSink) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	body := strings.Join(s.pending, "\n") + "\n"
+	s.pending = s.pending[:0]
+	resp, e := s.client.Post(s.url, "application/x-ndjson", bytes.NewBufferString(body))
+	if e != nil {
+		return e
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("log sink `" + s.url + "` replied " + resp.Status)
+	}
+	return nil
+}
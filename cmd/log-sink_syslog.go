@@ -0,0 +1,47 @@
+//go:build !windows && !plan9
+
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"log/syslog"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// syslogSink writes records to the local syslog daemon.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func newSyslogSink() (logSink, *probe.Error) {
+	w, e := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "mc")
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Write(record string) error {
+	return s.w.Info(record)
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
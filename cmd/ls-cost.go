@@ -0,0 +1,71 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// listPriceTable - monthly storage price in $ per GB of each storage
+// class, the "DEFAULT" entry prices classes missing from the table.
+type listPriceTable map[string]float64
+
+const listPriceDefaultClass = "DEFAULT"
+
+// bytesPerGB is the GB storage is billed by, 2^30 bytes as for Amazon S3.
+const bytesPerGB = 1 << 30
+
+// loadListPriceTable - reads a JSON price table given with --price-table,
+// e.g. {"STANDARD": 0.023, "GLACIER": 0.0036, "DEFAULT": 0.023}.
+func loadListPriceTable(file string) (listPriceTable, *probe.Error) {
+	data, e := os.ReadFile(file)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(file)
+	}
+	var prices map[string]float64
+	if e = json.Unmarshal(data, &prices); e != nil {
+		return nil, probe.NewError(e).Trace(file)
+	}
+	table := make(listPriceTable, len(prices))
+	for class, price := range prices {
+		if price < 0 {
+			return nil, probe.NewError(fmt.Errorf("negative price %v for storage class `%s`", price, class)).Trace(file)
+		}
+		table[strings.ToUpper(class)] = price
+	}
+	return table, nil
+}
+
+// monthlyCost - returns the estimated monthly cost of storing size bytes
+// in storageClass. Listings do not always report the storage class of
+// objects, those are priced as STANDARD and reported as inferred.
+func (t listPriceTable) monthlyCost(storageClass string, size int64) (cost float64, inferred bool) {
+	if storageClass == "" {
+		storageClass, inferred = "STANDARD", true
+	}
+	price, ok := t[strings.ToUpper(storageClass)]
+	if !ok {
+		price = t[listPriceDefaultClass]
+	}
+	return float64(size) / bytesPerGB * price, inferred
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// duplicateGroup - keys of the objects sharing the same content.
+type duplicateGroup struct {
+	ETag string   `json:"etag"`
+	Size int64    `json:"size"`
+	Keys []string `json:"keys"`
+}
+
+// duplicateGroupMessage container for ls --dupes output
+type duplicateGroupMessage duplicateGroup
+
+// String colorized group size and etag, followed by one key per line
+func (d duplicateGroupMessage) String() string {
+	var b strings.Builder
+	b.WriteString(console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(d.Size))), ""))))
+	b.WriteString(fmt.Sprintf(" x%d ", len(d.Keys)))
+	b.WriteString(console.Colorize("Dir", d.ETag))
+	for _, key := range d.Keys {
+		b.WriteString("\n  " + key)
+	}
+	return b.String()
+}
+
+// JSON jsonified duplicate group, {etag, size, keys}
+func (d duplicateGroupMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(duplicateGroup(d), "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// duplicateCandidate - a listed object, kept until the listing ends.
+type duplicateCandidate struct {
+	key     string
+	content *ClientContent
+}
+
+// isMultipartETag returns true if etag is the etag of an object uploaded
+// in parts, which is not the MD5 of its content.
+func isMultipartETag(etag string) bool {
+	return strings.Contains(etag, "-")
+}
+
+// findDuplicates groups the candidates by etag and size and returns the
+// groups of more than one object, those wasting the most space first.
+// Objects without an etag, like local files, and with hashMultipart the
+// objects uploaded in parts are compared by the SHA-256 of their content
+// returned by hash, only objects sharing their size with another one are
+// hashed.
+func findDuplicates(candidates map[int64][]duplicateCandidate, hashMultipart bool, hash func(*ClientContent) (string, *probe.Error)) (groups []duplicateGroup) {
+	for size, sized := range candidates {
+		if len(sized) < 2 {
+			continue
+		}
+		byETag := make(map[string][]string)
+		var etags []string
+		for _, c := range sized {
+			etag := strings.Trim(c.content.ETag, `"`)
+			if etag == "" || (hashMultipart && isMultipartETag(etag)) {
+				sum, err := hash(c.content)
+				if err != nil {
+					errorIf(err.Trace(c.key), "Unable to hash `"+c.key+"`.")
+					continue
+				}
+				etag = "sha256:" + sum
+			}
+			if _, ok := byETag[etag]; !ok {
+				etags = append(etags, etag)
+			}
+			byETag[etag] = append(byETag[etag], c.key)
+		}
+		for _, etag := range etags {
+			if keys := byETag[etag]; len(keys) > 1 {
+				sort.Strings(keys)
+				groups = append(groups, duplicateGroup{ETag: etag, Size: size, Keys: keys})
+			}
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		wi, wj := groups[i].Size*int64(len(groups[i].Keys)-1), groups[j].Size*int64(len(groups[j].Keys)-1)
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i].Keys[0] < groups[j].Keys[0]
+	})
+	return groups
+}
+
+// hashContent returns the hex encoded SHA-256 of the content of a listed
+// object, read in full.
+func hashContent(ctx context.Context, alias string, content *ClientContent) (string, *probe.Error) {
+	return hashObject(ctx, alias, content.URL.String(), GetOptions{VersionID: content.VersionID})
+}
+
+// hashObject returns the hex encoded SHA-256 of the content of an object,
+// read in full.
+func hashObject(ctx context.Context, alias, urlStr string, opts GetOptions) (string, *probe.Error) {
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return "", err.Trace(urlStr)
+	}
+	reader, err := clnt.Get(ctx, opts)
+	if err != nil {
+		return "", err.Trace(urlStr)
+	}
+	defer reader.Close()
+	h := sha256.New()
+	if _, e := io.Copy(h, reader); e != nil {
+		return "", probe.NewError(e).Trace(urlStr)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+)
+
+// Object attributes fetched per listed object with ls --enrich.
+const (
+	enrichContentType  = "content-type"
+	enrichTags         = "tags"
+	enrichStorageClass = "storage-class"
+	enrichMetadata     = "metadata"
+	enrichChecksum     = "checksum"
+	enrichLockInfo     = "lock-info"
+)
+
+// listEnrich - object attributes requested with ls --enrich, fetched
+// for every listed object by a pool of workers.
+type listEnrich struct {
+	contentType  bool
+	tags         bool
+	storageClass bool
+	metadata     bool
+	checksum     bool
+	lockInfo     bool
+	// encryption fetches the server side encryption headers, for
+	// 'stat --policy' only.
+	encryption bool
+	workers    int
+}
+
+// parseListEnrich - parses a comma separated list of attributes.
+func parseListEnrich(fields string, workers int) (*listEnrich, *probe.Error) {
+	if workers <= 0 {
+		return nil, probe.NewError(fmt.Errorf("number of workers must be positive, found %d", workers))
+	}
+	enrich := &listEnrich{workers: workers}
+	for _, field := range strings.Split(fields, ",") {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case enrichContentType:
+			enrich.contentType = true
+		case enrichTags:
+			enrich.tags = true
+		case enrichStorageClass:
+			enrich.storageClass = true
+		case enrichMetadata:
+			enrich.metadata = true
+		case enrichChecksum:
+			enrich.checksum = true
+		case enrichLockInfo:
+			enrich.lockInfo = true
+		default:
+			return nil, probe.NewError(fmt.Errorf("unknown attribute `%s`, choose from [%s, %s, %s, %s, %s, %s]",
+				field, enrichContentType, enrichTags, enrichStorageClass, enrichMetadata, enrichChecksum, enrichLockInfo))
+		}
+	}
+	return enrich, nil
+}
+
+// isMissingContentType returns true for an empty or the generic binary
+// content type, which browsers download instead of displaying.
+func isMissingContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "" || strings.EqualFold(mediaType, "application/octet-stream")
+}
+
+// needsStat returns true if the requested attributes need a HEAD request.
+func (e listEnrich) needsStat() bool {
+	return e.contentType || e.storageClass || e.metadata || e.checksum || e.encryption
+}
+
+// enrichListing - fetches the requested attributes of the listed objects
+// with up to e.workers concurrent requests. Contents are sent on the
+// returned channel in the order they were listed.
+func enrichListing(ctx context.Context, alias string, contentCh <-chan *ClientContent, e listEnrich) <-chan *ClientContent {
+	// Each content is queued with the channel its worker sends it back
+	// on, the queue length bounds the contents held in memory.
+	pendingCh := make(chan chan *ClientContent, e.workers)
+	go func() {
+		defer close(pendingCh)
+		workerCh := make(chan struct{}, e.workers)
+		for content := range contentCh {
+			doneCh := make(chan *ClientContent, 1)
+			pendingCh <- doneCh
+			if content.Err != nil || content.Type.IsDir() || content.IsDeleteMarker {
+				doneCh <- content
+				continue
+			}
+			workerCh <- struct{}{}
+			go func(content *ClientContent) {
+				defer func() { <-workerCh }()
+				enrichContent(ctx, alias, content, e)
+				doneCh <- content
+			}(content)
+		}
+	}()
+
+	enrichedCh := make(chan *ClientContent)
+	go func() {
+		defer close(enrichedCh)
+		for doneCh := range pendingCh {
+			enrichedCh <- <-doneCh
+		}
+	}()
+	return enrichedCh
+}
+
+// enrichContent - fetches the requested attributes of a listed object,
+// failures are reported and leave the object as listed.
+func enrichContent(ctx context.Context, alias string, content *ClientContent, e listEnrich) {
+	urlStr := content.URL.String()
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		errorIf(err.Trace(urlStr), "Unable to initialize `"+urlStr+"`.")
+		return
+	}
+	if e.needsStat() {
+		st, err := clnt.Stat(ctx, StatOptions{versionID: content.VersionID, checksum: e.checksum})
+		if err != nil {
+			errorIf(err.Trace(urlStr), "Unable to get the metadata of `"+urlStr+"`.")
+			return
+		}
+		if content.Metadata == nil {
+			content.Metadata = make(map[string]string)
+		}
+		if e.contentType {
+			content.Metadata["Content-Type"] = st.Metadata["Content-Type"]
+		}
+		if e.storageClass {
+			content.StorageClass = st.StorageClass
+			// Amazon S3 omits the storage class of STANDARD objects.
+			if content.StorageClass == "" && content.URL.Type == objectStorage {
+				content.StorageClass = "STANDARD"
+			}
+		}
+		if e.metadata {
+			content.UserMetadata = st.UserMetadata
+		}
+		if e.checksum {
+			content.ChecksumType, content.Checksum = st.ChecksumType, st.Checksum
+		}
+		if e.encryption {
+			content.Encryption = contentEncryption(st.Metadata)
+		}
+	}
+	if e.tags {
+		tags, err := clnt.GetTags(ctx, content.VersionID)
+		if err != nil {
+			errorIf(err.Trace(urlStr), "Unable to get the tags of `"+urlStr+"`.")
+			return
+		}
+		content.Tags = tags
+	}
+	if e.lockInfo {
+		mode, until, err := clnt.GetObjectRetention(ctx, content.VersionID)
+		if err != nil && !isObjectLockUnset(err) {
+			errorIf(err.Trace(urlStr), "Unable to get the retention of `"+urlStr+"`.")
+			return
+		}
+		legalHold, err := clnt.GetObjectLegalHold(ctx, content.VersionID)
+		if err != nil && !isObjectLockUnset(err) {
+			errorIf(err.Trace(urlStr), "Unable to get the legal hold of `"+urlStr+"`.")
+			return
+		}
+		content.RetentionMode, content.RetainUntil = string(mode), until
+		content.LegalHold = string(legalHold)
+	}
+}
+
+// isObjectLockUnset returns true for the errors of objects without a
+// retention or a legal hold, of buckets without object lock and of
+// clients which do not support it, all listed without lock info.
+func isObjectLockUnset(err *probe.Error) bool {
+	if _, ok := err.ToGoError().(APINotImplemented); ok {
+		return true
+	}
+	switch minio.ToErrorResponse(err.ToGoError()).Code {
+	case "NoSuchObjectLockConfiguration", "ObjectLockConfigurationNotFoundError":
+		return true
+	}
+	return false
+}
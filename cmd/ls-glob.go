@@ -0,0 +1,90 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// globChars are the wildcards of path.Match expanded in ls arguments.
+const globChars = "*?["
+
+// expandListGlob - expands the wildcards of a listed URL segment by
+// segment, listing each folder matched so far and matching its entries
+// against the next segment with path.Match. Segments without wildcards
+// are kept as they are, they narrow the listing without any request.
+func expandListGlob(ctx context.Context, url string) ([]string, *probe.Error) {
+	segments := strings.Split(filepath.ToSlash(url), "/")
+	for _, segment := range segments {
+		if _, e := path.Match(segment, ""); e != nil {
+			return nil, probe.NewError(e).Trace(url)
+		}
+	}
+	return expandListGlobSegments(ctx, "", segments)
+}
+
+// expandListGlobSegments - expands segments below the folder prefix.
+func expandListGlobSegments(ctx context.Context, prefix string, segments []string) ([]string, *probe.Error) {
+	i := 0
+	for i < len(segments) && !strings.ContainsAny(segments[i], globChars) {
+		i++
+	}
+	if i == len(segments) {
+		return []string{prefix + strings.Join(segments, "/")}, nil
+	}
+
+	folder := prefix + strings.Join(segments[:i], "/") + "/"
+	clnt, err := newClient(folder)
+	if err != nil {
+		return nil, err.Trace(folder)
+	}
+	var matches []string
+	for content := range clnt.List(ctx, ListOptions{ShowDir: DirFirst}) {
+		if content.Err != nil {
+			switch content.Err.ToGoError().(type) {
+			case PathNotFound, ObjectMissing, BucketDoesNotExist:
+				// Nothing below a folder matched so far.
+				return matches, nil
+			}
+			return nil, content.Err.Trace(folder)
+		}
+		name := path.Base(strings.TrimSuffix(filepath.ToSlash(content.URL.Path), "/"))
+		if matched, _ := path.Match(segments[i], name); !matched {
+			continue
+		}
+		rest := segments[i+1:]
+		switch {
+		case len(rest) == 0 && content.Type.IsDir():
+			matches = append(matches, folder+name+"/")
+		case len(rest) == 0:
+			matches = append(matches, folder+name)
+		case content.Type.IsDir():
+			sub, err := expandListGlobSegments(ctx, folder+name+"/", rest)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, sub...)
+		}
+	}
+	return matches, nil
+}
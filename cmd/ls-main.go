@@ -20,10 +20,12 @@ package cmd
 import (
 	"context"
 	"errors"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/console"
@@ -40,6 +42,14 @@ var (
 			Name:  "versions",
 			Usage: "list all versions",
 		},
+		cli.BoolFlag{
+			Name:  "only-noncurrent",
+			Usage: "list only noncurrent versions, requires --versions",
+		},
+		cli.BoolFlag{
+			Name:  "only-delete-markers",
+			Usage: "list only delete markers, requires --versions",
+		},
 		cli.BoolFlag{
 			Name:  "recursive, r",
 			Usage: "list recursively",
@@ -60,6 +70,173 @@ var (
 			Name:  "zip",
 			Usage: "list files inside zip archive (MinIO servers only)",
 		},
+		cli.BoolFlag{
+			Name:  "epoch",
+			Usage: "print last modified time as Unix epoch seconds, same as --time-style=epoch",
+		},
+		cli.BoolFlag{
+			Name:  "relative-time",
+			Usage: "print last modified time relative to now, e.g. \"3 hours ago\", same as --time-style=relative",
+		},
+		cli.StringFlag{
+			Name:  "time-style",
+			Usage: "style of the last modified time, choose one of [local, utc, epoch, relative]",
+		},
+		cli.BoolFlag{
+			Name:  "icons",
+			Usage: "prefix entries with an icon for their type when printing to a terminal",
+		},
+		cli.BoolFlag{
+			Name:  "progress",
+			Usage: "periodically print the number of listed objects on stderr when it is a terminal",
+		},
+		cli.BoolFlag{
+			Name:  "stats",
+			Usage: "print listing duration, API calls and objects per second on stderr",
+		},
+		cli.BoolFlag{
+			Name:  "heartbeat",
+			Usage: "print a heartbeat record in JSON output when nothing was listed for a few seconds",
+		},
+		cli.StringFlag{
+			Name:  "delimiter",
+			Usage: "group keys by this delimiter, '' lists every object as a full key in a single pass",
+			Value: "/",
+		},
+		cli.BoolFlag{
+			Name:  "no-trim",
+			Usage: "print object keys in full instead of relative to the listed prefix",
+		},
+		cli.BoolFlag{
+			Name:  "unique-prefixes",
+			Usage: "list each distinct prefix once instead of objects, requires --recursive",
+		},
+		cli.BoolFlag{
+			Name:  "group-sizes",
+			Usage: "print the total size and number of objects of each immediate child prefix, requires --recursive",
+		},
+		cli.StringFlag{
+			Name:  "group-by",
+			Usage: "print the total size and number of objects of each group, requires --recursive, choose one of [extension]",
+		},
+		cli.IntFlag{
+			Name:  "top",
+			Usage: "print only the first N groups of --group-by",
+		},
+		cli.IntFlag{
+			Name:  "per-prefix-limit",
+			Usage: "list at most this many objects under each immediate child prefix, requires --recursive",
+		},
+		cli.StringFlag{
+			Name:  "sort",
+			Usage: "order of --group-sizes and --group-by output (default: size) or, with size, list the largest objects with --per-prefix-limit, choose one of [size, objects, name]",
+		},
+		cli.StringFlag{
+			Name:  "log-sink",
+			Usage: "also send each record as JSON to a log sink, choose one of [syslog, unix:///path/to/socket, http(s)://endpoint]",
+		},
+		cli.BoolFlag{
+			Name:  "log-sink-only",
+			Usage: "send records to --log-sink only, without printing them",
+		},
+		cli.StringFlag{
+			Name:  "save-snapshot",
+			Usage: "save the size, modification time and etag of the listed objects to a snapshot file instead of printing them",
+		},
+		cli.StringFlag{
+			Name:  "diff-snapshot",
+			Usage: "print the objects added, removed or modified since the listing saved in a snapshot file",
+		},
+		cli.StringFlag{
+			Name:  "changed-since",
+			Usage: "like --diff-snapshot, but print only the objects added or whose etag changed",
+		},
+		cli.BoolFlag{
+			Name:  "as-urls",
+			Usage: "print the URLs of the objects found by --diff-snapshot or --changed-since, e.g. to purge them from a CDN",
+		},
+		cli.StringFlag{
+			Name:  "url-base",
+			Usage: "URL the keys are appended to with --as-urls, e.g. https://cdn.example.com/ (default: the listed URL)",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude-prefix",
+			Usage: "skip the objects under this prefix of the listed folder without listing them, can be repeated",
+		},
+		cli.StringFlag{
+			Name:  "enrich",
+			Usage: "fetch these attributes of each listed object, comma separated from [content-type, tags, storage-class, metadata, checksum, lock-info]",
+		},
+		cli.BoolFlag{
+			Name:  "flag-missing-content-type",
+			Usage: "flag objects without a content type or with application/octet-stream, fetched like --enrich",
+		},
+		cli.BoolFlag{
+			Name:  "checksum",
+			Usage: "show the additional checksum (CRC32C, CRC32, SHA256 or SHA1) stored with each object, fetched like --enrich",
+		},
+		cli.BoolFlag{
+			Name:  "lock-info",
+			Usage: "show the retention mode, retain until date and legal hold of each object, fetched like --enrich",
+		},
+		cli.StringFlag{
+			Name:  "missing-tag",
+			Usage: "list only the objects without this tag key and report the share of objects having it, fetched like --enrich, requires --recursive",
+		},
+		cli.IntFlag{
+			Name:  "workers",
+			Usage: "number of parallel requests fetching the attributes of --enrich",
+			Value: 8,
+		},
+		cli.BoolFlag{
+			Name:  "no-match-error",
+			Usage: "fail if the wildcards of a listed URL, e.g. \"s3/logs-202[34]/app/*.gz\", match no objects",
+		},
+		cli.BoolFlag{
+			Name:  "region-info",
+			Usage: "show the region of each bucket listed at the root of an alias, one extra request per bucket",
+		},
+		cli.BoolFlag{
+			Name:  "public-info",
+			Usage: "flag the buckets listed at the root of an alias whose policy allows anonymous access, one extra request per bucket",
+		},
+		cli.BoolFlag{
+			Name:  "dupes",
+			Usage: "report the groups of objects sharing the same etag and size, requires --recursive",
+		},
+		cli.BoolFlag{
+			Name:  "dupes-hash",
+			Usage: "with --dupes, compare the objects uploaded in parts by the SHA-256 of their content, reads them in full",
+		},
+		cli.StringFlag{
+			Name:  "grown-since",
+			Usage: "list only objects created or modified after a date or a duration ago, e.g. 2024-01-01 or 7d, and report their number and total size",
+		},
+		cli.BoolFlag{
+			Name:  "age-histogram",
+			Usage: "report the number and total size of objects by age of their last modification, requires --recursive",
+		},
+		cli.StringFlag{
+			Name:  "age-buckets",
+			Usage: "bounds of the age buckets of --age-histogram",
+			Value: defaultAgeBuckets,
+		},
+		cli.BoolFlag{
+			Name:  "merkle",
+			Usage: "print a Merkle root over the keys, sizes and etags of the objects in the key order of the listing, requires --recursive",
+		},
+		cli.BoolFlag{
+			Name:  "merkle-content",
+			Usage: "with --merkle, hash the content of the objects instead of their size and etag, reads them in full",
+		},
+		cli.DurationFlag{
+			Name:  "max-runtime",
+			Usage: "stop listing after a duration, e.g. 60s, print what was found and exit with status 124",
+		},
+		cli.StringFlag{
+			Name:  "price-table",
+			Usage: "estimate the monthly storage cost of objects from a JSON file of $ per GB-month by storage class, e.g. {\"STANDARD\": 0.023, \"DEFAULT\": 0.023}",
+		},
 	}
 )
 
@@ -112,6 +289,104 @@ EXAMPLES:
   
   10. List all objects on mybucket, for the GLACIER storage class
      {{.Prompt}} {{.HelpName}} --storage-class 'GLACIER' s3/mybucket 
+
+  11. List the distinct prefixes of all objects in mybucket.
+     {{.Prompt}} {{.HelpName}} --recursive --unique-prefixes s3/mybucket
+
+  12. Show how long a recursive listing of mybucket took and how many API calls it needed.
+     {{.Prompt}} {{.HelpName}} --recursive --stats s3/mybucket > /dev/null
+
+  13. List the contents of mybucket with an icon showing the type of each entry.
+     {{.Prompt}} {{.HelpName}} --icons s3/mybucket
+
+  14. List the contents of mybucket with last modified times as Unix epoch seconds.
+     {{.Prompt}} {{.HelpName}} --epoch s3/mybucket
+
+  15. List the noncurrent versions of all objects in mybucket, candidates for lifecycle expiry.
+     {{.Prompt}} {{.HelpName}} --recursive --versions --only-noncurrent s3/mybucket
+
+  16. Find the delete markers hiding objects in mybucket, to remove them and recover the objects.
+     {{.Prompt}} {{.HelpName}} --recursive --versions --only-delete-markers s3/mybucket
+
+  17. List a very large bucket into a file, showing how many objects were listed so far.
+     {{.Prompt}} {{.HelpName}} --recursive --progress s3/mybucket > objects.txt
+
+  18. List every object of mybucket under 'logs/' in a single pass, showing exact object keys.
+     {{.Prompt}} {{.HelpName}} --delimiter='' --no-trim s3/mybucket/logs/
+
+  19. List the contents of mybucket with how long ago each object was modified.
+     {{.Prompt}} {{.HelpName}} --relative-time s3/mybucket
+
+  20. Show which top level prefixes of mybucket take the most space.
+     {{.Prompt}} {{.HelpName}} --recursive --group-sizes s3/mybucket
+
+  21. Sample mybucket, listing the 5 largest objects under each top level prefix.
+     {{.Prompt}} {{.HelpName}} --recursive --per-prefix-limit=5 --sort=size s3/mybucket
+
+  22. Feed the full listing of mybucket to the local syslog for auditing, without printing it.
+     {{.Prompt}} {{.HelpName}} --recursive --log-sink=syslog --log-sink-only s3/mybucket
+
+  23. Report what changed in mybucket since yesterday's snapshot, then take today's.
+     {{.Prompt}} {{.HelpName}} --recursive --diff-snapshot=yesterday.json s3/mybucket
+     {{.Prompt}} {{.HelpName}} --recursive --save-snapshot=today.json s3/mybucket
+
+  24. Estimate the monthly storage cost of mybucket, per object in JSON and in total.
+     {{.Prompt}} {{.HelpName}} --recursive --summarize --json --price-table=prices.json s3/mybucket
+
+  25. List mybucket with the content type and tags of each object, fetched by 32 parallel workers.
+     {{.Prompt}} {{.HelpName}} --recursive --enrich=content-type,tags --workers=32 s3/mybucket
+
+  26. List mybucket recursively, skipping everything under the "tmp/" and "cache/" prefixes.
+     {{.Prompt}} {{.HelpName}} --recursive --exclude-prefix=tmp/ --exclude-prefix=cache/ s3/mybucket
+
+  27. List all buckets of an alias with their creation date and region.
+     {{.Prompt}} {{.HelpName}} --region-info s3/
+
+  28. Audit a static website bucket for objects served without a proper content type.
+     {{.Prompt}} {{.HelpName}} --recursive --flag-missing-content-type s3/website
+
+  29. List the compressed app logs of the buckets of 2023 and 2024, expanding the wildcards of every segment.
+     {{.Prompt}} {{.HelpName}} "s3/logs-202[34]/app/*.gz"
+
+  30. Report how much data landed in mybucket since the start of the year, and under which top level prefixes.
+     {{.Prompt}} {{.HelpName}} --recursive --grown-since 2024-01-01 --group-sizes s3/mybucket
+
+  31. Check which objects of mybucket were uploaded with an additional checksum, and with which algorithm.
+     {{.Prompt}} {{.HelpName}} --recursive --checksum s3/mybucket
+
+  32. Audit the buckets of an alias for accidental public exposure.
+     {{.Prompt}} {{.HelpName}} --public-info s3/
+
+  33. Find the objects of mybucket stored more than once, hashing the content of the objects uploaded in parts.
+     {{.Prompt}} {{.HelpName}} --recursive --dupes --dupes-hash s3/mybucket
+
+  34. Find out which five file types take the most space in mybucket.
+     {{.Prompt}} {{.HelpName}} --recursive --group-by extension --top 5 s3/mybucket
+
+  35. Stream a listing of a sparse bucket to a consumer that times out on silence.
+     {{.Prompt}} {{.HelpName}} --recursive --json --heartbeat s3/mybucket | consumer
+
+  36. Print the CDN URLs of the objects of a site added or changed since the last deploy, to purge them.
+     {{.Prompt}} {{.HelpName}} --recursive --changed-since deploy.json --as-urls --url-base https://cdn.example.com/ s3/site/
+
+  37. Audit the retention and legal hold of every object of a locked bucket.
+     {{.Prompt}} {{.HelpName}} --recursive --lock-info s3/mybucket
+
+  38. Count the objects a dashboard query finds within a minute, partial counts exit with status 124.
+     {{.Prompt}} {{.HelpName}} --recursive --summarize --max-runtime 60s s3/mybucket
+
+  39. Find how much of a bucket was not modified for a quarter, to plan a lifecycle transition.
+     {{.Prompt}} {{.HelpName}} --recursive --age-histogram --age-buckets 30d,90d,365d s3/mybucket
+
+  40. Audit a bucket where every object must have an owner tag, listing the objects without one.
+     {{.Prompt}} {{.HelpName}} --recursive --missing-tag owner s3/mybucket
+
+  41. Check that no object of a bucket was added, removed or rewritten since its Merkle root was last printed.
+     {{.Prompt}} {{.HelpName}} --recursive --merkle s3/mybucket
+
+  42. Check that a bucket and its copy hold the same objects, by their content since multipart etags differ with the part size.
+     {{.Prompt}} {{.HelpName}} --recursive --merkle --merkle-content s3/mybucket
+     {{.Prompt}} {{.HelpName}} --recursive --merkle --merkle-content backup/mybucket
 `,
 }
 
@@ -119,11 +394,20 @@ var rewindSupportedFormat = []string{
 	"2006.01.02",
 	"2006.01.02T15:04",
 	"2006.01.02T15:04:05",
+	"2006-01-02",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
 	time.RFC3339,
 }
 
 // Parse rewind flag while considering the system local time zone
 func parseRewindFlag(rewind string) (timeRef time.Time) {
+	return parseTimeRefFlag("rewind", rewind)
+}
+
+// parseTimeRefFlag parses the date or the duration ago of a flag like
+// --rewind in the system local time zone.
+func parseTimeRefFlag(flag, rewind string) (timeRef time.Time) {
 	if rewind != "" {
 		location, e := time.LoadLocation("Local")
 		if e != nil {
@@ -142,7 +426,7 @@ func parseRewindFlag(rewind string) (timeRef time.Time) {
 			if duration, e := ParseDuration(rewind); e == nil {
 				if duration < 0 {
 					fatalIf(probe.NewError(errors.New("negative duration is not supported")),
-						"Unable to parse --rewind argument")
+						"Unable to parse --"+flag+" argument")
 				}
 				timeRef = time.Now().Add(-time.Duration(duration))
 			}
@@ -150,7 +434,7 @@ func parseRewindFlag(rewind string) (timeRef time.Time) {
 
 		if timeRef.IsZero() {
 			// rewind argument still not parsed, error out
-			fatalIf(probe.NewError(errors.New("unknown format")), "Unable to parse --rewind argument")
+			fatalIf(probe.NewError(errors.New("unknown format")), "Unable to parse --"+flag+" argument")
 		}
 	}
 	return
@@ -173,21 +457,262 @@ func checkListSyntax(cliCtx *cli.Context) ([]string, doListOptions) {
 	withOlderVersions := cliCtx.Bool("versions")
 	isSummary := cliCtx.Bool("summarize")
 	listZip := cliCtx.Bool("zip")
+	uniquePrefixes := cliCtx.Bool("unique-prefixes")
+	groupSizes := cliCtx.Bool("group-sizes")
 
 	timeRef := parseRewindFlag(cliCtx.String("rewind"))
 
 	if listZip && (withOlderVersions || !timeRef.IsZero()) {
 		fatalIf(errInvalidArgument().Trace(args...), "Zip file listing can only be performed on the latest version")
 	}
+	onlyNoncurrent := cliCtx.Bool("only-noncurrent")
+	onlyDeleteMarkers := cliCtx.Bool("only-delete-markers")
+	if (onlyNoncurrent || onlyDeleteMarkers) && !withOlderVersions {
+		fatalIf(errInvalidArgument().Trace(args...), "--only-noncurrent and --only-delete-markers can only be used with --versions")
+	}
+	if uniquePrefixes && !isRecursive {
+		fatalIf(errInvalidArgument().Trace(args...), "--unique-prefixes can only be used with --recursive")
+	}
+	if groupSizes && (!isRecursive || uniquePrefixes) {
+		fatalIf(errInvalidArgument().Trace(args...), "--group-sizes can only be used with --recursive and without --unique-prefixes")
+	}
+	if cliCtx.Bool("log-sink-only") && cliCtx.String("log-sink") == "" {
+		fatalIf(errInvalidArgument().Trace(args...), "--log-sink-only can only be used with --log-sink")
+	}
+	saveSnapshot := cliCtx.String("save-snapshot")
+	diffSnapshot := cliCtx.String("diff-snapshot")
+	// --changed-since is a --diff-snapshot printing fewer changes.
+	changedSince := cliCtx.String("changed-since")
+	if changedSince != "" {
+		if saveSnapshot != "" || diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--changed-since cannot be used with --save-snapshot or --diff-snapshot")
+		}
+		diffSnapshot = changedSince
+	}
+	if (cliCtx.Bool("as-urls") || cliCtx.String("url-base") != "") && diffSnapshot == "" {
+		fatalIf(errInvalidArgument().Trace(args...), "--as-urls and --url-base can only be used with --diff-snapshot or --changed-since")
+	}
+	if cliCtx.String("url-base") != "" && !cliCtx.Bool("as-urls") {
+		fatalIf(errInvalidArgument().Trace(args...), "--url-base can only be used with --as-urls")
+	}
+	if saveSnapshot != "" || diffSnapshot != "" {
+		if saveSnapshot != "" && diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--save-snapshot and --diff-snapshot cannot be used together")
+		}
+		if len(args) != 1 {
+			fatalIf(errInvalidArgument().Trace(args...), "--save-snapshot and --diff-snapshot take a single target")
+		}
+		if withOlderVersions || !timeRef.IsZero() || isIncomplete || listZip || uniquePrefixes || groupSizes || cliCtx.Int("per-prefix-limit") > 0 {
+			fatalIf(errInvalidArgument().Trace(args...), "--save-snapshot and --diff-snapshot cannot be used with --versions, --rewind, --incomplete, --zip, --unique-prefixes, --group-sizes or --per-prefix-limit")
+		}
+	}
+	var priceTable listPriceTable
+	if file := cliCtx.String("price-table"); file != "" {
+		if saveSnapshot != "" || diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--price-table cannot be used with --save-snapshot or --diff-snapshot")
+		}
+		var err *probe.Error
+		priceTable, err = loadListPriceTable(file)
+		fatalIf(err, "Unable to load the price table `"+file+"`.")
+	}
+	var excludePrefixes []string
+	for _, prefix := range cliCtx.StringSlice("exclude-prefix") {
+		if prefix == "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--exclude-prefix cannot be empty")
+		}
+		excludePrefixes = append(excludePrefixes, strings.TrimPrefix(prefix, "/"))
+	}
+	grownSince := parseTimeRefFlag("grown-since", cliCtx.String("grown-since"))
+	if !grownSince.IsZero() && (isIncomplete || uniquePrefixes || saveSnapshot != "" || diffSnapshot != "") {
+		fatalIf(errInvalidArgument().Trace(args...), "--grown-since cannot be used with --incomplete, --unique-prefixes, --save-snapshot or --diff-snapshot")
+	}
+	dupes := cliCtx.Bool("dupes")
+	if cliCtx.Bool("dupes-hash") && !dupes {
+		fatalIf(errInvalidArgument().Trace(args...), "--dupes-hash can only be used with --dupes")
+	}
+	if dupes && (!isRecursive || withOlderVersions || !timeRef.IsZero() || isIncomplete || uniquePrefixes || groupSizes || saveSnapshot != "" || diffSnapshot != "") {
+		fatalIf(errInvalidArgument().Trace(args...), "--dupes can only be used with --recursive and without --versions, --rewind, --incomplete, --unique-prefixes, --group-sizes, --save-snapshot or --diff-snapshot")
+	}
+	groupBy := strings.ToLower(cliCtx.String("group-by"))
+	if groupBy != "" {
+		if groupBy != groupByExtension {
+			fatalIf(errInvalidArgument().Trace(groupBy), "Invalid --group-by value, choose one of [extension].")
+		}
+		if !isRecursive || uniquePrefixes || groupSizes || dupes || saveSnapshot != "" || diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--group-by can only be used with --recursive and without --unique-prefixes, --group-sizes, --dupes, --save-snapshot or --diff-snapshot")
+		}
+	}
+	var ageHistogram *ageHistogram
+	if cliCtx.Bool("age-histogram") {
+		if !isRecursive || isIncomplete || uniquePrefixes || groupSizes || groupBy != "" || dupes || saveSnapshot != "" || diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--age-histogram can only be used with --recursive and without --incomplete, --unique-prefixes, --group-sizes, --group-by, --dupes, --save-snapshot or --diff-snapshot")
+		}
+		var err *probe.Error
+		ageHistogram, err = parseAgeBuckets(cliCtx.String("age-buckets"))
+		fatalIf(err, "Invalid --age-buckets value, expected increasing durations, e.g. "+defaultAgeBuckets+".")
+	} else if cliCtx.IsSet("age-buckets") {
+		fatalIf(errInvalidArgument().Trace(args...), "--age-buckets can only be used with --age-histogram")
+	}
+	top := cliCtx.Int("top")
+	if top < 0 || (top > 0 && groupBy == "") {
+		fatalIf(errInvalidArgument().Trace(args...), "--top must be positive and used with --group-by")
+	}
+	var enrich *listEnrich
+	missingContentType := cliCtx.Bool("flag-missing-content-type")
+	checksum := cliCtx.Bool("checksum")
+	lockInfo := cliCtx.Bool("lock-info")
+	missingTag := cliCtx.String("missing-tag")
+	if missingTag != "" && (!isRecursive || groupBy != "" || dupes || ageHistogram != nil) {
+		fatalIf(errInvalidArgument().Trace(args...), "--missing-tag can only be used with --recursive and without --group-by, --dupes or --age-histogram")
+	}
+	if fields := cliCtx.String("enrich"); fields != "" || missingContentType || checksum || lockInfo || missingTag != "" {
+		if isIncomplete || listZip || uniquePrefixes || groupSizes || saveSnapshot != "" || diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--enrich, --flag-missing-content-type, --checksum, --lock-info and --missing-tag cannot be used with --incomplete, --zip, --unique-prefixes, --group-sizes, --save-snapshot or --diff-snapshot")
+		}
+		// The content type, the checksum or the lock info of every
+		// object is fetched to flag or show it.
+		switch {
+		case fields != "":
+		case missingContentType:
+			fields = enrichContentType
+		case checksum:
+			fields = enrichChecksum
+		case missingTag != "":
+			fields = enrichTags
+		default:
+			fields = enrichLockInfo
+		}
+		var err *probe.Error
+		enrich, err = parseListEnrich(fields, cliCtx.Int("workers"))
+		fatalIf(err.Trace(fields), "Invalid --enrich value.")
+		enrich.contentType = enrich.contentType || missingContentType
+		enrich.checksum = enrich.checksum || checksum
+		enrich.lockInfo = enrich.lockInfo || lockInfo
+		enrich.tags = enrich.tags || missingTag != ""
+	}
+	perPrefixLimit := cliCtx.Int("per-prefix-limit")
+	if perPrefixLimit < 0 {
+		fatalIf(errInvalidArgument().Trace(args...), "--per-prefix-limit cannot be negative")
+	}
+	if perPrefixLimit > 0 && (!isRecursive || uniquePrefixes || groupSizes || groupBy != "" || dupes) {
+		fatalIf(errInvalidArgument().Trace(args...), "--per-prefix-limit can only be used with --recursive and without --unique-prefixes, --group-sizes, --group-by or --dupes")
+	}
+	var merkleMode string
+	if cliCtx.Bool("merkle") {
+		if !isRecursive || withOlderVersions || !timeRef.IsZero() || isIncomplete || uniquePrefixes || groupSizes || groupBy != "" || dupes || ageHistogram != nil || missingTag != "" || perPrefixLimit > 0 || saveSnapshot != "" || diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--merkle can only be used with --recursive and without --versions, --rewind, --incomplete, --unique-prefixes, --group-sizes, --group-by, --dupes, --age-histogram, --missing-tag, --per-prefix-limit, --save-snapshot or --diff-snapshot")
+		}
+		merkleMode = merkleModeMetadata
+		if cliCtx.Bool("merkle-content") {
+			merkleMode = merkleModeContent
+		}
+	} else if cliCtx.Bool("merkle-content") {
+		fatalIf(errInvalidArgument().Trace(args...), "--merkle-content can only be used with --merkle")
+	}
+	sortBy := strings.ToLower(cliCtx.String("sort"))
+	switch sortBy {
+	case "":
+		if groupSizes || groupBy != "" {
+			sortBy = prefixSortSize
+		}
+	case prefixSortSize, prefixSortObjects, prefixSortName:
+		if !groupSizes && groupBy == "" && perPrefixLimit == 0 {
+			fatalIf(errInvalidArgument().Trace(args...), "--sort can only be used with --group-sizes, --group-by or --per-prefix-limit")
+		}
+		if perPrefixLimit > 0 && sortBy == prefixSortObjects {
+			fatalIf(errInvalidArgument().Trace(sortBy), "--per-prefix-limit can only be sorted by size or name")
+		}
+		if perPrefixLimit > 0 && sortBy == prefixSortSize && withOlderVersions {
+			fatalIf(errInvalidArgument().Trace(args...), "--per-prefix-limit cannot be sorted by size with --versions")
+		}
+	default:
+		fatalIf(errInvalidArgument().Trace(sortBy), "Invalid --sort value, choose one of [size, objects, name].")
+	}
+	delimiter := cliCtx.String("delimiter")
+	if delimiter != "" && delimiter != "/" {
+		fatalIf(errInvalidArgument().Trace(delimiter), "--delimiter only supports '/' or '' (no delimiter)")
+	}
+	flat := delimiter == ""
+	if flat && uniquePrefixes {
+		fatalIf(errInvalidArgument().Trace(args...), "--unique-prefixes cannot be used with an empty --delimiter")
+	}
+	timeStyle := strings.ToLower(cliCtx.String("time-style"))
+	switch timeStyle {
+	case "", timeStyleLocal, timeStyleUTC, timeStyleEpoch, timeStyleRelative:
+	default:
+		fatalIf(errInvalidArgument().Trace(timeStyle), "Invalid --time-style value, choose one of [local, utc, epoch, relative].")
+	}
+	for flag, style := range map[string]string{"epoch": timeStyleEpoch, "relative-time": timeStyleRelative} {
+		if !cliCtx.Bool(flag) {
+			continue
+		}
+		if timeStyle != "" && timeStyle != style {
+			fatalIf(errInvalidArgument().Trace(args...), "--epoch, --relative-time and --time-style cannot be used together.")
+		}
+		timeStyle = style
+	}
+	if timeStyle == "" {
+		timeStyle = timeStyleLocal
+	}
+	if cliCtx.Bool("heartbeat") && !globalJSON {
+		fatalIf(errInvalidArgument().Trace(args...), "--heartbeat requires --json")
+	}
+	var deadline time.Time
+	if maxRuntime := cliCtx.Duration("max-runtime"); maxRuntime != 0 {
+		if maxRuntime < 0 {
+			fatalIf(errInvalidArgument().Trace(args...), "--max-runtime must be positive")
+		}
+		if saveSnapshot != "" || diffSnapshot != "" {
+			fatalIf(errInvalidArgument().Trace(args...), "--max-runtime cannot be used with --save-snapshot or --diff-snapshot")
+		}
+		deadline = time.Now().Add(maxRuntime)
+	}
+	snapshotDiff := listSnapshotDiffOptions{
+		changedOnly: changedSince != "",
+		asURLs:      cliCtx.Bool("as-urls"),
+		urlBase:     cliCtx.String("url-base"),
+	}
 	storageClasss := cliCtx.String("storage-class")
 	opts := doListOptions{
-		timeRef:           timeRef,
-		isRecursive:       isRecursive,
-		isIncomplete:      isIncomplete,
-		isSummary:         isSummary,
-		withOlderVersions: withOlderVersions,
-		listZip:           listZip,
-		filter:            storageClasss,
+		timeRef:            timeRef,
+		isRecursive:        isRecursive,
+		isIncomplete:       isIncomplete,
+		isSummary:          isSummary,
+		withOlderVersions:  withOlderVersions,
+		listZip:            listZip,
+		uniquePrefixes:     uniquePrefixes,
+		groupSizes:         groupSizes,
+		groupBy:            groupBy,
+		top:                top,
+		sortBy:             sortBy,
+		perPrefixLimit:     perPrefixLimit,
+		stats:              cliCtx.Bool("stats"),
+		heartbeat:          cliCtx.Bool("heartbeat"),
+		icons:              cliCtx.Bool("icons") && isTerminal(),
+		timeStyle:          timeStyle,
+		progress:           cliCtx.Bool("progress") && !globalQuiet && isatty.IsTerminal(os.Stderr.Fd()),
+		onlyNoncurrent:     onlyNoncurrent,
+		onlyDeleteMarkers:  onlyDeleteMarkers,
+		flat:               flat,
+		noTrim:             cliCtx.Bool("no-trim"),
+		filter:             storageClasss,
+		saveSnapshot:       saveSnapshot,
+		diffSnapshot:       diffSnapshot,
+		snapshotDiff:       snapshotDiff,
+		priceTable:         priceTable,
+		enrich:             enrich,
+		showEnriched:       cliCtx.String("enrich") != "",
+		missingContentType: missingContentType,
+		missingTag:         missingTag,
+		excludePrefixes:    excludePrefixes,
+		regionInfo:         cliCtx.Bool("region-info"),
+		publicInfo:         cliCtx.Bool("public-info"),
+		grownSince:         grownSince,
+		dupes:              dupes,
+		dupesHash:          cliCtx.Bool("dupes-hash"),
+		deadline:           deadline,
+		ageHistogram:       ageHistogram,
+		merkleMode:         merkleMode,
 	}
 	return args, opts
 }
@@ -208,12 +733,43 @@ func mainList(cliCtx *cli.Context) error {
 	console.SetColor("Time", color.New(color.FgGreen))
 	console.SetColor("Summarize", color.New(color.Bold))
 	console.SetColor("SC", color.New(color.FgBlue))
+	console.SetColor("Truncated", color.New(color.FgHiRed))
+	console.SetColor("PRE", color.New(color.FgHiBlack))
+	console.SetColor("Modified", color.New(color.FgYellow))
+	console.SetColor("Enrich", color.New(color.FgHiBlack))
+	console.SetColor("Region", color.New(color.FgYellow))
+	console.SetColor("Checksum", color.New(color.FgHiBlack))
+	console.SetColor("Lock", color.New(color.FgMagenta))
+	console.SetColor("Public", color.New(color.FgRed, color.Bold))
+	console.SetColor("Private", color.New(color.FgGreen))
+	console.SetColor("MissingContentType", color.New(color.FgRed, color.Bold))
+	console.SetColor("MissingTag", color.New(color.FgRed, color.Bold))
 
 	// check 'ls' cliCtx arguments.
 	args, opts := checkListSyntax(cliCtx)
 
+	if sink := cliCtx.String("log-sink"); sink != "" {
+		fatalIf(openLogSink(sink, cliCtx.Bool("log-sink-only")), "Unable to open the log sink `"+sink+"`.")
+		defer closeLogSink()
+	}
+
+	// Wildcards in the listed URLs are expanded against the listing.
+	var targetURLs []string
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, globChars) {
+			targetURLs = append(targetURLs, arg)
+			continue
+		}
+		matches, err := expandListGlob(ctx, arg)
+		fatalIf(err.Trace(arg), "Unable to expand the wildcards of `"+arg+"`.")
+		if len(matches) == 0 && cliCtx.Bool("no-match-error") {
+			fatalIf(errInvalidArgument().Trace(arg), "No objects match `"+arg+"`.")
+		}
+		targetURLs = append(targetURLs, matches...)
+	}
+
 	var cErr error
-	for _, targetURL := range args {
+	for _, targetURL := range targetURLs {
 		clnt, err := newClient(targetURL)
 		fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
 		if !strings.HasSuffix(targetURL, string(clnt.GetURL().Separator)) {
@@ -225,6 +781,17 @@ func mainList(cliCtx *cli.Context) error {
 				fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
 			}
 		}
+		switch {
+		case opts.saveSnapshot != "":
+			err = saveListSnapshot(ctx, clnt, targetURL, opts.saveSnapshot, opts.isRecursive)
+			fatalIf(err.Trace(targetURL), "Unable to save a snapshot of `"+targetURL+"`.")
+			continue
+		case opts.diffSnapshot != "":
+			err = diffListSnapshot(ctx, clnt, opts.diffSnapshot, opts.isRecursive, opts.snapshotDiff)
+			fatalIf(err.Trace(targetURL), "Unable to compare `"+targetURL+"` with snapshot `"+opts.diffSnapshot+"`.")
+			continue
+		}
+		opts.alias, _, _ = mustExpandAlias(targetURL)
 		if e := doList(ctx, clnt, opts); e != nil {
 			cErr = e
 		}
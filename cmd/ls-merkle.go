@@ -0,0 +1,158 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// merkleAlgorithm - the hash of the leaves and nodes of ls --merkle.
+const merkleAlgorithm = "sha256"
+
+// Modes of ls --merkle, the attributes of the objects hashed with
+// their key.
+const (
+	merkleModeMetadata = "metadata"
+	merkleModeContent  = "content"
+)
+
+// merkleTree - a binary tree over the objects hashed as they are listed
+// in the order of their keys, keeping only the frontier of the roots of
+// its complete subtrees, at most one per level.
+type merkleTree struct {
+	mode    string
+	alias   string
+	lastKey string
+	leaves  int64
+	// frontier[i] is the root of a complete subtree of 2^i leaves, nil
+	// when there is none at that level.
+	frontier [][]byte
+}
+
+// add a listed object by its key relative to the listed prefix, hashed
+// with its size and etag or, in content mode, with the SHA-256 of its
+// content read in full. Keys must come sorted, as S3 and the walk of
+// local folders list them.
+func (t *merkleTree) add(ctx context.Context, key string, content *ClientContent) *probe.Error {
+	if t.leaves > 0 && key <= t.lastKey {
+		return probe.NewError(fmt.Errorf("`%s` is listed after `%s`, the listing is not sorted by key", key, t.lastKey))
+	}
+	var hash []byte
+	if t.mode == merkleModeContent {
+		sum, err := hashContent(ctx, t.alias, content)
+		if err != nil {
+			return err
+		}
+		hash = merkleLeafHash(key, sum)
+	} else {
+		hash = merkleLeafHash(key, strconv.FormatInt(content.Size, 10), strings.Trim(content.ETag, `"`))
+	}
+	t.lastKey = key
+	t.leaves++
+	// Like a binary counter, merge with the subtrees of the same size.
+	for level := 0; ; level++ {
+		if level == len(t.frontier) {
+			t.frontier = append(t.frontier, nil)
+		}
+		if t.frontier[level] == nil {
+			t.frontier[level] = hash
+			return nil
+		}
+		hash = merkleNodeHash(t.frontier[level], hash)
+		t.frontier[level] = nil
+	}
+}
+
+// merkleLeafHash returns the hash of a leaf, of its key and of its
+// attributes separated by NUL, prefixed by 0 unlike the nodes.
+func merkleLeafHash(key string, attrs ...string) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	for _, attr := range attrs {
+		h.Write([]byte{0})
+		h.Write([]byte(attr))
+	}
+	return h.Sum(nil)
+}
+
+// merkleNodeHash returns the hash of a node, of its two children
+// prefixed by 1.
+func merkleNodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// root returns the hex encoded root, folding the frontier from its
+// smallest subtree up: the last subtree of a level without a sibling
+// goes up unchanged. The root of no object is the hash of nothing.
+func (t *merkleTree) root() string {
+	var root []byte
+	for _, hash := range t.frontier {
+		switch {
+		case hash == nil:
+		case root == nil:
+			root = hash
+		default:
+			root = merkleNodeHash(hash, root)
+		}
+	}
+	if root == nil {
+		sum := sha256.Sum256(nil)
+		root = sum[:]
+	}
+	return hex.EncodeToString(root)
+}
+
+// merkleRootMessage container for ls --merkle output
+type merkleRootMessage struct {
+	Status    string `json:"status"`
+	URL       string `json:"url"`
+	Root      string `json:"root"`
+	Algorithm string `json:"algorithm"`
+	Mode      string `json:"mode"`
+	Objects   int64  `json:"objects"`
+	TotalSize int64  `json:"totalSize"`
+}
+
+// String colorized root with the number and total size of the objects
+func (m merkleRootMessage) String() string {
+	return console.Colorize("Summarize", fmt.Sprintf("Merkle root (%s, %s): %s over %s objects, %s",
+		m.Algorithm, m.Mode, m.Root, humanize.Comma(m.Objects), humanize.IBytes(uint64(m.TotalSize))))
+}
+
+// JSON jsonified root message
+func (m merkleRootMessage) JSON() string {
+	m.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// listSnapshotVersion is the version of the snapshot files written by
+// ls --save-snapshot, bumped whenever their format changes.
+const listSnapshotVersion = 1
+
+// listSnapshotEntry - size, modification time and ETag of an object in
+// a listing snapshot, with short field names to keep snapshots compact.
+type listSnapshotEntry struct {
+	Size    int64  `json:"s"`
+	ModTime int64  `json:"t"` // Unix nanoseconds
+	ETag    string `json:"e,omitempty"`
+}
+
+// listSnapshot - listing snapshot, objects are keyed relative to URL.
+type listSnapshot struct {
+	Version int                          `json:"version"`
+	URL     string                       `json:"url"`
+	Created time.Time                    `json:"created"`
+	Objects map[string]listSnapshotEntry `json:"objects"`
+}
+
+// Kinds of changes reported by ls --diff-snapshot.
+const (
+	snapshotAdded    = "added"
+	snapshotRemoved  = "removed"
+	snapshotModified = "modified"
+)
+
+// listSnapshotMessage - printed once a snapshot is saved.
+type listSnapshotMessage struct {
+	Status   string `json:"status"`
+	URL      string `json:"url"`
+	Snapshot string `json:"snapshot"`
+	Objects  int    `json:"objects"`
+}
+
+func (s listSnapshotMessage) String() string {
+	return fmt.Sprintf("Saved a snapshot of %d objects under `%s` to `%s`.", s.Objects, s.URL, s.Snapshot)
+}
+
+func (s listSnapshotMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// listSnapshotDiffMessage - an object changed since a snapshot.
+type listSnapshotDiffMessage struct {
+	Status           string     `json:"status"`
+	Diff             string     `json:"diff"`
+	Key              string     `json:"key"`
+	Size             int64      `json:"size"`
+	PrevSize         int64      `json:"prevSize"`
+	LastModified     *time.Time `json:"lastModified,omitempty"`
+	PrevLastModified *time.Time `json:"prevLastModified,omitempty"`
+	ETag             string     `json:"etag,omitempty"`
+	PrevETag         string     `json:"prevETag,omitempty"`
+	URL              string     `json:"url,omitempty"`
+}
+
+func (d listSnapshotDiffMessage) String() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	switch d.Diff {
+	case snapshotAdded:
+		return console.Colorize("PUT", "+ "+d.Key)
+	case snapshotRemoved:
+		return console.Colorize("DEL", "- "+d.Key)
+	}
+	var changes []string
+	if d.Size != d.PrevSize {
+		changes = append(changes, fmt.Sprintf("size %d -> %d", d.PrevSize, d.Size))
+	}
+	if d.LastModified != nil && d.PrevLastModified != nil && !d.LastModified.Equal(*d.PrevLastModified) {
+		changes = append(changes, "modified "+d.PrevLastModified.Format(printDate)+" -> "+d.LastModified.Format(printDate))
+	}
+	if d.ETag != d.PrevETag {
+		changes = append(changes, "etag changed")
+	}
+	return console.Colorize("Modified", "! "+d.Key) + " (" + strings.Join(changes, ", ") + ")"
+}
+
+func (d listSnapshotDiffMessage) JSON() string {
+	d.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(d, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// listSnapshotObjects lists the objects under clnt, keyed relative to
+// the listed URL.
+func listSnapshotObjects(ctx context.Context, clnt Client, isRecursive bool) (map[string]listSnapshotEntry, *probe.Error) {
+	prefix := clnt.GetURL().Path
+	objects := map[string]listSnapshotEntry{}
+	for content := range clnt.List(ctx, ListOptions{Recursive: isRecursive, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(clnt.GetURL().String())
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		objects[strings.TrimPrefix(content.URL.Path, prefix)] = listSnapshotEntry{
+			Size:    content.Size,
+			ModTime: content.Time.UnixNano(),
+			ETag:    content.ETag,
+		}
+	}
+	return objects, nil
+}
+
+// saveListSnapshot - saves a snapshot of the listing of targetURL to file.
+func saveListSnapshot(ctx context.Context, clnt Client, targetURL, file string, isRecursive bool) *probe.Error {
+	objects, err := listSnapshotObjects(ctx, clnt, isRecursive)
+	if err != nil {
+		return err
+	}
+	snapshot := listSnapshot{
+		Version: listSnapshotVersion,
+		URL:     targetURL,
+		Created: UTCNow(),
+		Objects: objects,
+	}
+	data, e := json.Marshal(snapshot)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.WriteFile(file, data, 0o600); e != nil {
+		return probe.NewError(e).Trace(file)
+	}
+	printMsg(listSnapshotMessage{URL: targetURL, Snapshot: file, Objects: len(objects)})
+	return nil
+}
+
+// loadListSnapshot - reads a snapshot saved with ls --save-snapshot.
+func loadListSnapshot(file string) (*listSnapshot, *probe.Error) {
+	data, e := os.ReadFile(file)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(file)
+	}
+	snapshot := &listSnapshot{}
+	if e = json.Unmarshal(data, snapshot); e != nil {
+		return nil, probe.NewError(e).Trace(file)
+	}
+	if snapshot.Version != listSnapshotVersion {
+		return nil, probe.NewError(fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, listSnapshotVersion)).Trace(file)
+	}
+	return snapshot, nil
+}
+
+// listSnapshotDiffOptions - what ls --diff-snapshot prints.
+type listSnapshotDiffOptions struct {
+	// Only the objects added or whose etag changed.
+	changedOnly bool
+	// The URLs of the objects, the keys appended to urlBase if set.
+	asURLs  bool
+	urlBase string
+}
+
+// etagChanged returns true if an object modified since a snapshot has
+// a new etag, or no etag to compare.
+func (d listSnapshotDiffMessage) etagChanged() bool {
+	return d.ETag != d.PrevETag || d.ETag == ""
+}
+
+// snapshotKeyURL appends a snapshot key to base, escaping it.
+func snapshotKeyURL(base, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.Join(segments, "/")
+}
+
+// diffListSnapshot - prints the objects added, removed or modified
+// under targetURL since the snapshot saved in file.
+func diffListSnapshot(ctx context.Context, clnt Client, file string, isRecursive bool, opts listSnapshotDiffOptions) *probe.Error {
+	snapshot, err := loadListSnapshot(file)
+	if err != nil {
+		return err
+	}
+	objects, err := listSnapshotObjects(ctx, clnt, isRecursive)
+	if err != nil {
+		return err
+	}
+	urlBase := opts.urlBase
+	if urlBase == "" {
+		urlBase = clnt.GetURL().String()
+	}
+	for _, msg := range diffListSnapshotObjects(snapshot.Objects, objects) {
+		if opts.changedOnly && (msg.Diff == snapshotRemoved || (msg.Diff == snapshotModified && !msg.etagChanged())) {
+			continue
+		}
+		if opts.asURLs {
+			msg.URL = snapshotKeyURL(urlBase, msg.Key)
+		}
+		printMsg(msg)
+	}
+	return nil
+}
+
+// diffListSnapshotObjects compares two snapshots, changes are sorted by key.
+func diffListSnapshotObjects(prev, cur map[string]listSnapshotEntry) []listSnapshotDiffMessage {
+	keys := make([]string, 0, len(cur))
+	for key := range cur {
+		keys = append(keys, key)
+	}
+	for key := range prev {
+		if _, ok := cur[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []listSnapshotDiffMessage
+	for _, key := range keys {
+		before, inPrev := prev[key]
+		after, inCur := cur[key]
+		msg := listSnapshotDiffMessage{
+			Key:      key,
+			Size:     after.Size,
+			PrevSize: before.Size,
+			ETag:     after.ETag,
+			PrevETag: before.ETag,
+		}
+		if inCur {
+			t := time.Unix(0, after.ModTime)
+			msg.LastModified = &t
+		}
+		if inPrev {
+			t := time.Unix(0, before.ModTime)
+			msg.PrevLastModified = &t
+		}
+		switch {
+		case !inPrev:
+			msg.Diff = snapshotAdded
+		case !inCur:
+			msg.Diff = snapshotRemoved
+		case before != after:
+			msg.Diff = snapshotModified
+		default:
+			continue
+		}
+		diffs = append(diffs, msg)
+	}
+	return diffs
+}
@@ -18,11 +18,15 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -38,27 +42,138 @@ const (
 
 // contentMessage container for content message structure.
 type contentMessage struct {
-	Status   string    `json:"status"`
-	Filetype string    `json:"type"`
-	Time     time.Time `json:"lastModified"`
-	Size     int64     `json:"size"`
-	Key      string    `json:"key"`
-	ETag     string    `json:"etag"`
-	URL      string    `json:"url,omitempty"`
+	Status    string    `json:"status"`
+	Filetype  string    `json:"type"`
+	Time      time.Time `json:"lastModified"`
+	Size      int64     `json:"size"`
+	Key       string    `json:"key"`
+	ETag      string    `json:"etag"`
+	URL       string    `json:"url,omitempty"`
+	URLString string    `json:"urlString,omitempty"` // alias qualified, untrimmed
 
 	VersionID      string `json:"versionId,omitempty"`
 	VersionOrd     int    `json:"versionOrdinal,omitempty"`
 	VersionIndex   int    `json:"versionIndex,omitempty"`
 	IsDeleteMarker bool   `json:"isDeleteMarker,omitempty"`
+	IsLatest       *bool  `json:"isLatest,omitempty"` // set when listing versions only
 	StorageClass   string `json:"storageClass,omitempty"`
 
+	// Set for buckets listed at the root of an alias, the region
+	// with --region-info only.
+	CreationDate *time.Time `json:"creationDate,omitempty"`
+	Region       string     `json:"region,omitempty"`
+
+	// Set for buckets listed at the root of an alias with
+	// --public-info only.
+	Public       *bool  `json:"public,omitempty"`
+	PublicReason string `json:"publicReason,omitempty"`
+
+	// Set with --price-table only.
+	StorageClassInferred bool     `json:"storageClassInferred,omitempty"`
+	EstimatedMonthlyCost *float64 `json:"estimatedMonthlyCost,omitempty"`
+
+	// Set with --enrich content-type or --flag-missing-content-type only.
+	ContentType        string `json:"contentType,omitempty"`
+	MissingContentType bool   `json:"missingContentType,omitempty"`
+
+	// Set with --missing-tag only, to the tag key the object lacks.
+	MissingTag string `json:"missingTag,omitempty"`
+
+	// Set with --enrich checksum or --checksum only.
+	ChecksumType string `json:"checksumType,omitempty"`
+	Checksum     string `json:"checksum,omitempty"`
+
+	// Set with --enrich lock-info or --lock-info only, "none" for
+	// objects without a retention or a legal hold.
+	RetentionMode string     `json:"retentionMode,omitempty"`
+	RetainUntil   *time.Time `json:"retainUntil,omitempty"`
+	LegalHold     string     `json:"legalHold,omitempty"`
+
 	Metadata map[string]string `json:"metadata,omitempty"`
 	Tags     map[string]string `json:"tags,omitempty"`
+
+	// The last modified time in Unix seconds and milliseconds, set
+	// by JSON for consumers not parsing lastModified.
+	LastModifiedEpoch       int64 `json:"lastModifiedEpoch,omitempty"`
+	LastModifiedEpochMillis int64 `json:"lastModifiedEpochMillis,omitempty"`
+
+	showIcon     bool
+	showEnriched bool
+	showChecksum bool
+	showLock     bool
+	timeStyle    string
+
+	// Whether the version is the latest one, objects of unversioned
+	// listings are.
+	isLatest bool
+
+	// Alias of the listed target, the host of the JSON message.
+	host string
+}
+
+// jsonHost returns the alias of the listed target, if any.
+func (c contentMessage) jsonHost() (string, bool) {
+	return c.host, c.host != ""
+}
+
+// Styles of the last modified time printed by ls, see --time-style.
+const (
+	timeStyleLocal    = "local"
+	timeStyleUTC      = "utc"
+	timeStyleEpoch    = "epoch"
+	timeStyleRelative = "relative"
+)
+
+// formatTime renders the last modified time in the listing style.
+func (c contentMessage) formatTime() string {
+	switch c.timeStyle {
+	case timeStyleUTC:
+		return fmt.Sprintf("[%s]", c.Time.UTC().Format(printDate))
+	case timeStyleEpoch:
+		return fmt.Sprintf("%d", c.Time.Unix())
+	case timeStyleRelative:
+		return fmt.Sprintf("[%13s]", humanize.Time(c.Time))
+	}
+	return fmt.Sprintf("[%s]", c.Time.Format(printDate))
+}
+
+// Icons displayed in front of listed entries with --icons.
+const (
+	folderIcon = "📁"
+	fileIcon   = "📄"
+)
+
+// extensionIcons maps common file extensions to their icon.
+var extensionIcons = func() map[string]string {
+	icons := make(map[string]string)
+	for icon, exts := range map[string][]string{
+		"🖼": {".jpg", ".jpeg", ".png", ".gif", ".bmp", ".svg", ".webp", ".tif", ".tiff", ".ico"},
+		"📦": {".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".zst", ".7z", ".rar"},
+		"📝": {".go", ".py", ".js", ".ts", ".c", ".h", ".cpp", ".java", ".rs", ".rb", ".sh", ".json", ".yaml", ".yml"},
+		"🎵": {".mp3", ".wav", ".flac", ".ogg", ".m4a"},
+		"🎬": {".mp4", ".mkv", ".mov", ".avi", ".webm"},
+	} {
+		for _, ext := range exts {
+			icons[ext] = icon
+		}
+	}
+	return icons
+}()
+
+// contentIcon returns the icon matching the type of the listed entry.
+func contentIcon(key string, isDir bool) string {
+	if isDir {
+		return folderIcon
+	}
+	if icon, ok := extensionIcons[strings.ToLower(path.Ext(key))]; ok {
+		return icon
+	}
+	return fileIcon
 }
 
 // String colorized string message.
 func (c contentMessage) String() string {
-	message := console.Colorize("Time", fmt.Sprintf("[%s]", c.Time.Format(printDate)))
+	message := console.Colorize("Time", c.formatTime())
 	message += console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(c.Size))), "")))
 	fileDesc := ""
 
@@ -66,6 +181,18 @@ func (c contentMessage) String() string {
 		message += " " + console.Colorize("SC", c.StorageClass)
 	}
 
+	if c.Region != "" {
+		message += " " + console.Colorize("Region", c.Region)
+	}
+
+	if c.Public != nil {
+		if *c.Public {
+			message += " " + console.Colorize("Public", "PUBLIC")
+		} else {
+			message += " " + console.Colorize("Private", "private")
+		}
+	}
+
 	if c.VersionID != "" {
 		fileDesc += console.Colorize("VersionID", " "+c.VersionID) + console.Colorize("VersionOrd", fmt.Sprintf(" v%d", c.VersionOrd))
 		if c.IsDeleteMarker {
@@ -75,6 +202,9 @@ func (c contentMessage) String() string {
 		}
 	}
 
+	if c.showIcon {
+		fileDesc += " " + contentIcon(c.Key, c.Filetype == "folder")
+	}
 	fileDesc += " " + c.Key
 
 	if c.Filetype == "folder" {
@@ -82,12 +212,61 @@ func (c contentMessage) String() string {
 	} else {
 		message += console.Colorize("File", fileDesc)
 	}
+
+	if c.MissingContentType {
+		message += " " + console.Colorize("MissingContentType", "[missing content-type]")
+	}
+
+	if c.MissingTag != "" {
+		message += " " + console.Colorize("MissingTag", "[missing tag "+c.MissingTag+"]")
+	}
+
+	if c.showChecksum && c.Filetype != "folder" && !c.IsDeleteMarker {
+		checksum := "none"
+		if c.Checksum != "" {
+			checksum = c.ChecksumType + ":" + c.Checksum
+		}
+		message += " " + console.Colorize("Checksum", checksum)
+	}
+
+	if c.showLock && c.Filetype != "folder" && !c.IsDeleteMarker {
+		retention := c.RetentionMode
+		if c.RetainUntil != nil {
+			retention += ":" + c.RetainUntil.Format(time.RFC3339)
+		}
+		message += " " + console.Colorize("Lock", "retention="+retention+" legal-hold="+c.LegalHold)
+	}
+
+	if c.showEnriched {
+		if c.ContentType != "" {
+			message += " " + console.Colorize("Enrich", c.ContentType)
+		}
+		if len(c.Tags) > 0 {
+			message += " " + console.Colorize("Enrich", "tags["+formatKeyValues(c.Tags)+"]")
+		}
+		if len(c.Metadata) > 0 {
+			message += " " + console.Colorize("Enrich", "meta["+formatKeyValues(c.Metadata)+"]")
+		}
+	}
 	return message
 }
 
+// formatKeyValues formats a map as comma separated key=value pairs sorted by key.
+func formatKeyValues(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
 // JSON jsonified content message.
 func (c contentMessage) JSON() string {
 	c.Status = "success"
+	if !c.Time.IsZero() {
+		c.LastModifiedEpoch, c.LastModifiedEpochMillis = c.Time.Unix(), c.Time.UnixMilli()
+	}
 	jsonMessageBytes, e := json.MarshalIndent(c, "", " ")
 	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
 
@@ -109,16 +288,33 @@ func getKey(c *ClientContent) string {
 	return getOSDependantKey(c.URL.Path, c.Type.IsDir())
 }
 
-// Generate printable listing from a list of sorted client
-// contents, the latest created content comes first.
-func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAllVersions bool) (msgs []contentMessage) {
+// Return the path prefix trimmed from listed contents, keys
+// are displayed relative to it.
+func listPrefixPath(clntURL ClientURL) string {
 	prefixPath := clntURL.Path
 	prefixPath = filepath.ToSlash(prefixPath)
 	if !strings.HasSuffix(prefixPath, "/") {
 		prefixPath = prefixPath[:strings.LastIndex(prefixPath, "/")+1]
 	}
-	prefixPath = strings.TrimPrefix(prefixPath, "./")
+	return strings.TrimPrefix(prefixPath, "./")
+}
+
+// Return the path prefix trimmed from listed contents with --no-trim,
+// object keys are displayed in full, relative to their bucket.
+func listFullKeyPrefix(clntURL ClientURL) string {
+	if clntURL.Type != objectStorage {
+		return ""
+	}
+	bucket, _ := url2BucketAndObject(&clntURL)
+	if bucket == "" {
+		return "/"
+	}
+	return "/" + bucket + "/"
+}
 
+// Generate printable listing from a list of sorted client
+// contents, the latest created content comes first.
+func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAllVersions bool, prefixPath, alias string) (msgs []contentMessage) {
 	nrVersions := len(ctnts)
 
 	for i, c := range ctnts {
@@ -129,6 +325,8 @@ func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAll
 
 		contentMsg := contentMessage{}
 		contentMsg.Time = c.Time.Local()
+		contentMsg.URLString = alias + contentURL
+		contentMsg.host = alias
 
 		// guess file type.
 		contentMsg.Filetype = func() string {
@@ -142,6 +340,16 @@ func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAll
 		contentMsg.StorageClass = c.StorageClass
 		contentMsg.Metadata = c.Metadata
 		contentMsg.Tags = c.Tags
+		if c.BucketName != "" && strings.Trim(contentURL, "/") == c.BucketName {
+			creationDate := contentMsg.Time
+			contentMsg.CreationDate = &creationDate
+			contentMsg.Region = c.Region
+			if c.PublicReason != "" {
+				public := c.Public
+				contentMsg.Public = &public
+				contentMsg.PublicReason = c.PublicReason
+			}
+		}
 
 		md5sum := strings.TrimPrefix(c.ETag, "\"")
 		md5sum = strings.TrimSuffix(md5sum, "\"")
@@ -150,6 +358,11 @@ func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAll
 		contentMsg.Key = getKey(c)
 		contentMsg.VersionID = c.VersionID
 		contentMsg.IsDeleteMarker = c.IsDeleteMarker
+		contentMsg.isLatest = c.IsLatest || c.VersionID == ""
+		if printAllVersions {
+			isLatest := contentMsg.isLatest
+			contentMsg.IsLatest = &isLatest
+		}
 		contentMsg.VersionOrd = nrVersions - i
 		// URL is empty by default
 		// Set it to either relative dir (host) or public url (remote)
@@ -177,16 +390,350 @@ func sortObjectVersions(ctntVersions []*ClientContent) {
 	})
 }
 
+// prefixMessage container for a distinct prefix found while listing.
+type prefixMessage struct {
+	Status   string `json:"status"`
+	Filetype string `json:"type"`
+	Key      string `json:"key"`
+	URL      string `json:"url,omitempty"`
+}
+
+// String colorized prefix message.
+func (p prefixMessage) String() string {
+	return console.Colorize("PRE", "PRE ") + console.Colorize("Dir", p.Key)
+}
+
+// JSON jsonified prefix message.
+func (p prefixMessage) JSON() string {
+	p.Status = "success"
+	p.Filetype = "prefix"
+	jsonMessageBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// Print all prefixes of a content not seen before, parents first.
+func printUniquePrefixes(clntURL ClientURL, content *ClientContent, seenPrefixes map[string]struct{}) {
+	key := strings.TrimPrefix(filepath.ToSlash(content.URL.Path), listPrefixPath(clntURL))
+	for i, r := range key {
+		if r != '/' {
+			continue
+		}
+		prefix := key[:i+1]
+		if _, ok := seenPrefixes[prefix]; ok {
+			continue
+		}
+		seenPrefixes[prefix] = struct{}{}
+		printMsg(prefixMessage{
+			Key: prefix,
+			URL: clntURL.String(),
+		})
+	}
+}
+
+// Orders of ls --group-sizes output.
+const (
+	prefixSortSize    = "size"
+	prefixSortObjects = "objects"
+	prefixSortName    = "name"
+)
+
+// prefixSize holds the total size and number of objects of a prefix.
+type prefixSize struct {
+	Prefix  string `json:"prefix"`
+	Size    int64  `json:"size"`
+	Objects int64  `json:"objects"`
+}
+
+// topLevelPrefix returns the immediate child prefix of the listed prefix
+// a content belongs to, objects directly inside are their own prefix.
+func topLevelPrefix(clntURL ClientURL, content *ClientContent) string {
+	key := strings.TrimPrefix(filepath.ToSlash(content.URL.Path), listPrefixPath(clntURL))
+	if i := strings.Index(key, "/"); i >= 0 {
+		key = key[:i+1]
+	}
+	return key
+}
+
+// Add a content to the total of its top level prefix.
+func addPrefixSize(clntURL ClientURL, content *ClientContent, prefixSizes map[string]*prefixSize) {
+	key := topLevelPrefix(clntURL, content)
+	p, ok := prefixSizes[key]
+	if !ok {
+		p = &prefixSize{Prefix: key}
+		prefixSizes[key] = p
+	}
+	p.Size += content.Size
+	p.Objects++
+}
+
+// keepLargest adds a content to the largest contents of a prefix,
+// sorted by size descending, keeping at most limit of them.
+func keepLargest(largest []*ClientContent, content *ClientContent, limit int) []*ClientContent {
+	i := sort.Search(len(largest), func(i int) bool {
+		return largest[i].Size < content.Size
+	})
+	if i >= limit {
+		return largest
+	}
+	if len(largest) < limit {
+		largest = append(largest, nil)
+	}
+	copy(largest[i+1:], largest[i:])
+	largest[i] = content
+	return largest
+}
+
+// sortPrefixSizes returns the prefix totals in the given order, size and
+// objects descending, name ascending. Ties are ordered by name.
+func sortPrefixSizes(prefixSizes map[string]*prefixSize, sortBy string) []prefixSize {
+	sorted := make([]prefixSize, 0, len(prefixSizes))
+	for _, p := range prefixSizes {
+		sorted = append(sorted, *p)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		switch sortBy {
+		case prefixSortSize:
+			if sorted[i].Size != sorted[j].Size {
+				return sorted[i].Size > sorted[j].Size
+			}
+		case prefixSortObjects:
+			if sorted[i].Objects != sorted[j].Objects {
+				return sorted[i].Objects > sorted[j].Objects
+			}
+		}
+		return sorted[i].Prefix < sorted[j].Prefix
+	})
+	return sorted
+}
+
+// groupByExtension groups the objects listed with --group-by by the
+// extension of their name.
+const groupByExtension = "extension"
+
+// noExtension is the group of the objects without an extension.
+const noExtension = "(none)"
+
+// extensionSize - the number and total size of the objects of an extension.
+type extensionSize struct {
+	Extension string `json:"extension"`
+	Count     int64  `json:"count"`
+	Size      int64  `json:"size"`
+}
+
+// objectExtension returns the lower cased extension of the name of an
+// object, noExtension for names without one or starting with their
+// only dot.
+func objectExtension(key string) string {
+	base := path.Base(key)
+	ext := path.Ext(base)
+	if ext == "" || ext == base || ext == "." {
+		return noExtension
+	}
+	return strings.ToLower(ext)
+}
+
+// Add a content to the total of its extension.
+func addExtensionSize(content *ClientContent, extensionSizes map[string]*extensionSize) {
+	ext := objectExtension(getKey(content))
+	e, ok := extensionSizes[ext]
+	if !ok {
+		e = &extensionSize{Extension: ext}
+		extensionSizes[ext] = e
+	}
+	e.Size += content.Size
+	e.Count++
+}
+
+// sortExtensionSizes returns the extension totals in the given order,
+// like sortPrefixSizes, keeping only the first top of them if positive.
+func sortExtensionSizes(extensionSizes map[string]*extensionSize, sortBy string, top int) []extensionSize {
+	sorted := make([]extensionSize, 0, len(extensionSizes))
+	for _, e := range extensionSizes {
+		sorted = append(sorted, *e)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		switch sortBy {
+		case prefixSortSize:
+			if sorted[i].Size != sorted[j].Size {
+				return sorted[i].Size > sorted[j].Size
+			}
+		case prefixSortObjects:
+			if sorted[i].Count != sorted[j].Count {
+				return sorted[i].Count > sorted[j].Count
+			}
+		}
+		return sorted[i].Extension < sorted[j].Extension
+	})
+	if top > 0 && len(sorted) > top {
+		sorted = sorted[:top]
+	}
+	return sorted
+}
+
+// extensionSizesMessage container for ls --group-by extension output
+type extensionSizesMessage struct {
+	Extensions   []extensionSize
+	TotalObjects int64
+	TotalSize    int64
+}
+
+// String colorized extension totals, followed by the grand total
+func (m extensionSizesMessage) String() string {
+	var b strings.Builder
+	for _, e := range m.Extensions {
+		b.WriteString(console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(e.Size))), ""))))
+		b.WriteString(fmt.Sprintf(" %10s ", humanize.Comma(e.Count)))
+		b.WriteString(console.Colorize("File", e.Extension))
+		b.WriteString("\n")
+	}
+	b.WriteString(console.Colorize("Summarize", fmt.Sprintf("Total: %s in %s objects",
+		humanize.IBytes(uint64(m.TotalSize)), humanize.Comma(m.TotalObjects))))
+	return b.String()
+}
+
+// JSON jsonified extension totals, an array of {extension, count, size}
+func (m extensionSizesMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(m.Extensions, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// defaultAgeBuckets are the bounds of the age buckets of ls
+// --age-histogram without --age-buckets.
+const defaultAgeBuckets = "7d,30d,90d,365d"
+
+// ageSize - the number and total size of the objects of an age bucket.
+type ageSize struct {
+	AgeBucket string `json:"ageBucket"`
+	Count     int64  `json:"count"`
+	Size      int64  `json:"size"`
+}
+
+// ageHistogram - the objects listed with --age-histogram by age of their
+// last modification, buckets[i] holding the ones younger than bounds[i]
+// and the last one the oldest.
+type ageHistogram struct {
+	bounds  []time.Duration
+	buckets []ageSize
+}
+
+// parseAgeBuckets parses a comma separated list of increasing durations,
+// e.g. 7d,30d,90d, into an empty histogram.
+func parseAgeBuckets(value string) (*ageHistogram, *probe.Error) {
+	names := strings.Split(value, ",")
+	h := &ageHistogram{}
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		d, e := ParseDuration(name)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(name)
+		}
+		bound := time.Duration(d)
+		if bound <= 0 || (i > 0 && bound <= h.bounds[i-1]) {
+			return nil, errInvalidArgument().Trace(value)
+		}
+		h.bounds = append(h.bounds, bound)
+		names[i] = name
+	}
+	h.buckets = make([]ageSize, len(names)+1)
+	h.buckets[0].AgeBucket = "<" + names[0]
+	for i := 1; i < len(names); i++ {
+		h.buckets[i].AgeBucket = names[i-1] + "-" + names[i]
+	}
+	h.buckets[len(names)].AgeBucket = ">" + names[len(names)-1]
+	return h, nil
+}
+
+// add a content modified at t to the bucket of its age at now.
+func (h *ageHistogram) add(content *ClientContent, now time.Time) {
+	age := now.Sub(content.Time)
+	i := sort.Search(len(h.bounds), func(i int) bool { return age < h.bounds[i] })
+	h.buckets[i].Count++
+	h.buckets[i].Size += content.Size
+}
+
+// ageHistogramMessage container for ls --age-histogram output
+type ageHistogramMessage struct {
+	Buckets      []ageSize
+	TotalObjects int64
+	TotalSize    int64
+}
+
+// String colorized age buckets with their share of the total size,
+// followed by the grand total
+func (m ageHistogramMessage) String() string {
+	var b strings.Builder
+	for _, a := range m.Buckets {
+		var share float64
+		if m.TotalSize > 0 {
+			share = float64(a.Size) * 100 / float64(m.TotalSize)
+		}
+		b.WriteString(console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(a.Size))), ""))))
+		b.WriteString(fmt.Sprintf(" %5.1f%% %10s ", share, humanize.Comma(a.Count)))
+		b.WriteString(console.Colorize("Time", a.AgeBucket))
+		b.WriteString("\n")
+	}
+	b.WriteString(console.Colorize("Summarize", fmt.Sprintf("Total: %s in %s objects",
+		humanize.IBytes(uint64(m.TotalSize)), humanize.Comma(m.TotalObjects))))
+	return b.String()
+}
+
+// JSON jsonified age buckets, an array of {ageBucket, count, size}, with
+// the < and > of the bucket names left unescaped
+func (m ageHistogramMessage) JSON() string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", " ")
+	e := enc.Encode(m.Buckets)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// prefixSizesMessage container for ls --group-sizes output
+type prefixSizesMessage struct {
+	Prefixes     []prefixSize
+	TotalObjects int64
+	TotalSize    int64
+}
+
+// String colorized prefix totals, followed by the grand total
+func (p prefixSizesMessage) String() string {
+	var b strings.Builder
+	for _, s := range p.Prefixes {
+		b.WriteString(console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(s.Size))), ""))))
+		b.WriteString(fmt.Sprintf(" %10s ", humanize.Comma(s.Objects)))
+		b.WriteString(console.Colorize("Dir", s.Prefix))
+		b.WriteString("\n")
+	}
+	b.WriteString(console.Colorize("Summarize", fmt.Sprintf("Total: %s in %s objects",
+		humanize.IBytes(uint64(p.TotalSize)), humanize.Comma(p.TotalObjects))))
+	return b.String()
+}
+
+// JSON jsonified prefix totals, an array of {prefix, size, objects}
+func (p prefixSizesMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(p.Prefixes, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
 // summaryMessage container for summary message structure
 type summaryMessage struct {
-	TotalObjects int64 `json:"totalObjects"`
-	TotalSize    int64 `json:"totalSize"`
+	TotalObjects         int64    `json:"totalObjects"`
+	TotalSize            int64    `json:"totalSize"`
+	EstimatedMonthlyCost *float64 `json:"estimatedMonthlyCost,omitempty"`
 }
 
 // String colorized string message
 func (s summaryMessage) String() string {
 	msg := console.Colorize("Summarize", fmt.Sprintf("\nTotal Size: %s", humanize.IBytes(uint64(s.TotalSize))))
 	msg += "\n" + console.Colorize("Summarize", fmt.Sprintf("Total Objects: %d", s.TotalObjects))
+	if s.EstimatedMonthlyCost != nil {
+		msg += "\n" + console.Colorize("Summarize", fmt.Sprintf("Estimated Monthly Cost: $%.2f", *s.EstimatedMonthlyCost))
+	}
 	return msg
 }
 
@@ -197,23 +744,256 @@ func (s summaryMessage) JSON() string {
 	return string(jsonMessageBytes)
 }
 
+// grownSinceMessage container for the objects created or modified
+// since ls --grown-since
+type grownSinceMessage struct {
+	Objects   int64     `json:"objects"`
+	TotalSize int64     `json:"totalSize"`
+	Since     time.Time `json:"since"`
+}
+
+// String colorized grown since message
+func (g grownSinceMessage) String() string {
+	return console.Colorize("Summarize", fmt.Sprintf("\nGrown since %s: %d objects, %s",
+		g.Since.Format(printDate), g.Objects, humanize.IBytes(uint64(g.TotalSize))))
+}
+
+// JSON jsonified grown since message
+func (g grownSinceMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(g, "", "")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON")
+	return string(jsonMessageBytes)
+}
+
+// missingTagMessage container for the compliance of ls --missing-tag
+type missingTagMessage struct {
+	Tag       string  `json:"tag"`
+	Objects   int64   `json:"objects"`
+	Missing   int64   `json:"missing"`
+	Compliant float64 `json:"compliantPercent"`
+}
+
+// String colorized compliance of the listed objects
+func (m missingTagMessage) String() string {
+	return console.Colorize("Summarize", fmt.Sprintf("\n%d of %d objects lack the tag `%s`, %.1f%% compliant",
+		m.Missing, m.Objects, m.Tag, m.Compliant))
+}
+
+// JSON jsonified compliance of the listed objects
+func (m missingTagMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(m, "", "")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON")
+	return string(jsonMessageBytes)
+}
+
+// listStatsMessage container for listing performance statistics
+type listStatsMessage struct {
+	Status     string  `json:"status"`
+	DurationMs int64   `json:"durationMs"`
+	Pages      int64   `json:"pages"`
+	Objects    int64   `json:"objects"`
+	Rate       float64 `json:"rate"`
+}
+
+// String colorized listing statistics message
+func (s listStatsMessage) String() string {
+	return console.Colorize("Summarize", fmt.Sprintf("Listed %d objects in %s using %d API calls (%.1f objects/s)",
+		s.Objects, time.Duration(s.DurationMs)*time.Millisecond, s.Pages, s.Rate))
+}
+
+// JSON jsonified listing statistics message
+func (s listStatsMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// setLockInfo - sets the lock info fetched with --lock-info, "none"
+// for objects without a retention or a legal hold.
+func setLockInfo(msg *contentMessage, content *ClientContent) {
+	msg.RetentionMode, msg.RetainUntil, msg.LegalHold = "none", nil, "none"
+	if content.RetentionMode != "" {
+		msg.RetentionMode = content.RetentionMode
+		if !content.RetainUntil.IsZero() {
+			until := content.RetainUntil.UTC()
+			msg.RetainUntil = &until
+		}
+	}
+	if content.LegalHold != "" {
+		msg.LegalHold = content.LegalHold
+	}
+}
+
 // Pretty print the list of versions belonging to one object
-func printObjectVersions(clntURL ClientURL, ctntVersions []*ClientContent, printAllVersions bool) {
+func printObjectVersions(clntURL ClientURL, ctntVersions []*ClientContent, o doListOptions) {
 	sortObjectVersions(ctntVersions)
-	msgs := generateContentMessages(clntURL, ctntVersions, printAllVersions)
-	for _, msg := range msgs {
+	prefixPath := listPrefixPath(clntURL)
+	if o.noTrim {
+		prefixPath = listFullKeyPrefix(clntURL)
+	}
+	msgs := generateContentMessages(clntURL, ctntVersions, o.withOlderVersions, prefixPath, o.alias)
+	for i, msg := range msgs {
+		if o.skipVersion(msg.isLatest, msg.IsDeleteMarker) {
+			continue
+		}
+		msg.showIcon = o.icons
+		msg.timeStyle = o.timeStyle
+		if o.enrich != nil {
+			msg.showEnriched = o.showEnriched
+			msg.ContentType = msg.Metadata["Content-Type"]
+			msg.Metadata = nil
+			if o.enrich.metadata {
+				msg.Metadata = ctntVersions[i].UserMetadata
+			}
+			if o.enrich.checksum {
+				msg.showChecksum = true
+				msg.ChecksumType, msg.Checksum = ctntVersions[i].ChecksumType, ctntVersions[i].Checksum
+			}
+			if o.enrich.lockInfo && msg.Filetype != "folder" && !msg.IsDeleteMarker {
+				msg.showLock = true
+				setLockInfo(&msg, ctntVersions[i])
+			}
+		}
+		if o.missingContentType && msg.Filetype != "folder" && !msg.IsDeleteMarker {
+			msg.MissingContentType = isMissingContentType(msg.ContentType)
+		}
+		// Only objects lacking the tag are listed with --missing-tag.
+		if o.missingTag != "" && msg.Filetype != "folder" && !msg.IsDeleteMarker {
+			msg.MissingTag = o.missingTag
+		}
+		if o.priceTable != nil && msg.Filetype != "folder" {
+			cost, inferred := o.priceTable.monthlyCost(msg.StorageClass, msg.Size)
+			msg.EstimatedMonthlyCost = &cost
+			msg.StorageClassInferred = inferred
+		}
 		printMsg(msg)
 	}
 }
 
 type doListOptions struct {
-	timeRef           time.Time
-	isRecursive       bool
-	isIncomplete      bool
-	isSummary         bool
-	withOlderVersions bool
-	listZip           bool
-	filter            string
+	alias              string
+	timeRef            time.Time
+	isRecursive        bool
+	isIncomplete       bool
+	isSummary          bool
+	withOlderVersions  bool
+	listZip            bool
+	uniquePrefixes     bool
+	groupSizes         bool
+	groupBy            string
+	top                int
+	sortBy             string
+	perPrefixLimit     int
+	stats              bool
+	heartbeat          bool
+	icons              bool
+	timeStyle          string
+	progress           bool
+	onlyNoncurrent     bool
+	onlyDeleteMarkers  bool
+	flat               bool
+	noTrim             bool
+	filter             string
+	saveSnapshot       string
+	diffSnapshot       string
+	snapshotDiff       listSnapshotDiffOptions
+	priceTable         listPriceTable
+	enrich             *listEnrich
+	showEnriched       bool
+	missingContentType bool
+	missingTag         string
+	excludePrefixes    []string
+	regionInfo         bool
+	publicInfo         bool
+	grownSince         time.Time
+	dupes              bool
+	dupesHash          bool
+	deadline           time.Time
+	ageHistogram       *ageHistogram
+	merkleMode         string
+}
+
+// skipVersion returns true if a version is filtered out by
+// --only-noncurrent or --only-delete-markers.
+func (o doListOptions) skipVersion(isLatest, isDeleteMarker bool) bool {
+	return (o.onlyNoncurrent && isLatest) || (o.onlyDeleteMarkers && !isDeleteMarker)
+}
+
+// monthlyCost returns the estimated monthly cost of content with
+// --price-table, folders cost nothing.
+func (o doListOptions) monthlyCost(content *ClientContent) float64 {
+	if o.priceTable == nil || content.Type.IsDir() {
+		return 0
+	}
+	cost, _ := o.priceTable.monthlyCost(content.StorageClass, content.Size)
+	return cost
+}
+
+// listProgressInterval is the interval between updates of ls --progress.
+const listProgressInterval = time.Second
+
+// listHeartbeatInterval is the silence after which ls --heartbeat prints
+// a heartbeat record.
+var listHeartbeatInterval = 5 * time.Second
+
+// listHeartbeatMessage tells JSON consumers a quiet listing is alive.
+type listHeartbeatMessage struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"ts"`
+}
+
+// String heartbeat message
+func (h listHeartbeatMessage) String() string {
+	return fmt.Sprintf("Still listing at %s", h.Time.Format(printDate))
+}
+
+// JSON jsonified heartbeat message
+func (h listHeartbeatMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(h, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// listTruncatedExitStatus is the exit status of a listing stopped by
+// --max-runtime, the one of timeout(1).
+const listTruncatedExitStatus = 124
+
+// listTruncatedMessage tells a listing stopped by --max-runtime is
+// incomplete.
+type listTruncatedMessage struct {
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	URL     string `json:"url"`
+	Objects int64  `json:"objects"`
+}
+
+// String truncated message
+func (t listTruncatedMessage) String() string {
+	return console.Colorize("Truncated", fmt.Sprintf("Listing of `%s` truncated by --max-runtime after %s objects, results are incomplete.",
+		t.URL, humanize.Comma(t.Objects)))
+}
+
+// JSON jsonified truncated message
+func (t listTruncatedMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(t, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// showListProgress prints the number of listed entries on stderr every
+// listProgressInterval until doneCh is closed, then clears the line.
+func showListProgress(listed *int64, doneCh <-chan struct{}) {
+	ticker := time.NewTicker(listProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-doneCh:
+			fmt.Fprint(os.Stderr, "\r\033[2K")
+			return
+		case <-ticker.C:
+			fmt.Fprintf(os.Stderr, "\rlisted %s objects...", humanize.Comma(atomic.LoadInt64(listed)))
+		}
+	}
 }
 
 // doList - list all entities inside a folder.
@@ -224,47 +1004,360 @@ func doList(ctx context.Context, clnt Client, o doListOptions) error {
 		cErr              error
 		totalSize         int64
 		totalObjects      int64
+		totalCost         float64
+		seenPrefixes      = make(map[string]struct{})
+		prefixSizes       = make(map[string]*prefixSize)
+		extensionSizes    = make(map[string]*extensionSize)
+		perPrefixCount    = make(map[string]int)
+		perPrefixLargest  = make(map[string][]*ClientContent)
+		listedObjects     int64
+		duplicates        = make(map[int64][]duplicateCandidate)
+		apiCalls          int64
+		truncated         bool
 	)
 
-	for content := range clnt.List(ctx, ListOptions{
-		Recursive:         o.isRecursive,
+	startTime := time.Now()
+	if o.stats {
+		ctx = context.WithValue(ctx, apiCallsKey{}, &apiCalls)
+	}
+	stopProgress := func() {}
+	if o.progress {
+		doneCh := make(chan struct{})
+		exitCh := make(chan struct{})
+		go func() {
+			showListProgress(&listedObjects, doneCh)
+			close(exitCh)
+		}()
+		stopProgress = func() {
+			close(doneCh)
+			<-exitCh
+		}
+	}
+
+	ctx, cancelList := context.WithCancel(ctx)
+	defer cancelList()
+	contentCh := clnt.List(ctx, ListOptions{
+		Recursive:         o.isRecursive || o.flat,
 		Incomplete:        o.isIncomplete,
 		TimeRef:           o.timeRef,
 		WithOlderVersions: o.withOlderVersions || !o.timeRef.IsZero(),
 		WithDeleteMarkers: true,
 		ShowDir:           DirNone,
 		ListZip:           o.listZip,
-	}) {
+		ExcludePrefixes:   o.excludePrefixes,
+		BucketRegion:      o.regionInfo,
+		BucketPublic:      o.publicInfo,
+	})
+	if o.enrich != nil {
+		contentCh = enrichListing(ctx, o.alias, contentCh, *o.enrich)
+	}
+
+	// Heartbeats are records for JSON consumers only.
+	var heartbeatCh <-chan time.Time
+	if o.heartbeat && globalJSON {
+		ticker := time.NewTicker(listHeartbeatInterval)
+		defer ticker.Stop()
+		heartbeatCh = ticker.C
+	}
+	var deadlineCh <-chan time.Time
+	if !o.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(o.deadline))
+		defer timer.Stop()
+		deadlineCh = timer.C
+	}
+	var merkle *merkleTree
+	if o.merkleMode != "" {
+		merkle = &merkleTree{mode: o.merkleMode, alias: o.alias}
+	}
+	lastContent := time.Now()
+	// Objects whose tags were checked with --missing-tag, and those
+	// lacking the tag.
+	var checkedObjects, untaggedObjects int64
+	for {
+		if !o.deadline.IsZero() && !time.Now().Before(o.deadline) {
+			truncated = true
+			break
+		}
+		var content *ClientContent
+		var ok bool
+		select {
+		case <-deadlineCh:
+			continue // Stopped above.
+		case now := <-heartbeatCh:
+			if now.Sub(lastContent) >= listHeartbeatInterval {
+				printMsg(listHeartbeatMessage{Status: "heartbeat", Time: now.UTC()})
+				lastContent = now
+			}
+			continue
+		case content, ok = <-contentCh:
+		}
+		if !ok {
+			break
+		}
+		lastContent = time.Now()
 		if content.Err != nil {
 			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
 			cErr = exitStatus(globalErrorExitStatus) // Set the exit status.
 			continue
 		}
+		atomic.AddInt64(&listedObjects, 1)
+
+		// Without a delimiter there are no folders, directory
+		// markers are objects whose key ends with a slash.
+		if o.flat && content.Type.IsDir() {
+			content.Type = os.FileMode(0o664)
+		}
 
 		if content.StorageClass != "" && o.filter != "" && o.filter != "*" && content.StorageClass != o.filter {
 			continue
 		}
 
+		// Listings not skipping excluded prefixes are filtered here.
+		if len(o.excludePrefixes) > 0 {
+			key := strings.TrimPrefix(getKey(content), listPrefixPath(clnt.GetURL()))
+			if matchExcludePrefix(key, o.excludePrefixes) != "" {
+				continue
+			}
+		}
+
+		// Only objects created or modified since --grown-since are kept.
+		if !o.grownSince.IsZero() && (content.Type.IsDir() || content.IsDeleteMarker || !content.Time.After(o.grownSince)) {
+			continue
+		}
+
+		// Only objects lacking the --missing-tag key are kept, objects
+		// whose tags could not be fetched were reported and are not
+		// counted.
+		if o.missingTag != "" {
+			if content.Type.IsDir() || content.IsDeleteMarker || content.Tags == nil {
+				continue
+			}
+			checkedObjects++
+			if _, ok := content.Tags[o.missingTag]; ok {
+				continue
+			}
+			untaggedObjects++
+		}
+
+		if o.uniquePrefixes {
+			printUniquePrefixes(clnt.GetURL(), content, seenPrefixes)
+			totalSize += content.Size
+			totalCost += o.monthlyCost(content)
+			totalObjects++
+			continue
+		}
+
+		// Empty objects are not reported, they waste no space.
+		if o.dupes {
+			if !content.Type.IsDir() && !content.IsDeleteMarker && content.Size > 0 {
+				key := strings.TrimPrefix(getKey(content), listPrefixPath(clnt.GetURL()))
+				duplicates[content.Size] = append(duplicates[content.Size], duplicateCandidate{key: key, content: content})
+			}
+			continue
+		}
+
+		if o.groupSizes {
+			addPrefixSize(clnt.GetURL(), content, prefixSizes)
+			totalSize += content.Size
+			totalCost += o.monthlyCost(content)
+			totalObjects++
+			continue
+		}
+
+		if o.groupBy == groupByExtension {
+			addExtensionSize(content, extensionSizes)
+			totalSize += content.Size
+			totalCost += o.monthlyCost(content)
+			totalObjects++
+			continue
+		}
+
+		if o.ageHistogram != nil {
+			if !content.Type.IsDir() && !content.IsDeleteMarker {
+				o.ageHistogram.add(content, startTime)
+				totalSize += content.Size
+				totalCost += o.monthlyCost(content)
+				totalObjects++
+			}
+			continue
+		}
+
+		if merkle != nil {
+			if !content.Type.IsDir() && !content.IsDeleteMarker {
+				key := strings.TrimPrefix(getKey(content), listPrefixPath(clnt.GetURL()))
+				if err := merkle.add(ctx, key, content); err != nil {
+					errorIf(err.Trace(clnt.GetURL().String()), "Unable to compute the Merkle root of `"+clnt.GetURL().String()+"`.")
+					cErr = exitStatus(globalErrorExitStatus)
+					break
+				}
+				totalSize += content.Size
+				totalObjects++
+			}
+			continue
+		}
+
+		if o.perPrefixLimit > 0 {
+			prefix := topLevelPrefix(clnt.GetURL(), content)
+			if o.sortBy == prefixSortSize {
+				perPrefixLargest[prefix] = keepLargest(perPrefixLargest[prefix], content, o.perPrefixLimit)
+				continue
+			}
+			// Versions of a listed object are listed after it.
+			if lastPath != content.URL.Path {
+				if perPrefixCount[prefix] >= o.perPrefixLimit {
+					continue
+				}
+				perPrefixCount[prefix]++
+			}
+		}
+
 		if lastPath != content.URL.Path {
 			// Print any object in the current list before reinitializing it
-			printObjectVersions(clnt.GetURL(), perObjectVersions, o.withOlderVersions)
+			printObjectVersions(clnt.GetURL(), perObjectVersions, o)
 			lastPath = content.URL.Path
 			perObjectVersions = []*ClientContent{}
 		}
 
+		// Filtered versions are still kept to number the others.
 		perObjectVersions = append(perObjectVersions, content)
+		if o.skipVersion(content.IsLatest, content.IsDeleteMarker) {
+			continue
+		}
 		totalSize += content.Size
+		totalCost += o.monthlyCost(content)
 		totalObjects++
 	}
 
-	printObjectVersions(clnt.GetURL(), perObjectVersions, o.withOlderVersions)
+	stopProgress()
+	if truncated {
+		// Stop the listing, unblocking its producers.
+		cancelList()
+		go func() {
+			for range contentCh {
+			}
+		}()
+	}
+	printObjectVersions(clnt.GetURL(), perObjectVersions, o)
 
-	if o.isSummary {
-		printMsg(summaryMessage{
+	if len(perPrefixLargest) > 0 {
+		prefixes := make([]string, 0, len(perPrefixLargest))
+		for prefix := range perPrefixLargest {
+			prefixes = append(prefixes, prefix)
+		}
+		sort.Strings(prefixes)
+		for _, prefix := range prefixes {
+			for _, content := range perPrefixLargest[prefix] {
+				printObjectVersions(clnt.GetURL(), []*ClientContent{content}, o)
+				totalSize += content.Size
+				totalCost += o.monthlyCost(content)
+				totalObjects++
+			}
+		}
+	}
+
+	if o.groupSizes {
+		printMsg(prefixSizesMessage{
+			Prefixes:     sortPrefixSizes(prefixSizes, o.sortBy),
 			TotalObjects: totalObjects,
 			TotalSize:    totalSize,
 		})
 	}
 
+	if o.groupBy == groupByExtension {
+		printMsg(extensionSizesMessage{
+			Extensions:   sortExtensionSizes(extensionSizes, o.sortBy, o.top),
+			TotalObjects: totalObjects,
+			TotalSize:    totalSize,
+		})
+	}
+
+	if o.ageHistogram != nil {
+		printMsg(ageHistogramMessage{
+			Buckets:      o.ageHistogram.buckets,
+			TotalObjects: totalObjects,
+			TotalSize:    totalSize,
+		})
+	}
+
+	// The root of an incomplete listing would not match the one of the
+	// same objects, it is left out.
+	if merkle != nil && cErr == nil && !truncated {
+		printMsg(merkleRootMessage{
+			URL:       clnt.GetURL().String(),
+			Root:      merkle.root(),
+			Algorithm: merkleAlgorithm,
+			Mode:      merkle.mode,
+			Objects:   totalObjects,
+			TotalSize: totalSize,
+		})
+	}
+
+	if o.dupes {
+		hash := func(content *ClientContent) (string, *probe.Error) {
+			return hashContent(ctx, o.alias, content)
+		}
+		for _, group := range findDuplicates(duplicates, o.dupesHash, hash) {
+			printMsg(duplicateGroupMessage(group))
+		}
+	}
+
+	if o.missingTag != "" {
+		compliant := 100.0
+		if checkedObjects > 0 {
+			compliant = float64(checkedObjects-untaggedObjects) * 100 / float64(checkedObjects)
+		}
+		printMsg(missingTagMessage{
+			Tag:       o.missingTag,
+			Objects:   checkedObjects,
+			Missing:   untaggedObjects,
+			Compliant: compliant,
+		})
+	}
+
+	if !o.grownSince.IsZero() {
+		printMsg(grownSinceMessage{
+			Objects:   totalObjects,
+			TotalSize: totalSize,
+			Since:     o.grownSince,
+		})
+	}
+
+	if o.isSummary {
+		summary := summaryMessage{
+			TotalObjects: totalObjects,
+			TotalSize:    totalSize,
+		}
+		if o.priceTable != nil {
+			summary.EstimatedMonthlyCost = &totalCost
+		}
+		printMsg(summary)
+	}
+
+	if o.stats {
+		elapsed := time.Since(startTime)
+		stats := listStatsMessage{
+			Status:     "success",
+			DurationMs: elapsed.Milliseconds(),
+			Pages:      atomic.LoadInt64(&apiCalls),
+			Objects:    atomic.LoadInt64(&listedObjects),
+		}
+		if elapsed > 0 {
+			stats.Rate = float64(stats.Objects) / elapsed.Seconds()
+		}
+		if truncated {
+			stats.Status = "truncated"
+		}
+		printErrMsg(stats)
+	}
+
+	if truncated {
+		printMsg(listTruncatedMessage{
+			Status:  "truncated",
+			Reason:  "max-runtime",
+			URL:     clnt.GetURL().String(),
+			Objects: atomic.LoadInt64(&listedObjects),
+		})
+		cErr = exitStatus(listTruncatedExitStatus)
+	}
 	return cErr
 }
@@ -16,3 +16,786 @@
 // along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
 package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestListSkipVersion(t *testing.T) {
+	versions := []struct {
+		isLatest, isDeleteMarker bool
+	}{
+		{true, false},
+		{false, false},
+		{false, true},
+		{true, true},
+	}
+	testCases := []struct {
+		opts   doListOptions
+		listed []bool
+	}{
+		{doListOptions{}, []bool{true, true, true, true}},
+		{doListOptions{onlyNoncurrent: true}, []bool{false, true, true, false}},
+		{doListOptions{onlyDeleteMarkers: true}, []bool{false, false, true, true}},
+		{doListOptions{onlyNoncurrent: true, onlyDeleteMarkers: true}, []bool{false, false, true, false}},
+	}
+	for i, testCase := range testCases {
+		for j, v := range versions {
+			if listed := !testCase.opts.skipVersion(v.isLatest, v.isDeleteMarker); listed != testCase.listed[j] {
+				t.Errorf("Test %d, version %d: expected listed %t, got %t", i+1, j+1, testCase.listed[j], listed)
+			}
+		}
+	}
+}
+
+func TestListKeyPrefix(t *testing.T) {
+	testCases := []struct {
+		url                 string
+		trimmed, fullPrefix string
+	}{
+		{"http://localhost:9000/bucket/dir/", "/bucket/dir/", "/bucket/"},
+		{"http://localhost:9000/bucket/dir/obj", "/bucket/dir/", "/bucket/"},
+		{"http://localhost:9000/bucket", "/", "/bucket/"},
+		{"http://localhost:9000/", "/", "/"},
+		{"/tmp/dir/", "/tmp/dir/", ""},
+	}
+	for i, testCase := range testCases {
+		u := newClientURL(testCase.url)
+		if prefix := listPrefixPath(*u); prefix != testCase.trimmed {
+			t.Errorf("Test %d: expected prefix %q, got %q", i+1, testCase.trimmed, prefix)
+		}
+		if prefix := listFullKeyPrefix(*u); prefix != testCase.fullPrefix {
+			t.Errorf("Test %d: expected full key prefix %q, got %q", i+1, testCase.fullPrefix, prefix)
+		}
+	}
+}
+
+func TestContentMessageFormatTime(t *testing.T) {
+	modTime := time.Date(2023, 5, 4, 10, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	testCases := []struct {
+		timeStyle string
+		expected  string
+	}{
+		{timeStyleUTC, "[2023-05-04 08:30:00 UTC]"},
+		{timeStyleEpoch, "1683189000"},
+	}
+	for i, testCase := range testCases {
+		c := contentMessage{Time: modTime, timeStyle: testCase.timeStyle}
+		if s := c.formatTime(); s != testCase.expected {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expected, s)
+		}
+	}
+
+	c := contentMessage{Time: time.Now().Add(-3 * time.Hour), timeStyle: timeStyleRelative}
+	if s := c.formatTime(); s != "[  3 hours ago]" {
+		t.Errorf("expected relative time, got %q", s)
+	}
+}
+
+func TestContentMessageJSONStable(t *testing.T) {
+	msg := contentMessage{
+		Filetype: "file",
+		Time:     time.Date(2023, 5, 4, 8, 30, 0, 0, time.UTC),
+		Size:     12,
+		Key:      "a.txt",
+		ETag:     "9af2f8218b150c351ad802c6f3d66abe",
+		Metadata: map[string]string{"X-Amz-Meta-Zeta": "z", "Content-Type": "text/plain", "X-Amz-Meta-Alpha": "a"},
+	}
+	expected := `{"status":"success","type":"file","lastModified":"2023-05-04T08:30:00Z","size":12,"key":"a.txt",` +
+		`"etag":"9af2f8218b150c351ad802c6f3d66abe",` +
+		`"metadata":{"Content-Type":"text/plain","X-Amz-Meta-Alpha":"a","X-Amz-Meta-Zeta":"z"},` +
+		`"lastModifiedEpoch":1683189000,"lastModifiedEpochMillis":1683189000000}`
+	for i := 0; i < 10; i++ {
+		if s := compactJSON(msg.JSON()); s != expected {
+			t.Fatalf("expected %s, got %s", expected, s)
+		}
+	}
+}
+
+// Test --json prints each message on a single line when the output is
+// not a terminal.
+func TestFormatMsgJSONLine(t *testing.T) {
+	defer func(json, jsonLine bool) { globalJSON, globalJSONLine = json, jsonLine }(globalJSON, globalJSONLine)
+	globalJSON, globalJSONLine = true, true
+	msg := contentMessage{Filetype: "file", Key: "a.txt", Metadata: map[string]string{"Content-Type": "text/plain"}}
+	if s := formatMsg(msg); strings.ContainsAny(s, "\n ") {
+		t.Fatalf("expected a compact JSON line, got %q", s)
+	}
+}
+
+func TestWithJSONHost(t *testing.T) {
+	msg := contentMessage{Status: "success", Key: "a.txt", host: "play"}
+	if s := compactJSON(withJSONHost(msg, msg.JSON())); !strings.HasSuffix(s, `"etag":"","host":"play"}`) {
+		t.Fatalf("expected the host field last, got %s", s)
+	}
+	if s := withJSONHost(msg, "{}"); s != `{"host":"play"}` {
+		t.Fatalf("expected a single host field, got %s", s)
+	}
+	if s := withJSONHost(msg, `[{"key":"a.txt"}]`); s != `[{"key":"a.txt"}]` {
+		t.Fatalf("expected arrays unchanged, got %s", s)
+	}
+	// Local listings have no alias.
+	msg.host = ""
+	if s := withJSONHost(msg, `{"key":"a.txt"}`); s != `{"key":"a.txt"}` {
+		t.Fatalf("expected no host field, got %s", s)
+	}
+}
+
+func TestGroupPrefixSizes(t *testing.T) {
+	clntURL := newClientURL("http://localhost:9000/bucket/")
+	prefixSizes := make(map[string]*prefixSize)
+	for key, size := range map[string]int64{"logs/a/1": 10, "logs/2": 5, "img/1": 20, "top": 1} {
+		content := &ClientContent{URL: *newClientURL("http://localhost:9000/bucket/" + key), Size: size}
+		addPrefixSize(*clntURL, content, prefixSizes)
+	}
+	testCases := []struct {
+		sortBy   string
+		expected []prefixSize
+	}{
+		{prefixSortSize, []prefixSize{{"img/", 20, 1}, {"logs/", 15, 2}, {"top", 1, 1}}},
+		{prefixSortObjects, []prefixSize{{"logs/", 15, 2}, {"img/", 20, 1}, {"top", 1, 1}}},
+		{prefixSortName, []prefixSize{{"img/", 20, 1}, {"logs/", 15, 2}, {"top", 1, 1}}},
+	}
+	for i, testCase := range testCases {
+		if sorted := sortPrefixSizes(prefixSizes, testCase.sortBy); !reflect.DeepEqual(sorted, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, sorted)
+		}
+	}
+}
+
+func TestContentMessageURLString(t *testing.T) {
+	clntURL := newClientURL("http://localhost:9000/bucket/dir/")
+	content := &ClientContent{URL: *newClientURL("http://localhost:9000/bucket/dir/sub/obj")}
+	msgs := generateContentMessages(*clntURL, []*ClientContent{content}, false, "/bucket/dir/", "myminio")
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if msgs[0].Key != "sub/obj" || msgs[0].URLString != "myminio/bucket/dir/sub/obj" {
+		t.Errorf("unexpected key %q and urlString %q", msgs[0].Key, msgs[0].URLString)
+	}
+}
+
+// Test isLatest is only part of the JSON of version listings.
+func TestContentMessageIsLatest(t *testing.T) {
+	clntURL := newClientURL("http://localhost:9000/bucket/")
+	content := &ClientContent{URL: *newClientURL("http://localhost:9000/bucket/obj")}
+	msgs := generateContentMessages(*clntURL, []*ClientContent{content}, false, "/bucket/", "")
+	if s := msgs[0].JSON(); strings.Contains(s, "isLatest") {
+		t.Errorf("expected no isLatest without --versions, got %s", s)
+	}
+	versions := []*ClientContent{
+		{URL: *newClientURL("http://localhost:9000/bucket/obj"), VersionID: "v2", IsLatest: true},
+		{URL: *newClientURL("http://localhost:9000/bucket/obj"), VersionID: "v1"},
+	}
+	msgs = generateContentMessages(*clntURL, versions, true, "/bucket/", "")
+	if s := compactJSON(msgs[0].JSON()); !strings.Contains(s, `"isLatest":true`) {
+		t.Errorf("expected the latest version to be marked, got %s", s)
+	}
+	if s := compactJSON(msgs[1].JSON()); !strings.Contains(s, `"isLatest":false`) {
+		t.Errorf("expected the older version not to be marked, got %s", s)
+	}
+}
+
+func TestKeepLargest(t *testing.T) {
+	var largest []*ClientContent
+	for _, size := range []int64{5, 1, 9, 7, 3, 9} {
+		largest = keepLargest(largest, &ClientContent{Size: size}, 3)
+	}
+	var sizes []int64
+	for _, content := range largest {
+		sizes = append(sizes, content.Size)
+	}
+	if expected := []int64{9, 9, 7}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("expected %v, got %v", expected, sizes)
+	}
+}
+
+func TestHTTPLogSink(t *testing.T) {
+	var bodies []string
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	sink, err := newLogSink(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < logSinkBatchSize+1; i++ {
+		if e := sink.Write(`{"key":"a"}`); e != nil {
+			t.Fatal(e)
+		}
+	}
+	if e := sink.Close(); e != nil {
+		t.Fatal(e)
+	}
+	if len(bodies) != 2 || strings.Count(bodies[0], "\n") != logSinkBatchSize || bodies[1] != "{\"key\":\"a\"}\n" {
+		t.Fatalf("unexpected requests %q", bodies)
+	}
+
+	status = http.StatusServiceUnavailable
+	sink.Write(`{"key":"b"}`)
+	if e := sink.Close(); e == nil {
+		t.Fatal("expected an error when the sink rejects records")
+	}
+}
+
+// Test the log sink is flushed when mc exits, and that a record failing
+// to be delivered exits flushing it without a deadlock.
+func TestLogSinkExitHooks(t *testing.T) {
+	defer func(f func(...interface{})) { fatalln = f }(fatalln)
+	fatals := 0
+	fatalln = func(...interface{}) {
+		fatals++
+		runExitHooks()
+	}
+
+	var bodies []string
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	if err := openLogSink(ts.URL, false); err != nil {
+		t.Fatal(err)
+	}
+	sendToLogSink(rmMessage{Key: "a"})
+	sendToLogSink(rmMessage{Key: "b"})
+	if len(bodies) != 0 {
+		t.Fatalf("expected the records to be batched, got %q", bodies)
+	}
+	runExitHooks()
+	if len(bodies) != 1 || strings.Count(bodies[0], "\n") != 2 || globalLogSink != nil {
+		t.Fatalf("expected the records to be flushed when exiting, got %q", bodies)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := openLogSink(ts.URL, false); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < logSinkBatchSize; i++ {
+			sendToLogSink(rmMessage{Key: "c"})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the failed record did not exit")
+	}
+	if fatals != 1 || globalLogSink != nil {
+		t.Fatalf("expected one fatal error closing the sink, got %d", fatals)
+	}
+}
+
+func TestDiffListSnapshotObjects(t *testing.T) {
+	prev := map[string]listSnapshotEntry{
+		"a": {Size: 1, ModTime: 1},
+		"b": {Size: 2, ModTime: 2, ETag: "x"},
+		"c": {Size: 3, ModTime: 3},
+	}
+	cur := map[string]listSnapshotEntry{
+		"b": {Size: 2, ModTime: 2, ETag: "y"},
+		"c": {Size: 3, ModTime: 3},
+		"d": {Size: 4, ModTime: 4},
+	}
+	var got []string
+	for _, msg := range diffListSnapshotObjects(prev, cur) {
+		got = append(got, msg.Diff+" "+msg.Key)
+	}
+	want := []string{"removed a", "modified b", "added d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// Only a new etag counts as a change for --changed-since.
+	touched := listSnapshotDiffMessage{Diff: snapshotModified, ETag: "x", PrevETag: "x"}
+	if touched.etagChanged() {
+		t.Fatal("expected an object with the same etag to be unchanged")
+	}
+	if u := snapshotKeyURL("https://cdn.example.com/", "docs/a b.html"); u != "https://cdn.example.com/docs/a%20b.html" {
+		t.Fatalf("unexpected URL %s", u)
+	}
+}
+
+func TestListPriceTableMonthlyCost(t *testing.T) {
+	table := listPriceTable{"STANDARD": 0.02, "GLACIER": 0.004, listPriceDefaultClass: 0.01}
+	testCases := []struct {
+		storageClass string
+		size         int64
+		cost         float64
+		inferred     bool
+	}{
+		{"STANDARD", 50 << 30, 1, false},
+		{"", 50 << 30, 1, true},
+		{"glacier", 250 << 30, 1, false},
+		{"ONEZONE_IA", 100 << 30, 1, false},
+	}
+	for i, testCase := range testCases {
+		cost, inferred := table.monthlyCost(testCase.storageClass, testCase.size)
+		if cost != testCase.cost || inferred != testCase.inferred {
+			t.Errorf("Test %d: expected %v, %v, got %v, %v", i+1, testCase.cost, testCase.inferred, cost, inferred)
+		}
+	}
+}
+
+func TestEnrichListingOrder(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		for i := 0; i < 50; i++ {
+			name := filepath.Join(dir, fmt.Sprintf("%02d.txt", i))
+			if e := os.WriteFile(name, []byte("x"), 0o600); e != nil {
+				t.Error(e)
+				return
+			}
+			contentCh <- &ClientContent{URL: *newClientURL(name), Type: 0o600}
+		}
+	}()
+
+	i := 0
+	for content := range enrichListing(context.Background(), "", contentCh, listEnrich{contentType: true, workers: 4}) {
+		if expected := fmt.Sprintf("%02d.txt", i); filepath.Base(content.URL.Path) != expected {
+			t.Fatalf("expected %s at position %d, got %s", expected, i, content.URL.Path)
+		}
+		if !strings.HasPrefix(content.Metadata["Content-Type"], "text/plain") {
+			t.Fatalf("expected a text/plain content type for %s, got %q", content.URL.Path, content.Metadata["Content-Type"])
+		}
+		i++
+	}
+	if i != 50 {
+		t.Fatalf("expected 50 contents, got %d", i)
+	}
+}
+
+func TestIsMissingContentType(t *testing.T) {
+	testCases := map[string]bool{
+		"":                                  true,
+		"application/octet-stream":          true,
+		"Application/Octet-Stream; charset": true,
+		"text/html; charset=utf-8":          false,
+		"image/png":                         false,
+	}
+	for contentType, missing := range testCases {
+		if isMissingContentType(contentType) != missing {
+			t.Errorf("content type %q: expected missing %v", contentType, missing)
+		}
+	}
+}
+
+func TestExpandListGlob(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := filepath.ToSlash(t.TempDir())
+	for _, name := range []string{"logs-2022/app/a.gz", "logs-2023/app/a.gz", "logs-2023/app/b.txt", "logs-2024/app/c.gz", "logs-2024/web/d.gz"} {
+		file := filepath.Join(dir, name)
+		if e := os.MkdirAll(filepath.Dir(file), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(file, []byte("x"), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	testCases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"/logs-202[34]/app/*.gz", []string{"/logs-2023/app/a.gz", "/logs-2024/app/c.gz"}},
+		{"/logs-2024/*/", []string{"/logs-2024/app/", "/logs-2024/web/"}},
+		{"/logs-20?2", []string{"/logs-2022/"}},
+		{"/logs-*/none/*", nil},
+	}
+	for i, testCase := range testCases {
+		matches, err := expandListGlob(context.Background(), dir+testCase.pattern)
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, err)
+		}
+		var expected []string
+		for _, match := range testCase.expected {
+			expected = append(expected, dir+match)
+		}
+		sort.Strings(matches)
+		if !reflect.DeepEqual(matches, expected) {
+			t.Errorf("Test %d: expected %q, got %q", i+1, expected, matches)
+		}
+	}
+}
+
+func TestParseTimeRefFlag(t *testing.T) {
+	want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	for _, value := range []string{"2024-01-01", "2024.01.01", "2024-01-01T00:00"} {
+		if got := parseTimeRefFlag("grown-since", value); !got.Equal(want) {
+			t.Errorf("%q: expected %v, got %v", value, want, got)
+		}
+	}
+	if got := parseTimeRefFlag("grown-since", "7d"); time.Since(got) < 7*24*time.Hour {
+		t.Errorf("7d: expected at least 7 days ago, got %v", got)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	candidate := func(key, etag string, size int64) duplicateCandidate {
+		return duplicateCandidate{key: key, content: &ClientContent{ETag: etag, Size: size}}
+	}
+	candidates := map[int64][]duplicateCandidate{
+		10: {candidate("a", "e1", 10), candidate("b", "e1", 10), candidate("c", "e2", 10)},
+		20: {candidate("d", "e3-2", 20), candidate("e", "e4-2", 20)},
+		30: {candidate("f", "e1", 30)},
+	}
+	// Multipart objects of the same size hash the same.
+	hash := func(*ClientContent) (string, *probe.Error) { return "h", nil }
+	testCases := []struct {
+		hashMultipart bool
+		expected      []duplicateGroup
+	}{
+		{false, []duplicateGroup{{ETag: "e1", Size: 10, Keys: []string{"a", "b"}}}},
+		{true, []duplicateGroup{
+			{ETag: "sha256:h", Size: 20, Keys: []string{"d", "e"}},
+			{ETag: "e1", Size: 10, Keys: []string{"a", "b"}},
+		}},
+	}
+	for i, testCase := range testCases {
+		if got := findDuplicates(candidates, testCase.hashMultipart, hash); !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestSortExtensionSizes(t *testing.T) {
+	extensionSizes := make(map[string]*extensionSize)
+	for _, object := range []struct {
+		key  string
+		size int64
+	}{
+		{"photos/a.JPG", 10},
+		{"photos/b.jpg", 20},
+		{"videos/c.mp4", 100},
+		{"logs/.hidden", 1},
+		{"README", 2},
+		{"archive.tar.gz", 50},
+	} {
+		addExtensionSize(&ClientContent{URL: *newClientURL("/" + object.key), Size: object.size}, extensionSizes)
+	}
+	expected := []extensionSize{
+		{Extension: ".mp4", Count: 1, Size: 100},
+		{Extension: ".gz", Count: 1, Size: 50},
+		{Extension: ".jpg", Count: 2, Size: 30},
+	}
+	if got := sortExtensionSizes(extensionSizes, prefixSortSize, 3); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := sortExtensionSizes(extensionSizes, prefixSortName, 0); len(got) != 4 || got[0].Extension != noExtension || got[0].Count != 2 {
+		t.Errorf("expected 4 extensions starting with %v, got %v", noExtension, got)
+	}
+}
+
+func TestValidateJSONRecord(t *testing.T) {
+	good := contentMessage{Status: "success", Key: "a.txt", Metadata: map[string]string{"X-Amz-Meta-A": "b"}}
+	if err := validateJSONRecord(good, good.JSON()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bad := contentMessage{Status: "success", Key: "bad\xffkey"}
+	err := validateJSONRecord(bad, bad.JSON())
+	if err == nil || !strings.Contains(err.ToGoError().Error(), "field `key`") {
+		t.Fatalf("expected the key to be reported, got %v", err)
+	}
+	badMeta := contentMessage{Status: "success", Key: "a.txt", Metadata: map[string]string{"X-Amz-Meta-A": "\xfe"}}
+	err = validateJSONRecord(badMeta, badMeta.JSON())
+	if err == nil || !strings.Contains(err.ToGoError().Error(), "field `metadata.X-Amz-Meta-A`") {
+		t.Fatalf("expected the metadata to be reported, got %v", err)
+	}
+	if err := validateJSONRecord(good, `"a.txt"`); err == nil {
+		t.Fatal("expected a JSON string record to be rejected")
+	}
+}
+
+func TestSetLockInfo(t *testing.T) {
+	var msg contentMessage
+	setLockInfo(&msg, &ClientContent{})
+	if msg.RetentionMode != "none" || msg.RetainUntil != nil || msg.LegalHold != "none" {
+		t.Fatalf("expected no lock info, got %v %v %v", msg.RetentionMode, msg.RetainUntil, msg.LegalHold)
+	}
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	setLockInfo(&msg, &ClientContent{RetentionMode: "COMPLIANCE", RetainUntil: until, LegalHold: "ON"})
+	if msg.RetentionMode != "COMPLIANCE" || msg.RetainUntil == nil || !msg.RetainUntil.Equal(until) || msg.LegalHold != "ON" {
+		t.Fatalf("unexpected lock info %v %v %v", msg.RetentionMode, msg.RetainUntil, msg.LegalHold)
+	}
+	if !isObjectLockUnset(probe.NewError(APINotImplemented{API: "GetObjectRetention", APIType: "filesystem"})) {
+		t.Fatal("expected an unsupported client to be listed without lock info")
+	}
+}
+
+func TestListMaxRuntime(t *testing.T) {
+	root := t.TempDir()
+	if e := os.WriteFile(filepath.Join(root, "object"), []byte("data"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	clnt, err := fsNew(root + string(os.PathSeparator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := doList(context.Background(), clnt, doListOptions{isRecursive: true}); e != nil {
+		t.Fatalf("unexpected error %v", e)
+	}
+	e := doList(context.Background(), clnt, doListOptions{isRecursive: true, deadline: time.Now().Add(-time.Second)})
+	if exitErr, ok := e.(cli.ExitCoder); !ok || exitErr.ExitCode() != listTruncatedExitStatus {
+		t.Fatalf("expected exit status %d, got %v", listTruncatedExitStatus, e)
+	}
+}
+
+// Test --unique-prefixes prints each prefix once, parents first.
+func TestListUniquePrefixes(t *testing.T) {
+	defer func(json, jsonLine bool, output io.Writer) {
+		globalJSON, globalJSONLine, color.Output = json, jsonLine, output
+	}(globalJSON, globalJSONLine, color.Output)
+	var out bytes.Buffer
+	globalJSON, globalJSONLine, color.Output = true, true, &out
+
+	root := t.TempDir()
+	for _, name := range []string{"a/b/c/obj1", "a/obj2", "a/b/obj3", "d/obj4", "top"} {
+		path := filepath.Join(root, name)
+		if e := os.MkdirAll(filepath.Dir(path), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	clnt, err := fsNew(root + string(os.PathSeparator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := doList(context.Background(), clnt, doListOptions{isRecursive: true, uniquePrefixes: true}); e != nil {
+		t.Fatal(e)
+	}
+
+	var prefixes []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var msg prefixMessage
+		if e := json.Unmarshal([]byte(line), &msg); e != nil {
+			t.Fatalf("unable to parse %q: %v", line, e)
+		}
+		if msg.Filetype != "prefix" {
+			t.Errorf("expected the type of %s to be prefix, got %q", msg.Key, msg.Filetype)
+		}
+		prefixes = append(prefixes, msg.Key)
+	}
+	if expected := []string{"a/", "a/b/", "a/b/c/", "d/"}; !reflect.DeepEqual(prefixes, expected) {
+		t.Fatalf("expected the prefixes %v, got %v", expected, prefixes)
+	}
+}
+
+// slowListClient lists the contents of its client, each after the
+// delay found for its base name.
+type slowListClient struct {
+	Client
+	delays map[string]time.Duration
+}
+
+func (c slowListClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		for content := range c.Client.List(ctx, opts) {
+			time.Sleep(c.delays[filepath.Base(content.URL.Path)])
+			contentCh <- content
+		}
+	}()
+	return contentCh
+}
+
+// Test --heartbeat prints heartbeats in JSON mode only, while nothing is
+// listed for a while.
+func TestListHeartbeat(t *testing.T) {
+	defer func(interval time.Duration) { listHeartbeatInterval = interval }(listHeartbeatInterval)
+	listHeartbeatInterval = 20 * time.Millisecond
+	defer func(json, jsonLine bool, output io.Writer) {
+		globalJSON, globalJSONLine, color.Output = json, jsonLine, output
+	}(globalJSON, globalJSONLine, color.Output)
+	var out bytes.Buffer
+	color.Output = &out
+
+	root := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if e := os.WriteFile(filepath.Join(root, name), []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	fsClnt, err := fsNew(root + string(os.PathSeparator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt := slowListClient{Client: fsClnt, delays: map[string]time.Duration{"c": 10 * listHeartbeatInterval}}
+	list := func(jsonOutput bool) []string {
+		out.Reset()
+		globalJSON, globalJSONLine = jsonOutput, jsonOutput
+		if e := doList(context.Background(), clnt, doListOptions{isRecursive: true, heartbeat: true}); e != nil {
+			t.Fatal(e)
+		}
+		var records []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if !jsonOutput {
+				records = append(records, line)
+				continue
+			}
+			var msg struct {
+				Status string `json:"status"`
+				Key    string `json:"key"`
+			}
+			if e := json.Unmarshal([]byte(line), &msg); e != nil {
+				t.Fatalf("unable to parse %q: %v", line, e)
+			}
+			if msg.Status == "heartbeat" {
+				msg.Key = "heartbeat"
+			}
+			if n := len(records); n == 0 || records[n-1] != msg.Key {
+				records = append(records, msg.Key)
+			}
+		}
+		return records
+	}
+
+	// Objects are printed once the next one is listed, with all their
+	// versions: b is waiting for c.
+	if records := list(true); !reflect.DeepEqual(records, []string{"a", "heartbeat", "b", "c"}) {
+		t.Fatalf("expected heartbeats only while c is not listed, got %v", records)
+	}
+	for _, record := range list(false) {
+		if strings.Contains(record, "Still listing") {
+			t.Fatalf("expected no heartbeat without --json, got %q", record)
+		}
+	}
+}
+
+func TestAgeHistogram(t *testing.T) {
+	if _, err := parseAgeBuckets("30d,7d"); err == nil {
+		t.Fatal("expected decreasing age buckets to be rejected")
+	}
+	h, err := parseAgeBuckets("7d,30d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, age := range []time.Duration{time.Hour, 10 * 24 * time.Hour, 7 * 24 * time.Hour, 400 * 24 * time.Hour} {
+		h.add(&ClientContent{Time: now.Add(-age), Size: 1}, now)
+	}
+	want := []ageSize{{"<7d", 1, 1}, {"7d-30d", 2, 2}, {">30d", 1, 1}}
+	if !reflect.DeepEqual(h.buckets, want) {
+		t.Fatalf("expected %v, got %v", want, h.buckets)
+	}
+}
+
+func TestMissingTagMessage(t *testing.T) {
+	msg := missingTagMessage{Tag: "owner", Objects: 3, Missing: 2, Compliant: 100.0 / 3}
+	if got, want := msg.String(), "2 of 3 objects lack the tag `owner`, 33.3% compliant"; !strings.Contains(got, want) {
+		t.Fatalf("expected %q in %q", want, got)
+	}
+	if got := msg.JSON(); !strings.Contains(got, `"missing":2`) {
+		t.Fatalf("expected the missing count in %s", got)
+	}
+}
+
+func TestMerkleRoot(t *testing.T) {
+	root := func(contents map[string]*ClientContent, keys ...string) string {
+		tree := &merkleTree{mode: merkleModeMetadata}
+		for _, key := range keys {
+			if err := tree.add(context.Background(), key, contents[key]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return tree.root()
+	}
+	contents := map[string]*ClientContent{
+		"a":   {Size: 1, ETag: `"e1"`},
+		"b/c": {Size: 2, ETag: "e2"},
+		"d":   {Size: 3, ETag: "e3"},
+	}
+	want := root(contents, "a", "b/c", "d")
+	contents["a"] = &ClientContent{Size: 1, ETag: "e1"}
+	if got := root(contents, "a", "b/c", "d"); got != want {
+		t.Fatalf("expected quoted and unquoted etags to match, got %s", got)
+	}
+	contents["d"] = &ClientContent{Size: 3, ETag: "e4"}
+	if got := root(contents, "a", "b/c", "d"); got == want {
+		t.Fatal("expected a changed etag to change the root")
+	}
+	if got := root(contents); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Fatalf("expected the root of no object to be the hash of nothing, got %s", got)
+	}
+
+	tree := &merkleTree{mode: merkleModeMetadata}
+	if err := tree.add(context.Background(), "b/c", contents["b/c"]); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.add(context.Background(), "a", contents["a"]); err == nil {
+		t.Fatal("expected keys listed out of order to be rejected")
+	}
+}
+
+// Test the rolling root matches the one of the whole tree, hashed level
+// by level, and keeps a frontier logarithmic in the number of objects.
+func TestMerkleRootFrontier(t *testing.T) {
+	for n := 0; n <= 33; n++ {
+		tree := &merkleTree{mode: merkleModeMetadata}
+		var hashes [][]byte
+		for i := 0; i < n; i++ {
+			key := fmt.Sprintf("object-%02d", i)
+			if err := tree.add(context.Background(), key, &ClientContent{Size: int64(i), ETag: "etag"}); err != nil {
+				t.Fatal(err)
+			}
+			hashes = append(hashes, merkleLeafHash(key, strconv.Itoa(i), "etag"))
+		}
+		if len(tree.frontier) > bits.Len(uint(n)) {
+			t.Fatalf("expected at most %d subtrees for %d objects, got %d", bits.Len(uint(n)), n, len(tree.frontier))
+		}
+		for len(hashes) > 1 {
+			var next [][]byte
+			for i := 0; i < len(hashes); i += 2 {
+				if i+1 == len(hashes) {
+					next = append(next, hashes[i])
+					continue
+				}
+				next = append(next, merkleNodeHash(hashes[i], hashes[i+1]))
+			}
+			hashes = next
+		}
+		if n > 0 {
+			if got, want := tree.root(), hex.EncodeToString(hashes[0]); got != want {
+				t.Fatalf("expected the root %s of %d objects, got %s", want, n, got)
+			}
+		}
+	}
+}
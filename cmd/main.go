@@ -138,6 +138,17 @@ func Main(args []string) error {
 	defer globalHelpPager.WaitForExit()
 
 	parsePagerDisableFlag(args)
+	args = parseJSONArrayFlag(args)
+	// Commands failing with an exit status run the exit hooks as well.
+	cli.OsExiter = func(code int) {
+		runExitHooks()
+		os.Exit(code)
+	}
+	if globalJSONArray {
+		// Keep the array well formed whichever way mc exits.
+		defer closeJSONArray()
+		atExit(closeJSONArray)
+	}
 	// Run the app
 	return registerApp(appName).Run(args)
 }
@@ -431,6 +442,7 @@ var appCmds = []cli.Command{
 	duCmd,
 	encryptCmd,
 	eventCmd,
+	execMcBatchCmd,
 	findCmd,
 	headCmd,
 	ilmCmd,
@@ -448,6 +460,7 @@ var appCmds = []cli.Command{
 	quotaCmd,
 	rmCmd,
 	retentionCmd,
+	resumeCmd,
 	rbCmd,
 	replicateCmd,
 	readyCmd,
@@ -504,7 +517,7 @@ func registerApp(name string) *cli.App {
 	app.Before = registerBefore
 	app.HideHelpCommand = true
 	app.Usage = "MinIO Client for object storage and filesystems."
-	app.Commands = appCmds
+	app.Commands = guardDryRun(appCmds, "")
 	app.Author = "MinIO, Inc."
 	app.Version = ReleaseTag
 	app.Flags = append(mcFlags, globalFlags...)
@@ -27,6 +27,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
@@ -59,10 +60,6 @@ var (
 			Usage:  "perform a fake mirror operation",
 			Hidden: true, // deprecated 2022
 		},
-		cli.BoolFlag{
-			Name:  "dry-run",
-			Usage: "perform a fake mirror operation",
-		},
 		cli.BoolFlag{
 			Name:  "watch, w",
 			Usage: "watch and synchronize changes",
@@ -71,14 +68,19 @@ var (
 			Name:  "remove",
 			Usage: "remove extraneous object(s) on target",
 		},
+		cli.StringFlag{
+			Name:  "remove-older-than",
+			Usage: "with --remove, only remove extraneous object(s) older than value in duration string (e.g. 7d10h31s), or \"start\" for object(s) older than the mirror start",
+		},
 		cli.StringFlag{
 			Name:  "region",
 			Usage: "specify region when creating new bucket(s) on target",
 			Value: "us-east-1",
 		},
-		cli.BoolFlag{
+		cli.GenericFlag{
 			Name:  "preserve, a",
-			Usage: "preserve file(s)/object(s) attributes and bucket(s) policy/locking configuration(s) on target bucket(s)",
+			Usage: "preserve file(s)/object(s) attributes and bucket(s) policy/locking configuration(s) on target bucket(s), or only the attributes of a comma separated list of [metadata, tags, acl, timestamps], \"all\" for all of them",
+			Value: &preserveFlag{},
 		},
 		cli.BoolFlag{
 			Name:  "md5",
@@ -137,6 +139,15 @@ var (
 			Name:  "skip-errors",
 			Usage: "skip any errors when mirroring",
 		},
+		cli.BoolFlag{
+			Name:  "verify",
+			Usage: "verify each copied object by comparing the etags or checksums of source and target, reading both in full when they have none, see --retry",
+		},
+		cli.IntFlag{
+			Name:  "verify-workers",
+			Usage: "number of parallel verifications with --verify",
+			Value: 4,
+		},
 	}
 )
 
@@ -214,6 +225,15 @@ EXAMPLES:
   16. Cross mirror between sites in a active-active deployment.
       Site-A: {{.Prompt}} {{.HelpName}} --active-active siteA siteB
       Site-B: {{.Prompt}} {{.HelpName}} --active-active siteB siteA
+
+  17. Mirror a backup and verify every copied object, copying again the ones whose checksums do not match.
+      {{.Prompt}} {{.HelpName}} --verify --retry --verify-workers 8 backup/ s3/archive
+
+  18. Mirror a bucket and remove extraneous objects on target, keeping the ones written there during the last day.
+      {{.Prompt}} {{.HelpName}} --remove --remove-older-than 1d s3/source s3/target
+
+  19. Mirror a bucket to another site with the metadata, tags and ACL of every object.
+      {{.Prompt}} {{.HelpName}} --preserve=metadata,tags,acl s3/source s3-dr/source
 `,
 }
 
@@ -268,6 +288,10 @@ type mirrorJob struct {
 	targetURL string
 
 	opts mirrorOptions
+
+	// Bounds concurrent verifications, and counts their outcome.
+	verifySem                         chan struct{}
+	verifiedObjects, verifyMismatches int64
 }
 
 // mirrorMessage container for file mirror messages
@@ -294,6 +318,47 @@ func (m mirrorMessage) JSON() string {
 	return string(mirrorMessageBytes)
 }
 
+// mirrorVerifyMessage summarizes the verification of copied objects.
+type mirrorVerifyMessage struct {
+	Status     string `json:"status"`
+	Verified   int64  `json:"verified"`
+	Mismatches int64  `json:"mismatches"`
+}
+
+// String colorized mirror verification summary
+func (m mirrorVerifyMessage) String() string {
+	return console.Colorize("Mirror", fmt.Sprintf("Verified %d object(s), %d checksum mismatch(es).", m.Verified, m.Mismatches))
+}
+
+// JSON jsonified mirror verification summary
+func (m mirrorVerifyMessage) JSON() string {
+	m.Status = "success"
+	if m.Mismatches > 0 {
+		m.Status = "error"
+	}
+	mirrorVerifyMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(mirrorVerifyMessageBytes)
+}
+
+// verifyMirrored checks that a copied object matches its source, see
+// verifyCopied. At most --verify-workers verifications run at once.
+func (mj *mirrorJob) verifyMirrored(ctx context.Context, sURLs URLs) *probe.Error {
+	select {
+	case mj.verifySem <- struct{}{}:
+	case <-ctx.Done():
+		return probe.NewError(ctx.Err())
+	}
+	defer func() { <-mj.verifySem }()
+
+	if err := verifyCopied(ctx, sURLs, mj.opts.encKeyDB); err != nil {
+		return err
+	}
+	atomic.AddInt64(&mj.verifiedObjects, 1)
+	return nil
+}
+
 func (mj *mirrorJob) doCreateBucket(ctx context.Context, sURLs URLs) URLs {
 	if mj.opts.isFake {
 		return sURLs.WithError(nil)
@@ -480,6 +545,12 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 	}
 	sURLs.MD5 = mj.opts.md5
 	sURLs.DisableMultipart = mj.opts.disableMultipart
+	if mj.opts.preserve.isSet() {
+		sURLs.PreserveACL = mj.opts.preserve.acl
+		sURLs.PreserveTags = mj.opts.preserve.tags
+		sURLs.skipFileAttrs = !mj.opts.preserve.timestamps
+		sURLs.skipXattrs = !mj.opts.preserve.metadata
+	}
 
 	var ret URLs
 
@@ -489,6 +560,9 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 		if ret.Error == nil {
 			durationMs := time.Since(now).Milliseconds()
 			mirrorReplicationDurations.With(prometheus.Labels{"object_size": convertSizeToTag(sURLs.SourceContent.Size)}).Observe(float64(durationMs))
+			if mj.opts.verify {
+				ret = ret.WithError(mj.verifyMirrored(ctx, sURLs))
+			}
 		}
 
 		return ret
@@ -508,6 +582,10 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 		if ret.Error == nil {
 			durationMs := time.Since(now).Milliseconds()
 			mirrorReplicationDurations.With(prometheus.Labels{"object_size": convertSizeToTag(sURLs.SourceContent.Size)}).Observe(float64(durationMs))
+			// A mismatching copy is retried like a failed one.
+			if mj.opts.verify {
+				ret = ret.WithError(mj.verifyMirrored(ctx, sURLs))
+			}
 		}
 
 		return ret.Error
@@ -538,6 +616,10 @@ func (mj *mirrorJob) monitorMirrorStatus(cancel context.CancelFunc) (errDuringMi
 		if sURLs.Error != nil {
 			var ignoreErr bool
 
+			if _, ok := sURLs.Error.ToGoError().(ChecksumMismatch); ok {
+				mj.verifyMismatches++
+			}
+
 			switch {
 			case sURLs.SourceContent != nil:
 				if isErrIgnored(sURLs.Error) {
@@ -859,6 +941,9 @@ func newMirrorJob(srcURL, dstURL string, opts mirrorOptions) *mirrorJob {
 	}
 
 	mj.parallel = newParallelManager(mj.statusCh)
+	if opts.verify {
+		mj.verifySem = make(chan struct{}, opts.verifyWorkers)
+	}
 
 	// we'll define the status to use here,
 	// do we want the quiet status? or the progressbar
@@ -948,17 +1033,27 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 
 	isWatch := cli.Bool("watch") || cli.Bool("multi-master") || cli.Bool("active-active")
 	isRemove := cli.Bool("remove")
+	removeBefore, err := parseRemoveOlderThan(cli.String("remove-older-than"), time.Now())
+	fatalIf(err, "Invalid --remove-older-than value.")
+
+	preserved, err := parsePreserveAttrs(cli.Generic("preserve").(*preserveFlag).String())
+	fatalIf(err, "Invalid --preserve value.")
+	if dstClt.GetURL().Type == fileSystem {
+		preserved = preservedOnFilesystem(preserved, dstURL)
+	}
 
 	// preserve is also expected to be overwritten if necessary
-	isMetadata := cli.Bool("a") || isWatch || len(userMetadata) > 0
-	isFake := cli.Bool("fake") || cli.Bool("dry-run")
+	isMetadata := preserved.metadata || preserved.timestamps || isWatch || len(userMetadata) > 0
+	isFake := cli.Bool("fake") || globalDryRun
 
 	mopts := mirrorOptions{
 		isFake:                isFake,
 		isRemove:              isRemove,
+		removeBefore:          removeBefore,
 		isOverwrite:           isOverwrite,
 		isWatch:               isWatch,
 		isMetadata:            isMetadata,
+		preserve:              preserved,
 		isSummary:             cli.Bool("summary"),
 		isRetriable:           cli.Bool("retry"),
 		md5:                   cli.Bool("md5"),
@@ -972,12 +1067,14 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 		userMetadata:          userMetadata,
 		encKeyDB:              encKeyDB,
 		activeActive:          isWatch,
+		verify:                cli.Bool("verify"),
+		verifyWorkers:         cli.Int("verify-workers"),
 	}
 
 	// Create a new mirror job and execute it
 	mj := newMirrorJob(srcURL, dstURL, mopts)
 
-	preserve := cli.Bool("preserve")
+	preserve := preserved.bucketConfig
 
 	createDstBuckets := dstClt.GetURL().Type == objectStorage && dstClt.GetURL().Path == string(dstClt.GetURL().Separator)
 	mirrorSrcBuckets := srcClt.GetURL().Type == objectStorage && srcClt.GetURL().Path == string(srcClt.GetURL().Separator)
@@ -996,7 +1093,13 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 
 			if d.Diff == differInSecond {
 				diffBucket := strings.TrimPrefix(d.SecondURL, dstClt.GetURL().String())
-				if !isFake && isRemove {
+				if !isFake && isRemove && !removeBefore.IsZero() {
+					// Objects of the bucket written after the guard
+					// time would be removed with it.
+					if !globalQuiet && !globalJSON {
+						console.Infof("Keeping extraneous bucket `%s` with --remove-older-than.\n", path.Join(dstURL, diffBucket))
+					}
+				} else if !isFake && isRemove {
 					aliasedDstBucket := path.Join(dstURL, diffBucket)
 					err := deleteBucket(ctx, aliasedDstBucket, false)
 					mj.status.fatalIf(err, "Failed to start mirroring.")
@@ -1072,7 +1175,14 @@ func runMirror(ctx context.Context, srcURL, dstURL string, cli *cli.Context, enc
 		}
 	}
 
-	return mj.mirror(ctx)
+	errorDetected := mj.mirror(ctx)
+	if mj.opts.verify && !mj.opts.isFake {
+		printMsg(mirrorVerifyMessage{
+			Verified:   atomic.LoadInt64(&mj.verifiedObjects),
+			Mismatches: mj.verifyMismatches,
+		})
+	}
+	return errorDetected || mj.verifyMismatches > 0
 }
 
 // Main entry point for mirror command.
@@ -0,0 +1,134 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Test mirror --verify verifies the copies of objects by their etags,
+// without reading them back.
+func TestMirrorVerify(t *testing.T) {
+	defer func(configDir string, quiet bool) {
+		mcCustomConfigDir, globalQuiet = configDir, quiet
+	}(mcCustomConfigDir, globalQuiet)
+	defer func(exiter func(int)) { cli.OsExiter = exiter }(cli.OsExiter)
+	var exitCode int
+	cli.OsExiter = func(code int) { exitCode = code }
+
+	handler := newMemS3Handler()
+	handler.objects["src/a"] = []byte("a")
+	handler.objects["src/dir/b"] = []byte("b")
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	configDir := filepath.Join(t.TempDir(), "config")
+	if e := registerApp("mc").Run([]string{"mc", "--config-dir", configDir, "--quiet", "mirror", "--verify", "--verify-workers", "1", "fake/src", "fake/dst"}); e != nil || exitCode != 0 {
+		t.Fatalf("unable to mirror, exit code %d: %v", exitCode, e)
+	}
+	for _, key := range []string{"dst/a", "dst/dir/b"} {
+		if _, ok := handler.objects[key]; !ok {
+			t.Errorf("expected %s to be mirrored", key)
+		}
+	}
+	for _, request := range handler.requests {
+		if strings.HasPrefix(request, "GET /dst/") && !strings.HasPrefix(request, "GET /dst/ ") {
+			t.Errorf("expected the copies not to be read back, got %s", request)
+		}
+	}
+}
+
+func TestParseRemoveOlderThan(t *testing.T) {
+	start := time.Date(2023, 5, 4, 8, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		value    string
+		expected time.Time
+		ok       bool
+	}{
+		{"", time.Time{}, true},
+		{"start", start, true},
+		{"1h", start.Add(-time.Hour), true},
+		{"2d", start.Add(-48 * time.Hour), true},
+		{"0s", time.Time{}, false},
+		{"-1h", time.Time{}, false},
+		{"soon", time.Time{}, false},
+	}
+	for _, tc := range testCases {
+		removeBefore, err := parseRemoveOlderThan(tc.value, start)
+		if (err == nil) != tc.ok {
+			t.Errorf("%q: expected success %v, got %v", tc.value, tc.ok, err)
+			continue
+		}
+		if !removeBefore.Equal(tc.expected) {
+			t.Errorf("%q: expected %s, got %s", tc.value, tc.expected, removeBefore)
+		}
+	}
+}
+
+// Test mirror --remove with --remove-older-than only removes the
+// extraneous objects of the target older than the time given.
+func TestMirrorRemoveOlderThan(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	root := t.TempDir()
+	src, dst := filepath.Join(root, "src"), filepath.Join(root, "dst")
+	removeBefore := time.Now().Add(-time.Hour)
+	files := map[string]time.Time{
+		filepath.Join(src, "a"):   removeBefore,
+		filepath.Join(dst, "a"):   removeBefore,
+		filepath.Join(dst, "old"): removeBefore.Add(-time.Minute),
+		filepath.Join(dst, "new"): removeBefore.Add(time.Minute),
+	}
+	for name, mtime := range files {
+		if e := os.MkdirAll(filepath.Dir(name), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(name, []byte("a"), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.Chtimes(name, mtime, mtime); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	URLsCh := make(chan URLs)
+	go deltaSourceTarget(context.Background(), src, dst, mirrorOptions{isRemove: true, removeBefore: removeBefore}, URLsCh)
+	var removed []string
+	for u := range URLsCh {
+		if u.Error != nil {
+			t.Fatal(u.Error)
+		}
+		if u.SourceContent == nil {
+			removed = append(removed, filepath.Base(u.TargetContent.URL.Path))
+		}
+	}
+	if len(removed) != 1 || removed[0] != "old" {
+		t.Fatalf("expected only the older extraneous object to be removed, got %v", removed)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+// Attributes of objects selected by mirror --preserve.
+const (
+	preserveMetadata   = "metadata"
+	preserveTags       = "tags"
+	preserveACL        = "acl"
+	preserveTimestamps = "timestamps"
+	preserveAll        = "all"
+)
+
+// preserveAttrs selects the attributes mirror copies along with the
+// content of objects.
+type preserveAttrs struct {
+	// metadata is the user metadata and extended attributes of files.
+	metadata bool
+	tags     bool
+	acl      bool
+	// timestamps is the times, mode and owner of files.
+	timestamps bool
+	// bucketConfig is the policy and object lock configuration of
+	// mirrored buckets.
+	bucketConfig bool
+}
+
+// isSet returns true if any attribute is preserved.
+func (p preserveAttrs) isSet() bool {
+	return p.metadata || p.tags || p.acl || p.timestamps || p.bucketConfig
+}
+
+// parsePreserveAttrs parses a --preserve value, "true" for the flag
+// without value preserving the metadata, timestamps and bucket
+// configuration, "all" or a comma separated list of attributes.
+func parsePreserveAttrs(value string) (preserveAttrs, *probe.Error) {
+	if value == "" {
+		return preserveAttrs{}, nil
+	}
+	if b, e := strconv.ParseBool(value); e == nil {
+		return preserveAttrs{metadata: b, timestamps: b, bucketConfig: b}, nil
+	}
+	var p preserveAttrs
+	for _, attr := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(attr)) {
+		case preserveMetadata:
+			p.metadata = true
+		case preserveTags:
+			p.tags = true
+		case preserveACL:
+			p.acl = true
+		case preserveTimestamps:
+			p.timestamps = true
+		case preserveAll:
+			p = preserveAttrs{metadata: true, tags: true, acl: true, timestamps: true, bucketConfig: true}
+		default:
+			return preserveAttrs{}, errInvalidArgument().Trace(attr)
+		}
+	}
+	return p, nil
+}
+
+// preserveFlag is the value of mirror --preserve, a boolean flag which
+// may also be given attributes, e.g. --preserve=metadata,tags.
+type preserveFlag struct {
+	value string
+}
+
+// Set keeps the value, parsed once flags are validated as the flag
+// package reports invalid values of boolean flags as booleans.
+func (f *preserveFlag) Set(value string) error {
+	f.value = value
+	return nil
+}
+
+func (f *preserveFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// IsBoolFlag allows --preserve without value.
+func (f *preserveFlag) IsBoolFlag() bool {
+	return true
+}
+
+// unpreservedAttrs holds the attributes already warned about, a mirror
+// with --watch starts again after errors.
+var unpreservedAttrs sync.Map
+
+// preservedOnFilesystem drops the attributes a filesystem target cannot
+// hold, with a single warning for each.
+func preservedOnFilesystem(p preserveAttrs, targetURL string) preserveAttrs {
+	for _, attr := range []struct {
+		name string
+		set  *bool
+	}{
+		{preserveMetadata, &p.metadata},
+		{preserveTags, &p.tags},
+		{preserveACL, &p.acl},
+	} {
+		if !*attr.set {
+			continue
+		}
+		*attr.set = false
+		if _, loaded := unpreservedAttrs.LoadOrStore(attr.name, struct{}{}); !loaded && !globalQuiet && !globalJSON {
+			console.Infof("[Warn] Filesystem target `%s` cannot hold object %s, they are not preserved.\n", targetURL, attr.name)
+		}
+	}
+	return p
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/wildcard"
 )
 
@@ -56,14 +57,29 @@ func checkMirrorSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[st
 	_, expandedTargetPath, _ := mustExpandAlias(tgtURL)
 	destClient := newClientURL(expandedTargetPath)
 
+	preserved, err := parsePreserveAttrs(cliCtx.Generic("preserve").(*preserveFlag).String())
+	fatalIf(err, "Invalid --preserve value, choose one or more of [metadata, tags, acl, timestamps] or all.")
+
 	// Mirror with preserve option on windows
 	// only works for object storage to object storage
-	if runtime.GOOS == "windows" && cliCtx.Bool("a") {
+	if runtime.GOOS == "windows" && preserved.isSet() {
 		if srcClient.Type == fileSystem || destClient.Type == fileSystem {
 			errorIf(errInvalidArgument(), "Preserve functionality on windows support object storage to object storage transfer only.")
 		}
 	}
 
+	if cliCtx.String("remove-older-than") != "" {
+		if !cliCtx.Bool("remove") {
+			fatalIf(errInvalidArgument().Trace(), "--remove-older-than requires --remove.")
+		}
+		_, err := parseRemoveOlderThan(cliCtx.String("remove-older-than"), time.Now())
+		fatalIf(err, "Invalid --remove-older-than value.")
+	}
+
+	if cliCtx.IsSet("verify-workers") && cliCtx.Int("verify-workers") <= 0 {
+		fatalIf(errInvalidArgument().Trace(), "--verify-workers must be a positive number.")
+	}
+
 	/****** Generic rules *******/
 	if !cliCtx.Bool("watch") && !cliCtx.Bool("active-active") && !cliCtx.Bool("multi-master") {
 		_, srcContent, err := url2Stat(ctx, srcURL, "", false, encKeyDB, time.Time{}, false)
@@ -89,6 +105,26 @@ func checkMirrorSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[st
 	return
 }
 
+// parseRemoveOlderThan - returns the time before which extraneous
+// objects are removed with --remove-older-than, either a duration
+// before start or start itself. An empty value returns a zero time.
+func parseRemoveOlderThan(value string, start time.Time) (time.Time, *probe.Error) {
+	switch value {
+	case "":
+		return time.Time{}, nil
+	case "start":
+		return start, nil
+	}
+	olderThan, e := ParseDuration(value)
+	if e != nil {
+		return time.Time{}, probe.NewError(e).Trace(value)
+	}
+	if olderThan <= 0 {
+		return time.Time{}, probe.NewError(fmt.Errorf("duration must be positive, found %s", value))
+	}
+	return start.Add(-time.Duration(olderThan)), nil
+}
+
 func matchExcludeOptions(excludeOptions []string, srcSuffix string) bool {
 	for _, pattern := range excludeOptions {
 		if wildcard.Match(pattern, srcSuffix) {
@@ -137,7 +173,7 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 	}
 
 	// List both source and target, compare and return values through channel.
-	for diffMsg := range objectDifference(ctx, sourceClnt, targetClnt, opts.isMetadata) {
+	for diffMsg := range objectDifference(ctx, sourceClnt, targetClnt, opts.isMetadata, false) {
 		if diffMsg.Error != nil {
 			// Send all errors through the channel
 			URLsCh <- URLs{Error: diffMsg.Error, ErrorCond: differInUnknown}
@@ -184,7 +220,7 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 				continue
 			}
 
-			sourceSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
+			sourceSuffix := sourceKeySuffix(diffMsg.FirstURL, sourceURL)
 			// Either available only in source or size differs and force is set
 			targetPath := urlJoinPath(targetURL, sourceSuffix)
 			sourceContent := diffMsg.firstContent
@@ -197,7 +233,7 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 			}
 		case differInFirst:
 			// Only in first, always copy.
-			sourceSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
+			sourceSuffix := sourceKeySuffix(diffMsg.FirstURL, sourceURL)
 			targetPath := urlJoinPath(targetURL, sourceSuffix)
 			sourceContent := diffMsg.firstContent
 			targetContent := &ClientContent{URL: *newClientURL(targetPath)}
@@ -211,6 +247,11 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 			if !opts.isRemove && !opts.isFake {
 				continue
 			}
+			// Keep objects written to the target after the time
+			// of --remove-older-than.
+			if !opts.removeBefore.IsZero() && !diffMsg.secondContent.Time.Before(opts.removeBefore) {
+				continue
+			}
 			URLsCh <- URLs{
 				TargetAlias:   targetAlias,
 				TargetContent: diffMsg.secondContent,
@@ -227,6 +268,8 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 type mirrorOptions struct {
 	isFake, isOverwrite, activeActive     bool
 	isWatch, isRemove, isMetadata         bool
+	preserve                              preserveAttrs
+	removeBefore                          time.Time
 	isRetriable                           bool
 	isSummary                             bool
 	skipErrors                            bool
@@ -236,6 +279,8 @@ type mirrorOptions struct {
 	olderThan, newerThan                  string
 	storageClass                          string
 	userMetadata                          map[string]string
+	verify                                bool
+	verifyWorkers                         int
 }
 
 // Prepares urls that need to be copied or removed based on requested options.
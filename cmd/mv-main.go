@@ -254,8 +254,9 @@ func mainMove(cliCtx *cli.Context) error {
 
 	var session *sessionV8
 
-	if cliCtx.Bool("continue") {
-		sessionID := getHash("mv", cliCtx.Args())
+	args := cliCtx.Args()
+	sessionID := getHash("mv", cliCtx.Args())
+	if !globalDryRun && (cliCtx.Bool("continue") || isResumeNeeded(ctx, sessionID, args[:len(args)-1], args[len(args)-1], recursive)) {
 		if isSessionExists(sessionID) {
 			session, err = loadSessionV8(sessionID)
 			fatalIf(err.Trace(sessionID), "Unable to load session.")
@@ -268,7 +269,7 @@ func mainMove(cliCtx *cli.Context) error {
 			session.Header.CommandStringFlags["storage-class"] = storageClass
 			session.Header.CommandStringFlags["encrypt-key"] = sseKeys
 			session.Header.CommandStringFlags["encrypt"] = sse
-			session.Header.CommandBoolFlags["session"] = cliCtx.Bool("continue")
+			session.Header.CommandBoolFlags["session"] = true
 
 			if cliCtx.Bool("preserve") {
 				session.Header.CommandBoolFlags["preserve"] = cliCtx.Bool("preserve")
@@ -287,7 +288,7 @@ func mainMove(cliCtx *cli.Context) error {
 		}
 	}
 
-	e := doCopySession(ctx, cancelMove, cliCtx, session, encKeyDB, true)
+	e := doCopySession(ctx, cancelMove, cliCtx, session, encKeyDB, true, cliCtx.Args()[len(cliCtx.Args())-1])
 	if session != nil {
 		session.Delete()
 	}
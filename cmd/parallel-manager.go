@@ -76,6 +76,9 @@ type ParallelManager struct {
 
 	// The maximum memory to use
 	maxMem uint64
+
+	// Reduces the tasks running at once on SlowDown responses
+	throttle *adaptiveThrottle
 }
 
 // addWorker creates a new worker to process tasks
@@ -102,7 +105,7 @@ func (p *ParallelManager) addWorker() {
 			}
 
 			// Execute the task and send the result to channel.
-			p.resultCh <- t.fn()
+			p.resultCh <- p.throttle.run(t.fn, int(atomic.LoadUint32(&p.workersNum)))
 
 			if t.barrier {
 				p.barrierSync.Unlock()
@@ -274,6 +277,7 @@ func newParallelManager(resultCh chan URLs) *ParallelManager {
 		queueCh:       make(chan task),
 		resultCh:      resultCh,
 		maxMem:        availableMemory(),
+		throttle:      newAdaptiveThrottle(),
 	}
 
 	// Start with runtime.NumCPU().
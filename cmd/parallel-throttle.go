@@ -0,0 +1,173 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/v2/console"
+)
+
+const (
+	// Number of times a task failing with SlowDown is run again.
+	slowDownRetries = 3
+
+	// Wait before the first retry of a task failing with SlowDown,
+	// doubled on every further retry.
+	slowDownBackoff = time.Second
+)
+
+// isSlowDown returns true if err asks the client to reduce its request rate.
+func isSlowDown(err *probe.Error) bool {
+	if err == nil {
+		return false
+	}
+	var errResp minio.ErrorResponse
+	if !errors.As(err.ToGoError(), &errResp) {
+		return false
+	}
+	switch errResp.Code {
+	case "SlowDown", "SlowDownRead", "SlowDownWrite":
+		return true
+	}
+	return errResp.StatusCode == http.StatusServiceUnavailable
+}
+
+// slowDownWait returns how long to wait before the retry number attempt
+// of a task, with up to 50% of jitter to spread the retries of workers.
+func slowDownWait(attempt int) time.Duration {
+	wait := slowDownBackoff << (attempt - 1)
+	return wait + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// adaptiveThrottle limits the number of tasks of a ParallelManager running
+// at once, as an AIMD controller: every SlowDown halves the limit, every
+// limit tasks succeeding in a row add one to it, until the limit reaches
+// the number of workers and the throttling stops.
+type adaptiveThrottle struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// Maximum number of running tasks, 0 when not throttled.
+	limit   int
+	running int
+	// Tasks succeeding since the last change of limit.
+	healthy int
+}
+
+func newAdaptiveThrottle() *adaptiveThrottle {
+	t := &adaptiveThrottle{}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// acquire waits until a task may run.
+func (t *adaptiveThrottle) acquire() {
+	t.mu.Lock()
+	for t.limit > 0 && t.running >= t.limit {
+		t.cond.Wait()
+	}
+	t.running++
+	t.mu.Unlock()
+}
+
+// release records the end of a task.
+func (t *adaptiveThrottle) release() {
+	t.mu.Lock()
+	t.running--
+	t.mu.Unlock()
+	t.cond.Signal()
+}
+
+// slowDown halves the number of tasks running at once.
+func (t *adaptiveThrottle) slowDown() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := t.limit
+	if current == 0 {
+		current = t.running
+	}
+	limit := current / 2
+	if limit < 1 {
+		limit = 1
+	}
+	if limit == t.limit {
+		return
+	}
+	t.limit = limit
+	t.healthy = 0
+	if !globalQuiet && !globalJSON {
+		console.Infof("Server asked to slow down, running at most %d transfer(s) at once.\n", limit)
+	}
+}
+
+// succeeded adds one to the number of tasks running at once after limit
+// tasks succeed in a row, workers being the count past which it stops
+// throttling.
+func (t *adaptiveThrottle) succeeded(workers int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.limit == 0 {
+		return
+	}
+	t.healthy++
+	if t.healthy < t.limit {
+		return
+	}
+	t.healthy = 0
+	t.limit++
+	if t.limit >= workers {
+		t.limit = 0
+		if !globalQuiet && !globalJSON {
+			console.Infof("Server recovered, running transfers at full concurrency.\n")
+		}
+	} else if globalDebug {
+		console.Debugln("Running at most", t.limit, "transfer(s) at once.")
+	}
+	t.cond.Broadcast()
+}
+
+// run runs fn, running it again after a backoff while it fails with SlowDown.
+func (t *adaptiveThrottle) run(fn func() URLs, workers int) URLs {
+	t.acquire()
+	result := fn()
+	for attempt := 1; isSlowDown(result.Error) && attempt <= slowDownRetries; attempt++ {
+		t.slowDown()
+		t.release()
+		select {
+		case <-globalContext.Done():
+			return result
+		case <-time.After(slowDownWait(attempt)):
+		}
+		t.acquire()
+		result = fn()
+	}
+	t.release()
+	if result.Error == nil {
+		t.succeeded(workers)
+	}
+	return result
+}
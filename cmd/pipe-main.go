@@ -57,6 +57,10 @@ var pipeFlags = []cli.Flag{
 		Value: defaultPartSize(),
 		Usage: "customize chunk size for each concurrent upload",
 	},
+	cli.StringFlag{
+		Name:  "trailer-checksum",
+		Usage: "send a checksum of each part computed while streaming in the request trailers, one of [crc32c, crc32, sha1, sha256]",
+	},
 	cli.IntFlag{
 		Name:   "pipe-max-size",
 		Usage:  "increase the pipe buffer size to a custom value",
@@ -106,6 +110,9 @@ EXAMPLES:
 
   7. Set tags to the uploaded objects
       {{.Prompt}} tar cvf - . | {{.HelpName}} --tags "category=prod&type=backup" play/mybucket/backup.tar
+
+  8. Stream a database dump having the server verify a CRC32C checksum of each part, which a Content-MD5 cannot cover for stdin.
+      {{.Prompt}} pg_dump mydb | {{.HelpName}} --trailer-checksum crc32c play/mybucket/mydb.sql
 `,
 }
 
@@ -139,6 +146,11 @@ func pipe(ctx *cli.Context, targetURL string, encKeyDB map[string][]prefixSSEPai
 		}
 	}
 
+	trailerChecksum, err := parseTrailerChecksum(ctx.String("trailer-checksum"))
+	if err != nil {
+		return err.Trace(ctx.String("trailer-checksum"))
+	}
+
 	// Stream from stdin to multiple objects until EOF.
 	// Ignore size, since os.Stat() would not return proper size all the time
 	// for local filesystem for example /proc files.
@@ -149,6 +161,7 @@ func pipe(ctx *cli.Context, targetURL string, encKeyDB map[string][]prefixSSEPai
 		multipartSize:    multipartSize,
 		multipartThreads: uint(multipartThreads),
 		concurrentStream: ctx.IsSet("concurrent"),
+		trailerChecksum:  trailerChecksum,
 	}
 
 	var reader io.Reader
@@ -159,7 +172,7 @@ func pipe(ctx *cli.Context, targetURL string, encKeyDB map[string][]prefixSSEPai
 		reader = os.Stdin
 	}
 
-	_, err := putTargetStreamWithURL(targetURL, reader, -1, opts)
+	_, err = putTargetStreamWithURL(targetURL, reader, -1, opts)
 	// TODO: See if this check is necessary.
 	switch e := err.ToGoError().(type) {
 	case *os.PathError:
@@ -20,8 +20,14 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/v2/console"
 )
 
@@ -31,21 +37,244 @@ type message interface {
 	String() string
 }
 
+// jsonHoster is implemented by messages which know the alias they were
+// produced from, ok is false to fall back to the alias of the command.
+type jsonHoster interface {
+	jsonHost() (host string, ok bool)
+}
+
+// jsonHostArgs are the arguments of the running command, their alias
+// is the host of the JSON messages not implementing jsonHoster.
+var (
+	jsonHostArgs []string
+	jsonHostOnce sync.Once
+	jsonHostName string
+)
+
+// commandJSONHost returns the alias shared by the arguments of the
+// running command, empty if none or several aliases are used.
+func commandJSONHost() string {
+	jsonHostOnce.Do(func() {
+		for _, arg := range jsonHostArgs {
+			alias, _, aliasCfg, err := expandAlias(arg)
+			if err != nil || aliasCfg == nil {
+				continue
+			}
+			if jsonHostName != "" && jsonHostName != alias {
+				jsonHostName = ""
+				return
+			}
+			jsonHostName = alias
+		}
+	})
+	return jsonHostName
+}
+
+// withJSONHost adds the alias msg was produced from as the host field of
+// its JSON object, msgStr.
+func withJSONHost(msg message, msgStr string) string {
+	host := commandJSONHost()
+	if h, ok := msg.(jsonHoster); ok {
+		if msgHost, ok := h.jsonHost(); ok {
+			host = msgHost
+		}
+	}
+	end := strings.LastIndexByte(msgStr, '}')
+	if host == "" || end < 0 || !strings.HasPrefix(strings.TrimSpace(msgStr), "{") {
+		return msgStr
+	}
+	hostJSON, _ := json.Marshal(host)
+	head := strings.TrimRight(msgStr[:end], " \t\n")
+	if !strings.HasSuffix(head, "{") {
+		head += ","
+	}
+	if strings.Contains(msgStr, "\n") {
+		head += "\n "
+	}
+	return head + `"host":` + string(hostJSON) + msgStr[end:]
+}
+
 // printMsg prints message string or JSON structure depending on the type of output console.
 func printMsg(msg message) {
+	if globalLogSink != nil {
+		sendToLogSink(msg)
+		if globalLogSinkOnly {
+			return
+		}
+	}
+	if globalJSONArray && globalJSON {
+		printJSONArrayElement(formatMsg(msg))
+		return
+	}
+	console.Println(formatMsg(msg))
+}
+
+// jsonArray tracks the array printed with --json=array, opened by its
+// first element and closed once on exit.
+var jsonArray struct {
+	sync.Mutex
+	opened, closed bool
+}
+
+// printJSONArrayElement prints a JSON record as the next element of the
+// --json=array output. Elements are written as they come, so the array
+// is streamed rather than buffered.
+func printJSONArrayElement(record string) {
+	jsonArray.Lock()
+	defer jsonArray.Unlock()
+	if jsonArray.closed {
+		return
+	}
+	separator := ",\n"
+	if !jsonArray.opened {
+		separator = "[\n"
+		jsonArray.opened = true
+	}
+	console.Print(separator + record)
+}
+
+// closeJSONArray terminates the --json=array output, printing an empty
+// array if no element was printed.
+func closeJSONArray() {
+	jsonArray.Lock()
+	defer jsonArray.Unlock()
+	if jsonArray.closed {
+		return
+	}
+	jsonArray.closed = true
+	if !jsonArray.opened {
+		console.Println("[]")
+		return
+	}
+	console.Println("\n]")
+}
+
+// printErrMsg is like printMsg but writes to stderr, keeping
+// diagnostic output apart from the regular command output.
+func printErrMsg(msg message) {
+	console.Lock()
+	defer console.Unlock()
+	fmt.Fprintln(os.Stderr, formatMsg(msg))
+}
+
+// formatMsg returns the message string or JSON structure depending on the type of output console.
+func formatMsg(msg message) string {
 	var msgStr string
 	if !globalJSON {
 		msgStr = msg.String()
 	} else {
-		msgStr = msg.JSON()
-		if globalJSONLine && strings.ContainsRune(msgStr, '\n') {
-			// Reformat.
-			var dst bytes.Buffer
-			if err := json.Compact(&dst, []byte(msgStr)); err == nil {
-				msgStr = dst.String()
+		msgStr = withJSONHost(msg, msg.JSON())
+		if globalStrictJSON {
+			fatalIf(validateJSONRecord(msg, msgStr), "Unable to print a JSON record with --strict.")
+		}
+		if globalJSONLine {
+			msgStr = compactJSON(msgStr)
+		}
+	}
+	return strings.TrimSuffix(msgStr, "\n")
+}
+
+// validateJSONRecord checks that record, the JSON of msg, is a JSON
+// object or array representing msg exactly. Encoders replace the bytes
+// of strings which are not valid UTF-8 silently, so they are looked for
+// in msg itself.
+func validateJSONRecord(msg message, record string) *probe.Error {
+	trimmed := strings.TrimSpace(record)
+	if !json.Valid([]byte(trimmed)) {
+		return probe.NewError(fmt.Errorf("invalid JSON record %q", trimmed))
+	}
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return probe.NewError(fmt.Errorf("JSON record %q is not an object or an array", trimmed))
+	}
+	if field, value, ok := findInvalidUTF8(reflect.ValueOf(msg), "", 0); ok {
+		return probe.NewError(fmt.Errorf("field `%s` is not valid UTF-8: %q", field, value))
+	}
+	return nil
+}
+
+// maxStrictDepth bounds the nesting of values walked by findInvalidUTF8.
+const maxStrictDepth = 32
+
+// findInvalidUTF8 returns the path and the value of the first string of v
+// which is not valid UTF-8, walking the exported fields as named in JSON.
+func findInvalidUTF8(v reflect.Value, path string, depth int) (string, string, bool) {
+	if depth > maxStrictDepth {
+		return "", "", false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if !utf8.ValidString(v.String()) {
+			return path, v.String(), true
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return findInvalidUTF8(v.Elem(), path, depth+1)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if field.Anonymous && field.Tag.Get("json") == "" {
+				name = ""
+			}
+			if p, s, ok := findInvalidUTF8(v.Field(i), joinJSONPath(path, name), depth+1); ok {
+				return p, s, true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if !utf8.ValidString(key) {
+				return joinJSONPath(path, key), key, true
+			}
+			if p, s, ok := findInvalidUTF8(iter.Value(), joinJSONPath(path, key), depth+1); ok {
+				return p, s, true
 			}
 		}
+	case reflect.Slice, reflect.Array:
+		// Byte slices are encoded in base64.
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			if p, s, ok := findInvalidUTF8(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1); ok {
+				return p, s, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// joinJSONPath appends the field name to a dotted path.
+func joinJSONPath(path, name string) string {
+	switch {
+	case name == "":
+		return path
+	case path == "":
+		return name
+	}
+	return path + "." + name
+}
+
+// compactJSON removes insignificant whitespace from a JSON record, so
+// that JSON lines are byte for byte identical for identical messages
+// whichever way their JSON() method indents them. Messages are encoded
+// with their fields in declaration order and map keys sorted.
+func compactJSON(msgStr string) string {
+	var dst bytes.Buffer
+	if err := json.Compact(&dst, []byte(msgStr)); err != nil {
+		return msgStr
 	}
-	msgStr = strings.TrimSuffix(msgStr, "\n")
-	console.Println(msgStr)
+	return dst.String()
 }
@@ -299,6 +299,9 @@ func mainRemoveBucket(cliCtx *cli.Context) error {
 		}
 
 		for _, bucketURL := range bucketsURL {
+			if skipDryRun(dryRunMessage{Action: "remove bucket", Target: bucketURL}) {
+				continue
+			}
 			e := deleteBucket(ctx, bucketURL, isForce)
 			fatalIf(e.Trace(bucketURL), "Failed to remove `"+bucketURL+"`.")
 
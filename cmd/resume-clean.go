@@ -0,0 +1,144 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+var resumeCleanFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "older-than",
+		Usage: "remove the sessions started before this duration ago (e.g. 7d10h31s)",
+		Value: "7d",
+	},
+	cli.BoolFlag{
+		Name:  "all",
+		Usage: "remove all sessions, whatever their age",
+	},
+}
+
+var resumeCleanCmd = cli.Command{
+	Name:         "clean",
+	Usage:        "remove the stale sessions of interrupted copies",
+	Action:       mainResumeClean,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(resumeCleanFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Sessions are stale when they are older than --older-than or cannot be read. The incomplete
+  uploads of removed sessions can then be aborted with "mc resume gc".
+
+EXAMPLES:
+  1. Remove the sessions of copies interrupted more than a week ago.
+     {{.Prompt}} {{.HelpName}}
+
+  2. Show the sessions older than a day without removing them.
+     {{.Prompt}} {{.HelpName}} --older-than 1d --dry-run
+`,
+}
+
+// resumeCleanMessage container for a stale session.
+type resumeCleanMessage struct {
+	Status    string `json:"status"`
+	SessionID string `json:"sessionId"`
+	Age       int64  `json:"age"`
+	Reason    string `json:"reason"`
+	Removed   bool   `json:"removed"`
+}
+
+// String colorized stale session.
+func (r resumeCleanMessage) String() string {
+	if !r.Removed {
+		return console.Colorize("Stale", fmt.Sprintf("Stale session `%s`, %s.", r.SessionID, r.Reason))
+	}
+	return console.Colorize("Removed", fmt.Sprintf("Removed stale session `%s`, %s.", r.SessionID, r.Reason))
+}
+
+// JSON jsonified stale session, age in seconds.
+func (r resumeCleanMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// staleSessionReason returns why a session is stale, an empty string
+// if it is not.
+func staleSessionReason(s resumeSession, olderThan time.Duration, all bool) string {
+	switch {
+	case s.header == nil:
+		return "unreadable"
+	case all:
+		return "started " + timeDurationToHumanizedDuration(s.age()).StringShort() + " ago"
+	case s.age() > olderThan:
+		return "older than " + timeDurationToHumanizedDuration(olderThan).StringShort()
+	}
+	return ""
+}
+
+// mainResumeClean is the handle for "mc resume clean" command.
+func mainResumeClean(cliCtx *cli.Context) error {
+	if cliCtx.Args().Present() {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code
+	}
+	olderThan, e := ParseDuration(cliCtx.String("older-than"))
+	fatalIf(probe.NewError(e).Trace(cliCtx.String("older-than")), "Unable to parse --older-than.")
+
+	console.SetColor("Stale", color.New(color.FgYellow))
+	console.SetColor("Removed", color.New(color.FgGreen, color.Bold))
+
+	var cErr error
+	for _, s := range loadResumeSessions() {
+		reason := staleSessionReason(s, time.Duration(olderThan), cliCtx.Bool("all"))
+		if reason == "" {
+			continue
+		}
+		msg := resumeCleanMessage{
+			SessionID: s.id,
+			Age:       int64(s.age().Seconds()),
+			Reason:    reason,
+		}
+		if !globalDryRun {
+			if err := removeSessionFiles(s.id); err != nil {
+				errorIf(err.Trace(s.id), "Unable to remove session `"+s.id+"`.")
+				cErr = exitStatus(globalErrorExitStatus)
+				continue
+			}
+			msg.Removed = true
+		}
+		printMsg(msg)
+	}
+	return cErr
+}
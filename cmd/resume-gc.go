@@ -0,0 +1,280 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+var resumeGCFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "older-than",
+		Usage: "abort only the uploads started before this duration ago, younger ones may still be running (e.g. 7d10h31s)",
+		Value: "24h",
+	},
+	cli.BoolFlag{
+		Name:  "sessions",
+		Usage: "remove the sessions started before --older-than and abort the incomplete uploads they started",
+	},
+}
+
+var resumeGCCmd = cli.Command{
+	Name:         "gc",
+	Usage:        "abort the incomplete uploads left by copies which cannot be resumed",
+	Action:       mainResumeGC,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(resumeGCFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET [TARGET...]
+  {{.HelpName}} [FLAGS] --sessions [TARGET...]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Incomplete uploads under TARGET are orphaned, and aborted, when no saved session copies to them.
+  Uploads of the copies listed by "mc resume list" are kept to be resumed.
+
+  With --sessions the sessions older than --older-than are removed first, and the incomplete uploads
+  they recorded are aborted, those started and not finished while they ran.
+
+EXAMPLES:
+  1. Show the orphaned incomplete uploads of mybucket without aborting them.
+     {{.Prompt}} {{.HelpName}} --dry-run s3/mybucket
+
+  2. Abort the orphaned incomplete uploads of mybucket started more than a week ago.
+     {{.Prompt}} {{.HelpName}} --older-than 7d s3/mybucket
+
+  3. Show the sessions older than a week and the incomplete uploads they left, without removing them.
+     {{.Prompt}} {{.HelpName}} --sessions --older-than 7d --dry-run
+`,
+}
+
+// resumeGCMessage container for an orphaned incomplete upload.
+type resumeGCMessage struct {
+	Status    string    `json:"status"`
+	SessionID string    `json:"sessionId,omitempty"`
+	Key       string    `json:"key"`
+	UploadID  string    `json:"uploadId"`
+	Initiated time.Time `json:"initiated"`
+	Age       int64     `json:"age"`
+	Aborted   bool      `json:"aborted"`
+}
+
+// String colorized orphaned upload.
+func (r resumeGCMessage) String() string {
+	age := timeDurationToHumanizedDuration(time.Duration(r.Age) * time.Second).StringShort()
+	if !r.Aborted {
+		return console.Colorize("Orphaned", fmt.Sprintf("Orphaned upload `%s` started %s ago.", r.Key, age))
+	}
+	return console.Colorize("Aborted", fmt.Sprintf("Aborted orphaned upload `%s` started %s ago.", r.Key, age))
+}
+
+// JSON jsonified orphaned upload, age in seconds.
+func (r resumeGCMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// checkResumeGCSyntax - validate all the passed arguments
+func checkResumeGCSyntax(cliCtx *cli.Context) {
+	if !cliCtx.Args().Present() && !cliCtx.Bool("sessions") {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code
+	}
+	if _, e := ParseDuration(cliCtx.String("older-than")); e != nil {
+		fatalIf(probe.NewError(e).Trace(cliCtx.String("older-than")), "Unable to parse --older-than.")
+	}
+}
+
+// mainResumeGC is the handle for "mc resume gc" command.
+func mainResumeGC(cliCtx *cli.Context) error {
+	ctx, cancelResumeGC := context.WithCancel(globalContext)
+	defer cancelResumeGC()
+
+	checkResumeGCSyntax(cliCtx)
+
+	console.SetColor("Orphaned", color.New(color.FgYellow))
+	console.SetColor("Aborted", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Stale", color.New(color.FgYellow))
+	console.SetColor("Removed", color.New(color.FgGreen, color.Bold))
+
+	olderThan := cliCtx.String("older-than")
+	isDryRun := globalDryRun
+
+	var cErr error
+	if cliCtx.Bool("sessions") {
+		cErr = gcSessions(ctx, olderThan, isDryRun)
+	}
+
+	sessions := loadResumeSessions()
+	for _, targetURL := range cliCtx.Args() {
+		targetAlias, _, _ := mustExpandAlias(targetURL)
+		clnt, err := newClient(targetURL)
+		fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+
+		var orphans []*ClientContent
+		for content := range clnt.List(ctx, ListOptions{Recursive: true, Incomplete: true, ShowDir: DirNone}) {
+			if content.Err != nil {
+				errorIf(content.Err.Trace(targetURL), "Unable to list incomplete uploads.")
+				cErr = exitStatus(globalErrorExitStatus)
+				continue
+			}
+			if isOlder(content.Time, olderThan) || isSessionTarget(sessions, content.URL.String()) {
+				continue
+			}
+			orphans = append(orphans, content)
+		}
+
+		if err := abortUploads(ctx, clnt, targetAlias, "", orphans, isDryRun); err != nil {
+			cErr = err
+		}
+	}
+	return cErr
+}
+
+// gcSessions removes the sessions started before olderThan and aborts
+// the incomplete uploads they recorded. A session is kept when its
+// uploads cannot all be aborted, to be collected again.
+func gcSessions(ctx context.Context, olderThan string, isDryRun bool) (cErr error) {
+	maxAge, e := ParseDuration(olderThan)
+	fatalIf(probe.NewError(e).Trace(olderThan), "Unable to parse --older-than.")
+
+	var stale []resumeSession
+	for _, s := range loadResumeSessions() {
+		if staleSessionReason(s, time.Duration(maxAge), false) != "" {
+			stale = append(stale, s)
+		}
+	}
+
+	for _, s := range stale {
+		if s.target() != "" {
+			if err := gcSessionUploads(ctx, s, isDryRun); err != nil {
+				errorIf(err.Trace(s.id), "Unable to abort the incomplete uploads of session `"+s.id+"`.")
+				cErr = exitStatus(globalErrorExitStatus)
+				continue
+			}
+		}
+		msg := resumeCleanMessage{
+			SessionID: s.id,
+			Age:       int64(s.age().Seconds()),
+			Reason:    staleSessionReason(s, time.Duration(maxAge), false),
+		}
+		if !isDryRun {
+			if err := removeSessionFiles(s.id); err != nil {
+				errorIf(err.Trace(s.id), "Unable to remove session `"+s.id+"`.")
+				cErr = exitStatus(globalErrorExitStatus)
+				continue
+			}
+			msg.Removed = true
+		}
+		printMsg(msg)
+	}
+	return cErr
+}
+
+// gcSessionUploads aborts the incomplete uploads recorded by s which
+// are still listed under its target, the others were finished since.
+func gcSessionUploads(ctx context.Context, s resumeSession, isDryRun bool) *probe.Error {
+	if len(s.header.Uploads) == 0 {
+		return nil
+	}
+	targetURL := s.target()
+	targetAlias, _, _ := mustExpandAlias(targetURL)
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if clnt.GetURL().Type != objectStorage {
+		return nil
+	}
+
+	var uploads []*ClientContent
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, Incomplete: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			// Nothing is left to abort in a removed bucket.
+			if _, ok := content.Err.ToGoError().(BucketDoesNotExist); ok {
+				return nil
+			}
+			return content.Err.Trace(targetURL)
+		}
+		if _, ok := s.header.Uploads[content.UploadID]; !ok {
+			continue
+		}
+		uploads = append(uploads, content)
+	}
+	if abortUploads(ctx, clnt, targetAlias, s.id, uploads, isDryRun) != nil {
+		return probe.NewError(UploadNotAborted{URL: targetURL}).Trace(targetURL)
+	}
+	return nil
+}
+
+// abortUploads aborts the incomplete uploads of clnt, or only shows them
+// with isDryRun, returning an error status if any cannot be aborted.
+func abortUploads(ctx context.Context, clnt Client, targetAlias, sessionID string, uploads []*ClientContent, isDryRun bool) (cErr error) {
+	if isDryRun {
+		for _, content := range uploads {
+			printMsg(resumeGCMessage{
+				SessionID: sessionID,
+				Key:       path.Join(targetAlias, content.URL.Path),
+				UploadID:  content.UploadID,
+				Initiated: content.Time,
+				Age:       int64(time.Since(content.Time).Seconds()),
+			})
+		}
+		return nil
+	}
+
+	initiated := make(map[string]time.Time, len(uploads))
+	uploadCh := make(chan *ClientContent, len(uploads))
+	for _, content := range uploads {
+		initiated[content.UploadID] = content.Time
+		uploadCh <- content
+	}
+	close(uploadCh)
+	for result := range clnt.Remove(ctx, true, false, false, false, uploadCh) {
+		if result.Err != nil {
+			errorIf(result.Err.Trace(clnt.GetURL().String()), "Unable to abort an orphaned upload.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		printMsg(resumeGCMessage{
+			SessionID: sessionID,
+			Key:       path.Join(targetAlias, result.BucketName, result.ObjectName),
+			UploadID:  result.UploadID,
+			Initiated: initiated[result.UploadID],
+			Age:       int64(time.Since(initiated[result.UploadID]).Seconds()),
+			Aborted:   true,
+		})
+	}
+	return cErr
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+)
+
+var resumeListCmd = cli.Command{
+	Name:         "list",
+	ShortName:    "ls",
+	Usage:        "list the sessions of interrupted copies",
+	Action:       mainResumeList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List the copies which can be resumed by running them again with --continue.
+     {{.Prompt}} {{.HelpName}}
+`,
+}
+
+// resumeListMessage container for a saved session.
+type resumeListMessage struct {
+	Status       string    `json:"status"`
+	SessionID    string    `json:"sessionId"`
+	Time         time.Time `json:"time,omitempty"`
+	Age          int64     `json:"age"`
+	CommandType  string    `json:"commandType,omitempty"`
+	CommandArgs  []string  `json:"commandArgs,omitempty"`
+	LastCopied   string    `json:"lastCopied,omitempty"`
+	TotalObjects int64     `json:"totalObjects"`
+	TotalBytes   int64     `json:"totalBytes"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// String colorized session, its age and command.
+func (r resumeListMessage) String() string {
+	if r.Error != "" {
+		return console.Colorize("SessionID", r.SessionID+" ") + console.Colorize("Error", "unreadable: "+r.Error)
+	}
+	msg := console.Colorize("SessionID", r.SessionID+" ")
+	msg += console.Colorize("SessionTime", fmt.Sprintf("[%s ago]", timeDurationToHumanizedDuration(time.Duration(r.Age)*time.Second).StringShort()))
+	msg += console.Colorize("Command", fmt.Sprintf(" %s %s", r.CommandType, strings.Join(r.CommandArgs, " ")))
+	msg += fmt.Sprintf(" (%s objects, %s)", humanize.Comma(r.TotalObjects), humanize.IBytes(uint64(r.TotalBytes)))
+	return msg
+}
+
+// JSON jsonified session, age in seconds.
+func (r resumeListMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainResumeList is the handle for "mc resume list" command.
+func mainResumeList(cliCtx *cli.Context) error {
+	if cliCtx.Args().Present() {
+		showCommandHelpAndExit(cliCtx, 1) // last argument is exit code
+	}
+
+	console.SetColor("SessionID", color.New(color.FgYellow, color.Bold))
+	console.SetColor("SessionTime", color.New(color.FgGreen))
+	console.SetColor("Command", color.New(color.Bold))
+	console.SetColor("Error", color.New(color.FgRed))
+
+	for _, s := range loadResumeSessions() {
+		msg := resumeListMessage{SessionID: s.id}
+		if s.header == nil {
+			msg.Error = s.err.ToGoError().Error()
+			printMsg(msg)
+			continue
+		}
+		msg.Time = s.header.When
+		msg.Age = int64(s.age().Seconds())
+		msg.CommandType = s.header.CommandType
+		msg.CommandArgs = s.header.CommandArgs
+		msg.LastCopied = s.header.LastCopied
+		msg.TotalObjects = s.header.TotalObjects
+		msg.TotalBytes = s.header.TotalBytes
+		printMsg(msg)
+	}
+	return nil
+}
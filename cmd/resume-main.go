@@ -0,0 +1,147 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/quick"
+)
+
+var resumeSubcommands = []cli.Command{
+	resumeListCmd,
+	resumeGCCmd,
+	resumeCleanCmd,
+}
+
+var resumeCmd = cli.Command{
+	Name:            "resume",
+	Usage:           "manage the sessions of interrupted copies and their incomplete uploads",
+	HideHelpCommand: true,
+	Action:          mainResume,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	Subcommands:     resumeSubcommands,
+}
+
+// mainResume is the handle for "mc resume" command.
+func mainResume(ctx *cli.Context) error {
+	commandNotFound(ctx, resumeSubcommands)
+	return nil
+	// Sub-commands like "list", "gc", "clean" have their own main.
+}
+
+// resumeSession - a saved session, its header is nil if the session
+// file cannot be read.
+type resumeSession struct {
+	id     string
+	header *sessionV8Header
+	err    *probe.Error
+}
+
+// age returns the time elapsed since the session started.
+func (s resumeSession) age() time.Duration {
+	if s.header == nil {
+		return 0
+	}
+	return time.Since(s.header.When)
+}
+
+// target returns the target of the copy of the session, the last of
+// its arguments.
+func (s resumeSession) target() string {
+	if s.header == nil || len(s.header.CommandArgs) == 0 {
+		return ""
+	}
+	return s.header.CommandArgs[len(s.header.CommandArgs)-1]
+}
+
+// loadResumeSessions reads the headers of all saved sessions, oldest
+// first. Unlike loadSessionV8 the data files are neither opened nor
+// required to exist.
+func loadResumeSessions() (sessions []resumeSession) {
+	if !isSessionDirExists() {
+		return nil
+	}
+	for _, sid := range getSessionIDs() {
+		s := resumeSession{id: sid}
+		sessionFile, err := getSessionFile(sid)
+		if err != nil {
+			s.err = err.Trace(sid)
+			sessions = append(sessions, s)
+			continue
+		}
+		header := &sessionV8Header{}
+		if _, e := quick.LoadConfig(sessionFile, nil, header); e != nil {
+			s.err = probe.NewError(e).Trace(sid)
+		} else if header.Version != globalSessionConfigVersion {
+			s.err = errInvalidArgument().Trace(sid, header.Version)
+		} else {
+			s.header = header
+		}
+		sessions = append(sessions, s)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].age() > sessions[j].age()
+	})
+	return sessions
+}
+
+// removeSessionFiles removes the header, data and backup files of a
+// session, files already missing are ignored.
+func removeSessionFiles(sid string) *probe.Error {
+	sessionFile, err := getSessionFile(sid)
+	if err != nil {
+		return err.Trace(sid)
+	}
+	dataFile, err := getSessionDataFile(sid)
+	if err != nil {
+		return err.Trace(sid)
+	}
+	for _, file := range []string{dataFile, sessionFile + ".old", sessionFile} {
+		if e := os.Remove(file); e != nil && !os.IsNotExist(e) {
+			return probe.NewError(e).Trace(sid, filepath.Base(file))
+		}
+	}
+	return nil
+}
+
+// isSessionTarget returns true if uploadURL, the full URL of an
+// incomplete upload, is under the target of one of sessions.
+func isSessionTarget(sessions []resumeSession, uploadURL string) bool {
+	for _, s := range sessions {
+		target := s.target()
+		if target == "" {
+			continue
+		}
+		_, targetURL, _, err := expandAlias(target)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(uploadURL, strings.TrimSuffix(targetURL, "/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -67,10 +67,6 @@ var (
 			Name:  "incomplete, I",
 			Usage: "remove incomplete uploads",
 		},
-		cli.BoolFlag{
-			Name:  "dry-run",
-			Usage: "perform a fake remove operation",
-		},
 		cli.BoolFlag{
 			Name:   "fake",
 			Usage:  "perform a fake remove operation",
@@ -167,6 +163,9 @@ EXAMPLES:
   14. Perform a fake removal of object(s) versions that are non-current and older than 10 days. If top-level version is a delete 
   marker, this will also be deleted when --non-current flag is specified.
       {{.Prompt}} {{.HelpName}} s3/docs/ --recursive --force --versions --non-current --older-than 10d --dry-run
+
+  15. Abort only the incomplete uploads under the prefix 'louis' started more than 7 days ago.
+      {{.Prompt}} {{.HelpName}} --incomplete --recursive --force --older-than 7d s3/jazz-songs/louis/
 `,
 }
 
@@ -210,6 +209,58 @@ func (r rmMessage) JSON() string {
 	return string(msgBytes)
 }
 
+// rmResultMessage returns the message printed for a removed object,
+// or for an aborted upload when removing incomplete uploads.
+func rmResultMessage(key string, result RemoveResult, isIncomplete bool) message {
+	if isIncomplete {
+		return rmIncompleteMessage{
+			Key:      key,
+			UploadID: result.UploadID,
+			Aborted:  true,
+		}
+	}
+	msg := rmMessage{
+		Key:       key,
+		VersionID: result.ObjectVersionID,
+	}
+	if result.DeleteMarker {
+		msg.DeleteMarker = true
+		msg.VersionID = result.DeleteMarkerVersionID
+	}
+	return msg
+}
+
+// rmIncompleteMessage is printed for each incomplete upload aborted
+// by rm --incomplete.
+type rmIncompleteMessage struct {
+	Status   string `json:"status"`
+	Key      string `json:"key"`
+	UploadID string `json:"uploadId"`
+	Aborted  bool   `json:"aborted"`
+	DryRun   bool   `json:"dryRun,omitempty"`
+}
+
+// Colorized message for console printing.
+func (r rmIncompleteMessage) String() string {
+	msg := "Aborted upload "
+	if r.DryRun {
+		msg = "DRYRUN: Aborting upload "
+	}
+	msg += console.Colorize("Removed", fmt.Sprintf("`%s`", r.Key))
+	if r.UploadID != "" {
+		msg += fmt.Sprintf(" (uploadId=%s)", r.UploadID)
+	}
+	return msg + "."
+}
+
+// JSON'ified message for scripting.
+func (r rmIncompleteMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
 // Validate command line arguments.
 func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	// Set command flags from context.
@@ -364,15 +415,7 @@ func removeSingle(url, versionID string, opts removeOpts) error {
 				}
 				return exitStatus(globalErrorExitStatus)
 			}
-			msg := rmMessage{
-				Key:       path.Join(targetAlias, result.BucketName, result.ObjectName),
-				VersionID: result.ObjectVersionID,
-			}
-			if result.DeleteMarker {
-				msg.DeleteMarker = true
-				msg.VersionID = result.DeleteMarkerVersionID
-			}
-			printMsg(msg)
+			printMsg(rmResultMessage(path.Join(targetAlias, result.BucketName, result.ObjectName), result, opts.isIncomplete))
 		}
 	} else {
 		printDryRunMsg(targetAlias, content, opts.withVersions)
@@ -399,6 +442,15 @@ func printDryRunMsg(targetAlias string, content *ClientContent, printModTime boo
 	if content == nil {
 		return
 	}
+	if content.UploadID != "" {
+		printMsg(rmIncompleteMessage{
+			Status:   "success",
+			DryRun:   true,
+			Key:      targetAlias + getKey(content),
+			UploadID: content.UploadID,
+		})
+		return
+	}
 	msg := rmMessage{
 		Status:    "success",
 		DryRun:    true,
@@ -513,15 +565,7 @@ func listAndRemove(url string, opts removeOpts) error {
 								close(contentCh)
 								return exitStatus(globalErrorExitStatus)
 							}
-							msg := rmMessage{
-								Key:       path,
-								VersionID: result.ObjectVersionID,
-							}
-							if result.DeleteMarker {
-								msg.DeleteMarker = true
-								msg.VersionID = result.DeleteMarkerVersionID
-							}
-							printMsg(msg)
+							printMsg(rmResultMessage(path, result, opts.isIncomplete))
 						}
 					}
 				}
@@ -575,15 +619,7 @@ func listAndRemove(url string, opts removeOpts) error {
 						close(contentCh)
 						return exitStatus(globalErrorExitStatus)
 					}
-					msg := rmMessage{
-						Key:       path,
-						VersionID: result.ObjectVersionID,
-					}
-					if result.DeleteMarker {
-						msg.DeleteMarker = true
-						msg.VersionID = result.DeleteMarkerVersionID
-					}
-					printMsg(msg)
+					printMsg(rmResultMessage(path, result, opts.isIncomplete))
 				}
 			}
 		} else {
@@ -634,15 +670,7 @@ func listAndRemove(url string, opts removeOpts) error {
 						close(contentCh)
 						return exitStatus(globalErrorExitStatus)
 					}
-					msg := rmMessage{
-						Key:       path,
-						VersionID: result.ObjectVersionID,
-					}
-					if result.DeleteMarker {
-						msg.DeleteMarker = true
-						msg.VersionID = result.DeleteMarkerVersionID
-					}
-					printMsg(msg)
+					printMsg(rmResultMessage(path, result, opts.isIncomplete))
 				}
 			}
 		}
@@ -663,15 +691,7 @@ func listAndRemove(url string, opts removeOpts) error {
 			}
 			return exitStatus(globalErrorExitStatus)
 		}
-		msg := rmMessage{
-			Key:       path,
-			VersionID: result.ObjectVersionID,
-		}
-		if result.DeleteMarker {
-			msg.DeleteMarker = true
-			msg.VersionID = result.DeleteMarkerVersionID
-		}
-		printMsg(msg)
+		printMsg(rmResultMessage(path, result, opts.isIncomplete))
 	}
 
 	if !atLeastOneObjectFound {
@@ -702,7 +722,7 @@ func mainRm(cliCtx *cli.Context) error {
 	// rm specific flags.
 	isIncomplete := cliCtx.Bool("incomplete")
 	isRecursive := cliCtx.Bool("recursive")
-	isFake := cliCtx.Bool("dry-run") || cliCtx.Bool("fake")
+	isFake := globalDryRun || cliCtx.Bool("fake")
 	isStdin := cliCtx.Bool("stdin")
 	isBypass := cliCtx.Bool("bypass")
 	olderThan := cliCtx.String("older-than")
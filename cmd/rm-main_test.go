@@ -0,0 +1,81 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+)
+
+// Test rm --incomplete aborts the incomplete uploads below the prefix
+// by their upload id, and keeps the objects and the other uploads.
+func TestRmIncomplete(t *testing.T) {
+	defer func(configDir string, quiet bool) {
+		mcCustomConfigDir, globalQuiet = configDir, quiet
+	}(mcCustomConfigDir, globalQuiet)
+	defer func(exiter func(int)) { cli.OsExiter = exiter }(cli.OsExiter)
+	var exitCode int
+	cli.OsExiter = func(code int) { exitCode = code }
+
+	handler := newMemS3Handler()
+	handler.objects["bucket/dir/a"] = []byte("a")
+	for i, object := range []string{"bucket/dir/a", "bucket/dir/a", "bucket/dir/sub/b", "bucket/other"} {
+		handler.uploads["upload-"+strconv.Itoa(i+1)] = memS3Upload{object: object, initiated: time.Now().UTC()}
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	configDir := filepath.Join(t.TempDir(), "config")
+	if e := registerApp("mc").Run([]string{"mc", "--config-dir", configDir, "--quiet", "rm", "--incomplete", "--recursive", "--force", "fake/bucket/dir/"}); e != nil || exitCode != 0 {
+		t.Fatalf("unable to remove the incomplete uploads, exit code %d: %v", exitCode, e)
+	}
+
+	var aborted, left []string
+	for _, request := range handler.requests {
+		if strings.HasPrefix(request, "DELETE ") {
+			aborted = append(aborted, request)
+		}
+	}
+	for id := range handler.uploads {
+		left = append(left, id)
+	}
+	sort.Strings(aborted)
+	expected := []string{
+		"DELETE /bucket/dir/a uploadId=upload-1",
+		"DELETE /bucket/dir/a uploadId=upload-2",
+		"DELETE /bucket/dir/sub/b uploadId=upload-3",
+	}
+	if !reflect.DeepEqual(aborted, expected) {
+		t.Errorf("expected the requests %v, got %v", expected, aborted)
+	}
+	if !reflect.DeepEqual(left, []string{"upload-4"}) {
+		t.Errorf("expected only upload-4 to be left, got %v", left)
+	}
+	if _, ok := handler.objects["bucket/dir/a"]; !ok {
+		t.Error("expected the object to be kept")
+	}
+}
@@ -20,6 +20,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -52,6 +53,9 @@ type sessionV8Header struct {
 	TotalBytes         int64             `json:"totalBytes"`
 	TotalObjects       int64             `json:"totalObjects"`
 	UserMetaData       map[string]string `json:"metaData"`
+	// Uploads are the multipart uploads started and not finished yet,
+	// by upload id to the aliased URL of their object.
+	Uploads map[string]string `json:"uploads,omitempty"`
 }
 
 // sessionMessage container for session messages
@@ -69,6 +73,9 @@ type sessionV8 struct {
 	SessionID string
 	mutex     *sync.Mutex
 	DataFP    *sessionDataFP
+	// uploads records the multipart uploads of the copy, saved in
+	// the header.
+	uploads *uploadRecorder
 }
 
 // sessionDataFP data file pointer.
@@ -228,6 +235,7 @@ func (s *sessionV8) Save() *probe.Error {
 		s.DataFP.dirty = false
 	}
 
+	s.saveUploads()
 	qs, e := quick.NewConfig(s.Header, nil)
 	if e != nil {
 		return probe.NewError(e).Trace(s.SessionID)
@@ -244,6 +252,24 @@ func (s *sessionV8) Save() *probe.Error {
 	return nil
 }
 
+// recordUploads returns a context recording the multipart uploads of
+// the copies made with it in the session, along with the ones recorded
+// by the previous runs of the session.
+func (s *sessionV8) recordUploads(ctx context.Context) context.Context {
+	s.uploads = newUploadRecorder(ctx, "")
+	for uploadID, url := range s.Header.Uploads {
+		s.uploads.add(uploadID, url)
+	}
+	return context.WithValue(ctx, uploadRecorderKey{}, s.uploads)
+}
+
+// saveUploads copies the recorded uploads into the session header.
+func (s *sessionV8) saveUploads() {
+	if s.uploads != nil {
+		s.Header.Uploads = s.uploads.list()
+	}
+}
+
 // setGlobals captures the state of global variables into session header.
 // Used by newSession.
 func (s *sessionV8) setGlobals() {
@@ -292,6 +318,7 @@ func (s *sessionV8) save() *probe.Error {
 	}
 
 	// Verify if sessionFile is modified.
+	s.saveUploads()
 	modified, err := s.isModified(sessionFile)
 	if err != nil {
 		return err.Trace(s.SessionID)
@@ -18,12 +18,14 @@
 package cmd
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/mc/pkg/probe"
 )
 
@@ -132,3 +134,77 @@ func getHash(prefix string, args []string) string {
 
 	return prefix + "-" + hex.EncodeToString(hasher.Sum(nil))
 }
+
+// resumeThreshold returns the copy size above which a session is
+// created automatically, zero if disabled. --resume-threshold wins
+// over the smallest threshold configured for the aliases in urls.
+func resumeThreshold(urls []string) uint64 {
+	if globalNoResume {
+		return 0
+	}
+	if globalResumeThreshold > 0 {
+		return globalResumeThreshold
+	}
+	var threshold uint64
+	for _, url := range urls {
+		_, _, aliasCfg, err := expandAlias(url)
+		if err != nil || aliasCfg == nil || aliasCfg.ResumeThreshold == "" {
+			continue
+		}
+		// Invalid values are rejected when validating the config.
+		size, _ := humanize.ParseBytes(aliasCfg.ResumeThreshold)
+		if size > 0 && (threshold == 0 || size < threshold) {
+			threshold = size
+		}
+	}
+	return threshold
+}
+
+// isResumeNeeded returns true if the copy from sourceURLs to targetURL
+// has a saved session, sessionID, to resume or if its sources add up to
+// at least the resume threshold. Recursive sources are listed only
+// without a saved session and until the threshold is reached.
+func isResumeNeeded(ctx context.Context, sessionID string, sourceURLs []string, targetURL string, isRecursive bool) bool {
+	threshold := resumeThreshold(append([]string{targetURL}, sourceURLs...))
+	if threshold == 0 {
+		return false
+	}
+	if isSessionExists(sessionID) {
+		return true
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var total uint64
+	for _, sourceURL := range sourceURLs {
+		clnt, err := newClient(sourceURL)
+		if err != nil {
+			continue
+		}
+		if !isRecursive {
+			if content, err := clnt.Stat(ctx, StatOptions{}); err == nil && content.Size > 0 {
+				total += uint64(content.Size)
+			}
+		} else {
+			contentCh := clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone})
+			for content := range contentCh {
+				if content.Err != nil || content.Size <= 0 {
+					continue
+				}
+				total += uint64(content.Size)
+				if total >= threshold {
+					cancel()
+					// Drain the listing to let it stop.
+					for range contentCh {
+					}
+					break
+				}
+			}
+		}
+		if total >= threshold {
+			return true
+		}
+	}
+	return false
+}
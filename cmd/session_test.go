@@ -18,10 +18,17 @@
 package cmd
 
 import (
+	"context"
 	"math/rand"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/minio/mc/pkg/probe"
 	checkv1 "gopkg.in/check.v1"
 )
 
@@ -71,3 +78,145 @@ func (s *TestSuite) TestSession(c *checkv1.C) {
 	_, e = os.Stat(session.DataFP.Name())
 	c.Assert(e, checkv1.NotNil)
 }
+
+func (s *TestSuite) TestResumeNeeded(c *checkv1.C) {
+	root := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(root, "dir"), 0o700), checkv1.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(root, "dir", "small"), make([]byte, 512), 0o600), checkv1.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(root, "dir", "large"), make([]byte, 4096), 0o600), checkv1.IsNil)
+
+	defer func(threshold uint64, noResume bool, load func() (*configV10, *probe.Error)) {
+		globalResumeThreshold, globalNoResume, loadMcConfig = threshold, noResume, load
+	}(globalResumeThreshold, globalNoResume, loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	ctx := context.Background()
+	target := filepath.Join(root, "target")
+	sessionID := getHash("cp", []string{filepath.Join(root, "missing"), target})
+	globalResumeThreshold = 1024
+	c.Assert(isResumeNeeded(ctx, sessionID, []string{filepath.Join(root, "dir", "small")}, target, false), checkv1.Equals, false)
+	c.Assert(isResumeNeeded(ctx, sessionID, []string{filepath.Join(root, "dir", "large")}, target, false), checkv1.Equals, true)
+	c.Assert(isResumeNeeded(ctx, sessionID, []string{filepath.Join(root, "dir")}, target, true), checkv1.Equals, true)
+
+	// A saved session is resumed whatever the size of the sources.
+	c.Assert(createSessionDir(), checkv1.IsNil)
+	c.Assert(isResumeNeeded(ctx, sessionID, []string{filepath.Join(root, "missing")}, target, true), checkv1.Equals, false)
+	session := newSessionV8(sessionID)
+	c.Assert(session.Close(), checkv1.IsNil)
+	defer removeSessionFiles(sessionID)
+	c.Assert(isResumeNeeded(ctx, sessionID, []string{filepath.Join(root, "missing")}, target, true), checkv1.Equals, true)
+
+	globalNoResume = true
+	c.Assert(isResumeNeeded(ctx, sessionID, []string{filepath.Join(root, "dir", "large")}, target, false), checkv1.Equals, false)
+}
+
+func (s *TestSuite) TestResumeSessions(c *checkv1.C) {
+	c.Assert(createSessionDir(), checkv1.IsNil)
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	target := filepath.Join(c.MkDir(), "target")
+	session := newSessionV8(getHash("cp", []string{"src", target}))
+	session.Header.CommandType = "cp"
+	session.Header.CommandArgs = []string{"src", target}
+	session.Header.When = UTCNow().Add(-48 * time.Hour)
+	c.Assert(session.Close(), checkv1.IsNil)
+
+	var saved *resumeSession
+	sessions := loadResumeSessions()
+	for i := range sessions {
+		if sessions[i].id == session.SessionID {
+			saved = &sessions[i]
+		}
+	}
+	c.Assert(saved, checkv1.NotNil)
+	c.Assert(saved.target(), checkv1.Equals, target)
+	c.Assert(isSessionTarget(sessions, target+"/dir/object"), checkv1.Equals, true)
+	c.Assert(isSessionTarget(sessions, filepath.Join(c.MkDir(), "object")), checkv1.Equals, false)
+
+	c.Assert(staleSessionReason(*saved, 72*time.Hour, false), checkv1.Equals, "")
+	c.Assert(staleSessionReason(*saved, 24*time.Hour, false), checkv1.Not(checkv1.Equals), "")
+	c.Assert(staleSessionReason(*saved, 72*time.Hour, true), checkv1.Not(checkv1.Equals), "")
+
+	c.Assert(removeSessionFiles(session.SessionID), checkv1.IsNil)
+	c.Assert(isSessionExists(session.SessionID), checkv1.Equals, false)
+	_, e := os.Stat(session.DataFP.Name())
+	c.Assert(os.IsNotExist(e), checkv1.Equals, true)
+}
+
+func (s *TestSuite) TestResumeGCSessions(c *checkv1.C) {
+	c.Assert(createSessionDir(), checkv1.IsNil)
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	newSession := func(target string, age time.Duration) *sessionV8 {
+		session := newSessionV8(getHash("cp", []string{"src", target}))
+		session.Header.CommandType = "cp"
+		session.Header.CommandArgs = []string{"src", target}
+		session.Header.When = UTCNow().Add(-age)
+		c.Assert(session.Close(), checkv1.IsNil)
+		return session
+	}
+	stale := newSession(filepath.Join(c.MkDir(), "stale"), 10*24*time.Hour)
+	fresh := newSession(filepath.Join(c.MkDir(), "fresh"), time.Hour)
+	defer removeSessionFiles(fresh.SessionID)
+
+	c.Assert(gcSessions(context.Background(), "7d", true), checkv1.IsNil)
+	c.Assert(isSessionExists(stale.SessionID), checkv1.Equals, true)
+
+	c.Assert(gcSessions(context.Background(), "7d", false), checkv1.IsNil)
+	c.Assert(isSessionExists(stale.SessionID), checkv1.Equals, false)
+	c.Assert(isSessionExists(fresh.SessionID), checkv1.Equals, true)
+}
+
+func (s *TestSuite) TestSessionRecordUploads(c *checkv1.C) {
+	c.Assert(createSessionDir(), checkv1.IsNil)
+
+	session := newSessionV8(getHash("cp", []string{"src", "fake/bucket"}))
+	defer removeSessionFiles(session.SessionID)
+	ctx := session.recordUploads(context.Background())
+	uploads := newUploadRecorder(ctx, "fake/bucket/a")
+	uploads.add("upload-1", "fake/bucket/a")
+	uploads.add("upload-2", "fake/bucket/a")
+	uploads.remove("upload-2")
+	c.Assert(session.Close(), checkv1.IsNil)
+
+	// A resumed session keeps the uploads of its previous runs.
+	resumed, err := loadSessionV8(session.SessionID)
+	c.Assert(err, checkv1.IsNil)
+	c.Assert(resumed.Header.Uploads, checkv1.DeepEquals, map[string]string{"upload-1": "fake/bucket/a"})
+	newUploadRecorder(resumed.recordUploads(context.Background()), "fake/bucket/b").add("upload-3", "fake/bucket/b")
+	c.Assert(resumed.Save(), checkv1.IsNil)
+	c.Assert(resumed.Header.Uploads, checkv1.DeepEquals, map[string]string{"upload-1": "fake/bucket/a", "upload-3": "fake/bucket/b"})
+	c.Assert(resumed.Close(), checkv1.IsNil)
+}
+
+// Test resume gc --sessions aborts only the uploads recorded by the
+// removed session, not the other uploads under its target.
+func (s *TestSuite) TestResumeGCSessionUploads(c *checkv1.C) {
+	c.Assert(createSessionDir(), checkv1.IsNil)
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	handler := newMemS3Handler()
+	for i, object := range []string{"bucket/dir/a", "bucket/dir/a", "bucket/dir/b"} {
+		handler.uploads["upload-"+strconv.Itoa(i+1)] = memS3Upload{object: object, initiated: time.Now().UTC()}
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	defer os.Unsetenv("MC_HOST_fake")
+	os.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	session := newSessionV8(getHash("cp", []string{"src", "fake/bucket/dir"}))
+	session.Header.CommandType = "cp"
+	session.Header.CommandArgs = []string{"src", "fake/bucket/dir"}
+	session.Header.When = UTCNow().Add(-10 * 24 * time.Hour)
+	// upload-4 was finished since.
+	session.Header.Uploads = map[string]string{"upload-1": "fake/bucket/dir/a", "upload-3": "fake/bucket/dir/b", "upload-4": "fake/bucket/dir/c"}
+	c.Assert(session.Close(), checkv1.IsNil)
+
+	c.Assert(gcSessions(context.Background(), "7d", false), checkv1.IsNil)
+	c.Assert(isSessionExists(session.SessionID), checkv1.Equals, false)
+	c.Assert(handler.uploads, checkv1.HasLen, 1)
+	c.Assert(handler.uploads["upload-2"].object, checkv1.Equals, "bucket/dir/a")
+}
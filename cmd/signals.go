@@ -56,5 +56,6 @@ func trapSignals(sig ...os.Signal) {
 	default:
 		exitCode = globalErrorExitStatus
 	}
+	runExitHooks()
 	os.Exit(exitCode)
 }
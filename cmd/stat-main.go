@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"context"
+	"os"
 	"strings"
 	"time"
 
@@ -46,6 +47,19 @@ var (
 			Name:  "recursive, r",
 			Usage: "stat all objects recursively",
 		},
+		cli.BoolFlag{
+			Name:  "stdin",
+			Usage: "stat the newline delimited object names read from STDIN, relative to TARGET if given",
+		},
+		cli.IntFlag{
+			Name:  "workers",
+			Usage: "number of parallel requests of --stdin and --policy, records are printed in the order of the names",
+			Value: 1,
+		},
+		cli.StringFlag{
+			Name:  "policy",
+			Usage: "report the objects violating the rules of a YAML policy file, requires --recursive",
+		},
 	}
 )
 
@@ -91,9 +105,43 @@ EXAMPLES:
 
   7. Stat all objects versions recursively created before 1st January 2020.
      {{.Prompt}} {{.HelpName}} --versions --rewind 2020.01.01T00:00 s3/personal-docs/
+
+  8. Stat a list of object names of mybucket with 16 parallel requests, one JSON record per line.
+     {{.Prompt}} cat keys.txt | {{.HelpName}} --stdin --workers 16 --json s3/mybucket/
+
+  9. Report the objects of mybucket violating the encryption, tag, age and storage class rules of policy.yaml.
+     {{.Prompt}} {{.HelpName}} --recursive --policy policy.yaml --workers 8 s3/mybucket/
 `,
 }
 
+// checkStatStdinSyntax - validate the arguments of 'stat --stdin'
+func checkStatStdinSyntax(cliCtx *cli.Context) {
+	args := cliCtx.Args()
+	if len(args) > 1 {
+		fatalIf(errInvalidArgument().Trace(args...), "--stdin takes at most one TARGET prefix.")
+	}
+	if cliCtx.Bool("recursive") || cliCtx.Bool("versions") || cliCtx.String("version-id") != "" {
+		fatalIf(errInvalidArgument().Trace(args...), "--stdin cannot be used with --recursive, --versions or --version-id.")
+	}
+	if cliCtx.Int("workers") <= 0 {
+		fatalIf(errInvalidArgument().Trace(args...), "--workers must be a positive number.")
+	}
+}
+
+// checkStatPolicySyntax - validate the arguments of 'stat --policy'
+func checkStatPolicySyntax(cliCtx *cli.Context) {
+	args := cliCtx.Args()
+	if !cliCtx.Bool("recursive") {
+		fatalIf(errInvalidArgument().Trace(args...), "--policy requires --recursive.")
+	}
+	if cliCtx.Bool("stdin") || cliCtx.Bool("versions") || cliCtx.String("version-id") != "" || cliCtx.String("rewind") != "" {
+		fatalIf(errInvalidArgument().Trace(args...), "--policy cannot be used with --stdin, --versions, --version-id or --rewind.")
+	}
+	if cliCtx.Int("workers") <= 0 {
+		fatalIf(errInvalidArgument().Trace(args...), "--workers must be a positive number.")
+	}
+}
+
 // parseAndCheckStatSyntax - parse and validate all the passed arguments
 func parseAndCheckStatSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) ([]string, bool, string, time.Time, bool) {
 	if !cliCtx.Args().Present() {
@@ -152,11 +200,61 @@ func mainStat(cliCtx *cli.Context) error {
 
 	console.SetColor("Title", color.New(color.Bold, color.FgBlue))
 	console.SetColor("Count", color.New(color.FgGreen))
+	// theme specific to stat policy
+	console.SetColor("Rule", color.New(color.FgRed))
 
 	// Parse encryption keys per command.
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	if cliCtx.String("policy") != "" {
+		checkStatPolicySyntax(cliCtx)
+		policy, err := parseStatPolicy(cliCtx.String("policy"))
+		fatalIf(err.Trace(cliCtx.String("policy")), "Unable to read policy file.")
+		args := cliCtx.Args()
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		var objects, violating int64
+		var listErr bool
+		for _, targetURL := range args {
+			o, v, err := statPolicyURL(ctx, targetURL, policy, cliCtx.Int("workers"))
+			// Listing errors are already reported, keep checking the rest.
+			listErr = listErr || err != nil
+			objects += o
+			violating += v
+		}
+		summary := complianceSummaryMessage{Status: "pass", Objects: objects, Violating: violating, Compliant: 100}
+		if objects > 0 {
+			summary.Compliant = float64(objects-violating) * 100 / float64(objects)
+		}
+		if violating > 0 {
+			summary.Status = "fail"
+		}
+		printMsg(summary)
+		if violating > 0 || listErr {
+			return exitStatus(globalErrorExitStatus)
+		}
+		return nil
+	}
+
+	if cliCtx.Bool("stdin") {
+		checkStatStdinSyntax(cliCtx)
+		var retErr error
+		err = statKeys(ctx, os.Stdin, cliCtx.Args().First(), cliCtx.Int("workers"), parseRewindFlag(cliCtx.String("rewind")), encKeyDB, func(result statKeyResult) {
+			if result.err != nil {
+				// Missing objects are reported without stopping.
+				errorIf(result.err.Trace(result.key), "Unable to stat `"+result.key+"`.")
+				retErr = exitStatus(globalErrorExitStatus)
+				return
+			}
+			result.content.URL.Path = result.key
+			printMsg(parseStat(result.content))
+		})
+		fatalIf(err.Trace(), "Unable to read object names from STDIN.")
+		return retErr
+	}
+
 	// check 'stat' cli arguments.
 	args, isRecursive, versionID, rewind, withVersions := parseAndCheckStatSyntax(ctx, cliCtx, encKeyDB)
 	// mimic operating system tool behavior.
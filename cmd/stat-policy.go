@@ -0,0 +1,266 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/v2/console"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Server side encryptions an object can be required to have by the
+// encryption rule of a 'stat --policy'.
+const (
+	policyEncryptionAny = "any"
+	policyEncryptionS3  = "sse-s3"
+	policyEncryptionKMS = "sse-kms"
+	policyEncryptionC   = "sse-c"
+
+	policyEncryptionNone = "none"
+)
+
+// statPolicy is a 'stat --policy' file, each rule set is checked against
+// every object, e.g.
+//
+//	encryption: sse-kms
+//	required-tags: [owner, project]
+//	max-age: 365d
+//	storage-classes: [STANDARD, STANDARD_IA]
+type statPolicy struct {
+	Encryption     string   `yaml:"encryption"`
+	RequiredTags   []string `yaml:"required-tags"`
+	MaxAge         string   `yaml:"max-age"`
+	StorageClasses []string `yaml:"storage-classes"`
+
+	maxAge time.Duration
+}
+
+// parseStatPolicy reads a YAML 'stat --policy' file.
+func parseStatPolicy(file string) (*statPolicy, *probe.Error) {
+	data, e := os.ReadFile(file)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	policy := &statPolicy{}
+	if e = yaml.UnmarshalStrict(data, policy); e != nil {
+		return nil, probe.NewError(e)
+	}
+	policy.Encryption = strings.ToLower(policy.Encryption)
+	switch policy.Encryption {
+	case "", policyEncryptionAny, policyEncryptionS3, policyEncryptionKMS, policyEncryptionC:
+	default:
+		return nil, probe.NewError(fmt.Errorf("encryption: unknown value `%s`, choose one of [%s, %s, %s, %s]",
+			policy.Encryption, policyEncryptionAny, policyEncryptionS3, policyEncryptionKMS, policyEncryptionC))
+	}
+	if policy.MaxAge != "" {
+		maxAge, e := ParseDuration(policy.MaxAge)
+		if e != nil {
+			return nil, probe.NewError(fmt.Errorf("max-age: %w", e))
+		}
+		policy.maxAge = time.Duration(maxAge)
+		if policy.maxAge <= 0 {
+			return nil, probe.NewError(fmt.Errorf("max-age: must be positive"))
+		}
+	}
+	if policy.Encryption == "" && len(policy.RequiredTags) == 0 && policy.maxAge == 0 && len(policy.StorageClasses) == 0 {
+		return nil, probe.NewError(fmt.Errorf("no rule is set, choose from [encryption, required-tags, max-age, storage-classes]"))
+	}
+	return policy, nil
+}
+
+// enrich - the attributes fetched for every object to check the rules.
+func (p *statPolicy) enrich(workers int) listEnrich {
+	return listEnrich{
+		tags:         len(p.RequiredTags) > 0,
+		storageClass: len(p.StorageClasses) > 0,
+		encryption:   p.Encryption != "",
+		workers:      workers,
+	}
+}
+
+// complianceViolation is a rule of a 'stat --policy' an object fails.
+type complianceViolation struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// complianceUnknown is the reason of the rules whose attributes could
+// not be fetched, the enrichment reports why.
+const complianceUnknown = "unknown, the object could not be checked"
+
+// check - returns the rules content fails at now. Rules whose attributes
+// could not be fetched fail, an object is compliant only when known to be.
+func (p *statPolicy) check(content *ClientContent, now time.Time) (violations []complianceViolation) {
+	if p.Encryption != "" {
+		switch encryption := content.Encryption; {
+		case encryption == "":
+			violations = append(violations, complianceViolation{Rule: "encryption", Reason: complianceUnknown})
+		case encryption == policyEncryptionNone:
+			violations = append(violations, complianceViolation{Rule: "encryption", Reason: "not encrypted"})
+		case p.Encryption != policyEncryptionAny && encryption != p.Encryption:
+			violations = append(violations, complianceViolation{Rule: "encryption", Reason: "encrypted with " + encryption + " instead of " + p.Encryption})
+		}
+	}
+	if len(p.RequiredTags) > 0 && content.Tags == nil {
+		violations = append(violations, complianceViolation{Rule: "required-tags", Reason: complianceUnknown})
+	} else if len(p.RequiredTags) > 0 {
+		var missing []string
+		for _, tag := range p.RequiredTags {
+			if _, ok := content.Tags[tag]; !ok {
+				missing = append(missing, tag)
+			}
+		}
+		if len(missing) > 0 {
+			violations = append(violations, complianceViolation{Rule: "required-tags", Reason: "missing tags " + strings.Join(missing, ", ")})
+		}
+	}
+	if p.maxAge > 0 {
+		if age := now.Sub(content.Time); age > p.maxAge {
+			violations = append(violations, complianceViolation{Rule: "max-age", Reason: "last modified " + timeDurationToHumanizedDuration(age).StringShort() + " ago, older than " + p.MaxAge})
+		}
+	}
+	if len(p.StorageClasses) > 0 && content.StorageClass == "" {
+		violations = append(violations, complianceViolation{Rule: "storage-classes", Reason: complianceUnknown})
+	} else if len(p.StorageClasses) > 0 {
+		allowed := false
+		for _, class := range p.StorageClasses {
+			allowed = allowed || strings.EqualFold(class, content.StorageClass)
+		}
+		if !allowed {
+			violations = append(violations, complianceViolation{Rule: "storage-classes", Reason: "stored in " + content.StorageClass})
+		}
+	}
+	return violations
+}
+
+// contentEncryption returns the server side encryption of an object from
+// its metadata, one of sse-s3, sse-kms, sse-c and none.
+func contentEncryption(metadata map[string]string) string {
+	encryption := policyEncryptionNone
+	for k, v := range metadata {
+		switch strings.ToLower(k) {
+		case serverEncryptionKeyPrefix + "-customer-algorithm":
+			return policyEncryptionC
+		case serverEncryptionKeyPrefix:
+			if strings.HasPrefix(strings.ToLower(v), "aws:kms") {
+				encryption = policyEncryptionKMS
+			} else if v != "" {
+				encryption = policyEncryptionS3
+			}
+		}
+	}
+	return encryption
+}
+
+// complianceMessage container for an object failing rules of a policy
+type complianceMessage struct {
+	Status     string                `json:"status"`
+	Key        string                `json:"name"`
+	VersionID  string                `json:"versionID,omitempty"`
+	Violations []complianceViolation `json:"violations"`
+}
+
+// String colorized failed rules of an object
+func (m complianceMessage) String() string {
+	reasons := make([]string, 0, len(m.Violations))
+	for _, v := range m.Violations {
+		reasons = append(reasons, console.Colorize("Rule", v.Rule)+": "+v.Reason)
+	}
+	return console.Colorize("Name", m.Key) + " " + strings.Join(reasons, "; ")
+}
+
+// JSON jsonified failed rules of an object
+func (m complianceMessage) JSON() string {
+	m.Status = "violation"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// complianceSummaryMessage container for the outcome of a policy check
+type complianceSummaryMessage struct {
+	Status    string  `json:"status"`
+	Objects   int64   `json:"objects"`
+	Violating int64   `json:"violating"`
+	Compliant float64 `json:"compliantPercent"`
+}
+
+// String colorized outcome of a policy check
+func (m complianceSummaryMessage) String() string {
+	color := "Set"
+	if m.Status == "fail" {
+		color = "Unset"
+	}
+	return console.Colorize(color, fmt.Sprintf("\n%s: %d of %d objects violate the policy, %.1f%% compliant",
+		strings.ToUpper(m.Status), m.Violating, m.Objects, m.Compliant))
+}
+
+// JSON jsonified outcome of a policy check
+func (m complianceSummaryMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// statPolicyURL - checks every object below targetURL against policy
+// with up to workers requests fetching their attributes, printing the
+// objects failing rules then a summary. Returns the number of objects
+// checked and of those failing rules, errors are reported as they occur.
+func statPolicyURL(ctx context.Context, targetURL string, policy *statPolicy, workers int) (objects, violating int64, err *probe.Error) {
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		errorIf(err.Trace(targetURL), "Unable to initialize `"+targetURL+"`.")
+		return 0, 0, err.Trace(targetURL)
+	}
+	alias, _, _ := mustExpandAlias(targetURL)
+	prefixPath := filepath.ToSlash(clnt.GetURL().Path)
+	if i := strings.LastIndex(prefixPath, "/"); i >= 0 {
+		prefixPath = prefixPath[:i+1]
+	}
+
+	contentCh := clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone})
+	now := time.Now()
+	for content := range enrichListing(ctx, alias, contentCh, policy.enrich(workers)) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
+			err = content.Err
+			continue
+		}
+		if content.Type.IsDir() || content.IsDeleteMarker {
+			continue
+		}
+		objects++
+		if violations := policy.check(content, now); len(violations) > 0 {
+			violating++
+			printMsg(complianceMessage{
+				Key:        strings.TrimPrefix(filepath.ToSlash(content.URL.Path), prefixPath),
+				VersionID:  content.VersionID,
+				Violations: violations,
+			})
+		}
+	}
+	return objects, violating, err
+}
@@ -18,9 +18,11 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -54,6 +56,7 @@ type statMessage struct {
 	VersionID         string             `json:"versionID,omitempty"`
 	DeleteMarker      bool               `json:"deleteMarker,omitempty"`
 	Restore           *minio.RestoreInfo `json:"restore,omitempty"`
+	RawHeaders        map[string]string  `json:"rawHeaders"`
 }
 
 func (stat statMessage) String() (msg string) {
@@ -172,6 +175,10 @@ func parseStat(c *ClientContent) statMessage {
 	content.ExpirationRuleID = c.ExpirationRuleID
 	content.ReplicationStatus = c.ReplicationStatus
 	content.Restore = c.Restore
+	content.RawHeaders = c.RawHeaders
+	if content.RawHeaders == nil {
+		content.RawHeaders = map[string]string{}
+	}
 	return content
 }
 
@@ -307,6 +314,46 @@ func statURL(ctx context.Context, targetURL, versionID string, timeRef time.Time
 	return probe.NewError(e)
 }
 
+// statKeyResult is the stat of a key read by 'stat --stdin'.
+type statKeyResult struct {
+	key     string
+	content *ClientContent
+	err     *probe.Error
+}
+
+// statKeys stats the newline delimited keys of reader, appended to
+// prefix, with up to workers parallel requests. Results are passed to
+// fn in the order of the keys.
+func statKeys(ctx context.Context, reader io.Reader, prefix string, workers int, timeRef time.Time, encKeyDB map[string][]prefixSSEPair, fn func(statKeyResult)) *probe.Error {
+	// At most workers results wait for the keys before them.
+	pending := make(chan chan statKeyResult, workers)
+	var scanErr error
+	go func() {
+		defer close(pending)
+		sem := make(chan struct{}, workers)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			key := strings.TrimSpace(scanner.Text())
+			if key == "" {
+				continue
+			}
+			resultCh := make(chan statKeyResult, 1)
+			pending <- resultCh
+			sem <- struct{}{}
+			go func() {
+				defer func() { <-sem }()
+				_, content, err := url2Stat(ctx, prefix+key, "", true, encKeyDB, timeRef, false)
+				resultCh <- statKeyResult{key: key, content: content, err: err}
+			}()
+		}
+		scanErr = scanner.Err()
+	}()
+	for resultCh := range pending {
+		fn(<-resultCh)
+	}
+	return probe.NewError(scanErr)
+}
+
 // BucketInfo holds info about a bucket
 type BucketInfo struct {
 	URL        ClientURL   `json:"-"`
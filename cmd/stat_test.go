@@ -18,11 +18,18 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/minio/mc/pkg/probe"
 )
 
 func TestParseStat(t *testing.T) {
@@ -40,6 +47,9 @@ func TestParseStat(t *testing.T) {
 		testCase := testCase
 		t.Run("", func(t *testing.T) {
 			statMsg := parseStat(&testCase.content)
+			if statMsg.RawHeaders == nil {
+				t.Errorf("Expecting empty raw headers, got nil")
+			}
 			if !reflect.DeepEqual(testCase.content.Metadata, statMsg.Metadata) {
 				t.Errorf("Expecting %s, got %s", testCase.content.Metadata, statMsg.Metadata)
 			}
@@ -68,3 +78,104 @@ func TestParseStat(t *testing.T) {
 		})
 	}
 }
+
+func TestStatKeysOrder(t *testing.T) {
+	defer func(load func() (*configV10, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV10, *probe.Error) { return newMcConfig(), nil }
+
+	dir := t.TempDir()
+	var keys []string
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("object-%02d", i)
+		if i%5 == 0 {
+			key = "missing-" + key
+		} else if e := os.WriteFile(filepath.Join(dir, key), []byte(key), 0o644); e != nil {
+			t.Fatal(e)
+		}
+		keys = append(keys, key)
+	}
+	var found []string
+	err := statKeys(context.Background(), strings.NewReader(strings.Join(keys, "\n")+"\n\n"), dir+"/", 8, time.Time{}, nil, func(result statKeyResult) {
+		found = append(found, result.key)
+		if missing := strings.HasPrefix(result.key, "missing-"); missing != (result.err != nil) {
+			t.Errorf("%s: expected missing %v, found error %v", result.key, missing, result.err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, keys) {
+		t.Fatalf("expected keys in order %v, found %v", keys, found)
+	}
+}
+
+func TestStatPolicyCheck(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if e := os.WriteFile(file, []byte("encryption: sse-kms\nrequired-tags: [owner]\nmax-age: 30d\nstorage-classes: [STANDARD]\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	policy, err := parseStatPolicy(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	testCases := []struct {
+		content ClientContent
+		rules   []string
+	}{
+		{ClientContent{Encryption: "sse-kms", Tags: map[string]string{"owner": "x"}, Time: now, StorageClass: "STANDARD"}, nil},
+		{ClientContent{Encryption: "none", Tags: map[string]string{}, Time: now.Add(-31 * 24 * time.Hour), StorageClass: "GLACIER"}, []string{"encryption", "required-tags", "max-age", "storage-classes"}},
+		{ClientContent{Encryption: "sse-s3", Tags: map[string]string{"owner": "x"}, Time: now, StorageClass: "STANDARD"}, []string{"encryption"}},
+		// Attributes which could not be fetched fail their rules.
+		{ClientContent{Time: now}, []string{"encryption", "required-tags", "storage-classes"}},
+	}
+	for i, testCase := range testCases {
+		var rules []string
+		for _, v := range policy.check(&testCase.content, now) {
+			rules = append(rules, v.Rule)
+		}
+		if !reflect.DeepEqual(rules, testCase.rules) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.rules, rules)
+		}
+	}
+
+	if e := os.WriteFile(file, []byte("encryption: sse-x\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if _, err = parseStatPolicy(file); err == nil {
+		t.Fatal("expected an error for an unknown encryption")
+	}
+}
+
+// Test objects whose attributes cannot be fetched violate the policy.
+func TestStatPolicyUnknown(t *testing.T) {
+	defer func(configDir string) { mcCustomConfigDir = configDir }(mcCustomConfigDir)
+	mcCustomConfigDir = t.TempDir()
+	handler := newMemS3Handler()
+	handler.objects["bucket/ok"] = []byte("ok")
+	handler.objects["bucket/denied"] = []byte("denied")
+	handler.fail = func(r *http.Request) bool {
+		return r.Method == http.MethodHead && r.URL.Path == "/bucket/denied"
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("MC_HOST_fake", strings.Replace(server.URL, "://", "://access:secretkey@", 1))
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if e := os.WriteFile(file, []byte("storage-classes: [STANDARD]\n"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	policy, err := parseStatPolicy(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+	objects, violating, err := statPolicyURL(context.Background(), "fake/bucket/", policy, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objects != 2 || violating != 1 {
+		t.Errorf("expected 1 violating object of 2, found %d of %d", violating, objects)
+	}
+}
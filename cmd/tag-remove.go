@@ -139,6 +139,10 @@ func deleteTags(ctx context.Context, clnt Client, versionID string) {
 		targetName += " (" + versionID + ")"
 	}
 
+	if skipDryRun(dryRunMessage{Action: "remove tags from", Target: targetName}) {
+		return
+	}
+
 	err := clnt.DeleteTags(ctx, versionID)
 	if err != nil {
 		fatalIf(err, "Unable to remove tags for "+targetName)
@@ -151,6 +151,10 @@ func setTags(ctx context.Context, clnt Client, versionID, tags string) {
 		targetName += " (" + versionID + ")"
 	}
 
+	if skipDryRun(dryRunMessage{Action: "set tags", Target: targetName, Value: tags}) {
+		return
+	}
+
 	err := clnt.SetTags(ctx, versionID, tags)
 	if err != nil {
 		fatalIf(err.Trace(tags), "Failed to set tags for "+targetName)
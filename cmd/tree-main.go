@@ -294,6 +294,7 @@ func mainTree(cliCtx *cli.Context) error {
 			clnt, err := newClientFromAlias(targetAlias, targetURL)
 			fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
 			opts := doListOptions{
+				alias:             targetAlias,
 				timeRef:           timeRef,
 				isRecursive:       true,
 				isIncomplete:      false,
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/mc/pkg/probe"
 )
 
@@ -125,6 +126,22 @@ var errTargetNotFound = func(URL string) *probe.Error {
 	return probe.NewError(targetNotFoundErr(errors.New(msg))).Untrace()
 }
 
+type targetKeyCollisionErr error
+
+var errTargetKeyCollision = func(firstURL, secondURL, targetURL string) *probe.Error {
+	msg := "Sources `" + firstURL + "` and `" + secondURL + "` both normalize to target `" + targetURL + "`."
+	return probe.NewError(targetKeyCollisionErr(errors.New(msg))).Untrace()
+}
+
+type keyTooLongErr struct {
+	error
+}
+
+var errKeyTooLong = func(targetURL string, length, maxLength int) *probe.Error {
+	msg := fmt.Sprintf("Target key of `%s` is %d bytes long, exceeding the limit of %d bytes.", targetURL, length, maxLength)
+	return probe.NewError(keyTooLongErr{errors.New(msg)}).Untrace()
+}
+
 type overwriteNotAllowedErr struct {
 	error
 }
@@ -154,3 +171,11 @@ var errConflictSSE = func(sseServer, sseKeys string) *probe.Error {
 	err := fmt.Errorf("SSE alias '%s' overlaps with SSE-C aliases '%s'", sseServer, sseKeys)
 	return probe.NewError(conflictSSEErr(err)).Untrace()
 }
+
+type objectTooLargeErr error
+
+var errObjectTooLarge = func(URL string, size, maxSize int64) *probe.Error {
+	msg := fmt.Sprintf("Object `%s` of %s exceeds the maximum object size of %s, use --split to copy it in parts.",
+		URL, humanize.IBytes(uint64(size)), humanize.IBytes(uint64(maxSize)))
+	return probe.NewError(objectTooLargeErr(errors.New(msg))).Untrace()
+}
@@ -50,10 +50,6 @@ var undoFlags = []cli.Flag{
 		Name:  "force",
 		Usage: "force recursive operation",
 	},
-	cli.BoolFlag{
-		Name:  "dry-run",
-		Usage: "fake an undo operation",
-	},
 	cli.StringFlag{
 		Name:  "action",
 		Usage: "undo only if the latest version is of the following type [PUT/DELETE]",
@@ -135,7 +131,7 @@ func parseUndoSyntax(ctx *cli.Context) (targetAliasedURL string, last int, recur
 		fatalIf(errInvalidArgument().Trace(), "This is a dangerous operation, you need to provide --force flag as well")
 	}
 
-	dryRun = ctx.Bool("dry-run")
+	dryRun = globalDryRun
 	action = strings.ToUpper(ctx.String("action"))
 	if action != actionPut && action != actionDelete && action != "" {
 		fatalIf(errInvalidArgument().Trace(), "unsupported action specified, supported actions are PUT, DELETE or empty (default)")
@@ -19,21 +19,45 @@ package cmd
 
 import (
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
 )
 
 // URLs contains source and target urls
 type URLs struct {
-	SourceAlias      string
-	SourceContent    *ClientContent
-	TargetAlias      string
-	TargetContent    *ClientContent
-	TotalCount       int64
-	TotalSize        int64
-	MD5              bool
-	DisableMultipart bool
-	encKeyDB         map[string][]prefixSSEPair
-	Error            *probe.Error `json:"-"`
-	ErrorCond        differType   `json:"-"`
+	SourceAlias          string
+	SourceContent        *ClientContent
+	TargetAlias          string
+	TargetContent        *ClientContent
+	TotalCount           int64
+	TotalSize            int64
+	MD5                  bool
+	DisableMultipart     bool
+	MultipartThreshold   uint64
+	PreserveXattr        bool
+	Preallocate          bool
+	NoClobber            bool
+	OverwriteDirWithFile bool
+	OverwriteFileWithDir bool
+	RemoveSource         bool
+	NoDereference        bool
+	PreserveACL          bool
+	PreserveTags         bool
+	MaxObjectSize        int64
+	Split                bool
+	DeltaBlockSize       int64
+	TrailerChecksum      minio.ChecksumType
+	AutoCompress         bool
+	AutoCompressMin      int64
+	Gzip                 bool
+	MetadataDirective    string
+	encKeyDB             map[string][]prefixSSEPair
+	contentTypes         map[string]string
+	contentHeaders       contentHeaders
+	waitConsistent       waitConsistentOptions
+	skipFileAttrs        bool
+	skipXattrs           bool
+	Error                *probe.Error `json:"-"`
+	ErrorCond            differType   `json:"-"`
 }
 
 // WithError sets the error and returns object
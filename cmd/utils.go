@@ -34,6 +34,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/mattn/go-ieproxy"
 	"github.com/minio/madmin-go/v3"
 	"github.com/minio/minio-go/v7"
@@ -149,7 +150,12 @@ func NewS3Config(alias, urlStr string, aliasCfg *aliasConfigV10) *Config {
 	s3Config.ConnReadDeadline = globalConnReadDeadline
 	s3Config.ConnWriteDeadline = globalConnWriteDeadline
 	s3Config.UploadLimit = int64(globalLimitUpload)
+	s3Config.UploadLimitShared = int64(globalLimitUploadShared)
 	s3Config.DownloadLimit = int64(globalLimitDownload)
+	s3Config.LimitBurst = int64(globalLimitBurst)
+	s3Config.RequestLimit = globalLimitRequests
+	s3Config.ListVersion = globalListVersion
+	s3Config.Accelerate = globalAccelerate
 
 	s3Config.HostURL = urlStr
 	s3Config.Alias = alias
@@ -159,10 +165,45 @@ func NewS3Config(alias, urlStr string, aliasCfg *aliasConfigV10) *Config {
 		s3Config.SessionToken = aliasCfg.SessionToken
 		s3Config.Signature = aliasCfg.API
 		s3Config.Lookup = getLookupType(aliasCfg.Path)
+		if aliasCfg.MultipartThreshold != "" {
+			// Invalid values are rejected when validating the config.
+			s3Config.MultipartThreshold, _ = parseMultipartThreshold(aliasCfg.MultipartThreshold)
+		}
+		s3Config.Accelerate = s3Config.Accelerate || aliasCfg.Accelerate
+		if aliasCfg.ListVersion != "" && s3Config.ListVersion == 0 {
+			s3Config.ListVersion, _ = parseListVersion(aliasCfg.ListVersion)
+		}
+		// Limits given on the command line override those of the alias.
+		if aliasCfg.LimitUpload != "" && s3Config.UploadLimit == 0 {
+			limit, _ := humanize.ParseBytes(aliasCfg.LimitUpload)
+			s3Config.UploadLimit = int64(limit)
+		}
+		if aliasCfg.LimitDownload != "" && s3Config.DownloadLimit == 0 {
+			limit, _ := humanize.ParseBytes(aliasCfg.LimitDownload)
+			s3Config.DownloadLimit = int64(limit)
+		}
+		if s3Config.RequestLimit == 0 {
+			s3Config.RequestLimit = aliasCfg.RequestsPerSecond
+		}
+	}
+	// Limits of a destination are more specific than --limit-upload.
+	if limit := destinationLimit(globalDestinationLimits, alias, urlStr); limit > 0 {
+		s3Config.UploadLimit = int64(limit)
 	}
 	return s3Config
 }
 
+// parseListVersion parses the listing API version, either 1 or 2.
+func parseListVersion(version string) (int, error) {
+	switch version {
+	case "1":
+		return 1, nil
+	case "2":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("unsupported list version `%s`, choose one of [1, 2]", version)
+}
+
 // lineTrunc - truncates a string to the given maximum length by
 // adding ellipsis in the middle
 func lineTrunc(content string, maxLen int) string {
@@ -470,3 +511,14 @@ func getPrometheusToken(hostConfig *aliasConfigV10) (string, error) {
 	}
 	return token, nil
 }
+
+// matchExcludePrefix - returns the prefix of excludePrefixes that key,
+// relative to the listed folder, starts with.
+func matchExcludePrefix(key string, excludePrefixes []string) string {
+	for _, prefix := range excludePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
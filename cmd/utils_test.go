@@ -160,3 +160,31 @@ func TestParseAttribute(t *testing.T) {
 
 	}
 }
+
+func TestNewS3ConfigLimits(t *testing.T) {
+	defer func(upload, download uint64, requests float64) {
+		globalLimitUpload, globalLimitDownload, globalLimitRequests = upload, download, requests
+	}(globalLimitUpload, globalLimitDownload, globalLimitRequests)
+	aliasCfg := &aliasConfigV10{LimitUpload: "1MiB", LimitDownload: "2MiB", RequestsPerSecond: 5}
+
+	// The limits of the alias apply without flags.
+	globalLimitUpload, globalLimitDownload, globalLimitRequests = 0, 0, 0
+	config := NewS3Config("myminio", "https://localhost:9000", aliasCfg)
+	if config.UploadLimit != 1<<20 || config.DownloadLimit != 2<<20 || config.RequestLimit != 5 {
+		t.Errorf("expected the limits of the alias, got %d, %d and %v", config.UploadLimit, config.DownloadLimit, config.RequestLimit)
+	}
+
+	// The flags override them.
+	globalLimitUpload, globalLimitDownload, globalLimitRequests = 100, 200, 1
+	config = NewS3Config("myminio", "https://localhost:9000", aliasCfg)
+	if config.UploadLimit != 100 || config.DownloadLimit != 200 || config.RequestLimit != 1 {
+		t.Errorf("expected the limits of the flags, got %d, %d and %v", config.UploadLimit, config.DownloadLimit, config.RequestLimit)
+	}
+
+	// Each flag overrides only its own limit.
+	globalLimitUpload, globalLimitDownload, globalLimitRequests = 100, 0, 0
+	config = NewS3Config("myminio", "https://localhost:9000", aliasCfg)
+	if config.UploadLimit != 100 || config.DownloadLimit != 2<<20 || config.RequestLimit != 5 {
+		t.Errorf("expected the upload limit of the flag only, got %d, %d and %v", config.UploadLimit, config.DownloadLimit, config.RequestLimit)
+	}
+}
@@ -0,0 +1,63 @@
+//go:build linux || darwin
+
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/pkg/xattr"
+)
+
+// xattrSupported reports if extended attributes can be preserved on this platform.
+const xattrSupported = true
+
+// listXattrs returns the raw extended attributes of a file, nothing
+// is returned if the filesystem does not support them.
+func listXattrs(path string) (map[string][]byte, error) {
+	list, e := xattr.List(path)
+	if e != nil {
+		if isNotSupported(e) {
+			return nil, nil
+		}
+		return nil, e
+	}
+	xattrs := make(map[string][]byte, len(list))
+	for _, name := range list {
+		// filter out system specific xattr
+		if runtime.GOOS == "linux" && strings.HasPrefix(name, "system.") {
+			continue
+		}
+		value, e := xattr.Get(path, name)
+		if e != nil {
+			if isNotSupported(e) {
+				return nil, nil
+			}
+			return nil, e
+		}
+		xattrs[name] = value
+	}
+	return xattrs, nil
+}
+
+// setXattr sets an extended attribute on a file.
+func setXattr(path, name string, value []byte) error {
+	return xattr.Set(path, name, value)
+}
@@ -0,0 +1,35 @@
+//go:build !linux && !darwin
+
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "errors"
+
+// xattrSupported reports if extended attributes can be preserved on this platform.
+const xattrSupported = false
+
+var errXattrNotSupported = errors.New("extended attributes are not supported on this platform")
+
+func listXattrs(_ string) (map[string][]byte, error) {
+	return nil, errXattrNotSupported
+}
+
+func setXattr(_, _ string, _ []byte) error {
+	return errXattrNotSupported
+}
@@ -18,10 +18,12 @@
 package disk
 
 import (
+	"os"
 	"os/user"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 // GetFileSystemAttrs return the file system attribute as string; containing mode,
@@ -62,3 +64,14 @@ func GetFileSystemAttrs(file string) (string, error) {
 
 	return fileAttr.String(), nil
 }
+
+// FileTimes returns the access and status change times of a file.
+func FileTimes(fi os.FileInfo) (atime, ctime time.Time) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return atime, ctime
+	}
+	atime = time.Unix(int64(st.Atimespec.Sec), int64(st.Atimespec.Nsec))
+	ctime = time.Unix(int64(st.Ctimespec.Sec), int64(st.Ctimespec.Nsec))
+	return atime, ctime
+}
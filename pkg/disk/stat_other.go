@@ -21,10 +21,12 @@
 package disk
 
 import (
+	"os"
 	"os/user"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 // GetFileSystemAttrs return the file system attribute as string; containing mode,
@@ -63,3 +65,14 @@ func GetFileSystemAttrs(file string) (string, error) {
 
 	return fileAttr.String(), nil
 }
+
+// FileTimes returns the access and status change times of a file.
+func FileTimes(fi os.FileInfo) (atime, ctime time.Time) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return atime, ctime
+	}
+	atime = time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec))
+	ctime = time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec))
+	return atime, ctime
+}
@@ -20,8 +20,23 @@
 
 package disk
 
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
 // GetFileSystemAttrs return the file system attribute as string; containing mode,
 // uid, gid, uname, Gname, atime, mtime, ctime and md5
 func GetFileSystemAttrs(file string) (string, error) {
 	return "", nil
 }
+
+// FileTimes returns the access time of a file, Windows does not record
+// status change times.
+func FileTimes(fi os.FileInfo) (atime, ctime time.Time) {
+	if d, ok := fi.Sys().(*syscall.Win32FileAttributeData); ok {
+		atime = time.Unix(0, d.LastAccessTime.Nanoseconds())
+	}
+	return atime, ctime
+}
@@ -21,7 +21,9 @@ package limiter
 import (
 	"errors"
 	"io"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/juju/ratelimit"
 )
@@ -68,8 +70,9 @@ func (l limiter) RoundTrip(req *http.Request) (res *http.Response, err error) {
 	return res, err
 }
 
-// New return a ratelimited transport
-func New(uploadLimit, downloadLimit int64, transport http.RoundTripper) http.RoundTripper {
+// New return a ratelimited transport, burst is the capacity of the token
+// buckets, when not positive it defaults to one second worth of the rate.
+func New(uploadLimit, downloadLimit, burst int64, transport http.RoundTripper) http.RoundTripper {
 	if uploadLimit == 0 && downloadLimit == 0 {
 		return transport
 	}
@@ -80,11 +83,11 @@ func New(uploadLimit, downloadLimit int64, transport http.RoundTripper) http.Rou
 	)
 
 	if uploadLimit > 0 {
-		uploadBucket = ratelimit.NewBucketWithRate(float64(uploadLimit), uploadLimit)
+		uploadBucket = ratelimit.NewBucketWithRate(float64(uploadLimit), bucketCapacity(uploadLimit, burst))
 	}
 
 	if downloadLimit > 0 {
-		downloadBucket = ratelimit.NewBucketWithRate(float64(downloadLimit), downloadLimit)
+		downloadBucket = ratelimit.NewBucketWithRate(float64(downloadLimit), bucketCapacity(downloadLimit, burst))
 	}
 
 	return &limiter{
@@ -93,3 +96,42 @@ func New(uploadLimit, downloadLimit int64, transport http.RoundTripper) http.Rou
 		transport: transport,
 	}
 }
+
+type requestLimiter struct {
+	requests  *ratelimit.Bucket
+	transport http.RoundTripper
+}
+
+// RoundTrip waits until the request rate allows req before sending it.
+func (l requestLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := l.requests.Take(1); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	return l.transport.RoundTrip(req)
+}
+
+// NewRequestRate returns a transport sending at most rate requests per
+// second, in bursts of up to one second worth of requests.
+func NewRequestRate(rate float64, transport http.RoundTripper) http.RoundTripper {
+	if rate <= 0 {
+		return transport
+	}
+	return requestLimiter{
+		requests:  ratelimit.NewBucketWithRate(rate, int64(math.Ceil(rate))),
+		transport: transport,
+	}
+}
+
+// bucketCapacity returns the number of tokens a bucket filling at rate can hold.
+func bucketCapacity(rate, burst int64) int64 {
+	if burst > 0 {
+		return burst
+	}
+	return rate
+}
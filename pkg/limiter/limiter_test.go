@@ -0,0 +1,100 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package limiter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// echoTransport returns the body of each request as the body of its
+// response, reading the request body in full first.
+type echoTransport struct{}
+
+func (echoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var e error
+		if body, e = io.ReadAll(req.Body); e != nil {
+			return nil, e
+		}
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func TestNew(t *testing.T) {
+	if transport := New(0, 0, 0, echoTransport{}); transport != (echoTransport{}) {
+		t.Fatal("expected no limit to return the transport")
+	}
+
+	// 600 bytes at 1000 bytes per second, the first 100 in a burst.
+	transfer := func(transport http.RoundTripper) time.Duration {
+		start := time.Now()
+		req, _ := http.NewRequest(http.MethodPut, "http://localhost/bucket/object", bytes.NewReader(make([]byte, 600)))
+		res, e := transport.RoundTrip(req)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if _, e = io.Copy(io.Discard, res.Body); e != nil {
+			t.Fatal(e)
+		}
+		return time.Since(start)
+	}
+	if elapsed := transfer(New(1000, 0, 100, echoTransport{})); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("expected the upload to take about 500ms, took %s", elapsed)
+	}
+	if elapsed := transfer(New(0, 1000, 100, echoTransport{})); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("expected the download to take about 500ms, took %s", elapsed)
+	}
+	// Without a burst the buckets hold one second worth of the rate.
+	if elapsed := transfer(New(1000, 1000, 0, echoTransport{})); elapsed > 200*time.Millisecond {
+		t.Errorf("expected the transfer to fit in the default burst, took %s", elapsed)
+	}
+}
+
+func TestNewRequestRate(t *testing.T) {
+	if transport := NewRequestRate(0, echoTransport{}); transport != (echoTransport{}) {
+		t.Fatal("expected no limit to return the transport")
+	}
+
+	// 15 requests at 10 per second, the first 10 in a burst.
+	transport := NewRequestRate(10, echoTransport{})
+	start := time.Now()
+	for i := 0; i < 15; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+		if _, e := transport.RoundTrip(req); e != nil {
+			t.Fatal(e)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("expected the requests to take about 500ms, took %s", elapsed)
+	}
+
+	// A request waiting for its turn is canceled with its context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/bucket/object", nil)
+	if _, e := transport.RoundTrip(req); !errors.Is(e, context.DeadlineExceeded) {
+		t.Errorf("expected the request to be canceled, got %v", e)
+	}
+}
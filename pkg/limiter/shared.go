@@ -0,0 +1,231 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package limiter
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// sharedStateSize is the size of the token bucket state persisted in
+// the shared file: available tokens (float64) and the last refill time
+// in unix nanoseconds (int64).
+const sharedStateSize = 16
+
+// maxSharedChunk bounds how many bytes are accounted per lock
+// acquisition, keeping the shared file lock short lived.
+const maxSharedChunk = 32 << 10
+
+// sharedBucket is a token bucket whose state lives in a file on the
+// local host. Every process opening the same file draws tokens from
+// the same bucket, so the configured rate applies to all of them.
+type sharedBucket struct {
+	mu       sync.Mutex
+	file     *os.File
+	rate     float64
+	capacity float64
+}
+
+// openSharedFile opens the state file at path, created readable and
+// writable by its owner only. A symbolic link or anything but a regular
+// file is refused, not to write the state through it.
+func openSharedFile(path string) (*os.File, error) {
+	f, e := os.OpenFile(path, os.O_RDWR|os.O_CREATE|oNoFollow, 0o600)
+	if e != nil {
+		return nil, e
+	}
+	st, e := f.Stat()
+	if e != nil {
+		f.Close()
+		return nil, e
+	}
+	if !st.Mode().IsRegular() {
+		f.Close()
+		return nil, errors.New("shared limit state " + path + " is not a regular file")
+	}
+
+	// Make sure locking works on this file before relying on it.
+	if e = lockFile(f); e != nil {
+		f.Close()
+		return nil, e
+	}
+	unlockFile(f)
+	return f, nil
+}
+
+// take blocks until n tokens were taken from the shared bucket.
+func (b *sharedBucket) take(n int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	need := float64(n)
+	for {
+		wait, e := b.takeLocked(need)
+		if e != nil {
+			return e
+		}
+		if wait == 0 {
+			return nil
+		}
+		time.Sleep(wait)
+	}
+}
+
+// takeLocked takes the requested tokens when available, otherwise it
+// returns how long to wait before trying again.
+func (b *sharedBucket) takeLocked(need float64) (time.Duration, error) {
+	if e := lockFile(b.file); e != nil {
+		return 0, e
+	}
+	defer unlockFile(b.file)
+
+	var state [sharedStateSize]byte
+	now := time.Now().UnixNano()
+	tokens, last := b.capacity, now
+	if _, e := b.file.ReadAt(state[:], 0); e == nil {
+		tokens = math.Float64frombits(binary.LittleEndian.Uint64(state[:8]))
+		last = int64(binary.LittleEndian.Uint64(state[8:]))
+	} else if e != io.EOF {
+		return 0, e
+	}
+
+	if elapsed := now - last; elapsed > 0 {
+		tokens += b.rate * float64(elapsed) / float64(time.Second)
+	}
+	if tokens > b.capacity || math.IsNaN(tokens) {
+		tokens = b.capacity
+	}
+
+	var wait time.Duration
+	if tokens >= need {
+		tokens -= need
+	} else {
+		wait = time.Duration((need - tokens) / b.rate * float64(time.Second))
+		if wait <= 0 {
+			wait = time.Millisecond
+		}
+	}
+
+	binary.LittleEndian.PutUint64(state[:8], math.Float64bits(tokens))
+	binary.LittleEndian.PutUint64(state[8:], uint64(now))
+	if _, e := b.file.WriteAt(state[:], 0); e != nil {
+		return 0, e
+	}
+	return wait, nil
+}
+
+type sharedReader struct {
+	r      io.Reader
+	bucket *sharedBucket
+}
+
+func (s sharedReader) Read(p []byte) (int, error) {
+	// A chunk never exceeds the bucket capacity, otherwise it
+	// could never be taken.
+	chunk := int(s.bucket.capacity)
+	if chunk > maxSharedChunk {
+		chunk = maxSharedChunk
+	}
+	if chunk > 0 && len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, e := s.r.Read(p)
+	if n > 0 {
+		if te := s.bucket.take(int64(n)); te != nil {
+			return n, te
+		}
+	}
+	return n, e
+}
+
+type sharedLimiter struct {
+	upload    *sharedBucket
+	transport http.RoundTripper
+}
+
+// RoundTrip limits the request body against the shared upload bucket.
+func (l sharedLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if l.transport == nil {
+		return nil, errors.New("Invalid Argument")
+	}
+
+	type readCloser struct {
+		io.Reader
+		io.Closer
+	}
+
+	if req.Body != nil {
+		req.Body = &readCloser{
+			Reader: sharedReader{r: req.Body, bucket: l.upload},
+			Closer: req.Body,
+		}
+	}
+	return l.transport.RoundTrip(req)
+}
+
+// sharedBucketKey - the buckets of a state file by rate and capacity,
+// clients limited differently sharing the file and its handle.
+type sharedBucketKey struct {
+	path           string
+	rate, capacity float64
+}
+
+var (
+	sharedBucketsMu sync.Mutex
+	sharedFiles     = map[string]*os.File{}
+	sharedBuckets   = map[sharedBucketKey]*sharedBucket{}
+)
+
+// NewShared returns a transport whose uploads are limited by a token
+// bucket shared with all other processes using the same state file.
+// An error is returned when the state file cannot be opened or locked,
+// callers are expected to fall back to per-process limiting.
+func NewShared(path string, uploadLimit, burst int64, transport http.RoundTripper) (http.RoundTripper, error) {
+	if uploadLimit <= 0 {
+		return transport, nil
+	}
+
+	sharedBucketsMu.Lock()
+	defer sharedBucketsMu.Unlock()
+
+	key := sharedBucketKey{path: path, rate: float64(uploadLimit), capacity: float64(bucketCapacity(uploadLimit, burst))}
+	bucket, ok := sharedBuckets[key]
+	if !ok {
+		f, ok := sharedFiles[path]
+		if !ok {
+			var e error
+			if f, e = openSharedFile(path); e != nil {
+				return nil, e
+			}
+			sharedFiles[path] = f
+		}
+		bucket = &sharedBucket{file: f, rate: key.rate, capacity: key.capacity}
+		sharedBuckets[key] = bucket
+	}
+
+	return &sharedLimiter{
+		upload:    bucket,
+		transport: transport,
+	}, nil
+}
@@ -0,0 +1,39 @@
+//go:build !unix
+
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package limiter
+
+import (
+	"errors"
+	"os"
+)
+
+var errLockNotSupported = errors.New("file locking is not supported on this platform")
+
+// oNoFollow is not supported, the state file is never used without
+// locking anyway.
+const oNoFollow = 0
+
+func lockFile(_ *os.File) error {
+	return errLockNotSupported
+}
+
+func unlockFile(_ *os.File) error {
+	return errLockNotSupported
+}
@@ -0,0 +1,98 @@
+//go:build unix
+
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package limiter
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSharedStateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state")
+	if _, e := NewShared(path, 1<<20, 0, http.DefaultTransport); e != nil {
+		t.Fatal(e)
+	}
+	st, e := os.Stat(path)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if perm := st.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected the state file to be private, got %o", perm)
+	}
+
+	// Limits differing by rate share the handle of the file.
+	if _, e = NewShared(path, 2<<20, 0, http.DefaultTransport); e != nil {
+		t.Fatal(e)
+	}
+	handles := 0
+	for _, b := range sharedBuckets {
+		if b.file == sharedFiles[path] {
+			handles++
+		}
+	}
+	if handles != 2 || len(sharedFiles) != 1 {
+		t.Fatalf("expected 2 buckets sharing 1 file, got %d buckets and %d files", handles, len(sharedFiles))
+	}
+
+	link := filepath.Join(dir, "link")
+	if e = os.Symlink(filepath.Join(dir, "target"), link); e != nil {
+		t.Fatal(e)
+	}
+	if _, e = NewShared(link, 1<<20, 0, http.DefaultTransport); e == nil {
+		t.Fatal("expected a symbolic link to be refused")
+	}
+	if _, e = os.Lstat(filepath.Join(dir, "target")); !os.IsNotExist(e) {
+		t.Fatal("expected the target of the link not to be created")
+	}
+	if _, e = NewShared(dir, 1<<20, 0, http.DefaultTransport); e == nil {
+		t.Fatal("expected a directory to be refused")
+	}
+}
+
+func TestSharedRate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	// Two limits with different bursts are two buckets drawing from
+	// the same state.
+	var buckets []*sharedBucket
+	for _, burst := range []int64{10 << 10, 20 << 10} {
+		transport, e := NewShared(path, 100<<10, burst, http.DefaultTransport)
+		if e != nil {
+			t.Fatal(e)
+		}
+		buckets = append(buckets, transport.(*sharedLimiter).upload)
+	}
+
+	start := time.Now()
+	for _, b := range buckets {
+		if _, e := io.Copy(io.Discard, sharedReader{r: bytes.NewReader(make([]byte, 30<<10)), bucket: b}); e != nil {
+			t.Fatal(e)
+		}
+	}
+	// 60 KiB at 100 KiB/s, less the 10 KiB of the first burst.
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 3*time.Second {
+		t.Fatalf("expected about 500ms to read 60 KiB, took %s", elapsed)
+	}
+}